@@ -20,6 +20,7 @@ type VendingConfig struct {
 	ControllerBoardLock1Cmd        string
 	ControllerBoardLock2Cmd        string
 	CardReaderDeviceName           string
+	CardReaderPINPromptCmd         string // optional, the card reader is not asked to collect PINs when empty
 	InferenceDeviceName            string
 	ControllerBoardDeviceName      string
 	DoorCloseStateTimeoutDuration  string
@@ -28,9 +29,12 @@ type VendingConfig struct {
 	InferenceHeartbeatCmd          string
 	InferenceTimeoutDuration       string
 	InventoryAuditLogService       string
+	InventoryExpiringService       string // optional, expired lots are not shown to stockers when empty
 	InventoryService               string
 	LCDRowLength                   int
 	LedgerService                  string
+	MachineID                      string // optional, identifies this machine to an inventory service that serves a fleet
+	PINEntryTimeoutDuration        string // optional, defaults to 30s
 }
 
 // UpdateFromRaw updates the service's full configuration from raw data received from
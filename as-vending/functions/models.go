@@ -32,6 +32,18 @@ type VendingState struct {
 	DoorCloseStateTimeout          time.Duration
 	DoorOpenStateTimeout           time.Duration
 	InferenceTimeout               time.Duration
+	// CardDenialStatus is the lifecycle status of the last card that the
+	// authentication service refused, such as "expired" or "suspended"
+	CardDenialStatus string `json:"cardDenialStatus"`
+	// PINPendingUserData is the authenticated card that waits for its PIN
+	// until PINDeadline, before it becomes the CurrentUserData
+	PINPendingUserData OutputData `json:"-"`
+	PINDeadline        time.Time  `json:"-"`
+	PINEntryTimeout    time.Duration
+	// VendSessionID identifies the vend that started when a card was last
+	// granted access. It is the idempotency key of the ledger transaction of
+	// the vend, so that a retried post does not charge the account twice
+	VendSessionID string `json:"-"`
 }
 
 // MaintenanceMode is a simple structure used to return the state of
@@ -54,11 +66,15 @@ type ControllerBoardStatus struct {
 }
 
 // Ledger is the data structure that represents financial ledger transactions,
-// and comes from the ledger service.
+// and comes from the ledger service. Amounts are in minor units of Currency,
+// such as cents for USD, and GrandTotal includes TaxTotal.
 type Ledger struct {
 	TransactionID int64      `json:"transactionID,string"`
 	TxTimeStamp   int64      `json:"txTimeStamp,string"`
-	LineTotal     float64    `json:"lineTotal"`
+	LineTotal     int64      `json:"lineTotal"`
+	TaxTotal      int64      `json:"taxTotal"`
+	GrandTotal    int64      `json:"grandTotal"`
+	Currency      string     `json:"currency"`
 	CreatedAt     int64      `json:"createdAt,string"`
 	UpdatedAt     int64      `json:"updatedAt,string"`
 	IsPaid        bool       `json:"isPaid"`
@@ -67,10 +83,11 @@ type Ledger struct {
 
 // LineItem is a single item contained in the Ledger.
 type LineItem struct {
-	SKU         string  `json:"sku"`
-	ProductName string  `json:"productName"`
-	ItemPrice   float64 `json:"itemPrice"`
-	ItemCount   int     `json:"itemCount"`
+	SKU         string `json:"sku"`
+	ProductName string `json:"productName"`
+	ItemPrice   int64  `json:"itemPrice"`
+	ItemCount   int    `json:"itemCount"`
+	Currency    string `json:"currency"`
 }
 
 // deltaLedger is a representation of a set of deltaSKUs from an upstream
@@ -84,8 +101,9 @@ type deltaLedger struct {
 // specific SKU in inventory. An inference will produce a list of deltaSKUs
 // when someone removes items from inventory.
 type deltaSKU struct {
-	SKU   string `json:"SKU"`
-	Delta int    `json:"delta"`
+	SKU       string `json:"SKU"`
+	Delta     int    `json:"delta"`
+	MachineID string `json:"machineId,omitempty"`
 }
 
 // OutputData represents the authentication information associated with
@@ -97,6 +115,70 @@ type OutputData struct {
 	PersonID  int    `json:"personID"`
 	RoleID    int    `json:"roleID"`
 	CardID    string `json:"cardID"`
+	// PINRequired is true when the role of the card requires its PIN to be
+	// verified before it is granted access
+	PINRequired bool `json:"pinRequired,omitempty"`
+	// RoleName and Permissions are the name and permissions of the role of
+	// the card. Permissions is nil when the authentication service does not
+	// return permissions, and the permissions of the role ID apply
+	RoleName    string   `json:"roleName,omitempty"`
+	Permissions []string `json:"permissions"`
+}
+
+// Permissions of the roles of the authentication service that this
+// application service acts on
+const (
+	PermissionUnlockLock1      = "unlock:lock1"
+	PermissionUnlockLock2      = "unlock:lock2"
+	PermissionBillable         = "billable"
+	PermissionRestock          = "restock"
+	PermissionMaintenanceClear = "maintenance:clear"
+)
+
+// legacyRolePermissions are the permissions of the role IDs of an
+// authentication service that does not return permissions: customers are
+// charged for what they take, stockers restock and maintainers end
+// maintenance mode
+var legacyRolePermissions = map[int][]string{
+	1: {PermissionUnlockLock1, PermissionBillable},
+	2: {PermissionUnlockLock1, PermissionRestock},
+	3: {PermissionUnlockLock1, PermissionMaintenanceClear},
+}
+
+// HasPermission returns true if the role of the authenticated card has the
+// permission
+func (data OutputData) HasPermission(permission string) bool {
+	permissions := data.Permissions
+	if permissions == nil {
+		permissions = legacyRolePermissions[data.RoleID]
+	}
+	for _, p := range permissions {
+		if p == permission {
+			return true
+		}
+	}
+	return false
+}
+
+// PINVerification is the verification of a PIN entered for a card, which
+// the card reader gets from the authentication service together with its
+// status code, with the wrong PINs that may still be entered before the PIN
+// locks.
+type PINVerification struct {
+	CardID            string `json:"cardID"`
+	StatusCode        int    `json:"statusCode"`
+	Verified          bool   `json:"verified"`
+	AttemptsRemaining int    `json:"attemptsRemaining"`
+	Status            string `json:"status"`
+}
+
+// CardDenial is the response of the authentication service for a known card
+// that cannot be used, because it is outside its validity window, suspended
+// or revoked.
+type CardDenial struct {
+	CardID  string `json:"cardID"`
+	Status  string `json:"status"`
+	Message string `json:"message"`
 }
 
 // AuditLogEntry is the representation of an inventory transaction that
@@ -110,6 +192,22 @@ type AuditLogEntry struct {
 	InventoryDelta []deltaSKU `json:"inventoryDelta"`
 	CreatedAt      int64      `json:"createdAt,string"`
 	AuditEntryID   string     `json:"auditEntryId"`
+	MachineID      string     `json:"machineId,omitempty"`
+}
+
+// ExpiringLots is the report of expiring and expired lots that comes from
+// the inventory service.
+type ExpiringLots struct {
+	Data []ExpiringLot `json:"data"`
+}
+
+// ExpiringLot is a single lot in the ExpiringLots report. Lots that have
+// expired are flagged for removal during the next stocker session.
+type ExpiringLot struct {
+	SKU               string `json:"sku"`
+	ProductName       string `json:"productName"`
+	Quantity          int    `json:"quantity"`
+	FlaggedForRemoval bool   `json:"flaggedForRemoval"`
 }
 
 func (vs *VendingState) ParseDurationFromConfig() error {
@@ -128,5 +226,14 @@ func (vs *VendingState) ParseDurationFromConfig() error {
 	if err != nil {
 		return fmt.Errorf("failed to parse InferenceTimeoutDuration configuration: %v", err)
 	}
+
+	// PINEntryTimeoutDuration is optional, a PIN may be entered for 30s
+	// after its card when it is not set
+	if vs.Configuration.PINEntryTimeoutDuration != "" {
+		vs.PINEntryTimeout, err = time.ParseDuration(vs.Configuration.PINEntryTimeoutDuration)
+		if err != nil {
+			return fmt.Errorf("failed to parse PINEntryTimeoutDuration configuration: %v", err)
+		}
+	}
 	return nil
 }
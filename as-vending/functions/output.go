@@ -6,22 +6,40 @@ package functions
 import (
 	"bytes"
 	"context"
+	"crypto/rand"
+	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
 	"strconv"
 	"time"
 
 	"github.com/edgexfoundry/app-functions-sdk-go/v3/pkg/interfaces"
 	"github.com/edgexfoundry/go-mod-core-contracts/v3/clients/logger"
 	"github.com/edgexfoundry/go-mod-core-contracts/v3/dtos"
+	"golang.org/x/text/currency"
 )
 
 const (
 	InferenceMQTTDevice = "Inference-device"
 	DsCardReader        = "card-reader"
+	// CardReaderPINVerificationResource is the resource of the card reader
+	// readings with the verification of the PIN entered after a card whose
+	// role requires a PIN. The card reader verifies the PIN itself, so that
+	// the PIN is never sent as a reading
+	CardReaderPINVerificationResource = "pin-verification"
+	// defaultPINEntryTimeout is how long a PIN may be entered after a card
+	// when PINEntryTimeoutDuration is not configured
+	defaultPINEntryTimeout = 30 * time.Second
+	// idempotencyKeyHeader is the header with the idempotency key of a ledger
+	// transaction, which the ledger service replays instead of posting twice
+	idempotencyKeyHeader = "Idempotency-Key"
+	// defaultMachineID is the machine ID the inventory service reports the
+	// stock of a machine without a configured MachineID under
+	defaultMachineID = "default"
 )
 
 // DeviceHelper is an EdgeX function that is passed into the EdgeX SDK's function pipeline.
@@ -80,6 +98,10 @@ func (vendingState *VendingState) HandleMqttDeviceReading(lc logger.LoggingClien
 						lc.Error("Inference Failed")
 						return false, err
 					}
+					// attribute the stock changes to this machine of the fleet
+					for i := range skuDelta {
+						skuDelta[i].MachineID = vendingState.Configuration.MachineID
+					}
 
 					// do some things with the skuDelta
 					// example:
@@ -94,7 +116,8 @@ func (vendingState *VendingState) HandleMqttDeviceReading(lc logger.LoggingClien
 					close(vendingState.InferenceWaitThreadStopChannel)
 					vendingState.InferenceWaitThreadStopChannel = make(chan int)
 
-					if vendingState.CurrentUserData.RoleID == 1 {
+					// only cards with a billable role are charged for what they take
+					if vendingState.CurrentUserData.HasPermission(PermissionBillable) {
 						// POST the deltaLedger json string to the ledger endpoint
 						outputBytes, err := json.Marshal(deltaLedger)
 						if err != nil {
@@ -104,7 +127,9 @@ func (vendingState *VendingState) HandleMqttDeviceReading(lc logger.LoggingClien
 
 						lc.Info("Sending SKU delta to ledger service")
 						// send SKU delta to ledger service and get back current ledger information
-						resp, err := sendHTTPRequest(lc, http.MethodPost, vendingState.Configuration.LedgerService, outputBytes)
+						resp, err := sendHTTPRequestWithHeaders(lc, http.MethodPost, vendingState.Configuration.LedgerService, outputBytes, map[string]string{
+							idempotencyKeyHeader: vendingState.VendSessionID,
+						})
 						if err != nil {
 							lc.Errorf("Ledger service failed: %s", err.Error())
 							return false, err
@@ -129,19 +154,9 @@ func (vendingState *VendingState) HandleMqttDeviceReading(lc logger.LoggingClien
 
 					}
 
-					// POST the deltaLedger json string to the inventory endpoint
-					outputBytes, err := json.Marshal(deltaLedger.DeltaSKUs)
-					if err != nil {
-						return false, fmt.Errorf("HandleMqttDeviceReading failed to marshal deltaLedger.DeltaSKUs")
-					}
-
-					lc.Info("Sending SKU delta to inventory service")
-					inventoryResp, err := sendHTTPRequest(lc, http.MethodPost, vendingState.Configuration.InventoryService, outputBytes)
-					if err != nil {
-						return false, err
-					}
-					defer inventoryResp.Body.Close()
-					// Post an audit log entry for this transaction, regardless of ledger or not
+					// Post an audit log entry for this transaction, regardless of ledger or not.
+					// It is posted before the inventory delta so that stock anomalies caused
+					// by the delta can be traced back to the audit log entry of the session
 					auditLogEntry := AuditLogEntry{
 						AccountID:      vendingState.CurrentUserData.AccountID,
 						CardID:         vendingState.CurrentUserData.CardID,
@@ -149,9 +164,10 @@ func (vendingState *VendingState) HandleMqttDeviceReading(lc logger.LoggingClien
 						PersonID:       vendingState.CurrentUserData.PersonID,
 						InventoryDelta: deltaLedger.DeltaSKUs,
 						CreatedAt:      time.Now().UnixNano(),
+						MachineID:      vendingState.Configuration.MachineID,
 					}
 
-					outputBytes, err = json.Marshal(auditLogEntry)
+					outputBytes, err := json.Marshal(auditLogEntry)
 					if err != nil {
 						return false, err
 					}
@@ -162,6 +178,27 @@ func (vendingState *VendingState) HandleMqttDeviceReading(lc logger.LoggingClien
 						return false, err
 					}
 					defer auditResp.Body.Close()
+					var postedAuditLogEntry AuditLogEntry
+					if body, err := io.ReadAll(auditResp.Body); err != nil || json.Unmarshal(body, &postedAuditLogEntry) != nil {
+						lc.Warn("Failed to read the posted audit log entry, the inventory delta will not be linked to it")
+					}
+
+					// POST the deltaLedger json string to the inventory endpoint
+					outputBytes, err = json.Marshal(deltaLedger.DeltaSKUs)
+					if err != nil {
+						return false, fmt.Errorf("HandleMqttDeviceReading failed to marshal deltaLedger.DeltaSKUs")
+					}
+
+					inventoryService := vendingState.Configuration.InventoryService
+					if postedAuditLogEntry.AuditEntryID != "" {
+						inventoryService += "?auditEntryId=" + url.QueryEscape(postedAuditLogEntry.AuditEntryID)
+					}
+					lc.Info("Sending SKU delta to inventory service")
+					inventoryResp, err := sendHTTPRequest(lc, http.MethodPost, inventoryService, outputBytes)
+					if err != nil {
+						return false, err
+					}
+					defer inventoryResp.Body.Close()
 					vendingState.CurrentUserData = OutputData{}
 					vendingState.CVWorkflowStarted = false
 					lc.Info("Inference complete and workflow status reset")
@@ -214,145 +251,205 @@ func (vendingState *VendingState) VerifyDoorAccess(lc logger.LoggingClient, even
 				return false, fmt.Errorf("event reading was empty, devicename: %s, resourcename: %s", eventReading.DeviceName, eventReading.ResourceName)
 			}
 
+			// the PIN of a card whose role requires one is read after the card
+			if eventReading.ResourceName == CardReaderPINVerificationResource {
+				if err := vendingState.handlePINVerification(lc, eventReading.Value); err != nil {
+					return false, err
+				}
+				continue
+			}
+
+			// a new card ends the wait for the PIN of the previous card
+			vendingState.PINPendingUserData = OutputData{}
+
 			// Retrieve & Hit auth endpoint
 			vendingState.getCardAuthInfo(lc, vendingState.Configuration.AuthenticationEndpoint, eventReading.Value)
 
-			switch vendingState.CurrentUserData.RoleID {
-			// Check the role of the card scanned. Role 1 = customer and Role 2 = item stocker
-			case 1, 2:
-				{
-					if !vendingState.MaintenanceMode {
-						lc.Infof("%s readable value from %s is %s", eventReading.ResourceName, eventReading.DeviceName, eventReading.Value)
-						// display "hello" on row 2
-						settings := make(map[string]string)
-						settings["displayRow2"] = "hello"
-						err := vendingState.SendCommand(lc, http.MethodPut, vendingState.Configuration.ControllerBoardDeviceName, vendingState.Configuration.ControllerBoardDisplayRow2Cmd, settings)
-						if err != nil {
-							return false, err
-						}
+			if vendingState.CurrentUserData.PINRequired {
+				if err := vendingState.promptForPIN(lc); err != nil {
+					return false, err
+				}
+				continue
+			}
 
-						settings = make(map[string]string)
-						settings["displayRow3"] = eventReading.Value
-						// display the card number on row 3
-						err = vendingState.SendCommand(lc, http.MethodPut, vendingState.Configuration.ControllerBoardDeviceName, vendingState.Configuration.ControllerBoardDisplayRow3Cmd, settings)
-						if err != nil {
-							return false, err
-						}
+			if err := vendingState.grantAccess(lc, eventReading.Value); err != nil {
+				return false, err
+			}
+		}
+	}
+	return true, event // Continues the functions pipeline execution with the current event
+}
 
-						settings = make(map[string]string)
-						settings["lock1"] = "true"
-						// unlock
-						err = vendingState.SendCommand(lc, http.MethodPut, vendingState.Configuration.ControllerBoardDeviceName, vendingState.Configuration.ControllerBoardLock1Cmd, settings)
-						if err != nil {
-							return false, err
-						}
+// grantAccess acts on the permissions of the role of the authenticated card
+// with the card ID: it ends maintenance mode for cards that may clear it, and
+// unlocks the locks that other cards may unlock. Any other card is refused
+func (vendingState *VendingState) grantAccess(lc logger.LoggingClient, cardID string) error {
+	userData := vendingState.CurrentUserData
+	switch {
+	// Check the permissions of the card scanned, maintainers may clear maintenance mode
+	case userData.HasPermission(PermissionMaintenanceClear):
+		{
+			close(vendingState.ThreadStopChannel)
+			vendingState.ThreadStopChannel = make(chan int)
 
-						// Start the workflow state and set all of the thread states to false
-						vendingState.CVWorkflowStarted = true
-						vendingState.DoorClosedDuringCVWorkflow = false
-						vendingState.DoorOpenedDuringCVWorkflow = false
-						vendingState.InferenceDataReceived = false
-
-						// Wait for the door open event to be received. If we don't receive the door open event within the timeout
-						// then leave the workflow state and remove all user data
-						go func() {
-							for {
-								select {
-								case <-time.After(vendingState.DoorOpenStateTimeout):
-									if !vendingState.DoorOpenedDuringCVWorkflow {
-										lc.Info("door wasn't opened so we reset")
-										vendingState.CVWorkflowStarted = false
-										vendingState.CurrentUserData = OutputData{}
-									}
-
-									lc.Infof("Card Scan")
-									lc.Debugf("workflow: +%v", vendingState.CVWorkflowStarted)
-									lc.Debugf("maintenance mode: +%v", vendingState.MaintenanceMode)
-									lc.Debugf("open: +%v", vendingState.DoorOpenedDuringCVWorkflow)
-									lc.Debugf("closed: +%v", vendingState.DoorClosedDuringCVWorkflow)
-									lc.Debugf("Inference: +%v ", vendingState.InferenceDataReceived)
-									lc.Debugf("door: +%v", vendingState.DoorClosed)
-									return
-
-								case <-vendingState.DoorOpenWaitThreadStopChannel:
-									lc.Info("Stopped the door open wait thread")
-									return
-
-								case <-vendingState.ThreadStopChannel:
-									lc.Info("Globally stopped the door open wait thread")
-									return
-								}
-							}
-						}()
-					} else {
-						settings := make(map[string]string)
-						settings["displayRow1"] = "Out of Order"
-						// display out of order when door waiting state is set to false
-						err := vendingState.SendCommand(lc, http.MethodPut, vendingState.Configuration.ControllerBoardDeviceName, vendingState.Configuration.ControllerBoardDisplayRow1Cmd, settings)
-						if err != nil {
-							return false, err
-						}
-					}
+			lc.Infof("card %s was granted access with role %d %s", cardID, userData.RoleID, userData.RoleName)
+
+			// display text "Maintenance Mode" in row 2
+			settings := make(map[string]string)
+			settings["displayRow2"] = "Maintenance Mode"
+			err := vendingState.SendCommand(lc, http.MethodPut, vendingState.Configuration.ControllerBoardDeviceName, vendingState.Configuration.ControllerBoardDisplayRow2Cmd, settings)
+			if err != nil {
+				return err
+			}
+
+			// display any reading value in row 3
+			settings = make(map[string]string)
+			settings["displayRow3"] = cardID
+			err = vendingState.SendCommand(lc, http.MethodPut, vendingState.Configuration.ControllerBoardDeviceName, vendingState.Configuration.ControllerBoardDisplayRow3Cmd, settings)
+			if err != nil {
+				return err
+			}
 
+			// send lock command, if the card may unlock the door
+			if userData.HasPermission(PermissionUnlockLock1) {
+				settings = make(map[string]string)
+				settings["lock1"] = "true"
+				err = vendingState.SendCommand(lc, http.MethodPut, vendingState.Configuration.ControllerBoardDeviceName, vendingState.Configuration.ControllerBoardLock1Cmd, settings)
+				if err != nil {
+					return err
 				}
-			// Check the role of the card scanned. Role 3 = maintainer
-			case 3:
-				{
-					close(vendingState.ThreadStopChannel)
-					vendingState.ThreadStopChannel = make(chan int)
+			}
 
-					lc.Infof("%s readable value from %s is %s", eventReading.ResourceName, eventReading.DeviceName, eventReading.Value)
+			vendingState.MaintenanceMode = false
+			vendingState.CVWorkflowStarted = false
+			vendingState.DoorClosedDuringCVWorkflow = false
+			vendingState.DoorOpenedDuringCVWorkflow = false
+			vendingState.InferenceDataReceived = false
+			lc.Infof("Maintenance Scan")
+			lc.Debugf("workflow: +%v", vendingState.CVWorkflowStarted)
+			lc.Debugf("maintenance mode: +%v", vendingState.MaintenanceMode)
+			lc.Debugf("open: +%v", vendingState.DoorOpenedDuringCVWorkflow)
+			lc.Debugf("closed: +%v", vendingState.DoorClosedDuringCVWorkflow)
+			lc.Debugf("Inference: +%v ", vendingState.InferenceDataReceived)
+			lc.Debugf("door: +%v", vendingState.DoorClosed)
+		}
+	// Customers and item stockers may unlock the door
+	case userData.HasPermission(PermissionUnlockLock1) || userData.HasPermission(PermissionUnlockLock2):
+		{
+			if !vendingState.MaintenanceMode {
+				lc.Infof("card %s was granted access with role %d %s", cardID, userData.RoleID, userData.RoleName)
+				// display "hello" on row 2
+				settings := make(map[string]string)
+				settings["displayRow2"] = "hello"
+				err := vendingState.SendCommand(lc, http.MethodPut, vendingState.Configuration.ControllerBoardDeviceName, vendingState.Configuration.ControllerBoardDisplayRow2Cmd, settings)
+				if err != nil {
+					return err
+				}
 
-					// display text "Maintenance Mode" in row 2
-					settings := make(map[string]string)
-					settings["displayRow2"] = "Maintenance Mode"
-					err := vendingState.SendCommand(lc, http.MethodPut, vendingState.Configuration.ControllerBoardDeviceName, vendingState.Configuration.ControllerBoardDisplayRow2Cmd, settings)
-					if err != nil {
-						return false, err
-					}
+				settings = make(map[string]string)
+				settings["displayRow3"] = cardID
+				// display the card number on row 3
+				err = vendingState.SendCommand(lc, http.MethodPut, vendingState.Configuration.ControllerBoardDeviceName, vendingState.Configuration.ControllerBoardDisplayRow3Cmd, settings)
+				if err != nil {
+					return err
+				}
 
-					// display any reading value in row 3
-					settings = make(map[string]string)
-					settings["displayRow3"] = eventReading.Value
-					err = vendingState.SendCommand(lc, http.MethodPut, vendingState.Configuration.ControllerBoardDeviceName, vendingState.Configuration.ControllerBoardDisplayRow3Cmd, settings)
-					if err != nil {
-						return false, err
-					}
+				// unlock the locks that the card may unlock
+				if err := vendingState.unlockPermittedLocks(lc, userData); err != nil {
+					return err
+				}
 
-					// send lock command
-					settings = make(map[string]string)
-					settings["lock1"] = "true"
-					err = vendingState.SendCommand(lc, http.MethodPut, vendingState.Configuration.ControllerBoardDeviceName, vendingState.Configuration.ControllerBoardLock1Cmd, settings)
-					if err != nil {
-						return false, err
+				// item stockers are reminded to remove any expired lots
+				if userData.HasPermission(PermissionRestock) {
+					if err := vendingState.displayExpiredLots(lc); err != nil {
+						lc.Errorf("Failed to display expired lots: %s", err.Error())
 					}
-
-					vendingState.MaintenanceMode = false
-					vendingState.CVWorkflowStarted = false
-					vendingState.DoorClosedDuringCVWorkflow = false
-					vendingState.DoorOpenedDuringCVWorkflow = false
-					vendingState.InferenceDataReceived = false
-					lc.Infof("Maintenance Scan")
-					lc.Debugf("workflow: +%v", vendingState.CVWorkflowStarted)
-					lc.Debugf("maintenance mode: +%v", vendingState.MaintenanceMode)
-					lc.Debugf("open: +%v", vendingState.DoorOpenedDuringCVWorkflow)
-					lc.Debugf("closed: +%v", vendingState.DoorClosedDuringCVWorkflow)
-					lc.Debugf("Inference: +%v ", vendingState.InferenceDataReceived)
-					lc.Debugf("door: +%v", vendingState.DoorClosed)
 				}
-			default:
-				// display "Unauthorized" on display row 2
+
+				// Start the workflow state and set all of the thread states to false
+				vendingState.CVWorkflowStarted = true
+				vendingState.VendSessionID = newVendSessionID(vendingState.Configuration.MachineID)
+				vendingState.DoorClosedDuringCVWorkflow = false
+				vendingState.DoorOpenedDuringCVWorkflow = false
+				vendingState.InferenceDataReceived = false
+
+				// Wait for the door open event to be received. If we don't receive the door open event within the timeout
+				// then leave the workflow state and remove all user data
+				go func() {
+					for {
+						select {
+						case <-time.After(vendingState.DoorOpenStateTimeout):
+							if !vendingState.DoorOpenedDuringCVWorkflow {
+								lc.Info("door wasn't opened so we reset")
+								vendingState.CVWorkflowStarted = false
+								vendingState.CurrentUserData = OutputData{}
+							}
+
+							lc.Infof("Card Scan")
+							lc.Debugf("workflow: +%v", vendingState.CVWorkflowStarted)
+							lc.Debugf("maintenance mode: +%v", vendingState.MaintenanceMode)
+							lc.Debugf("open: +%v", vendingState.DoorOpenedDuringCVWorkflow)
+							lc.Debugf("closed: +%v", vendingState.DoorClosedDuringCVWorkflow)
+							lc.Debugf("Inference: +%v ", vendingState.InferenceDataReceived)
+							lc.Debugf("door: +%v", vendingState.DoorClosed)
+							return
+
+						case <-vendingState.DoorOpenWaitThreadStopChannel:
+							lc.Info("Stopped the door open wait thread")
+							return
+
+						case <-vendingState.ThreadStopChannel:
+							lc.Info("Globally stopped the door open wait thread")
+							return
+						}
+					}
+				}()
+			} else {
 				settings := make(map[string]string)
-				settings["displayRow2"] = "Unauthorized"
-				err := vendingState.SendCommand(lc, http.MethodPut, vendingState.Configuration.ControllerBoardDeviceName, vendingState.Configuration.ControllerBoardDisplayRow2Cmd, settings)
+				settings["displayRow1"] = "Out of Order"
+				// display out of order when door waiting state is set to false
+				err := vendingState.SendCommand(lc, http.MethodPut, vendingState.Configuration.ControllerBoardDeviceName, vendingState.Configuration.ControllerBoardDisplayRow1Cmd, settings)
 				if err != nil {
-					return false, err
+					return err
 				}
-				lc.Infof("Invalid card: %s", eventReading.Value)
 			}
+
 		}
+	default:
+		// display "Unauthorized", or why a known card was refused, on display row 2
+		settings := make(map[string]string)
+		settings["displayRow2"] = cardDenialDisplayText(vendingState.CardDenialStatus)
+		err := vendingState.SendCommand(lc, http.MethodPut, vendingState.Configuration.ControllerBoardDeviceName, vendingState.Configuration.ControllerBoardDisplayRow2Cmd, settings)
+		if err != nil {
+			return err
+		}
+		lc.Infof("Invalid card: %s", cardID)
 	}
-	return true, event // Continues the functions pipeline execution with the current event
+	return nil
+}
+
+// unlockPermittedLocks unlocks lock 1 and lock 2 if the role of the card may
+// unlock them
+func (vendingState *VendingState) unlockPermittedLocks(lc logger.LoggingClient, userData OutputData) error {
+	locks := []struct {
+		permission string
+		command    string
+	}{
+		{PermissionUnlockLock1, vendingState.Configuration.ControllerBoardLock1Cmd},
+		{PermissionUnlockLock2, vendingState.Configuration.ControllerBoardLock2Cmd},
+	}
+	for i, lock := range locks {
+		if !userData.HasPermission(lock.permission) {
+			continue
+		}
+		settings := make(map[string]string)
+		settings[fmt.Sprintf("lock%d", i+1)] = "true"
+		err := vendingState.SendCommand(lc, http.MethodPut, vendingState.Configuration.ControllerBoardDeviceName, lock.command, settings)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
 func (vendingState *VendingState) checkInferenceStatus(lc logger.LoggingClient, heartbeatEndPoint string, deviceName string) bool {
@@ -369,9 +466,14 @@ func (vendingState *VendingState) getCardAuthInfo(lc logger.LoggingClient, authE
 	// Push the authenticated user info to the current vendingState
 	// First, reset it, then populate it at the end of the function
 	vendingState.CurrentUserData = OutputData{}
+	vendingState.CardDenialStatus = ""
 
 	resp, err := sendHTTPRequest(lc, http.MethodGet, authEndpoint+"/"+cardID, []byte(""))
 	if err != nil {
+		if resp != nil && resp.StatusCode == http.StatusForbidden {
+			vendingState.readCardDenial(lc, resp, cardID)
+			return
+		}
 		lc.Infof("Unauthorized card: %s", cardID)
 		return
 	}
@@ -394,6 +496,131 @@ func (vendingState *VendingState) getCardAuthInfo(lc logger.LoggingClient, authE
 	lc.Info("Successfully found user data for card " + cardID)
 }
 
+// readCardDenial keeps the status of a known card that the authentication
+// service refused, so the display can tell the user why
+func (vendingState *VendingState) readCardDenial(lc logger.LoggingClient, resp *http.Response, cardID string) {
+	defer resp.Body.Close()
+	var denial CardDenial
+	if err := json.NewDecoder(resp.Body).Decode(&denial); err != nil {
+		lc.Errorf("Could not unmarshal card denial from AuthenticationEndpoint for card ID %s: %s", cardID, err.Error())
+		return
+	}
+	vendingState.CardDenialStatus = denial.Status
+	lc.Infof("Card %s was denied: %s", cardID, denial.Message)
+}
+
+// cardDenialDisplayText returns the text to show on the display for a card
+// with the denial status
+func cardDenialDisplayText(status string) string {
+	switch status {
+	case "expired":
+		return "Card expired"
+	case "notYetValid":
+		return "Card not active"
+	case "suspended":
+		return "Card suspended"
+	case "revoked":
+		return "Card revoked"
+	case "pinNotSet":
+		return "PIN not set"
+	case "pinLocked":
+		return "PIN locked"
+	}
+	return "Unauthorized"
+}
+
+// displayRow2 shows the text on display row 2 of the controller board
+func (vendingState *VendingState) displayRow2(lc logger.LoggingClient, text string) error {
+	settings := make(map[string]string)
+	settings["displayRow2"] = text
+	return vendingState.SendCommand(lc, http.MethodPut, vendingState.Configuration.ControllerBoardDeviceName, vendingState.Configuration.ControllerBoardDisplayRow2Cmd, settings)
+}
+
+// promptForPIN holds the authenticated card whose role requires a PIN until
+// its PIN is verified, and prompts for the PIN on the display and the card
+// reader
+func (vendingState *VendingState) promptForPIN(lc logger.LoggingClient) error {
+	vendingState.PINPendingUserData = vendingState.CurrentUserData
+	vendingState.CurrentUserData = OutputData{}
+	timeout := vendingState.PINEntryTimeout
+	if timeout <= 0 {
+		timeout = defaultPINEntryTimeout
+	}
+	vendingState.PINDeadline = time.Now().Add(timeout)
+	lc.Infof("Card %s requires a PIN", vendingState.PINPendingUserData.CardID)
+
+	if err := vendingState.displayRow2(lc, "Enter PIN"); err != nil {
+		return err
+	}
+	return vendingState.sendPINPrompt(lc, timeout)
+}
+
+// sendPINPrompt asks the card reader to read the next digits as the PIN of
+// the card that is waiting for one, when the card reader has a PIN prompt
+// command
+func (vendingState *VendingState) sendPINPrompt(lc logger.LoggingClient, timeout time.Duration) error {
+	promptCmd := vendingState.Configuration.CardReaderPINPromptCmd
+	if promptCmd == "" {
+		return nil
+	}
+	prompt, err := json.Marshal(map[string]string{
+		"cardID":  vendingState.PINPendingUserData.CardID,
+		"timeout": timeout.String(),
+	})
+	if err != nil {
+		return err
+	}
+	settings := make(map[string]string)
+	settings[promptCmd] = string(prompt)
+	return vendingState.SendCommand(lc, http.MethodPut, vendingState.Configuration.CardReaderDeviceName, promptCmd, settings)
+}
+
+// handlePINVerification acts on the verification of the PIN entered after
+// the card that is waiting for one, and grants the card access once its PIN
+// is verified. A wrong PIN may be entered again until the authentication
+// service locks the PIN
+func (vendingState *VendingState) handlePINVerification(lc logger.LoggingClient, value string) error {
+	pending := vendingState.PINPendingUserData
+	if pending.CardID == "" {
+		lc.Info("Ignoring a PIN that was entered without a card")
+		return nil
+	}
+	var verification PINVerification
+	if err := json.Unmarshal([]byte(value), &verification); err != nil {
+		return fmt.Errorf("could not unmarshal PIN verification: %s", err.Error())
+	}
+	if verification.CardID != pending.CardID {
+		lc.Infof("Ignoring the PIN of card %s while card %s is waiting for one", verification.CardID, pending.CardID)
+		return nil
+	}
+	if time.Now().After(vendingState.PINDeadline) {
+		vendingState.PINPendingUserData = OutputData{}
+		lc.Infof("The PIN of card %s was entered too late", pending.CardID)
+		return vendingState.displayRow2(lc, "PIN timed out")
+	}
+
+	switch statusCode := verification.StatusCode; {
+	case statusCode == http.StatusOK && verification.Verified:
+		vendingState.PINPendingUserData = OutputData{}
+		vendingState.CurrentUserData = pending
+		lc.Infof("Verified the PIN of card %s", pending.CardID)
+		return vendingState.grantAccess(lc, pending.CardID)
+	case statusCode == http.StatusUnauthorized:
+		lc.Infof("Wrong PIN for card %s, %d attempts remaining", pending.CardID, verification.AttemptsRemaining)
+		if err := vendingState.displayRow2(lc, fmt.Sprintf("Wrong PIN: %d left", verification.AttemptsRemaining)); err != nil {
+			return err
+		}
+		return vendingState.sendPINPrompt(lc, time.Until(vendingState.PINDeadline))
+	case statusCode == http.StatusLocked:
+		vendingState.PINPendingUserData = OutputData{}
+		lc.Infof("The PIN of card %s is locked", pending.CardID)
+		return vendingState.displayRow2(lc, cardDenialDisplayText("pinLocked"))
+	}
+	vendingState.PINPendingUserData = OutputData{}
+	lc.Errorf("Failed to verify the PIN of card %s, status code %d", pending.CardID, verification.StatusCode)
+	return vendingState.displayRow2(lc, "Unauthorized")
+}
+
 func (vendingState *VendingState) displayLedger(lc logger.LoggingClient, deviceName string, ledger Ledger) error {
 	settings := make(map[string]string)
 	settings["displayReset"] = ""
@@ -426,8 +653,8 @@ func (vendingState *VendingState) displayLedger(lc logger.LoggingClient, deviceN
 		return fmt.Errorf("sendCommand returned nil for %v : %v", vendingState.Configuration.ControllerBoardDisplayResetCmd, err.Error())
 	}
 
-	//display ledger.LineTotal from in currency format
-	displayLedgerTotal := "Total: $" + fmt.Sprintf("%3.2f", ledger.LineTotal)
+	//display ledger.GrandTotal, which includes tax, in currency format
+	displayLedgerTotal := "Total: " + formatAmount(ledger.GrandTotal, ledger.Currency)
 	settings = make(map[string]string)
 	settings["displayRow1"] = displayLedgerTotal
 	err = vendingState.SendCommand(lc, http.MethodPut, deviceName, vendingState.Configuration.ControllerBoardDisplayRow1Cmd, settings)
@@ -438,6 +665,77 @@ func (vendingState *VendingState) displayLedger(lc logger.LoggingClient, deviceN
 	return nil
 }
 
+// formatAmount formats an amount in minor units of an ISO 4217 currency for
+// the LCD, such as $5.97 for 597 USD or 150 JPY for 150 JPY. The minor unit
+// of the currency comes from the same CLDR data the ledger service uses, and
+// amounts without a known currency are shown with 2 decimal places
+func formatAmount(amount int64, currencyCode string) string {
+	digits := 2
+	if unit, err := currency.ParseISO(currencyCode); err == nil && unit.String() == currencyCode {
+		digits, _ = currency.Standard.Rounding(unit)
+	}
+
+	sign := ""
+	if amount < 0 {
+		sign = "-"
+		amount = -amount
+	}
+	value := strconv.FormatInt(amount, 10)
+	if digits > 0 {
+		value = fmt.Sprintf("%0*d", digits+1, amount)
+		value = value[:len(value)-digits] + "." + value[len(value)-digits:]
+	}
+	if currencyCode == "" || currencyCode == "USD" {
+		return sign + "$" + value
+	}
+	return sign + value + " " + currencyCode
+}
+
+// displayExpiredLots shows the number of expired units that are flagged for
+// removal on the LCD, so that the stocker can remove them during this session
+func (vendingState *VendingState) displayExpiredLots(lc logger.LoggingClient) error {
+	if len(vendingState.Configuration.InventoryExpiringService) == 0 {
+		return nil
+	}
+
+	// only the lots of this machine need to be removed by the stocker
+	machineID := vendingState.Configuration.MachineID
+	if len(machineID) == 0 {
+		machineID = defaultMachineID
+	}
+	resp, err := sendHTTPRequest(lc, http.MethodGet, vendingState.Configuration.InventoryExpiringService+"?days=0&machineId="+url.QueryEscape(machineID), []byte(""))
+	if resp != nil {
+		defer resp.Body.Close()
+	}
+	if err != nil {
+		return err
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read response body: %s", err.Error())
+	}
+	var expiringLots ExpiringLots
+	if err := json.Unmarshal(body, &expiringLots); err != nil {
+		return fmt.Errorf("failed to unmarshal expiring lots from response body: %s", err.Error())
+	}
+
+	expiredUnits := 0
+	for _, lot := range expiringLots.Data {
+		if lot.FlaggedForRemoval {
+			expiredUnits += lot.Quantity
+		}
+	}
+	if expiredUnits == 0 {
+		return nil
+	}
+
+	lc.Infof("%d expired units are flagged for removal", expiredUnits)
+	settings := make(map[string]string)
+	settings["displayRow1"] = fmt.Sprintf("Remove %d expired", expiredUnits)
+	return vendingState.SendCommand(lc, http.MethodPut, vendingState.Configuration.ControllerBoardDeviceName, vendingState.Configuration.ControllerBoardDisplayRow1Cmd, settings)
+}
+
 // SendCommand issues CommandClient GET and SET command calls, CommandClient takes care of http calls,
 // here the requirement are actionName, deviceName, commandName and settings, logger client is needed for logging
 func (vendingState *VendingState) SendCommand(lc logger.LoggingClient, actionName string, deviceName string,
@@ -474,11 +772,36 @@ func (vendingState *VendingState) SendCommand(lc logger.LoggingClient, actionNam
 
 // sendHTTPRequest will make an http request to an EdgeX command endpoint
 func sendHTTPRequest(lc logger.LoggingClient, method string, commandURL string, inputBytes []byte) (*http.Response, error) { //revive
+	return sendHTTPRequestWithHeaders(lc, method, commandURL, inputBytes, nil)
+}
+
+// newVendSessionID returns a new ID for a vend on the machine, which stays
+// the same for every post of the vend
+func newVendSessionID(machineID string) string {
+	if machineID == "" {
+		machineID = defaultMachineID
+	}
+	random := make([]byte, 8)
+	if _, err := rand.Read(random); err != nil {
+		// the time alone still tells the vends of the machine apart
+		return fmt.Sprintf("vend-%s-%d", machineID, time.Now().UnixNano())
+	}
+	return fmt.Sprintf("vend-%s-%d-%s", machineID, time.Now().UnixNano(), hex.EncodeToString(random))
+}
+
+// sendHTTPRequestWithHeaders sends the request like sendHTTPRequest, with
+// the headers that are not empty
+func sendHTTPRequestWithHeaders(lc logger.LoggingClient, method string, commandURL string, inputBytes []byte, headers map[string]string) (*http.Response, error) {
 
 	lc.Debugf("sending command to edgex endpoint: %v", commandURL)
 
 	// Create the http request based on the parameters
 	request, _ := http.NewRequest(method, commandURL, bytes.NewBuffer(inputBytes))
+	for name, value := range headers {
+		if value != "" {
+			request.Header.Set(name, value)
+		}
+	}
 	timeout := 60 * time.Second
 	client := &http.Client{
 		Timeout: timeout,
@@ -12,7 +12,9 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"strings"
 	"testing"
+	"time"
 
 	client_mocks "github.com/edgexfoundry/go-mod-core-contracts/v3/clients/interfaces/mocks"
 	"github.com/edgexfoundry/go-mod-core-contracts/v3/clients/logger"
@@ -67,13 +69,15 @@ func TestCheckInferenceStatus(t *testing.T) {
 
 func TestGetCardAuthInfo(t *testing.T) {
 	testCases := []struct {
-		TestCaseName string
-		statusCode   int
-		cardID       string
-		Expected     string
+		TestCaseName   string
+		statusCode     int
+		cardID         string
+		Expected       string
+		ExpectedDenial string
 	}{
-		{"Successful case", http.StatusOK, "1234567890", "1234567890"},
-		{"Internal error case", http.StatusInternalServerError, "1234567890", ""},
+		{"Successful case", http.StatusOK, "1234567890", "1234567890", ""},
+		{"Internal error case", http.StatusInternalServerError, "1234567890", "", ""},
+		{"Expired card case", http.StatusForbidden, "1234567890", "", "expired"},
 	}
 
 	var vendingState VendingState
@@ -83,9 +87,12 @@ func TestGetCardAuthInfo(t *testing.T) {
 
 			testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 
-				output := OutputData{
+				var output interface{} = OutputData{
 					CardID: tc.cardID,
 				}
+				if tc.statusCode == http.StatusForbidden {
+					output = CardDenial{CardID: tc.cardID, Status: tc.ExpectedDenial, Message: "Card ID is expired"}
+				}
 
 				authDataJSON, err := json.Marshal(output)
 				require.NoError(t, err)
@@ -97,10 +104,20 @@ func TestGetCardAuthInfo(t *testing.T) {
 			defer testServer.Close()
 			vendingState.getCardAuthInfo(logger.NewMockClient(), testServer.URL, tc.cardID)
 			assert.Equal(t, tc.Expected, vendingState.CurrentUserData.CardID, "Expected value to match output")
+			assert.Equal(t, tc.ExpectedDenial, vendingState.CardDenialStatus, "Expected denial status to match output")
 		})
 	}
 }
 
+func TestCardDenialDisplayText(t *testing.T) {
+	assert.Equal(t, "Card expired", cardDenialDisplayText("expired"))
+	assert.Equal(t, "Card not active", cardDenialDisplayText("notYetValid"))
+	assert.Equal(t, "Card suspended", cardDenialDisplayText("suspended"))
+	assert.Equal(t, "Card revoked", cardDenialDisplayText("revoked"))
+	assert.Equal(t, "PIN locked", cardDenialDisplayText("pinLocked"))
+	assert.Equal(t, "Unauthorized", cardDenialDisplayText(""))
+}
+
 func TestDisplayLedger(t *testing.T) {
 
 	mockCommandClient := &client_mocks.CommandClient{}
@@ -120,10 +137,85 @@ func TestDisplayLedger(t *testing.T) {
 	}
 
 	ledger := Ledger{
-		LineItems: []LineItem{{ProductName: "itemX", ItemCount: 2, ItemPrice: 1.50, SKU: "1234"}},
+		LineItems:  []LineItem{{ProductName: "itemX", ItemCount: 2, ItemPrice: 150, SKU: "1234", Currency: "USD"}},
+		LineTotal:  300,
+		TaxTotal:   22,
+		GrandTotal: 322,
+		Currency:   "USD",
 	}
 	err := vendingState.displayLedger(logger.NewMockClient(), "test-device", ledger)
 	assert.NoError(t, err)
+	// the total shown on the LCD includes tax
+	mockCommandClient.AssertCalled(t, "IssueSetCommandByName", mock.Anything, "test-device", "diplayrow1", map[string]string{"displayRow1": "Total: $3.22"})
+}
+
+func TestFormatAmount(t *testing.T) {
+	tests := []struct {
+		Amount   int64
+		Currency string
+		Expected string
+	}{
+		{597, "USD", "$5.97"},
+		{5, "USD", "$0.05"},
+		{-150, "USD", "-$1.50"},
+		{250, "", "$2.50"},
+		{1999, "EUR", "19.99 EUR"},
+		{150, "JPY", "150 JPY"},
+		{1500, "KWD", "1.500 KWD"},
+	}
+	for _, test := range tests {
+		currentTest := test
+		t.Run(currentTest.Expected, func(t *testing.T) {
+			assert.Equal(t, currentTest.Expected, formatAmount(currentTest.Amount, currentTest.Currency))
+		})
+	}
+}
+
+func TestDisplayExpiredLots(t *testing.T) {
+	testCases := []struct {
+		TestCaseName     string
+		StatusCode       int
+		Response         string
+		ExpectedSetCalls int
+		ExpectedError    bool
+	}{
+		{"Expired lots", http.StatusOK, `{"data":[{"sku":"4900002470","quantity":4,"flaggedForRemoval":true},{"sku":"1200010735","quantity":2,"flaggedForRemoval":false}]}`, 1, false},
+		{"No expired lots", http.StatusOK, `{"data":[{"sku":"1200010735","quantity":2,"flaggedForRemoval":false}]}`, 0, false},
+		{"Invalid response", http.StatusOK, `invalid json`, 0, true},
+		{"Inventory service error", http.StatusInternalServerError, ``, 0, true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.TestCaseName, func(t *testing.T) {
+			inventoryServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, "0", r.URL.Query().Get("days"))
+				assert.Equal(t, "default", r.URL.Query().Get("machineId"))
+				w.WriteHeader(tc.StatusCode)
+				w.Write([]byte(tc.Response))
+			}))
+			defer inventoryServer.Close()
+
+			mockCommandClient := &client_mocks.CommandClient{}
+			mockCommandClient.On("IssueSetCommandByName", mock.Anything, mock.Anything, mock.Anything, map[string]string{"displayRow1": "Remove 4 expired"}).
+				Return(common.BaseResponse{StatusCode: http.StatusOK}, nil)
+
+			vendingState := VendingState{
+				Configuration: &config.VendingConfig{
+					ControllerBoardDisplayRow1Cmd: "displayRow1",
+					InventoryExpiringService:      inventoryServer.URL,
+				},
+				CommandClient: mockCommandClient,
+			}
+
+			err := vendingState.displayExpiredLots(logger.NewMockClient())
+			if tc.ExpectedError {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+			}
+			mockCommandClient.AssertNumberOfCalls(t, "IssueSetCommandByName", tc.ExpectedSetCalls)
+		})
+	}
 }
 
 func TestHandleMqttDeviceReading(t *testing.T) {
@@ -177,7 +269,9 @@ func TestHandleMqttDeviceReading(t *testing.T) {
 					IsPaid:        false,
 					LineItems:     []LineItem{},
 					TransactionID: 123,
-					LineTotal:     20.5,
+					LineTotal:     2050,
+					GrandTotal:    2050,
+					Currency:      "USD",
 				}
 
 				outputJSON, err := json.Marshal(output)
@@ -223,6 +317,100 @@ func TestHandleMqttDeviceReading(t *testing.T) {
 	}
 }
 
+func TestHandleMqttDeviceReadingLinksAuditEntry(t *testing.T) {
+	event := dtos.Event{
+		DeviceName: InferenceMQTTDevice,
+		Readings: []dtos.BaseReading{
+			{
+				ResourceName: "inferenceSkuDelta",
+				SimpleReading: dtos.SimpleReading{
+					Value: `[{"SKU": "HXI86WHU", "delta": -2}]`,
+				},
+			},
+		},
+	}
+
+	auditLogPosted := false
+	auditServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auditLogPosted = true
+		w.Write([]byte(`{"auditEntryId":"session-1"}`))
+	}))
+	defer auditServer.Close()
+	inventoryServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.True(t, auditLogPosted, "the audit log entry should be posted before the inventory delta")
+		assert.Equal(t, "session-1", r.URL.Query().Get("auditEntryId"))
+		w.Write([]byte(`[]`))
+	}))
+	defer inventoryServer.Close()
+
+	vendingState := VendingState{
+		InferenceWaitThreadStopChannel: make(chan int),
+		ThreadStopChannel:              make(chan int),
+		CurrentUserData:                OutputData{RoleID: 2},
+		Configuration: &config.VendingConfig{
+			InventoryService:         inventoryServer.URL,
+			InventoryAuditLogService: auditServer.URL,
+		},
+	}
+
+	_, result := vendingState.HandleMqttDeviceReading(logger.NewMockClient(), event)
+	assert.Nil(t, result)
+	assert.True(t, auditLogPosted)
+}
+
+func TestHandleMqttDeviceReadingSendsIdempotencyKey(t *testing.T) {
+	event := dtos.Event{
+		DeviceName: InferenceMQTTDevice,
+		Readings: []dtos.BaseReading{
+			{
+				ResourceName: "inferenceSkuDelta",
+				SimpleReading: dtos.SimpleReading{
+					Value: `[{"SKU": "HXI86WHU", "delta": -2}]`,
+				},
+			},
+		},
+	}
+
+	var idempotencyKeys []string
+	ledgerServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		idempotencyKeys = append(idempotencyKeys, r.Header.Get(idempotencyKeyHeader))
+		w.Write([]byte(`{"transactionID":123,"lineItems":[]}`))
+	}))
+	defer ledgerServer.Close()
+	otherServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{}`))
+	}))
+	defer otherServer.Close()
+
+	mockCommandClient := &client_mocks.CommandClient{}
+	mockCommandClient.On("IssueSetCommandByName", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(common.BaseResponse{StatusCode: http.StatusOK}, nil)
+	vendingState := VendingState{
+		InferenceWaitThreadStopChannel: make(chan int),
+		ThreadStopChannel:              make(chan int),
+		CurrentUserData:                OutputData{RoleID: 1},
+		VendSessionID:                  newVendSessionID(""),
+		Configuration: &config.VendingConfig{
+			InventoryService:         otherServer.URL,
+			InventoryAuditLogService: otherServer.URL,
+			LedgerService:            ledgerServer.URL,
+		},
+		CommandClient: mockCommandClient,
+	}
+
+	vendingState.HandleMqttDeviceReading(logger.NewMockClient(), event)
+	vendingState.HandleMqttDeviceReading(logger.NewMockClient(), event)
+	require.Len(t, idempotencyKeys, 2)
+	assert.Equal(t, vendingState.VendSessionID, idempotencyKeys[0], "the ledger post should carry the key of the vend")
+	assert.Equal(t, idempotencyKeys[0], idempotencyKeys[1], "a retried post of the same vend should carry the same key")
+}
+
+func TestNewVendSessionID(t *testing.T) {
+	first := newVendSessionID("")
+	assert.True(t, strings.HasPrefix(first, "vend-"+defaultMachineID+"-"))
+	assert.NotEqual(t, first, newVendSessionID(""), "every vend should have its own key")
+	assert.True(t, strings.HasPrefix(newVendSessionID("machine-7"), "vend-machine-7-"))
+}
+
 func TestVerifyDoorAccess(t *testing.T) {
 	baseEvent := dtos.Event{
 		DeviceName: "card-reader",
@@ -322,3 +510,205 @@ func TestVerifyDoorAccess(t *testing.T) {
 		})
 	}
 }
+
+func TestVerifyDoorAccessPIN(t *testing.T) {
+	cardEvent := dtos.Event{
+		DeviceName: "card-reader",
+		Readings: []dtos.BaseReading{
+			{DeviceName: "card-reader", ResourceName: "card-number", SimpleReading: dtos.SimpleReading{Value: "0001230003"}},
+		},
+	}
+	// the card reader verifies the PIN itself and only sends its verification
+	attemptsRemaining := 3
+	pinEvent := func(pin string) dtos.Event {
+		verification := PINVerification{CardID: "0001230003", StatusCode: http.StatusOK}
+		switch {
+		case pin == "1234":
+			verification.Verified = true
+		case attemptsRemaining > 1:
+			attemptsRemaining--
+			verification.AttemptsRemaining = attemptsRemaining
+			verification.StatusCode = http.StatusUnauthorized
+		default:
+			verification.Status = "pinLocked"
+			verification.StatusCode = http.StatusLocked
+		}
+		verificationJSON, err := json.Marshal(verification)
+		require.NoError(t, err)
+		return dtos.Event{
+			DeviceName: "card-reader",
+			Readings: []dtos.BaseReading{
+				{DeviceName: "card-reader", ResourceName: CardReaderPINVerificationResource, SimpleReading: dtos.SimpleReading{Value: string(verificationJSON)}},
+			},
+		}
+	}
+
+	authServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/authentication/0001230003":
+			authDataJSON, err := json.Marshal(OutputData{CardID: "0001230003", RoleID: 3, PINRequired: true})
+			require.NoError(t, err)
+			w.Write(authDataJSON)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer authServer.Close()
+
+	newVendingState := func() (*VendingState, *client_mocks.CommandClient) {
+		mockCommandClient := &client_mocks.CommandClient{}
+		eventResp := responses.NewEventResponse("", "", http.StatusOK, dtos.Event{})
+		mockCommandClient.On("IssueSetCommandByName", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(common.BaseResponse{StatusCode: http.StatusOK}, nil)
+		mockCommandClient.On("IssueGetCommandByName", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(&eventResp, nil)
+		return &VendingState{
+			ThreadStopChannel: make(chan int),
+			Configuration: &config.VendingConfig{
+				AuthenticationEndpoint:        authServer.URL + "/authentication",
+				CardReaderDeviceName:          "card-reader",
+				CardReaderPINPromptCmd:        "pin-prompt",
+				ControllerBoardDeviceName:     "controller-board",
+				ControllerBoardDisplayRow2Cmd: "displayRow2",
+				ControllerBoardDisplayRow3Cmd: "displayRow3",
+				ControllerBoardLock1Cmd:       "lock1",
+				InferenceHeartbeatCmd:         "inferenceHeartbeat",
+			},
+			PINEntryTimeout: time.Minute,
+			CommandClient:   mockCommandClient,
+		}, mockCommandClient
+	}
+
+	t.Run("Right PIN", func(t *testing.T) {
+		vendingState, mockCommandClient := newVendingState()
+		ok, _ := vendingState.VerifyDoorAccess(logger.NewMockClient(), cardEvent)
+		require.True(t, ok)
+		assert.Equal(t, "0001230003", vendingState.PINPendingUserData.CardID)
+		assert.Zero(t, vendingState.CurrentUserData.RoleID, "the card should not be granted access before its PIN")
+		mockCommandClient.AssertCalled(t, "IssueSetCommandByName", mock.Anything, "card-reader", "pin-prompt", map[string]string{"pin-prompt": `{"cardID":"0001230003","timeout":"1m0s"}`})
+		mockCommandClient.AssertCalled(t, "IssueSetCommandByName", mock.Anything, "controller-board", "displayRow2", map[string]string{"displayRow2": "Enter PIN"})
+		mockCommandClient.AssertNotCalled(t, "IssueSetCommandByName", mock.Anything, mock.Anything, "lock1", mock.Anything)
+
+		ok, _ = vendingState.VerifyDoorAccess(logger.NewMockClient(), pinEvent("1234"))
+		require.True(t, ok)
+		assert.Equal(t, 3, vendingState.CurrentUserData.RoleID)
+		assert.Empty(t, vendingState.PINPendingUserData.CardID)
+		mockCommandClient.AssertCalled(t, "IssueSetCommandByName", mock.Anything, "controller-board", "displayRow2", map[string]string{"displayRow2": "Maintenance Mode"})
+		mockCommandClient.AssertCalled(t, "IssueSetCommandByName", mock.Anything, "controller-board", "displayRow3", map[string]string{"displayRow3": "0001230003"})
+		mockCommandClient.AssertCalled(t, "IssueSetCommandByName", mock.Anything, "controller-board", "lock1", map[string]string{"lock1": "true"})
+	})
+
+	t.Run("Wrong PINs lock the PIN", func(t *testing.T) {
+		vendingState, mockCommandClient := newVendingState()
+		ok, _ := vendingState.VerifyDoorAccess(logger.NewMockClient(), cardEvent)
+		require.True(t, ok)
+
+		ok, _ = vendingState.VerifyDoorAccess(logger.NewMockClient(), pinEvent("0000"))
+		require.True(t, ok)
+		mockCommandClient.AssertCalled(t, "IssueSetCommandByName", mock.Anything, "controller-board", "displayRow2", map[string]string{"displayRow2": "Wrong PIN: 2 left"})
+		assert.Equal(t, "0001230003", vendingState.PINPendingUserData.CardID, "a wrong PIN may be entered again")
+
+		vendingState.VerifyDoorAccess(logger.NewMockClient(), pinEvent("0000"))
+		vendingState.VerifyDoorAccess(logger.NewMockClient(), pinEvent("0000"))
+		mockCommandClient.AssertCalled(t, "IssueSetCommandByName", mock.Anything, "controller-board", "displayRow2", map[string]string{"displayRow2": "PIN locked"})
+		assert.Empty(t, vendingState.PINPendingUserData.CardID)
+		mockCommandClient.AssertNotCalled(t, "IssueSetCommandByName", mock.Anything, mock.Anything, "lock1", mock.Anything)
+	})
+
+	t.Run("PIN of another card", func(t *testing.T) {
+		vendingState, mockCommandClient := newVendingState()
+		ok, _ := vendingState.VerifyDoorAccess(logger.NewMockClient(), cardEvent)
+		require.True(t, ok)
+		otherCard := dtos.Event{
+			DeviceName: "card-reader",
+			Readings: []dtos.BaseReading{
+				{DeviceName: "card-reader", ResourceName: CardReaderPINVerificationResource, SimpleReading: dtos.SimpleReading{Value: `{"cardID":"0001230001","statusCode":200,"verified":true}`}},
+			},
+		}
+		ok, _ = vendingState.VerifyDoorAccess(logger.NewMockClient(), otherCard)
+		require.True(t, ok)
+		assert.Equal(t, "0001230003", vendingState.PINPendingUserData.CardID, "the verification of another card should be ignored")
+		mockCommandClient.AssertNotCalled(t, "IssueSetCommandByName", mock.Anything, mock.Anything, "lock1", mock.Anything)
+	})
+
+	t.Run("PIN without a card", func(t *testing.T) {
+		vendingState, mockCommandClient := newVendingState()
+		ok, _ := vendingState.VerifyDoorAccess(logger.NewMockClient(), pinEvent("1234"))
+		require.True(t, ok)
+		mockCommandClient.AssertNotCalled(t, "IssueSetCommandByName", mock.Anything, mock.Anything, "lock1", mock.Anything)
+	})
+
+	t.Run("PIN entered too late", func(t *testing.T) {
+		vendingState, mockCommandClient := newVendingState()
+		ok, _ := vendingState.VerifyDoorAccess(logger.NewMockClient(), cardEvent)
+		require.True(t, ok)
+		vendingState.PINDeadline = time.Now().Add(-time.Second)
+
+		ok, _ = vendingState.VerifyDoorAccess(logger.NewMockClient(), pinEvent("1234"))
+		require.True(t, ok)
+		mockCommandClient.AssertCalled(t, "IssueSetCommandByName", mock.Anything, "controller-board", "displayRow2", map[string]string{"displayRow2": "PIN timed out"})
+		mockCommandClient.AssertNotCalled(t, "IssueSetCommandByName", mock.Anything, mock.Anything, "lock1", mock.Anything)
+	})
+}
+
+func TestHasPermission(t *testing.T) {
+	assert.True(t, OutputData{RoleID: 1}.HasPermission(PermissionBillable), "role 1 should be billable without permissions")
+	assert.True(t, OutputData{RoleID: 2}.HasPermission(PermissionRestock))
+	assert.True(t, OutputData{RoleID: 3}.HasPermission(PermissionMaintenanceClear))
+	assert.False(t, OutputData{RoleID: 4}.HasPermission(PermissionUnlockLock1))
+	assert.False(t, OutputData{RoleID: 1, Permissions: []string{}}.HasPermission(PermissionUnlockLock1), "returned permissions should replace those of the role ID")
+	assert.True(t, OutputData{RoleID: 7, Permissions: []string{PermissionUnlockLock2}}.HasPermission(PermissionUnlockLock2))
+}
+
+func TestGrantAccessPermissions(t *testing.T) {
+	tests := []struct {
+		Name            string
+		Permissions     []string
+		MaintenanceMode bool
+		ExpectedLocks   []string
+		ExpectedRow2    string
+	}{
+		{"Both locks", []string{PermissionUnlockLock1, PermissionUnlockLock2}, false, []string{"lock1", "lock2"}, "hello"},
+		{"Lock 2 only", []string{PermissionUnlockLock2}, false, []string{"lock2"}, "hello"},
+		{"Maintenance without unlock", []string{PermissionMaintenanceClear}, true, nil, "Maintenance Mode"},
+		{"No permissions", []string{PermissionBillable}, false, nil, "Unauthorized"},
+	}
+	for _, test := range tests {
+		currentTest := test
+		t.Run(currentTest.Name, func(t *testing.T) {
+			mockCommandClient := &client_mocks.CommandClient{}
+			mockCommandClient.On("IssueSetCommandByName", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(common.BaseResponse{StatusCode: http.StatusOK}, nil)
+			vendingState := &VendingState{
+				ThreadStopChannel: make(chan int),
+				MaintenanceMode:   currentTest.MaintenanceMode,
+				CurrentUserData:   OutputData{RoleID: 5, Permissions: currentTest.Permissions},
+				Configuration: &config.VendingConfig{
+					ControllerBoardDeviceName:     "controller-board",
+					ControllerBoardDisplayRow2Cmd: "displayRow2",
+					ControllerBoardDisplayRow3Cmd: "displayRow3",
+					ControllerBoardLock1Cmd:       "lock1",
+					ControllerBoardLock2Cmd:       "lock2",
+				},
+				CommandClient: mockCommandClient,
+			}
+			require.NoError(t, vendingState.grantAccess(logger.NewMockClient(), "0001230005"))
+			close(vendingState.ThreadStopChannel)
+
+			mockCommandClient.AssertCalled(t, "IssueSetCommandByName", mock.Anything, "controller-board", "displayRow2", map[string]string{"displayRow2": currentTest.ExpectedRow2})
+			for _, lock := range []string{"lock1", "lock2"} {
+				if containsLock(currentTest.ExpectedLocks, lock) {
+					mockCommandClient.AssertCalled(t, "IssueSetCommandByName", mock.Anything, "controller-board", lock, map[string]string{lock: "true"})
+				} else {
+					mockCommandClient.AssertNotCalled(t, "IssueSetCommandByName", mock.Anything, mock.Anything, lock, mock.Anything)
+				}
+			}
+		})
+	}
+}
+
+func containsLock(locks []string, lock string) bool {
+	for _, l := range locks {
+		if l == lock {
+			return true
+		}
+	}
+	return false
+}
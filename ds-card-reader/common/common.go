@@ -6,7 +6,17 @@ package common
 // CommandCardReaderStatus is the string value of the command that will be
 // sent to the card reader driver that performs a health check of the underlying
 // device
+//
+// CommandPINPrompt asks the card reader to collect the PIN of a card for a
+// duration, such as {"cardID":"0003300001","timeout":"30s"}, after which
+// entered digits are read as a card number again. CommandPIN is a PIN entered
+// on the card reader, which the card reader verifies with the authentication
+// service itself, and CommandPINVerification is the reading with the result.
+// A PIN is never sent as a reading, so that core-data never stores it
 const (
 	CommandCardReaderStatus = "status"
 	CommandCardNumber       = "card-number"
+	CommandPIN              = "pin"
+	CommandPINPrompt        = "pin-prompt"
+	CommandPINVerification  = "pin-verification"
 )
@@ -16,6 +16,9 @@ type Config struct {
 	VID              uint16
 	PID              uint16
 	SimulateDevice   bool
+	// CardsEndpoint is the cards endpoint of the authentication service,
+	// which verifies the PINs entered on the card reader
+	CardsEndpoint string
 }
 
 // UpdateFromRaw updates the service's full configuration from raw data received from
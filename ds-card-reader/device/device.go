@@ -5,6 +5,7 @@ package device
 
 import (
 	"fmt"
+	"time"
 
 	dsModels "github.com/edgexfoundry/device-sdk-go/v3/pkg/models"
 	"github.com/edgexfoundry/go-mod-core-contracts/v3/clients/logger"
@@ -15,6 +16,7 @@ import (
 // a virtual or physical card reader struct
 type CardReader interface {
 	Write(string, string)
+	PromptPIN(string, time.Duration)
 	Status() error
 	Listen()
 	Release() error
@@ -26,9 +28,13 @@ type CardReader interface {
 // simulateDevice dictates whether the device is a physical or virtual card
 // reader
 //
+// cardsEndpoint is the cards endpoint of the authentication service that
+// verifies the PINs entered on the card reader, and PINs are not verified
+// when it is empty
+//
 // mockDevice is used for tests, and dictates whether or not the Listen
 // loop goes forever. This should be false unless running unit tests
-func InitializeCardReader(lc logger.LoggingClient, asyncCh chan<- *dsModels.AsyncValues, deviceSearchPath string, deviceName string, vid uint16, pid uint16, simulateDevice bool, mockDevice bool) (cardReader CardReader, err error) {
+func InitializeCardReader(lc logger.LoggingClient, asyncCh chan<- *dsModels.AsyncValues, deviceSearchPath string, deviceName string, vid uint16, pid uint16, simulateDevice bool, cardsEndpoint string, mockDevice bool) (cardReader CardReader, err error) {
 	// check if we are configured to only simulate a physical card reader
 	// device, or if we are allowed to use an actual physical card reader device
 	if !simulateDevice {
@@ -48,6 +54,7 @@ func InitializeCardReader(lc logger.LoggingClient, asyncCh chan<- *dsModels.Asyn
 			PID:              pid,
 			DeviceSearchPath: deviceSearchPath,
 			StableDevice:     true,
+			PINVerifier:      NewPINVerifier(cardsEndpoint),
 		}
 
 		if !mockDevice {
@@ -60,6 +67,7 @@ func InitializeCardReader(lc logger.LoggingClient, asyncCh chan<- *dsModels.Asyn
 			AsyncCh:       asyncCh,
 			DeviceName:    deviceName,
 			LoggingClient: lc,
+			PINVerifier:   NewPINVerifier(cardsEndpoint),
 		}
 	}
 
@@ -61,7 +61,7 @@ func TestInitializeCardReader(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			gotCardReader, err := InitializeCardReader(tt.lc, tt.asyncCh, tt.deviceSearchPath, tt.deviceName, tt.vid, tt.pid, tt.simulateDevice, tt.mockDevice)
+			gotCardReader, err := InitializeCardReader(tt.lc, tt.asyncCh, tt.deviceSearchPath, tt.deviceName, tt.vid, tt.pid, tt.simulateDevice, "", tt.mockDevice)
 			if tt.wantErr {
 				require.Error(err)
 				return
@@ -27,6 +27,12 @@ type CardReaderPhysical struct {
 	CardNumber       string
 	Mocked           bool // used for unit testing
 	StableDevice     bool
+
+	// PINVerifier verifies the PINs entered on the card reader, which are
+	// never sent as readings
+	PINVerifier *PINVerifier
+
+	pinPrompt pinPrompt
 }
 
 // Listen is called by the EdgeX driver for this device service, it listens
@@ -89,6 +95,15 @@ func (reader *CardReaderPhysical) Status() error {
 // to "badge-in", but only the physical card reader is listening for input
 // events from a real device
 func (reader *CardReaderPhysical) Write(commandName string, cardNumber string) {
+	// a PIN is verified here and only its verification is sent, so that the
+	// PIN is not stored by core-data with the card read before it
+	if commandName == common.CommandPIN {
+		if verification, ok := verifyPIN(reader.LoggingClient, &reader.pinPrompt, reader.PINVerifier, cardNumber); ok {
+			reader.Write(common.CommandPINVerification, verification)
+		}
+		return
+	}
+
 	// assemble the values that will be propagated throughout the
 	// device service
 	commandvalue, err := dsModels.NewCommandValueWithOrigin(
@@ -123,9 +138,20 @@ func (reader *CardReaderPhysical) Write(commandName string, cardNumber string) {
 	// reset the card number after pushing to the async channel
 	reader.CardNumber = ""
 
+	if commandName == common.CommandPINVerification {
+		reader.LoggingClient.Info("sent event with PIN verification")
+		return
+	}
 	reader.LoggingClient.Info(fmt.Sprintf("received event with card number %v", cardNumber))
 }
 
+// PromptPIN makes the card reader read the next digits that are entered
+// within the timeout as the PIN of the card instead of a card number
+func (reader *CardReaderPhysical) PromptPIN(cardID string, timeout time.Duration) {
+	reader.pinPrompt.set(cardID, timeout)
+	reader.LoggingClient.Info(fmt.Sprintf("prompting for the PIN of card %v for %v", cardID, timeout))
+}
+
 // processDevReadEvents handles an incoming evdev device event and pushes it
 // into the EdgeX framework
 func (reader *CardReaderPhysical) processDevReadEvents(events []evdev.InputEvent) {
@@ -142,6 +168,13 @@ func (reader *CardReaderPhysical) processDevReadEvents(events []evdev.InputEvent
 			continue
 		}
 
+		// the digits entered after a card are its PIN while one is prompted
+		// for
+		if reader.pinPrompt.active() {
+			reader.Write(common.CommandPIN, reader.CardNumber)
+			reader.CardNumber = ""
+			continue
+		}
 		reader.Write(common.CommandCardNumber, reader.CardNumber)
 	}
 }
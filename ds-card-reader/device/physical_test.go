@@ -12,6 +12,7 @@ import (
 	"os"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/edgexfoundry/go-mod-core-contracts/v3/clients/logger"
 	evdev "github.com/gvalkov/golang-evdev"
@@ -376,6 +377,28 @@ func TestProcessDevReadEvents(t *testing.T) {
 				fmt.Sprintf("received event with card number %v", expectedCardNumberPhysical),
 			},
 		},
+		{
+			Name: "PIN entered while prompted",
+			InputEvents: []evdev.InputEvent{
+				{Code: evdev.KEY_1, Type: evdev.EV_KEY, Value: int32(evdev.KeyDown)},
+				{Code: evdev.KEY_2, Type: evdev.EV_KEY, Value: int32(evdev.KeyDown)},
+				{Code: evdev.KEY_3, Type: evdev.EV_KEY, Value: int32(evdev.KeyDown)},
+				{Code: evdev.KEY_4, Type: evdev.EV_KEY, Value: int32(evdev.KeyDown)},
+				{Code: evdev.KEY_ENTER, Type: evdev.EV_KEY, Value: int32(evdev.KeyDown)},
+			},
+			InputCardReaderPhysical: &CardReaderPhysical{
+				DeviceName:    physicalDeviceName,
+				LoggingClient: lc,
+				Mocked:        true,
+				CardNumber:    "",
+				pinPrompt:     pinPrompt{cardID: expectedCardNumberPhysical, deadline: time.Now().Add(time.Minute)},
+			},
+			ExpectedCardReaderCardNumber: "", // PIN gets cleared
+			ExpectedLogLines: []string{
+				"configuration CardsEndpoint is empty, so PINs cannot be verified",
+				"sent event with PIN verification",
+			},
+		},
 	}
 
 	// run the tests
@@ -0,0 +1,165 @@
+// Copyright © 2026 Intel Corporation. All rights reserved.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package device
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	logger "github.com/edgexfoundry/go-mod-core-contracts/v3/clients/logger"
+)
+
+// pinVerifyTimeout is how long the authentication service may take to
+// verify a PIN
+const pinVerifyTimeout = 10 * time.Second
+
+// PINPrompt is the value of the pin-prompt command: the card whose PIN is
+// read, and how long it may be entered for, such as "30s"
+type PINPrompt struct {
+	CardID  string `json:"cardID"`
+	Timeout string `json:"timeout"`
+}
+
+// PINVerification is the result of verifying a PIN with the authentication
+// service, which the card reader sends as a pin-verification reading in
+// place of the PIN. StatusCode is the status code of the authentication
+// service, or zero when the PIN could not be verified at all
+type PINVerification struct {
+	CardID            string `json:"cardID"`
+	StatusCode        int    `json:"statusCode"`
+	Verified          bool   `json:"verified"`
+	AttemptsRemaining int    `json:"attemptsRemaining"`
+	Status            string `json:"status,omitempty"`
+}
+
+// ParsePINPrompt returns the card ID and the timeout of the value of the
+// pin-prompt command
+func ParsePINPrompt(value string) (string, time.Duration, error) {
+	var prompt PINPrompt
+	if err := json.Unmarshal([]byte(value), &prompt); err != nil {
+		return "", 0, fmt.Errorf("invalid PIN prompt: %v", err)
+	}
+	if prompt.CardID == "" {
+		return "", 0, fmt.Errorf("invalid PIN prompt: cardID is empty")
+	}
+	timeout, err := time.ParseDuration(prompt.Timeout)
+	if err != nil || timeout <= 0 {
+		return "", 0, fmt.Errorf("invalid PIN prompt: invalid duration: %v", prompt.Timeout)
+	}
+	return prompt.CardID, timeout, nil
+}
+
+// PINVerifier sends the PINs entered on the card reader straight to the
+// authentication service, so that a PIN never leaves this service as a
+// reading that core-data would store
+type PINVerifier struct {
+	// CardsEndpoint is the cards endpoint of the authentication service,
+	// such as http://localhost:48096/cards
+	CardsEndpoint string
+	Client        *http.Client
+}
+
+// NewPINVerifier returns a PINVerifier for the cards endpoint, or nil when
+// the endpoint is empty so that PINs cannot be verified
+func NewPINVerifier(cardsEndpoint string) *PINVerifier {
+	if cardsEndpoint == "" {
+		return nil
+	}
+	return &PINVerifier{
+		CardsEndpoint: strings.TrimSuffix(cardsEndpoint, "/"),
+		Client:        &http.Client{Timeout: pinVerifyTimeout},
+	}
+}
+
+// Verify asks the authentication service to verify the PIN of the card
+func (verifier *PINVerifier) Verify(cardID string, pin string) (PINVerification, error) {
+	verification := PINVerification{CardID: cardID}
+	body, err := json.Marshal(map[string]string{"pin": pin})
+	if err != nil {
+		return verification, err
+	}
+	resp, err := verifier.Client.Post(verifier.CardsEndpoint+"/"+url.PathEscape(cardID)+"/pin/verify", "application/json", bytes.NewReader(body))
+	if err != nil {
+		return verification, fmt.Errorf("failed to verify PIN: %v", err)
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK, http.StatusUnauthorized, http.StatusLocked, http.StatusForbidden:
+		// the body of 401 for a card that is not valid is not JSON, which
+		// leaves the verification as it is
+		_ = json.NewDecoder(resp.Body).Decode(&verification)
+	}
+	verification.CardID = cardID
+	verification.StatusCode = resp.StatusCode
+	return verification, nil
+}
+
+// pinPrompt is the card whose PIN the next digits entered on a card reader
+// are, until its deadline passes
+type pinPrompt struct {
+	mutex    sync.Mutex
+	cardID   string
+	deadline time.Time
+}
+
+// set prompts for the PIN of the card for the timeout
+func (prompt *pinPrompt) set(cardID string, timeout time.Duration) {
+	prompt.mutex.Lock()
+	defer prompt.mutex.Unlock()
+	prompt.cardID = cardID
+	prompt.deadline = time.Now().Add(timeout)
+}
+
+// active returns true if a PIN is prompted for and its timeout has not
+// passed
+func (prompt *pinPrompt) active() bool {
+	prompt.mutex.Lock()
+	defer prompt.mutex.Unlock()
+	return prompt.cardID != "" && time.Now().Before(prompt.deadline)
+}
+
+// take returns the card of the active prompt, and ends the prompt either
+// way, since only one PIN is entered per prompt
+func (prompt *pinPrompt) take() (string, bool) {
+	prompt.mutex.Lock()
+	defer prompt.mutex.Unlock()
+	cardID := prompt.cardID
+	prompted := cardID != "" && time.Now().Before(prompt.deadline)
+	prompt.cardID = ""
+	prompt.deadline = time.Time{}
+	return cardID, prompted
+}
+
+// verifyPIN verifies the PIN for the card of the prompt and returns the
+// verification as the value of a pin-verification reading. It returns false
+// when no PIN is prompted for, so that nothing is sent
+func verifyPIN(lc logger.LoggingClient, prompt *pinPrompt, verifier *PINVerifier, pin string) (string, bool) {
+	cardID, prompted := prompt.take()
+	if !prompted {
+		lc.Info("ignoring a PIN that was entered without a prompt")
+		return "", false
+	}
+	verification := PINVerification{CardID: cardID}
+	if verifier == nil {
+		lc.Error("configuration CardsEndpoint is empty, so PINs cannot be verified")
+	} else {
+		var err error
+		if verification, err = verifier.Verify(cardID, pin); err != nil {
+			lc.Error(fmt.Sprintf("failed to verify the PIN of card %v: %v", cardID, err.Error()))
+		}
+	}
+	value, err := json.Marshal(verification)
+	if err != nil {
+		lc.Error(fmt.Sprintf("failed to marshal the PIN verification of card %v: %v", cardID, err.Error()))
+		return "", false
+	}
+	return string(value), true
+}
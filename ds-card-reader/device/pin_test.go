@@ -0,0 +1,78 @@
+//go:build all || !physical
+// +build all !physical
+
+// Copyright © 2026 Intel Corporation. All rights reserved.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package device
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	assert "github.com/stretchr/testify/assert"
+	require "github.com/stretchr/testify/require"
+)
+
+func TestParsePINPrompt(t *testing.T) {
+	tests := []struct {
+		name            string
+		value           string
+		expectedCardID  string
+		expectedTimeout time.Duration
+		wantErr         bool
+	}{
+		{"valid prompt", `{"cardID":"0003300001","timeout":"30s"}`, "0003300001", 30 * time.Second, false},
+		{"duration without a card", "30s", "", 0, true},
+		{"empty card ID", `{"cardID":"","timeout":"30s"}`, "", 0, true},
+		{"invalid duration", `{"cardID":"0003300001","timeout":"soon"}`, "", 0, true},
+		{"negative duration", `{"cardID":"0003300001","timeout":"-1s"}`, "", 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cardID, timeout, err := ParsePINPrompt(tt.value)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedCardID, cardID)
+			assert.Equal(t, tt.expectedTimeout, timeout)
+		})
+	}
+}
+
+func TestPINVerifierVerify(t *testing.T) {
+	tests := []struct {
+		name         string
+		statusCode   int
+		body         string
+		expected     PINVerification
+	}{
+		{"wrong PIN", http.StatusUnauthorized, `{"cardID":"0003300001","verified":false,"attemptsRemaining":2,"status":"active"}`,
+			PINVerification{CardID: "0003300001", StatusCode: http.StatusUnauthorized, AttemptsRemaining: 2, Status: "active"}},
+		{"locked PIN", http.StatusLocked, `{"cardID":"0003300001","verified":false,"status":"pinLocked"}`,
+			PINVerification{CardID: "0003300001", StatusCode: http.StatusLocked, Status: "pinLocked"}},
+		{"card that is not valid", http.StatusUnauthorized, "Card ID is not a valid card",
+			PINVerification{CardID: "0003300001", StatusCode: http.StatusUnauthorized}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			authServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.statusCode)
+				w.Write([]byte(tt.body))
+			}))
+			defer authServer.Close()
+
+			verification, err := NewPINVerifier(authServer.URL + "/cards/").Verify("0003300001", "1234")
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, verification)
+		})
+	}
+
+	assert.Nil(t, NewPINVerifier(""), "PINs cannot be verified without a cards endpoint")
+	_, err := NewPINVerifier("http://127.0.0.1:0/cards").Verify("0003300001", "1234")
+	assert.Error(t, err)
+}
@@ -4,12 +4,13 @@
 package device
 
 import (
+	"ds-card-reader/common"
 	"fmt"
 	"time"
 
 	dsModels "github.com/edgexfoundry/device-sdk-go/v3/pkg/models"
 	logger "github.com/edgexfoundry/go-mod-core-contracts/v3/clients/logger"
-	"github.com/edgexfoundry/go-mod-core-contracts/v3/common"
+	edgexcommon "github.com/edgexfoundry/go-mod-core-contracts/v3/common"
 )
 
 // CardReaderVirtual allows for the emulation of a physical card reader device
@@ -18,17 +19,31 @@ type CardReaderVirtual struct {
 	DeviceName          string
 	LoggingClient       logger.LoggingClient
 	MockFailStatusCheck bool // mocks an error message on status()
+	// PINVerifier verifies the PINs written with the "pin" command, which
+	// are never sent as readings
+	PINVerifier *PINVerifier
+
+	pinPrompt pinPrompt
 }
 
 // Write grants the virtual card reader device the ability to respond to
 // EdgeX commands to create a card reader "badge-in" event, and push the event
 // through the EdgeX framework
 func (reader *CardReaderVirtual) Write(commandName string, cardNumber string) {
+	// a PIN is verified here and only its verification is sent, so that the
+	// PIN is not stored by core-data with the card read before it
+	if commandName == common.CommandPIN {
+		if verification, ok := verifyPIN(reader.LoggingClient, &reader.pinPrompt, reader.PINVerifier, cardNumber); ok {
+			reader.Write(common.CommandPINVerification, verification)
+		}
+		return
+	}
+
 	// assemble the values that will be propagated throughout the
 	// device service
 	commandvalue, err := dsModels.NewCommandValueWithOrigin(
 		commandName,
-		common.ValueTypeString,
+		edgexcommon.ValueTypeString,
 		cardNumber,
 		time.Now().UnixNano()/int64(time.Millisecond),
 	)
@@ -51,9 +66,20 @@ func (reader *CardReaderVirtual) Write(commandName string, cardNumber string) {
 	// as-vending service for processing
 	reader.AsyncCh <- asyncValues
 
+	if commandName == common.CommandPINVerification {
+		reader.LoggingClient.Info("sent event with PIN verification")
+		return
+	}
 	reader.LoggingClient.Info(fmt.Sprintf("received event with card number %v", cardNumber))
 }
 
+// PromptPIN makes the PIN that is written with the "pin" command within the
+// timeout the PIN of the card
+func (reader *CardReaderVirtual) PromptPIN(cardID string, timeout time.Duration) {
+	reader.pinPrompt.set(cardID, timeout)
+	reader.LoggingClient.Info(fmt.Sprintf("prompting for the PIN of card %v for %v", cardID, timeout))
+}
+
 // Status is a health check function that alays returns true for the virtual
 // card reader
 func (reader *CardReaderVirtual) Status() error {
@@ -7,8 +7,13 @@
 package device
 
 import (
+	"encoding/json"
 	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
 	"testing"
+	"time"
 
 	"ds-card-reader/common"
 
@@ -48,6 +53,7 @@ func TestVirtualCardReader(t *testing.T) {
 		virtualVID,
 		virtualPID,
 		true,
+		"",
 		true,
 	)
 
@@ -131,3 +137,44 @@ func TestWrite(t *testing.T) {
 
 	assert.Equal(expectedCardNumberVirtual, actualStringValue)
 }
+
+// TestWritePIN validates that the virtual device's Write function verifies
+// a PIN with the authentication service and pushes only the verification to
+// the async channel of the device
+func TestWritePIN(t *testing.T) {
+	authServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/cards/0003300001/pin/verify", r.URL.Path)
+		body, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"pin":"1234"}`, string(body))
+		w.Write([]byte(`{"cardID":"0003300001","verified":true,"attemptsRemaining":3,"status":"active"}`))
+	}))
+	defer authServer.Close()
+
+	asyncCh := make(chan *dsModels.AsyncValues, 16)
+	reader := CardReaderVirtual{
+		AsyncCh:       asyncCh,
+		DeviceName:    virtualDeviceName,
+		LoggingClient: logger.NewMockClient(),
+		PINVerifier:   NewPINVerifier(authServer.URL + "/cards"),
+	}
+
+	reader.Write(common.CommandPIN, "1234")
+	assert.Empty(t, asyncCh, "a PIN entered without a prompt should not be sent")
+
+	reader.PromptPIN("0003300001", 30*time.Second)
+	reader.Write(common.CommandPIN, "1234")
+
+	actual := <-asyncCh
+	require.Len(t, actual.CommandValues, 1)
+	assert.Equal(t, common.CommandPINVerification, actual.CommandValues[0].DeviceResourceName)
+	actualStringValue, err := actual.CommandValues[0].StringValue()
+	require.NoError(t, err)
+	assert.NotContains(t, actualStringValue, "1234", "the PIN should never be sent as a reading")
+	var verification PINVerification
+	require.NoError(t, json.Unmarshal([]byte(actualStringValue), &verification))
+	assert.Equal(t, PINVerification{CardID: "0003300001", StatusCode: http.StatusOK, Verified: true, AttemptsRemaining: 3, Status: "active"}, verification)
+
+	reader.Write(common.CommandPIN, "1234")
+	assert.Empty(t, asyncCh, "only one PIN should be entered per prompt")
+}
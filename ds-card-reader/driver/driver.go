@@ -60,6 +60,7 @@ func (drv *CardReaderDriver) Initialize(sdk interfaces.DeviceServiceSDK) (err er
 		drv.Config.DriverConfig.VID,
 		drv.Config.DriverConfig.PID,
 		drv.Config.DriverConfig.SimulateDevice,
+		drv.Config.DriverConfig.CardsEndpoint,
 		false,
 	)
 	if err != nil {
@@ -119,6 +120,42 @@ func (drv *CardReaderDriver) HandleWriteCommands(deviceName string, protocols ma
 
 			drv.CardReader.Write(common.CommandCardNumber, cardNumber)
 
+			return nil
+		}
+	case common.CommandPIN:
+		{
+			// parse the PIN from the event, which is entered like a card
+			// number on the virtual card reader, and verified instead of
+			// being sent as a reading
+			pin, err := params[0].StringValue()
+			if err != nil {
+				errMsg := fmt.Sprintf("write command \"%v\" received non-string value: %v", commandName, err.Error())
+				drv.LoggingClient.Debug(errMsg)
+				return fmt.Errorf(errMsg)
+			}
+
+			drv.CardReader.Write(common.CommandPIN, pin)
+
+			return nil
+		}
+	case common.CommandPINPrompt:
+		{
+			// parse the card and how long to wait for its PIN
+			value, err := params[0].StringValue()
+			if err != nil {
+				errMsg := fmt.Sprintf("write command \"%v\" received non-string value: %v", commandName, err.Error())
+				drv.LoggingClient.Debug(errMsg)
+				return fmt.Errorf(errMsg)
+			}
+			cardID, timeout, err := device.ParsePINPrompt(value)
+			if err != nil {
+				errMsg := fmt.Sprintf("write command \"%v\" received an invalid value: %v", commandName, err.Error())
+				drv.LoggingClient.Debug(errMsg)
+				return fmt.Errorf(errMsg)
+			}
+
+			drv.CardReader.PromptPIN(cardID, timeout)
+
 			return nil
 		}
 	}
@@ -206,6 +206,15 @@ func TestHandleWriteCommands(t *testing.T) {
 	nonStringCommandVal, err := dsModels.NewCommandValueWithOrigin(common.CommandCardNumber, edgexcommon.ValueTypeFloat64, 0.01, 0)
 	require.NoError(err)
 
+	pinCommandVal, err := dsModels.NewCommandValueWithOrigin(common.CommandPIN, edgexcommon.ValueTypeString, "1234", 0)
+	require.NoError(err)
+
+	pinPromptCommandVal, err := dsModels.NewCommandValueWithOrigin(common.CommandPINPrompt, edgexcommon.ValueTypeString, `{"cardID":"0003300001","timeout":"30s"}`, 0)
+	require.NoError(err)
+
+	invalidPINPromptCommandVal, err := dsModels.NewCommandValueWithOrigin(common.CommandPINPrompt, edgexcommon.ValueTypeString, `{"cardID":"0003300001","timeout":"soon"}`, 0)
+	require.NoError(err)
+
 	tests := []struct {
 		name             string
 		inputParams      []*dsModels.CommandValue
@@ -271,6 +280,63 @@ func TestHandleWriteCommands(t *testing.T) {
 				},
 			},
 		},
+		{
+			"HandleWriteCommands successful PIN write test",
+			[]*dsModels.CommandValue{pinCommandVal},
+			[]dsModels.CommandRequest{{DeviceResourceName: common.CommandPIN}},
+			[]string{},
+			nil,
+			&CardReaderDriver{
+				LoggingClient: lc,
+				CardReader: &device.CardReaderVirtual{
+					AsyncCh:       make(chan *dsModels.AsyncValues, 16),
+					LoggingClient: lc,
+				},
+				Config: &device.ServiceConfig{
+					DriverConfig: device.Config{
+						DeviceName: cardReaderDeviceServiceName,
+					},
+				},
+			},
+		},
+		{
+			"HandleWriteCommands successful PIN prompt test",
+			[]*dsModels.CommandValue{pinPromptCommandVal},
+			[]dsModels.CommandRequest{{DeviceResourceName: common.CommandPINPrompt}},
+			[]string{},
+			nil,
+			&CardReaderDriver{
+				LoggingClient: lc,
+				CardReader: &device.CardReaderVirtual{
+					AsyncCh:       make(chan *dsModels.AsyncValues, 16),
+					LoggingClient: lc,
+				},
+				Config: &device.ServiceConfig{
+					DriverConfig: device.Config{
+						DeviceName: cardReaderDeviceServiceName,
+					},
+				},
+			},
+		},
+		{
+			"HandleWriteCommands PIN prompt with invalid duration",
+			[]*dsModels.CommandValue{invalidPINPromptCommandVal},
+			[]dsModels.CommandRequest{{DeviceResourceName: common.CommandPINPrompt}},
+			[]string{},
+			fmt.Errorf("write command \"%v\" received an invalid value: %v", common.CommandPINPrompt, "invalid PIN prompt: invalid duration: soon"),
+			&CardReaderDriver{
+				LoggingClient: lc,
+				CardReader: &device.CardReaderVirtual{
+					AsyncCh:       make(chan *dsModels.AsyncValues, 16),
+					LoggingClient: lc,
+				},
+				Config: &device.ServiceConfig{
+					DriverConfig: device.Config{
+						DeviceName: cardReaderDeviceServiceName,
+					},
+				},
+			},
+		},
 		{
 			"HandleWriteCommands successful write test",
 			[]*dsModels.CommandValue{successfulCommandVal},
@@ -7,19 +7,24 @@ go 1.21
 
 require (
 	github.com/edgexfoundry/app-functions-sdk-go/v3 v3.1.0
+	github.com/edgexfoundry/go-mod-bootstrap/v3 v3.1.0
 	github.com/edgexfoundry/go-mod-core-contracts/v3 v3.1.0
+	github.com/go-asn1-ber/asn1-ber v1.5.5
+	github.com/go-ldap/ldap/v3 v3.4.6
 	github.com/gorilla/mux v1.8.0
 	github.com/stretchr/testify v1.8.4
+	go.etcd.io/bbolt v1.3.8
+	golang.org/x/crypto v0.21.0
 )
 
 require (
+	github.com/Azure/go-ntlmssp v0.0.0-20221128193559-754e69321358 // indirect
 	github.com/Microsoft/go-winio v0.6.1 // indirect
 	github.com/armon/go-metrics v0.4.1 // indirect
 	github.com/cenkalti/backoff v2.2.1+incompatible // indirect
 	github.com/davecgh/go-spew v1.1.2-0.20180830191138-d8f796af33cc // indirect
 	github.com/diegoholiveira/jsonlogic/v3 v3.3.2 // indirect
 	github.com/eclipse/paho.mqtt.golang v1.4.3 // indirect
-	github.com/edgexfoundry/go-mod-bootstrap/v3 v3.1.0 // indirect
 	github.com/edgexfoundry/go-mod-configuration/v3 v3.1.0 // indirect
 	github.com/edgexfoundry/go-mod-messaging/v3 v3.1.0 // indirect
 	github.com/edgexfoundry/go-mod-registry/v3 v3.1.0 // indirect
@@ -70,7 +75,6 @@ require (
 	github.com/valyala/fasttemplate v1.2.2 // indirect
 	github.com/x448/float16 v0.8.4 // indirect
 	github.com/zeebo/errs v1.3.0 // indirect
-	golang.org/x/crypto v0.21.0 // indirect
 	golang.org/x/exp v0.0.0-20230905200255-921286631fa9 // indirect
 	golang.org/x/mod v0.12.0 // indirect
 	golang.org/x/net v0.23.0 // indirect
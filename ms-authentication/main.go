@@ -23,12 +23,28 @@ func main() {
 	lc := service.LoggingClient()
 
 	controller := routes.NewController(service)
+	if err := controller.LoadSettings(); err != nil {
+		lc.Errorf("failed to load ApplicationSettings: %s", err.Error())
+		os.Exit(1)
+	}
+	if err := controller.LoadSecrets(); err != nil {
+		lc.Errorf("failed to load secrets: %s", err.Error())
+		os.Exit(1)
+	}
+	if err := controller.LoadIdentityProvider(); err != nil {
+		lc.Errorf("failed to load identity provider: %s", err.Error())
+		os.Exit(1)
+	}
 	err := controller.AddAllRoutes()
 	if err != nil {
 		lc.Errorf("failed to add all Routes: %s", err.Error())
 		os.Exit(1)
 	}
-	if err := service.Run(); err != nil {
+	err = service.Run()
+	if closeErr := controller.Close(); closeErr != nil {
+		lc.Errorf("failed to close storage: %s", closeErr.Error())
+	}
+	if err != nil {
 		lc.Errorf("Run returned error: %s", err.Error())
 		os.Exit(1)
 	}
@@ -0,0 +1,334 @@
+// Copyright © 2026 Intel Corporation. All rights reserved.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package routes
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+var (
+	cardsBucket    = []byte("cards")
+	peopleBucket   = []byte("people")
+	accountsBucket = []byte("accounts")
+	rolesBucket    = []byte("roles")
+)
+
+// boltRepository stores the cards, people, accounts and roles in an embedded
+// bolt database, with a bucket of each keyed by their IDs
+type boltRepository struct {
+	db *bolt.DB
+}
+
+// newBoltRepository opens or creates the database file. Only one service
+// can open the file at a time
+func newBoltRepository(fileName string) (*boltRepository, error) {
+	if fileName == "" {
+		fileName = DatabaseFileName
+	}
+	db, err := bolt.Open(fileName, 0600, &bolt.Options{Timeout: 5 * time.Second})
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database %s: %s", fileName, err.Error())
+	}
+	err = db.Update(func(tx *bolt.Tx) error {
+		for _, bucket := range [][]byte{cardsBucket, peopleBucket, accountsBucket, rolesBucket} {
+			if _, err := tx.CreateBucketIfNotExists(bucket); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to create buckets in database %s: %s", fileName, err.Error())
+	}
+	return &boltRepository{db: db}, nil
+}
+
+// idKey returns the key of a person, account or role ID, which sorts the
+// keys by ID
+func idKey(id int) []byte {
+	key := make([]byte, 8)
+	binary.BigEndian.PutUint64(key, uint64(id))
+	return key
+}
+
+// importFiles imports each JSON file that exists into its bucket, if the
+// bucket is empty, so the data of the file storage type carries over. An
+// empty roles bucket without a roles file gets the default roles, like the
+// file storage type
+func (repo *boltRepository) importFiles(cardsFileName string, peopleFileName string, accountsFileName string, rolesFileName string) error {
+	empty := func(bucket []byte) (bool, error) {
+		isEmpty := false
+		err := repo.db.View(func(tx *bolt.Tx) error {
+			key, _ := tx.Bucket(bucket).Cursor().First()
+			isEmpty = key == nil
+			return nil
+		})
+		return isEmpty, err
+	}
+	exists := func(fileName string) bool {
+		_, err := os.Stat(fileName)
+		return fileName != "" && err == nil
+	}
+
+	if isEmpty, err := empty(cardsBucket); err != nil {
+		return err
+	} else if isEmpty && exists(cardsFileName) {
+		var cards Cards
+		if err := readDataFile(cardsFileName, "cards", &cards); err != nil {
+			return err
+		}
+		if err := repo.WriteCards(cards); err != nil {
+			return err
+		}
+	}
+	if isEmpty, err := empty(peopleBucket); err != nil {
+		return err
+	} else if isEmpty && exists(peopleFileName) {
+		var people People
+		if err := readDataFile(peopleFileName, "people", &people); err != nil {
+			return err
+		}
+		if err := repo.WritePeople(people); err != nil {
+			return err
+		}
+	}
+	if isEmpty, err := empty(accountsBucket); err != nil {
+		return err
+	} else if isEmpty && exists(accountsFileName) {
+		var accounts Accounts
+		if err := readDataFile(accountsFileName, "accounts", &accounts); err != nil {
+			return err
+		}
+		if err := repo.WriteAccounts(accounts); err != nil {
+			return err
+		}
+	}
+	if isEmpty, err := empty(rolesBucket); err != nil {
+		return err
+	} else if isEmpty {
+		roles := defaultRoles()
+		if exists(rolesFileName) {
+			if err := readDataFile(rolesFileName, "roles", &roles); err != nil {
+				return err
+			}
+		}
+		if err := repo.WriteRoles(roles); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// readAll unmarshals every value of the bucket with the function
+func (repo *boltRepository) readAll(bucket []byte, unmarshal func(value []byte) error) error {
+	err := repo.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(bucket).ForEach(func(_, value []byte) error {
+			return unmarshal(value)
+		})
+	})
+	if err != nil {
+		return fmt.Errorf("failed to read %s from database: %s", bucket, err.Error())
+	}
+	return nil
+}
+
+// readOne unmarshals the value of the key into the record, and returns false
+// if there is no such key
+func (repo *boltRepository) readOne(bucket []byte, key []byte, record interface{}) (bool, error) {
+	found := false
+	err := repo.db.View(func(tx *bolt.Tx) error {
+		value := tx.Bucket(bucket).Get(key)
+		if value == nil {
+			return nil
+		}
+		found = true
+		return json.Unmarshal(value, record)
+	})
+	if err != nil {
+		return false, fmt.Errorf("failed to read %s from database: %s", bucket, err.Error())
+	}
+	return found, nil
+}
+
+// writeAll replaces every value of the bucket with the values by their keys
+// in one transaction. A key that is in the values twice keeps its first value
+func (repo *boltRepository) writeAll(bucket []byte, keys [][]byte, values []interface{}) error {
+	err := repo.db.Update(func(tx *bolt.Tx) error {
+		if err := tx.DeleteBucket(bucket); err != nil && !errors.Is(err, bolt.ErrBucketNotFound) {
+			return err
+		}
+		b, err := tx.CreateBucket(bucket)
+		if err != nil {
+			return err
+		}
+		for i, key := range keys {
+			if b.Get(key) != nil {
+				continue
+			}
+			value, err := json.Marshal(values[i])
+			if err != nil {
+				return err
+			}
+			if err := b.Put(key, value); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to write %s to database: %s", bucket, err.Error())
+	}
+	return nil
+}
+
+// GetCards returns every card, sorted by card ID
+func (repo *boltRepository) GetCards() (Cards, error) {
+	cards := Cards{Cards: []Card{}}
+	err := repo.readAll(cardsBucket, func(value []byte) error {
+		var card Card
+		if err := json.Unmarshal(value, &card); err != nil {
+			return err
+		}
+		cards.Cards = append(cards.Cards, card)
+		return nil
+	})
+	if err != nil {
+		return Cards{}, err
+	}
+	return cards, nil
+}
+
+// GetPeople returns every person, sorted by person ID
+func (repo *boltRepository) GetPeople() (People, error) {
+	people := People{People: []Person{}}
+	err := repo.readAll(peopleBucket, func(value []byte) error {
+		var person Person
+		if err := json.Unmarshal(value, &person); err != nil {
+			return err
+		}
+		people.People = append(people.People, person)
+		return nil
+	})
+	if err != nil {
+		return People{}, err
+	}
+	return people, nil
+}
+
+// GetAccounts returns every account, sorted by account ID
+func (repo *boltRepository) GetAccounts() (Accounts, error) {
+	accounts := Accounts{Accounts: []Account{}}
+	err := repo.readAll(accountsBucket, func(value []byte) error {
+		var account Account
+		if err := json.Unmarshal(value, &account); err != nil {
+			return err
+		}
+		accounts.Accounts = append(accounts.Accounts, account)
+		return nil
+	})
+	if err != nil {
+		return Accounts{}, err
+	}
+	return accounts, nil
+}
+
+// GetRoles returns every role, sorted by role ID
+func (repo *boltRepository) GetRoles() (Roles, error) {
+	roles := Roles{Roles: []Role{}}
+	err := repo.readAll(rolesBucket, func(value []byte) error {
+		var role Role
+		if err := json.Unmarshal(value, &role); err != nil {
+			return err
+		}
+		roles.Roles = append(roles.Roles, role)
+		return nil
+	})
+	if err != nil {
+		return Roles{}, err
+	}
+	return roles, nil
+}
+
+// GetCard returns the card with the card ID, and false if there is none
+func (repo *boltRepository) GetCard(cardID string) (Card, bool, error) {
+	var card Card
+	found, err := repo.readOne(cardsBucket, []byte(cardID), &card)
+	return card, found, err
+}
+
+// GetPerson returns the person with the person ID, and false if there is
+// none
+func (repo *boltRepository) GetPerson(personID int) (Person, bool, error) {
+	var person Person
+	found, err := repo.readOne(peopleBucket, idKey(personID), &person)
+	return person, found, err
+}
+
+// GetAccount returns the account with the account ID, and false if there is
+// none
+func (repo *boltRepository) GetAccount(accountID int) (Account, bool, error) {
+	var account Account
+	found, err := repo.readOne(accountsBucket, idKey(accountID), &account)
+	return account, found, err
+}
+
+// GetRole returns the role with the role ID, and false if there is none
+func (repo *boltRepository) GetRole(roleID int) (Role, bool, error) {
+	var role Role
+	found, err := repo.readOne(rolesBucket, idKey(roleID), &role)
+	return role, found, err
+}
+
+// WriteCards replaces every card with the cards
+func (repo *boltRepository) WriteCards(cards Cards) error {
+	keys := make([][]byte, len(cards.Cards))
+	values := make([]interface{}, len(cards.Cards))
+	for i, card := range cards.Cards {
+		keys[i], values[i] = []byte(card.CardID), card
+	}
+	return repo.writeAll(cardsBucket, keys, values)
+}
+
+// WritePeople replaces every person with the people
+func (repo *boltRepository) WritePeople(people People) error {
+	keys := make([][]byte, len(people.People))
+	values := make([]interface{}, len(people.People))
+	for i, person := range people.People {
+		keys[i], values[i] = idKey(person.PersonID), person
+	}
+	return repo.writeAll(peopleBucket, keys, values)
+}
+
+// WriteAccounts replaces every account with the accounts
+func (repo *boltRepository) WriteAccounts(accounts Accounts) error {
+	keys := make([][]byte, len(accounts.Accounts))
+	values := make([]interface{}, len(accounts.Accounts))
+	for i, account := range accounts.Accounts {
+		keys[i], values[i] = idKey(account.AccountID), account
+	}
+	return repo.writeAll(accountsBucket, keys, values)
+}
+
+// WriteRoles replaces every role with the roles
+func (repo *boltRepository) WriteRoles(roles Roles) error {
+	keys := make([][]byte, len(roles.Roles))
+	values := make([]interface{}, len(roles.Roles))
+	for i, role := range roles.Roles {
+		keys[i], values[i] = idKey(role.RoleID), role
+	}
+	return repo.writeAll(rolesBucket, keys, values)
+}
+
+// Close closes the database
+func (repo *boltRepository) Close() error {
+	return repo.db.Close()
+}
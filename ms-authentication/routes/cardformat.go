@@ -0,0 +1,263 @@
+// Copyright © 2026 Intel Corporation. All rights reserved.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package routes
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Character sets of card ID formats. The Wiegand character sets are the
+// bits of a raw Wiegand 26 or 34 frame, which are decoded into the facility
+// code and card number of the frame
+const (
+	CardIDCharsetAny          = "any"
+	CardIDCharsetDecimal      = "decimal"
+	CardIDCharsetHex          = "hex"
+	CardIDCharsetAlphanumeric = "alphanumeric"
+	CardIDCharsetWiegand26    = "wiegand26"
+	CardIDCharsetWiegand34    = "wiegand34"
+)
+
+// Normalizations of card ID formats
+const (
+	cardIDTrimZeros = "trim-zeros"
+	cardIDUpper     = "upper"
+	cardIDLower     = "lower"
+)
+
+// DefaultCardIDFormats accepts the 10-character card IDs of the card reader
+// as they are, unless the CardIDFormats application setting is set
+const DefaultCardIDFormats = CardIDCharsetAny + ":10"
+
+// CardIDFormat is a format of the card IDs that are accepted, and how they
+// are normalized before they are looked up or stored
+type CardIDFormat struct {
+	Charset   string
+	MinLength int
+	MaxLength int
+	// TrimZeros strips the leading zeros of card IDs
+	TrimZeros bool
+	// UpperCase and LowerCase change the case of the letters of card IDs,
+	// such as the digits of hex card IDs
+	UpperCase bool
+	LowerCase bool
+}
+
+// wiegandFrame is the layout of a Wiegand frame: an even parity bit, the
+// facility code, the card number and an odd parity bit. The parity bits
+// cover the first and the second half of the bits in between
+type wiegandFrame struct {
+	bits             int
+	facilityBits     int
+	facilityDigits   int
+	cardNumberDigits int
+}
+
+var wiegandFrames = map[string]wiegandFrame{
+	CardIDCharsetWiegand26: {bits: 26, facilityBits: 8, facilityDigits: 3, cardNumberDigits: 5},
+	CardIDCharsetWiegand34: {bits: 34, facilityBits: 16, facilityDigits: 5, cardNumberDigits: 5},
+}
+
+// parseCardIDFormats parses a comma separated list of card ID formats, such
+// as "decimal:14,hex:16:upper,wiegand26". A format is its character set,
+// its length or range of lengths, such as 10 or 8-16, and its
+// normalizations, which are trim-zeros, upper and lower. Wiegand formats
+// only have their character set, since their frames have a fixed length
+func parseCardIDFormats(value string) ([]CardIDFormat, error) {
+	var formats []CardIDFormat
+	for _, field := range strings.Split(value, ",") {
+		field = strings.TrimSpace(field)
+		if field == "" {
+			continue
+		}
+		format, err := parseCardIDFormat(field)
+		if err != nil {
+			return nil, err
+		}
+		formats = append(formats, format)
+	}
+	if len(formats) == 0 {
+		return nil, fmt.Errorf("at least one card ID format is required")
+	}
+	return formats, nil
+}
+
+func parseCardIDFormat(value string) (CardIDFormat, error) {
+	parts := strings.Split(value, ":")
+	format := CardIDFormat{Charset: strings.ToLower(parts[0])}
+	if frame, isWiegand := wiegandFrames[format.Charset]; isWiegand {
+		if len(parts) > 1 {
+			return CardIDFormat{}, fmt.Errorf("card ID format %q: %s card IDs have no length or normalization", value, format.Charset)
+		}
+		format.MinLength, format.MaxLength = frame.bits, frame.bits
+		return format, nil
+	}
+	switch format.Charset {
+	case CardIDCharsetAny, CardIDCharsetDecimal, CardIDCharsetHex, CardIDCharsetAlphanumeric:
+	default:
+		return CardIDFormat{}, fmt.Errorf("card ID format %q: unknown character set %s", value, parts[0])
+	}
+	if len(parts) < 2 {
+		return CardIDFormat{}, fmt.Errorf("card ID format %q: a length is required", value)
+	}
+	minLength, maxLength, isRange := strings.Cut(parts[1], "-")
+	var err error
+	if format.MinLength, err = strconv.Atoi(minLength); err != nil || format.MinLength <= 0 {
+		return CardIDFormat{}, fmt.Errorf("card ID format %q: length %s is not a positive number", value, minLength)
+	}
+	format.MaxLength = format.MinLength
+	if isRange {
+		if format.MaxLength, err = strconv.Atoi(maxLength); err != nil || format.MaxLength < format.MinLength {
+			return CardIDFormat{}, fmt.Errorf("card ID format %q: length range %s is not valid", value, parts[1])
+		}
+	}
+	for _, normalization := range parts[2:] {
+		switch strings.ToLower(normalization) {
+		case cardIDTrimZeros:
+			format.TrimZeros = true
+		case cardIDUpper:
+			format.UpperCase = true
+		case cardIDLower:
+			format.LowerCase = true
+		default:
+			return CardIDFormat{}, fmt.Errorf("card ID format %q: unknown normalization %s", value, normalization)
+		}
+	}
+	if format.UpperCase && format.LowerCase {
+		return CardIDFormat{}, fmt.Errorf("card ID format %q: upper and lower cannot both be set", value)
+	}
+	return format, nil
+}
+
+// normalize returns the normalized card ID, and false if the card ID does
+// not have the format. A normalized card ID also has the format, so card
+// IDs that were normalized can be sent back as they are: a card ID shorter
+// than the format is only accepted when it is the normalized card ID of the
+// card ID padded with zeros to the length of the format
+func (format CardIDFormat) normalize(cardID string) (string, bool) {
+	if frame, isWiegand := wiegandFrames[format.Charset]; isWiegand {
+		return frame.decode(cardID)
+	}
+	if !format.inCharset(cardID) || len(cardID) > format.MaxLength {
+		return "", false
+	}
+	if len(cardID) < format.MinLength {
+		if !format.TrimZeros || cardID == "" {
+			return "", false
+		}
+		normalized, ok := format.normalize(strings.Repeat("0", format.MinLength-len(cardID)) + cardID)
+		if !ok || normalized != cardID {
+			return "", false
+		}
+		return normalized, true
+	}
+	if format.TrimZeros {
+		cardID = strings.TrimLeft(cardID, "0")
+		if cardID == "" {
+			cardID = "0"
+		}
+	}
+	switch {
+	case format.UpperCase:
+		cardID = strings.ToUpper(cardID)
+	case format.LowerCase:
+		cardID = strings.ToLower(cardID)
+	}
+	return cardID, true
+}
+
+// inCharset returns true if every character of the card ID is in the
+// character set of the format
+func (format CardIDFormat) inCharset(cardID string) bool {
+	for _, char := range cardID {
+		switch format.Charset {
+		case CardIDCharsetDecimal:
+			if char < '0' || char > '9' {
+				return false
+			}
+		case CardIDCharsetHex:
+			if !strings.ContainsRune("0123456789abcdefABCDEF", char) {
+				return false
+			}
+		case CardIDCharsetAlphanumeric:
+			if !strings.ContainsRune("0123456789abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ", char) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// decode returns the facility code and card number of a Wiegand frame as a
+// card ID in the form <facility code>-<card number>, both zero padded, such
+// as 012-34567. The frame is its bits, and must have valid parity bits. A
+// card ID in the decoded form is returned as it is
+func (frame wiegandFrame) decode(cardID string) (string, bool) {
+	if facilityCode, cardNumber, isDecoded := strings.Cut(cardID, "-"); isDecoded {
+		if len(facilityCode) != frame.facilityDigits || len(cardNumber) != frame.cardNumberDigits {
+			return "", false
+		}
+		facility, err := strconv.ParseUint(facilityCode, 10, 64)
+		if err != nil || facility >= 1<<frame.facilityBits {
+			return "", false
+		}
+		number, err := strconv.ParseUint(cardNumber, 10, 64)
+		if err != nil || number >= 1<<(frame.bits-2-frame.facilityBits) {
+			return "", false
+		}
+		return cardID, true
+	}
+
+	if len(cardID) != frame.bits {
+		return "", false
+	}
+	bits := make([]uint64, frame.bits)
+	for i, char := range cardID {
+		switch char {
+		case '0':
+		case '1':
+			bits[i] = 1
+		default:
+			return "", false
+		}
+	}
+	half := (frame.bits - 2) / 2
+	var evenParity, oddParity uint64
+	for i := 1; i <= half; i++ {
+		evenParity ^= bits[i]
+	}
+	for i := half + 1; i <= 2*half; i++ {
+		oddParity ^= bits[i]
+	}
+	if evenParity != bits[0] || oddParity^1 != bits[frame.bits-1] {
+		return "", false
+	}
+	var facility, number uint64
+	for i := 1; i <= frame.facilityBits; i++ {
+		facility = facility<<1 | bits[i]
+	}
+	for i := frame.facilityBits + 1; i < frame.bits-1; i++ {
+		number = number<<1 | bits[i]
+	}
+	return fmt.Sprintf("%0*d-%0*d", frame.facilityDigits, facility, frame.cardNumberDigits, number), true
+}
+
+// normalizeCardID returns the card ID normalized by the first card ID
+// format that it has
+func normalizeCardID(formats []CardIDFormat, cardID string) (string, error) {
+	for _, format := range formats {
+		if normalized, ok := format.normalize(cardID); ok {
+			return normalized, nil
+		}
+	}
+	return "", fmt.Errorf("%w: card ID %q does not have any of the card ID formats", errInvalidRecord, cardID)
+}
+
+// normalizeCardID returns the card ID normalized by the card ID formats of
+// the CardIDFormats application setting
+func (c *Controller) normalizeCardID(cardID string) (string, error) {
+	return normalizeCardID(c.cardIDFormats, cardID)
+}
@@ -0,0 +1,169 @@
+// Copyright © 2026 Intel Corporation. All rights reserved.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package routes
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/edgexfoundry/app-functions-sdk-go/v3/pkg/interfaces/mocks"
+	"github.com/edgexfoundry/go-mod-core-contracts/v3/clients/logger"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseCardIDFormats(t *testing.T) {
+	formats, err := parseCardIDFormats(" decimal:14, HEX:8-16:upper:trim-zeros,wiegand34,")
+	require.NoError(t, err)
+	assert.Equal(t, []CardIDFormat{
+		{Charset: CardIDCharsetDecimal, MinLength: 14, MaxLength: 14},
+		{Charset: CardIDCharsetHex, MinLength: 8, MaxLength: 16, UpperCase: true, TrimZeros: true},
+		{Charset: CardIDCharsetWiegand34, MinLength: 34, MaxLength: 34},
+	}, formats)
+
+	for _, value := range []string{"", "octal:8", "decimal", "decimal:0", "decimal:16-8", "decimal:8-x", "hex:16:reverse", "hex:16:upper:lower", "wiegand26:26"} {
+		_, err := parseCardIDFormats(value)
+		assert.Error(t, err, value)
+	}
+}
+
+func TestNormalizeCardID(t *testing.T) {
+	formats, err := parseCardIDFormats("decimal:14:trim-zeros,hex:16:upper,wiegand26,wiegand34,any:10")
+	require.NoError(t, err)
+	tests := []struct {
+		Name          string
+		CardID        string
+		ExpectedID    string
+		ExpectedError bool
+	}{
+		{"Decimal with leading zeros", "00000001234567", "1234567", false},
+		{"Decimal without leading zeros", "12345678901234", "12345678901234", false},
+		{"Normalized decimal", "1234567", "1234567", false},
+		{"Zero decimal", "00000000000000", "0", false},
+		{"Hex in lower case", "04a1b2c3d4e5f607", "04A1B2C3D4E5F607", false},
+		{"Wiegand 26 frame", "00001001000110000001110011", "018-12345", false},
+		{"Wiegand 26 frame with most bits set", "01111111111111111111111111", "255-65535", false},
+		{"Wiegand 26 frame with wrong parity", "00001001000110000001110010", "", true},
+		{"Normalized Wiegand 26", "018-12345", "018-12345", false},
+		{"Wiegand 34 frame", "1000001001101001011010100001100010", "01234-54321", false},
+		{"Legacy card ID", "0001230001", "0001230001", false},
+		{"Too long", "123456789012345", "", true},
+		{"Too short", "0123", "", true},
+		{"Facility code too large", "256-00001", "", true},
+		{"Short normalized decimal", "5", "5", false},
+		{"Short decimal with a leading zero", "05", "", true},
+	}
+	for _, test := range tests {
+		currentTest := test
+		t.Run(currentTest.Name, func(t *testing.T) {
+			cardID, err := normalizeCardID(formats, currentTest.CardID)
+			if currentTest.ExpectedError {
+				assert.ErrorIs(t, err, errInvalidRecord)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, currentTest.ExpectedID, cardID)
+			again, err := normalizeCardID(formats, cardID)
+			require.NoError(t, err, "a normalized card ID should have a format")
+			assert.Equal(t, cardID, again)
+		})
+	}
+}
+
+func TestNormalizeShortCardID(t *testing.T) {
+	formats, err := parseCardIDFormats("hex:8:trim-zeros:upper")
+	require.NoError(t, err)
+	cardID, err := normalizeCardID(formats, "00000abc")
+	require.NoError(t, err)
+	assert.Equal(t, "ABC", cardID)
+	_, err = normalizeCardID(formats, "ABC")
+	assert.NoError(t, err, "a short card ID in normalized form should be accepted")
+	_, err = normalizeCardID(formats, "abc")
+	assert.ErrorIs(t, err, errInvalidRecord, "a short card ID that is not in normalized form should be refused")
+	_, err = normalizeCardID(formats, "")
+	assert.ErrorIs(t, err, errInvalidRecord)
+}
+
+func TestLoadSettingsMigratesCardIDs(t *testing.T) {
+	defer writeJSONFiles(setupPeople(), setupAccounts(), setupCards())
+	cards := setupCards()
+	cards.Cards[0].CardID = "04a1b2c3d4e5f607"
+	cards.Cards[1].CardID = "04A1B2C3D4E5F608"
+	cards.Cards[2].CardID = "04a1b2c3d4e5f608"
+	cards.Cards[3].ReplacedBy = "04a1b2c3d4e5f607"
+	require.NoError(t, writeJSONFiles(setupPeople(), setupAccounts(), cards))
+
+	mockAppService := &mocks.ApplicationService{}
+	mockAppService.On("LoggingClient").Return(logger.NewMockClient())
+	mockAppService.On("GetAppSetting", mock.Anything).Return(func(name string) (string, error) {
+		return map[string]string{"CardIDFormats": "hex:16:upper,any:10"}[name], nil
+	})
+	c := NewController(mockAppService)
+	require.NoError(t, c.LoadSettings())
+	defer c.Close()
+
+	card, found, err := c.repo.GetCard("04A1B2C3D4E5F607")
+	require.NoError(t, err)
+	require.True(t, found, "the stored card ID should be normalized")
+	assert.Equal(t, 1, card.PersonID)
+	_, found, err = c.repo.GetCard("04a1b2c3d4e5f607")
+	require.NoError(t, err)
+	assert.False(t, found)
+	card, found, err = c.repo.GetCard("04a1b2c3d4e5f608")
+	require.NoError(t, err)
+	require.True(t, found, "a card ID whose normalized form is another card should be kept")
+	assert.Equal(t, 3, card.PersonID)
+	card, found, err = c.repo.GetCard(cards.Cards[3].CardID)
+	require.NoError(t, err)
+	require.True(t, found)
+	assert.Equal(t, "04A1B2C3D4E5F607", card.ReplacedBy)
+}
+
+func TestAuthenticationGetNormalizedCardID(t *testing.T) {
+	defer writeJSONFiles(setupPeople(), setupAccounts(), setupCards())
+	cards := setupCards()
+	cards.Cards[0].CardID = "04A1B2C3D4E5F607"
+	cards.Cards[1].CardID = "018-12345"
+	require.NoError(t, writeJSONFiles(setupPeople(), setupAccounts(), cards))
+
+	values := map[string]string{"CardIDFormats": "hex:16:upper,wiegand26"}
+	mockAppService := &mocks.ApplicationService{}
+	mockAppService.On("LoggingClient").Return(logger.NewMockClient())
+	mockAppService.On("GetAppSetting", mock.Anything).Return(func(name string) (string, error) {
+		return values[name], nil
+	})
+	c := NewController(mockAppService)
+	require.NoError(t, c.LoadSettings())
+	defer c.Close()
+
+	authenticate := func(cardID string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("GET", "/authentication/"+cardID, nil)
+		req = mux.SetURLVars(req, map[string]string{"cardid": cardID})
+		w := httptest.NewRecorder()
+		c.AuthenticationGet(w, req)
+		return w
+	}
+	w := authenticate("04a1b2c3d4e5f607")
+	require.Equal(t, http.StatusOK, w.Code)
+	var authData AuthData
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&authData))
+	assert.Equal(t, "04A1B2C3D4E5F607", authData.CardID, "the card should be found by its normalized card ID")
+	w = authenticate("00001001000110000001110011")
+	assert.Equal(t, http.StatusUnauthorized, w.Code, "the Wiegand frame should find the card of person 2, who is inactive")
+	assert.Contains(t, w.Body.String(), "inactive person")
+	assert.Equal(t, http.StatusBadRequest, authenticate("0001230003").Code, "the legacy format is not configured")
+
+	req := httptest.NewRequest("GET", "/cards/04a1b2c3d4e5f607", nil)
+	req = mux.SetURLVars(req, map[string]string{"cardid": "04a1b2c3d4e5f607"})
+	w = httptest.NewRecorder()
+	c.CardGet(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	values["CardIDFormats"] = "octal:8"
+	assert.Error(t, c.LoadSettings())
+}
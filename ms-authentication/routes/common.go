@@ -10,54 +10,53 @@ import (
 )
 
 // PeopleFileName is the name of the respective struct data file that
-// contains sample authentication data
+// contains sample authentication data, unless the PeopleFileName
+// application setting names another file
 const PeopleFileName = "people.json"
 
 // AccountsFileName is the name of the respective struct data file that
-// contains sample authentication data
+// contains sample authentication data, unless the AccountsFileName
+// application setting names another file
 const AccountsFileName = "accounts.json"
 
 // CardsFileName is the name of the respective struct data file that
-// contains sample authentication data
+// contains sample authentication data, unless the CardsFileName application
+// setting names another file
 const CardsFileName = "cards.json"
 
 // WritePeople writes data to the respective JSON file
 func (people *People) WritePeople() (err error) {
-	peopleJson, err := json.Marshal(people)
-	if err != nil {
-		return fmt.Errorf("failed to marshal people: %s", err.Error())
-	}
-	err = os.WriteFile(PeopleFileName, peopleJson, 0644)
-	if err != nil {
-		return fmt.Errorf("failed to write people data to file: %s", err.Error())
-	}
-	return nil
+	return writeDataFile(PeopleFileName, "people", people, 0644)
 }
 
-// WriteAccounts writes data to the respective JSON file
+// WriteAccounts writes data to the respective JSON file. Only the owner can
+// read the file, since it has the payment and billing information
 func (accounts *Accounts) WriteAccounts() (err error) {
-	accountsJson, err := json.Marshal(accounts)
-	if err != nil {
-		return fmt.Errorf("failed to marshal accounts: %s", err.Error())
-	}
-	err = os.WriteFile(AccountsFileName, accountsJson, 0644)
-	if err != nil {
-		return fmt.Errorf("failed to write accounts data to file: %s", err.Error())
-	}
-	return
+	return writeDataFile(AccountsFileName, "accounts", accounts, 0600)
 }
 
 // WriteCards writes data to the respective JSON file
 func (cards *Cards) WriteCards() (err error) {
-	cardsJson, err := json.Marshal(cards)
+	return writeDataFile(CardsFileName, "cards", cards, 0644)
+}
+
+// writeDataFile writes the data as JSON to the file with the permissions.
+// The name of the data is used in errors
+func writeDataFile(fileName string, name string, data interface{}, perm os.FileMode) error {
+	dataJson, err := json.Marshal(data)
 	if err != nil {
-		return fmt.Errorf("failed to marshal cards: %s", err.Error())
+		return fmt.Errorf("failed to marshal %s: %s", name, err.Error())
 	}
-	err = os.WriteFile(CardsFileName, cardsJson, 0644)
+	err = os.WriteFile(fileName, dataJson, perm)
 	if err != nil {
-		return fmt.Errorf("failed to write cards data to file: %s", err.Error())
+		return fmt.Errorf("failed to write %s data to file: %s", name, err.Error())
 	}
-	return
+	// WriteFile keeps the permissions of a file that exists already
+	err = os.Chmod(fileName, perm)
+	if err != nil {
+		return fmt.Errorf("failed to set permissions of %s file: %s", name, err.Error())
+	}
+	return nil
 }
 
 // DeletePeople writes an empty list to the respective JSON file
@@ -110,38 +109,33 @@ func (cards *Cards) DeleteCard(cardToDelete Card) {
 
 // GetPeopleData reads the data from the respective JSON file
 func GetPeopleData() (people People, err error) {
-	data, err := os.ReadFile(PeopleFileName)
-	if err != nil {
-		return People{}, fmt.Errorf("failed to read from people JSON file: %s", err.Error())
-	}
-	if err = json.Unmarshal(data, &people); err != nil {
-		return People{}, fmt.Errorf("failed to unmarshal people from JSON file: %s", err.Error())
-	}
+	err = readDataFile(PeopleFileName, "people", &people)
 	return
 }
 
 // GetAccountsData reads the data from the respective JSON file
 func GetAccountsData() (accounts Accounts, err error) {
-	data, err := os.ReadFile(AccountsFileName)
-	if err != nil {
-		return Accounts{}, fmt.Errorf("failed to read from accounts JSON file: %s", err.Error())
-	}
-	if err = json.Unmarshal(data, &accounts); err != nil {
-		return Accounts{}, fmt.Errorf("failed to unmarshal accounts from JSON file: %s", err.Error())
-	}
+	err = readDataFile(AccountsFileName, "accounts", &accounts)
 	return
 }
 
 // GetCardsData reads the data from the respective JSON file
 func GetCardsData() (cards Cards, err error) {
-	data, err := os.ReadFile(CardsFileName)
+	err = readDataFile(CardsFileName, "cards", &cards)
+	return
+}
+
+// readDataFile reads the JSON file into the data. The name of the data is
+// used in errors
+func readDataFile(fileName string, name string, data interface{}) error {
+	dataJson, err := os.ReadFile(fileName)
 	if err != nil {
-		return Cards{}, fmt.Errorf("failed to read from cards JSON file: %s", err.Error())
+		return fmt.Errorf("failed to read from %s JSON file: %s", name, err.Error())
 	}
-	if err = json.Unmarshal(data, &cards); err != nil {
-		return Cards{}, fmt.Errorf("failed to unmarshal cards from JSON file: %s", err.Error())
+	if err = json.Unmarshal(dataJson, data); err != nil {
+		return fmt.Errorf("failed to unmarshal %s from JSON file: %s", name, err.Error())
 	}
-	return
+	return nil
 }
 
 // GetPersonByPersonID queries and returns the respective data
@@ -5,6 +5,10 @@ package routes
 
 import (
 	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
 
 	"github.com/edgexfoundry/app-functions-sdk-go/v3/pkg/interfaces"
 	"github.com/edgexfoundry/go-mod-core-contracts/v3/clients/logger"
@@ -13,13 +17,267 @@ import (
 type Controller struct {
 	service interfaces.ApplicationService
 	lc      logger.LoggingClient
+	// repo stores the cards, people and accounts
+	repo Repository
+	// dataMutex serializes changes to the cards, people and accounts, so
+	// that concurrent changes are not lost
+	dataMutex sync.Mutex
+	// pii encrypts and tokenizes the payment and billing information of
+	// accounts, once LoadSecrets loaded its key
+	pii *piiProtector
+	// pinRequiredRoles are the roles whose cards must have their PIN
+	// verified after they authenticate
+	pinRequiredRoles map[int]bool
+	// pinMaxAttempts is the number of wrong PINs in a row that lock the PIN
+	// of a card
+	pinMaxAttempts int
+	// identity resolves the cards that are authenticated, or is nil when
+	// they are resolved with the repository
+	identity IdentityProvider
+	// cardIDFormats are the formats of the card IDs that are accepted, which
+	// normalize card IDs before they are looked up or stored
+	cardIDFormats []CardIDFormat
 }
 
 func NewController(service interfaces.ApplicationService) Controller {
+	cardIDFormats, _ := parseCardIDFormats(DefaultCardIDFormats)
 	return Controller{
 		service: service,
 		lc:      service.LoggingClient(),
+		repo:    newFileRepository(CardsFileName, PeopleFileName, AccountsFileName, RolesFileName),
+
+		pinRequiredRoles: map[int]bool{},
+		pinMaxAttempts:   DefaultPINMaxAttempts,
+		cardIDFormats:    cardIDFormats,
+	}
+}
+
+// LoadSettings reads the optional ApplicationSettings of this service and
+// opens the repository they select. Without them, the cards, people and
+// accounts are stored in the JSON files in the working directory, and no
+// role requires a PIN
+func (c *Controller) LoadSettings() error {
+	pinRequiredRoles, err := parseRoleIDs(c.optionalAppSetting("PINRequiredRoles", ""))
+	if err != nil {
+		return fmt.Errorf("failed to parse PINRequiredRoles: %s", err.Error())
+	}
+	pinMaxAttempts, err := strconv.Atoi(c.optionalAppSetting("PINMaxAttempts", strconv.Itoa(DefaultPINMaxAttempts)))
+	if err != nil || pinMaxAttempts <= 0 {
+		return fmt.Errorf("PINMaxAttempts must be a positive number")
+	}
+	cardIDFormats, err := parseCardIDFormats(c.optionalAppSetting("CardIDFormats", DefaultCardIDFormats))
+	if err != nil {
+		return fmt.Errorf("failed to parse CardIDFormats: %s", err.Error())
+	}
+
+	settings := StorageSettings{
+		StorageType:      c.optionalAppSetting("StorageType", StorageTypeFile),
+		CardsFileName:    c.optionalAppSetting("CardsFileName", CardsFileName),
+		PeopleFileName:   c.optionalAppSetting("PeopleFileName", PeopleFileName),
+		AccountsFileName: c.optionalAppSetting("AccountsFileName", AccountsFileName),
+		RolesFileName:    c.optionalAppSetting("RolesFileName", RolesFileName),
+		DatabaseFileName: c.optionalAppSetting("DatabaseFileName", DatabaseFileName),
+	}
+	repo, err := NewRepository(settings)
+	if err != nil {
+		return fmt.Errorf("failed to open %s storage: %s", settings.StorageType, err.Error())
+	}
+	c.dataMutex.Lock()
+	defer c.dataMutex.Unlock()
+	if err := c.repo.Close(); err != nil {
+		c.lc.Warnf("failed to close storage: %s", err.Error())
+	}
+	c.repo = repo
+	c.pinRequiredRoles = pinRequiredRoles
+	c.pinMaxAttempts = pinMaxAttempts
+	c.cardIDFormats = cardIDFormats
+	if err := c.migrateCardIDs(); err != nil {
+		return fmt.Errorf("failed to normalize the stored card IDs: %s", err.Error())
+	}
+	c.lc.Infof("Storing authentication data with the %s storage type", settings.StorageType)
+	return nil
+}
+
+// migrateCardIDs rewrites the stored card IDs, and the card IDs that cards
+// were replaced by, into the normalized form of the card ID formats, so that
+// cards stored before the formats changed are still found by their card IDs.
+// A card ID without a format, or whose normalized form is the card ID of
+// another card, is kept as it is and logged
+func (c *Controller) migrateCardIDs() error {
+	cards, err := c.repo.GetCards()
+	if err != nil {
+		return fmt.Errorf("failed to read the cards: %s", err.Error())
+	}
+	stored := map[string]bool{}
+	for _, card := range cards.Cards {
+		stored[card.CardID] = true
+	}
+	normalize := func(cardID string) string {
+		normalized, err := c.normalizeCardID(cardID)
+		if err != nil {
+			c.lc.Warnf("Card ID %s does not have any of the card ID formats, and is kept as it is", cardID)
+			return cardID
+		}
+		return normalized
+	}
+	migrated := 0
+	for i, card := range cards.Cards {
+		cardID := normalize(card.CardID)
+		if cardID != card.CardID {
+			if stored[cardID] {
+				c.lc.Warnf("Card ID %s is kept as it is, since its normalized card ID %s is another card", card.CardID, cardID)
+			} else {
+				delete(stored, card.CardID)
+				stored[cardID] = true
+				cards.Cards[i].CardID = cardID
+				migrated++
+			}
+		}
+		if card.ReplacedBy != "" {
+			if replacedBy := normalize(card.ReplacedBy); replacedBy != card.ReplacedBy {
+				cards.Cards[i].ReplacedBy = replacedBy
+				migrated++
+			}
+		}
+	}
+	if migrated == 0 {
+		return nil
+	}
+	if err := c.repo.WriteCards(cards); err != nil {
+		return fmt.Errorf("failed to write the normalized card IDs: %s", err.Error())
+	}
+	c.lc.Infof("Normalized %d card IDs of the stored cards", migrated)
+	return nil
+}
+
+// parseRoleIDs parses a comma separated list of role IDs, such as "1,3"
+func parseRoleIDs(value string) (map[int]bool, error) {
+	roleIDs := map[int]bool{}
+	for _, field := range strings.Split(value, ",") {
+		field = strings.TrimSpace(field)
+		if field == "" {
+			continue
+		}
+		roleID, err := strconv.Atoi(field)
+		if err != nil {
+			return nil, fmt.Errorf("role ID %s is not a number", field)
+		}
+		roleIDs[roleID] = true
+	}
+	return roleIDs, nil
+}
+
+// Close releases the storage of the cards, people and accounts
+func (c *Controller) Close() error {
+	c.dataMutex.Lock()
+	defer c.dataMutex.Unlock()
+	return c.repo.Close()
+}
+
+// optionalAppSetting returns the value of the application setting, or the
+// default value when it is not set
+func (c *Controller) optionalAppSetting(name string, defaultValue string) string {
+	value, err := c.service.GetAppSetting(name)
+	if err != nil || len(value) == 0 {
+		return defaultValue
+	}
+	return value
+}
+
+// LoadSecrets loads the key that encrypts the personal and payment data of
+// accounts, and the admin token, from the secret store. It fails when either
+// is missing or still a published development value. Accounts stored before
+// encryption was enabled are encrypted and tokenized right away
+func (c *Controller) LoadSecrets() error {
+	secretName := c.optionalAppSetting("PIISecretName", DefaultPIISecretName)
+	secrets, err := c.service.SecretProvider().GetSecret(secretName, EncryptionKeySecretKey, AdminTokenSecretKey)
+	if err != nil {
+		return fmt.Errorf("failed to get secret %s: %s", secretName, err.Error())
+	}
+	key, err := parsePIISecrets(secrets[EncryptionKeySecretKey], secrets[AdminTokenSecretKey])
+	if err != nil {
+		return fmt.Errorf("invalid secret %s: %s", secretName, err.Error())
+	}
+	pii, err := newPIIProtector(key, secrets[AdminTokenSecretKey])
+	if err != nil {
+		return err
 	}
+
+	c.dataMutex.Lock()
+	defer c.dataMutex.Unlock()
+	c.pii = pii
+	accounts, err := c.readAccounts()
+	if err != nil {
+		return err
+	}
+	return c.writeAccounts(accounts)
+}
+
+// readAccounts reads the accounts with their fields decrypted
+func (c *Controller) readAccounts() (Accounts, error) {
+	if c.pii == nil {
+		return Accounts{}, errPIIKeyNotLoaded
+	}
+	accounts, err := c.repo.GetAccounts()
+	if err != nil {
+		return Accounts{}, err
+	}
+	for i, account := range accounts.Accounts {
+		if accounts.Accounts[i], err = c.pii.openAccount(account); err != nil {
+			return Accounts{}, err
+		}
+	}
+	return accounts, nil
+}
+
+// openAccount returns one stored account with its fields decrypted
+func (c *Controller) openAccount(account Account) (Account, error) {
+	if c.pii == nil {
+		return Account{}, errPIIKeyNotLoaded
+	}
+	return c.pii.openAccount(account)
+}
+
+// writeAccounts writes the accounts with their fields encrypted
+func (c *Controller) writeAccounts(accounts Accounts) error {
+	if c.pii == nil {
+		return errPIIKeyNotLoaded
+	}
+	sealed := Accounts{Accounts: make([]Account, len(accounts.Accounts))}
+	for i, account := range accounts.Accounts {
+		var err error
+		if sealed.Accounts[i], err = c.pii.sealAccount(account); err != nil {
+			return err
+		}
+	}
+	return c.repo.WriteAccounts(sealed)
+}
+
+// accountsView returns the accounts as the caller of the request is allowed
+// to see them: masked, unless the caller has the admin scope
+func (c *Controller) accountsView(req *http.Request, accounts ...Account) []Account {
+	if c.pii != nil && c.pii.hasAdminScope(req) {
+		return accounts
+	}
+	masked := make([]Account, len(accounts))
+	for i, account := range accounts {
+		masked[i] = maskAccount(account)
+	}
+	return masked
+}
+
+// requireAdminScope returns true if the request has the admin scope, and
+// otherwise responds with 401 so that the change is refused. Every request
+// that changes cards, people, accounts, roles or PINs requires it, since a
+// card of a role with more permissions would open the cooler
+func (c *Controller) requireAdminScope(writer http.ResponseWriter, req *http.Request) bool {
+	if c.pii != nil && c.pii.hasAdminScope(req) {
+		return true
+	}
+	c.lc.Errorf("Refused %s %s without the admin scope", req.Method, req.URL.Path)
+	writer.WriteHeader(http.StatusUnauthorized)
+	writer.Write([]byte("this request requires the " + AdminTokenHeader + " header"))
+	return false
 }
 
 func (c *Controller) AddAllRoutes() error {
@@ -27,6 +285,131 @@ func (c *Controller) AddAllRoutes() error {
 	if errWithMsg := errorAddRouteHandler(err); errWithMsg != nil {
 		return errWithMsg
 	}
+
+	err = c.service.AddRoute("/accounts", c.AccountsGet, "GET")
+	if errWithMsg := errorAddRouteHandler(err); errWithMsg != nil {
+		return errWithMsg
+	}
+
+	err = c.service.AddRoute("/accounts/{accountid}", c.AccountGet, "GET")
+	if errWithMsg := errorAddRouteHandler(err); errWithMsg != nil {
+		return errWithMsg
+	}
+
+	err = c.service.AddRoute("/accounts", c.AccountPost, "POST")
+	if errWithMsg := errorAddRouteHandler(err); errWithMsg != nil {
+		return errWithMsg
+	}
+
+	err = c.service.AddRoute("/accounts/{accountid}", c.AccountPut, "PUT")
+	if errWithMsg := errorAddRouteHandler(err); errWithMsg != nil {
+		return errWithMsg
+	}
+
+	err = c.service.AddRoute("/accounts/{accountid}", c.AccountDelete, "DELETE")
+	if errWithMsg := errorAddRouteHandler(err); errWithMsg != nil {
+		return errWithMsg
+	}
+
+	err = c.service.AddRoute("/people", c.PeopleGet, "GET")
+	if errWithMsg := errorAddRouteHandler(err); errWithMsg != nil {
+		return errWithMsg
+	}
+
+	err = c.service.AddRoute("/people", c.PersonPost, "POST")
+	if errWithMsg := errorAddRouteHandler(err); errWithMsg != nil {
+		return errWithMsg
+	}
+
+	err = c.service.AddRoute("/people/{personid}", c.PersonGet, "GET")
+	if errWithMsg := errorAddRouteHandler(err); errWithMsg != nil {
+		return errWithMsg
+	}
+
+	err = c.service.AddRoute("/people/{personid}", c.PersonPut, "PUT")
+	if errWithMsg := errorAddRouteHandler(err); errWithMsg != nil {
+		return errWithMsg
+	}
+
+	err = c.service.AddRoute("/people/{personid}", c.PersonDelete, "DELETE")
+	if errWithMsg := errorAddRouteHandler(err); errWithMsg != nil {
+		return errWithMsg
+	}
+
+	err = c.service.AddRoute("/roles", c.RolesGet, "GET")
+	if errWithMsg := errorAddRouteHandler(err); errWithMsg != nil {
+		return errWithMsg
+	}
+
+	err = c.service.AddRoute("/roles", c.RolePost, "POST")
+	if errWithMsg := errorAddRouteHandler(err); errWithMsg != nil {
+		return errWithMsg
+	}
+
+	err = c.service.AddRoute("/roles/{roleid}", c.RoleGet, "GET")
+	if errWithMsg := errorAddRouteHandler(err); errWithMsg != nil {
+		return errWithMsg
+	}
+
+	err = c.service.AddRoute("/roles/{roleid}", c.RolePut, "PUT")
+	if errWithMsg := errorAddRouteHandler(err); errWithMsg != nil {
+		return errWithMsg
+	}
+
+	err = c.service.AddRoute("/roles/{roleid}", c.RoleDelete, "DELETE")
+	if errWithMsg := errorAddRouteHandler(err); errWithMsg != nil {
+		return errWithMsg
+	}
+
+	err = c.service.AddRoute("/cards", c.CardsGet, "GET")
+	if errWithMsg := errorAddRouteHandler(err); errWithMsg != nil {
+		return errWithMsg
+	}
+
+	err = c.service.AddRoute("/cards", c.CardPost, "POST")
+	if errWithMsg := errorAddRouteHandler(err); errWithMsg != nil {
+		return errWithMsg
+	}
+
+	err = c.service.AddRoute("/cards/{cardid}", c.CardGet, "GET")
+	if errWithMsg := errorAddRouteHandler(err); errWithMsg != nil {
+		return errWithMsg
+	}
+
+	err = c.service.AddRoute("/cards/{cardid}", c.CardPut, "PUT")
+	if errWithMsg := errorAddRouteHandler(err); errWithMsg != nil {
+		return errWithMsg
+	}
+
+	err = c.service.AddRoute("/cards/{cardid}", c.CardDelete, "DELETE")
+	if errWithMsg := errorAddRouteHandler(err); errWithMsg != nil {
+		return errWithMsg
+	}
+
+	err = c.service.AddRoute("/cards/{cardid}/suspension", c.CardSuspensionPost, "POST")
+	if errWithMsg := errorAddRouteHandler(err); errWithMsg != nil {
+		return errWithMsg
+	}
+
+	err = c.service.AddRoute("/cards/{cardid}/suspension", c.CardSuspensionDelete, "DELETE")
+	if errWithMsg := errorAddRouteHandler(err); errWithMsg != nil {
+		return errWithMsg
+	}
+
+	err = c.service.AddRoute("/cards/{cardid}/lost", c.CardLostPost, "POST")
+	if errWithMsg := errorAddRouteHandler(err); errWithMsg != nil {
+		return errWithMsg
+	}
+
+	err = c.service.AddRoute("/cards/{cardid}/pin", c.CardPINPut, "PUT")
+	if errWithMsg := errorAddRouteHandler(err); errWithMsg != nil {
+		return errWithMsg
+	}
+
+	err = c.service.AddRoute("/cards/{cardid}/pin/verify", c.CardPINVerifyPost, "POST")
+	if errWithMsg := errorAddRouteHandler(err); errWithMsg != nil {
+		return errWithMsg
+	}
 	return nil
 }
 func errorAddRouteHandler(err error) error {
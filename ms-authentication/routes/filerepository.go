@@ -0,0 +1,330 @@
+// Copyright © 2026 Intel Corporation. All rights reserved.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package routes
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+// dataFile is a JSON file of the file repository, with the state it had when
+// it was last read or written
+type dataFile struct {
+	fileName string
+	name     string
+	perm     os.FileMode
+	modTime  time.Time
+	size     int64
+	loaded   bool
+}
+
+// changed returns the state of the file, and true if it changed since it was
+// last read or written
+func (file *dataFile) changed() (os.FileInfo, bool, error) {
+	info, err := os.Stat(file.fileName)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to read from %s JSON file: %s", file.name, err.Error())
+	}
+	return info, !file.loaded || !info.ModTime().Equal(file.modTime) || info.Size() != file.size, nil
+}
+
+// seen records the state of the file after it was read or written
+func (file *dataFile) seen(info os.FileInfo) {
+	file.modTime = info.ModTime()
+	file.size = info.Size()
+	file.loaded = true
+}
+
+// fileRepository stores the cards, people, accounts and roles in JSON files.
+// The files are kept in memory with an index by ID, and read again when they
+// change, such as when they are edited by hand. Until the roles file exists,
+// the roles are the default roles
+type fileRepository struct {
+	mutex        sync.Mutex
+	cardsFile    dataFile
+	peopleFile   dataFile
+	accountsFile dataFile
+	rolesFile    dataFile
+	cards        Cards
+	cardIndex    map[string]int
+	people       People
+	personIndex  map[int]int
+	accounts     Accounts
+	accountIndex map[int]int
+	roles        Roles
+	roleIndex    map[int]int
+}
+
+// newFileRepository returns a repository of the JSON files. A file name that
+// is empty is the default file name in the working directory
+func newFileRepository(cardsFileName string, peopleFileName string, accountsFileName string, rolesFileName string) *fileRepository {
+	if cardsFileName == "" {
+		cardsFileName = CardsFileName
+	}
+	if peopleFileName == "" {
+		peopleFileName = PeopleFileName
+	}
+	if accountsFileName == "" {
+		accountsFileName = AccountsFileName
+	}
+	if rolesFileName == "" {
+		rolesFileName = RolesFileName
+	}
+	return &fileRepository{
+		cardsFile:    dataFile{fileName: cardsFileName, name: "cards", perm: 0644},
+		peopleFile:   dataFile{fileName: peopleFileName, name: "people", perm: 0644},
+		accountsFile: dataFile{fileName: accountsFileName, name: "accounts", perm: 0600},
+		rolesFile:    dataFile{fileName: rolesFileName, name: "roles", perm: 0644},
+	}
+}
+
+// loadCards reads the cards file if it changed. The caller must hold the
+// mutex
+func (repo *fileRepository) loadCards() error {
+	info, changed, err := repo.cardsFile.changed()
+	if err != nil || !changed {
+		return err
+	}
+	var cards Cards
+	if err := readDataFile(repo.cardsFile.fileName, repo.cardsFile.name, &cards); err != nil {
+		return err
+	}
+	repo.cards, repo.cardIndex = cards, indexCards(cards)
+	repo.cardsFile.seen(info)
+	return nil
+}
+
+// loadPeople reads the people file if it changed. The caller must hold the
+// mutex
+func (repo *fileRepository) loadPeople() error {
+	info, changed, err := repo.peopleFile.changed()
+	if err != nil || !changed {
+		return err
+	}
+	var people People
+	if err := readDataFile(repo.peopleFile.fileName, repo.peopleFile.name, &people); err != nil {
+		return err
+	}
+	repo.people, repo.personIndex = people, indexPeople(people)
+	repo.peopleFile.seen(info)
+	return nil
+}
+
+// loadAccounts reads the accounts file if it changed. The caller must hold
+// the mutex
+func (repo *fileRepository) loadAccounts() error {
+	info, changed, err := repo.accountsFile.changed()
+	if err != nil || !changed {
+		return err
+	}
+	var accounts Accounts
+	if err := readDataFile(repo.accountsFile.fileName, repo.accountsFile.name, &accounts); err != nil {
+		return err
+	}
+	repo.accounts, repo.accountIndex = accounts, indexAccounts(accounts)
+	repo.accountsFile.seen(info)
+	return nil
+}
+
+// loadRoles reads the roles file if it changed, or uses the default roles
+// while there is no roles file. The caller must hold the mutex
+func (repo *fileRepository) loadRoles() error {
+	if _, err := os.Stat(repo.rolesFile.fileName); errors.Is(err, os.ErrNotExist) {
+		roles := defaultRoles()
+		repo.roles, repo.roleIndex = roles, indexRoles(roles)
+		repo.rolesFile.loaded = false
+		return nil
+	}
+	info, changed, err := repo.rolesFile.changed()
+	if err != nil || !changed {
+		return err
+	}
+	var roles Roles
+	if err := readDataFile(repo.rolesFile.fileName, repo.rolesFile.name, &roles); err != nil {
+		return err
+	}
+	repo.roles, repo.roleIndex = roles, indexRoles(roles)
+	repo.rolesFile.seen(info)
+	return nil
+}
+
+// write writes the data to the file and records its new state. The caller
+// must hold the mutex
+func (repo *fileRepository) write(file *dataFile, data interface{}) error {
+	if err := writeDataFile(file.fileName, file.name, data, file.perm); err != nil {
+		return err
+	}
+	info, err := os.Stat(file.fileName)
+	if err != nil {
+		return fmt.Errorf("failed to read from %s JSON file: %s", file.name, err.Error())
+	}
+	file.seen(info)
+	return nil
+}
+
+// GetCards returns every card
+func (repo *fileRepository) GetCards() (Cards, error) {
+	repo.mutex.Lock()
+	defer repo.mutex.Unlock()
+	if err := repo.loadCards(); err != nil {
+		return Cards{}, err
+	}
+	cards := Cards{Cards: make([]Card, len(repo.cards.Cards))}
+	copy(cards.Cards, repo.cards.Cards)
+	return cards, nil
+}
+
+// GetPeople returns every person
+func (repo *fileRepository) GetPeople() (People, error) {
+	repo.mutex.Lock()
+	defer repo.mutex.Unlock()
+	if err := repo.loadPeople(); err != nil {
+		return People{}, err
+	}
+	people := People{People: make([]Person, len(repo.people.People))}
+	copy(people.People, repo.people.People)
+	return people, nil
+}
+
+// GetAccounts returns every account
+func (repo *fileRepository) GetAccounts() (Accounts, error) {
+	repo.mutex.Lock()
+	defer repo.mutex.Unlock()
+	if err := repo.loadAccounts(); err != nil {
+		return Accounts{}, err
+	}
+	accounts := Accounts{Accounts: make([]Account, len(repo.accounts.Accounts))}
+	copy(accounts.Accounts, repo.accounts.Accounts)
+	return accounts, nil
+}
+
+// GetRoles returns every role
+func (repo *fileRepository) GetRoles() (Roles, error) {
+	repo.mutex.Lock()
+	defer repo.mutex.Unlock()
+	if err := repo.loadRoles(); err != nil {
+		return Roles{}, err
+	}
+	roles := Roles{Roles: make([]Role, len(repo.roles.Roles))}
+	copy(roles.Roles, repo.roles.Roles)
+	return roles, nil
+}
+
+// GetCard returns the card with the card ID, and false if there is none
+func (repo *fileRepository) GetCard(cardID string) (Card, bool, error) {
+	repo.mutex.Lock()
+	defer repo.mutex.Unlock()
+	if err := repo.loadCards(); err != nil {
+		return Card{}, false, err
+	}
+	i, ok := repo.cardIndex[cardID]
+	if !ok {
+		return Card{}, false, nil
+	}
+	return repo.cards.Cards[i], true, nil
+}
+
+// GetPerson returns the person with the person ID, and false if there is
+// none
+func (repo *fileRepository) GetPerson(personID int) (Person, bool, error) {
+	repo.mutex.Lock()
+	defer repo.mutex.Unlock()
+	if err := repo.loadPeople(); err != nil {
+		return Person{}, false, err
+	}
+	i, ok := repo.personIndex[personID]
+	if !ok {
+		return Person{}, false, nil
+	}
+	return repo.people.People[i], true, nil
+}
+
+// GetAccount returns the account with the account ID, and false if there is
+// none
+func (repo *fileRepository) GetAccount(accountID int) (Account, bool, error) {
+	repo.mutex.Lock()
+	defer repo.mutex.Unlock()
+	if err := repo.loadAccounts(); err != nil {
+		return Account{}, false, err
+	}
+	i, ok := repo.accountIndex[accountID]
+	if !ok {
+		return Account{}, false, nil
+	}
+	return repo.accounts.Accounts[i], true, nil
+}
+
+// GetRole returns the role with the role ID, and false if there is none
+func (repo *fileRepository) GetRole(roleID int) (Role, bool, error) {
+	repo.mutex.Lock()
+	defer repo.mutex.Unlock()
+	if err := repo.loadRoles(); err != nil {
+		return Role{}, false, err
+	}
+	i, ok := repo.roleIndex[roleID]
+	if !ok {
+		return Role{}, false, nil
+	}
+	return repo.roles.Roles[i], true, nil
+}
+
+// WriteCards replaces every card with the cards
+func (repo *fileRepository) WriteCards(cards Cards) error {
+	repo.mutex.Lock()
+	defer repo.mutex.Unlock()
+	stored := Cards{Cards: make([]Card, len(cards.Cards))}
+	copy(stored.Cards, cards.Cards)
+	if err := repo.write(&repo.cardsFile, stored); err != nil {
+		return err
+	}
+	repo.cards, repo.cardIndex = stored, indexCards(stored)
+	return nil
+}
+
+// WritePeople replaces every person with the people
+func (repo *fileRepository) WritePeople(people People) error {
+	repo.mutex.Lock()
+	defer repo.mutex.Unlock()
+	stored := People{People: make([]Person, len(people.People))}
+	copy(stored.People, people.People)
+	if err := repo.write(&repo.peopleFile, stored); err != nil {
+		return err
+	}
+	repo.people, repo.personIndex = stored, indexPeople(stored)
+	return nil
+}
+
+// WriteAccounts replaces every account with the accounts
+func (repo *fileRepository) WriteAccounts(accounts Accounts) error {
+	repo.mutex.Lock()
+	defer repo.mutex.Unlock()
+	stored := Accounts{Accounts: make([]Account, len(accounts.Accounts))}
+	copy(stored.Accounts, accounts.Accounts)
+	if err := repo.write(&repo.accountsFile, stored); err != nil {
+		return err
+	}
+	repo.accounts, repo.accountIndex = stored, indexAccounts(stored)
+	return nil
+}
+
+// WriteRoles replaces every role with the roles
+func (repo *fileRepository) WriteRoles(roles Roles) error {
+	repo.mutex.Lock()
+	defer repo.mutex.Unlock()
+	stored := Roles{Roles: make([]Role, len(roles.Roles))}
+	copy(stored.Roles, roles.Roles)
+	if err := repo.write(&repo.rolesFile, stored); err != nil {
+		return err
+	}
+	repo.roles, repo.roleIndex = stored, indexRoles(stored)
+	return nil
+}
+
+// Close releases the storage. The files are not kept open
+func (repo *fileRepository) Close() error {
+	return nil
+}
@@ -5,73 +5,91 @@ package routes
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
+	"strconv"
+	"time"
 
 	"github.com/gorilla/mux"
 )
 
-// AuthenticationGet accepts a 10-character URL parameter in the form:
+// AuthenticationGet accepts a card ID URL parameter in the form:
 // /authentication/0001230001
+// The card ID must have one of the card ID formats, and is normalized
+// before it is looked up
 // It will look up the associated Person and Account for the given card and
 // return an instance of AuthData
 func (c *Controller) AuthenticationGet(writer http.ResponseWriter, req *http.Request) {
 	vars := mux.Vars(req)
-	cardID := vars["cardid"]
-	// check if the passed cardID is valid
-	if cardID == "" || len(cardID) != 10 {
-		c.lc.Infof("Please pass in a 10-character card ID as a URL parameter, like this: /authentication/0001230001")
+	// check if the passed cardID has one of the card ID formats, and look it
+	// up by its normalized form
+	cardID, err := c.normalizeCardID(vars["cardid"])
+	if err != nil {
+		c.lc.Infof("Please pass in a card ID of one of the card ID formats as a URL parameter, like this: /authentication/0001230001")
 		writer.WriteHeader(http.StatusBadRequest)
-		writer.Write([]byte("Please pass in a 10-character card ID as a URL parameter, like this: /authentication/0001230001"))
+		writer.Write([]byte("Please pass in a card ID of one of the card ID formats as a URL parameter, like this: /authentication/0001230001"))
 		return
 	}
 
-	// load up all card data so we can find our card
-	cards, err := GetCardsData()
+	// resolve the card, its person and its account by the card ID
+	identity, found, err := c.identityProvider().ResolveCard(cardID)
 	if err != nil {
 		c.lc.Errorf("Failed to read authentication data: %s", err.Error())
+		message := "failed to read authentication data"
+		switch {
+		case errors.Is(err, errReadPeople):
+			message = errReadPeople.Error()
+		case errors.Is(err, errReadAccounts):
+			message = errReadAccounts.Error()
+		}
 		writer.WriteHeader(http.StatusInternalServerError)
-		writer.Write([]byte("failed to read authentication data"))
+		writer.Write([]byte(message))
 		return
 	}
-
-	// check if the card's ID matches our given cardID
-	card := cards.GetCardByCardID(cardID)
-	if card.CardID != cardID {
+	if !found {
 		c.lc.Infof("Card ID: %s is not an authorized card", cardID)
 		writer.WriteHeader(http.StatusUnauthorized)
 		writer.Write([]byte("Card ID is not an authorized card"))
 		return
 	}
+	card, err := c.withStoredState(identity.Card)
+	if err != nil {
+		c.lc.Errorf("Failed to read authentication data: %s", err.Error())
+		writer.WriteHeader(http.StatusInternalServerError)
+		writer.Write([]byte("failed to read authentication data"))
+		return
+	}
+	if card.RevokedAt != 0 {
+		c.denyCard(writer, card, CardStatusRevoked)
+		return
+	}
 	if !card.IsValid {
 		c.lc.Infof("Card ID: %s is not an valid card", cardID)
 		writer.WriteHeader(http.StatusUnauthorized)
 		writer.Write([]byte("Card ID is not a valid card"))
 		return
 	}
-
-	// card is found, get the cardholder's AccountID, RoleID, and PersonID
-	accounts, err := GetAccountsData()
-	if err != nil {
-		c.lc.Errorf("Failed to read accounts data: %s", err.Error())
-		writer.WriteHeader(http.StatusInternalServerError)
-		writer.Write([]byte("failed to read accounts data"))
+	if status := card.Status(time.Now().Unix()); status != CardStatusActive {
+		c.denyCard(writer, card, status)
 		return
 	}
-	people, err := GetPeopleData()
-	if err != nil {
-		c.lc.Errorf("Failed to read people data: %s", err.Error())
-		writer.WriteHeader(http.StatusInternalServerError)
-		writer.Write([]byte("failed to read people data"))
-		return
+	// a card of a role that requires a PIN cannot authenticate without a PIN
+	// that can be verified
+	pinRequired := c.pinRequiredRoles[card.RoleID]
+	if pinRequired {
+		if status := card.pinStatus(); status != CardStatusActive {
+			c.denyCard(writer, card, status)
+			return
+		}
 	}
 
 	// begin to store the output AuthData
-	authData := AuthData{CardID: cardID, RoleID: card.RoleID}
+	authData := AuthData{CardID: cardID, RoleID: card.RoleID, PINRequired: pinRequired}
 
 	// check if the associated person is valid
-	person := people.GetPersonByPersonID(card.PersonID)
-	if person.PersonID != card.PersonID {
-		c.lc.Infof("Card ID is associated with an unknown person %s", person.PersonID)
+	person := identity.Person
+	if person == nil {
+		c.lc.Infof("Card ID is associated with an unknown person %d", card.PersonID)
 		writer.WriteHeader(http.StatusUnauthorized)
 		writer.Write([]byte("Card ID is associated with an unknown person"))
 		return
@@ -87,8 +105,8 @@ func (c *Controller) AuthenticationGet(writer http.ResponseWriter, req *http.Req
 	authData.PersonID = person.PersonID
 
 	// check if the associated account is valid
-	account := accounts.GetAccountByAccountID(person.AccountID)
-	if account.AccountID != person.AccountID {
+	account := identity.Account
+	if account == nil {
 		c.lc.Infof("Card ID is associated with an unknown account %s", person.AccountID)
 		writer.WriteHeader(http.StatusUnauthorized)
 		writer.Write([]byte("Card ID is associated with an unknown account"))
@@ -104,6 +122,23 @@ func (c *Controller) AuthenticationGet(writer http.ResponseWriter, req *http.Req
 	// store the accountID in the output AuthData
 	authData.AccountID = account.AccountID
 
+	// resolve the permissions of the role of the card
+	role, found, err := c.repo.GetRole(card.RoleID)
+	if err != nil {
+		c.lc.Errorf("Failed to read roles data: %s", err.Error())
+		writer.WriteHeader(http.StatusInternalServerError)
+		writer.Write([]byte("failed to read roles data"))
+		return
+	}
+	if !found {
+		c.lc.Infof("Card ID is associated with an unknown role %d", card.RoleID)
+		writer.WriteHeader(http.StatusUnauthorized)
+		writer.Write([]byte("Card ID is associated with an unknown role"))
+		return
+	}
+	authData.RoleName = role.Name
+	authData.Permissions = append([]string{}, role.Permissions...)
+
 	authDataJSON, err := json.Marshal(authData)
 	if err != nil {
 		writer.WriteHeader(http.StatusInternalServerError)
@@ -121,3 +156,262 @@ func (c *Controller) AuthenticationGet(writer http.ResponseWriter, req *http.Req
 	c.lc.Infof("Successfully authenticated person and card")
 	writer.Write(authDataJSON)
 }
+
+// fullView returns true if the request asks for the full records with the
+// "view=full" query parameter instead of their status
+func fullView(req *http.Request) bool {
+	return req.URL.Query().Get("view") == "full"
+}
+
+// denyCard writes the 403 response of a known card that is not allowed to
+// authenticate because of its lifecycle status, so that the status can be
+// shown to the card holder
+func (c *Controller) denyCard(writer http.ResponseWriter, card Card, status string) {
+	c.lc.Infof("Card ID: %s is %s", card.CardID, status)
+	denialJSON, err := json.Marshal(CardDenial{CardID: card.CardID, Status: status, Message: cardStatusMessages[status]})
+	if err != nil {
+		writer.WriteHeader(http.StatusInternalServerError)
+		writer.Write([]byte("failed to marshal authentication data"))
+		return
+	}
+	writer.WriteHeader(http.StatusForbidden)
+	writer.Write(denialJSON)
+}
+
+// AccountsGet returns the status of every account, so that other services
+// can provision and reconcile their own records of the accounts. With the
+// "view=full" query parameter it returns the accounts with their payment
+// and billing information, masked unless the caller has the admin scope
+func (c *Controller) AccountsGet(writer http.ResponseWriter, req *http.Request) {
+	if fullView(req) {
+		accounts, err := c.readAccounts()
+		if err != nil {
+			c.writeDataError(writer, err)
+			return
+		}
+		c.writeRecord(writer, c.accountsView(req, accounts.Accounts...))
+		return
+	}
+
+	accounts, err := c.repo.GetAccounts()
+	if err != nil {
+		c.lc.Errorf("Failed to read accounts data: %s", err.Error())
+		writer.WriteHeader(http.StatusInternalServerError)
+		writer.Write([]byte("failed to read accounts data"))
+		return
+	}
+
+	accountStatuses := []AccountStatus{}
+	for _, account := range accounts.Accounts {
+		accountStatuses = append(accountStatuses, AccountStatus{AccountID: account.AccountID, IsActive: account.IsActive})
+	}
+	accountStatusesJSON, err := json.Marshal(accountStatuses)
+	if err != nil {
+		c.lc.Errorf("Failed to marshal accounts data: %s", err.Error())
+		writer.WriteHeader(http.StatusInternalServerError)
+		writer.Write([]byte("failed to marshal accounts data"))
+		return
+	}
+	writer.Write(accountStatusesJSON)
+}
+
+// AccountGet accepts an account ID URL parameter in the form:
+// /accounts/1
+// It returns the status of the account, or 404 when there is no such account.
+// With the "view=full" query parameter it returns the whole account, masked
+// unless the caller has the admin scope
+func (c *Controller) AccountGet(writer http.ResponseWriter, req *http.Request) {
+	vars := mux.Vars(req)
+	accountID, err := strconv.Atoi(vars["accountid"])
+	if err != nil {
+		c.lc.Infof("Account ID %s is not a number", vars["accountid"])
+		writer.WriteHeader(http.StatusBadRequest)
+		writer.Write([]byte("Please pass in a numeric account ID as a URL parameter, like this: /accounts/1"))
+		return
+	}
+
+	account, found, err := c.repo.GetAccount(accountID)
+	if err != nil {
+		c.lc.Errorf("Failed to read accounts data: %s", err.Error())
+		writer.WriteHeader(http.StatusInternalServerError)
+		writer.Write([]byte("failed to read accounts data"))
+		return
+	}
+	if !found {
+		c.lc.Infof("Account ID %d is not a known account", accountID)
+		writer.WriteHeader(http.StatusNotFound)
+		writer.Write([]byte("Account ID is not a known account"))
+		return
+	}
+
+	if fullView(req) {
+		account, err := c.openAccount(account)
+		if err != nil {
+			c.writeDataError(writer, err)
+			return
+		}
+		c.writeRecord(writer, c.accountsView(req, account)[0])
+		return
+	}
+
+	accountStatusJSON, err := json.Marshal(AccountStatus{AccountID: account.AccountID, IsActive: account.IsActive})
+	if err != nil {
+		c.lc.Errorf("Failed to marshal account data: %s", err.Error())
+		writer.WriteHeader(http.StatusInternalServerError)
+		writer.Write([]byte("failed to marshal account data"))
+		return
+	}
+	writer.Write(accountStatusJSON)
+}
+
+// CardsGet returns every card, or only the cards of the person with the
+// "personid" query parameter
+func (c *Controller) CardsGet(writer http.ResponseWriter, req *http.Request) {
+	personIDstr := req.URL.Query().Get("personid")
+	personID, err := strconv.Atoi(personIDstr)
+	if personIDstr != "" && err != nil {
+		c.lc.Infof("Person ID %s is not a number", personIDstr)
+		writer.WriteHeader(http.StatusBadRequest)
+		writer.Write([]byte("Please pass in a numeric person ID as the personid query parameter"))
+		return
+	}
+
+	cards, err := c.repo.GetCards()
+	if err != nil {
+		c.lc.Errorf("Failed to read cards data: %s", err.Error())
+		writer.WriteHeader(http.StatusInternalServerError)
+		writer.Write([]byte("failed to read cards data"))
+		return
+	}
+
+	matching := []Card{}
+	for _, card := range cards.Cards {
+		if personIDstr == "" || card.PersonID == personID {
+			matching = append(matching, card)
+		}
+	}
+	c.writeRecord(writer, cardsView(matching...))
+}
+
+// CardGet accepts a card ID URL parameter in the form:
+// /cards/0001230001
+// It returns the card, or 404 when there is no such card
+func (c *Controller) CardGet(writer http.ResponseWriter, req *http.Request) {
+	cardID, err := c.normalizeCardID(mux.Vars(req)["cardid"])
+	if err != nil {
+		c.writeChangeError(writer, err)
+		return
+	}
+
+	card, found, err := c.repo.GetCard(cardID)
+	if err != nil {
+		c.lc.Errorf("Failed to read cards data: %s", err.Error())
+		writer.WriteHeader(http.StatusInternalServerError)
+		writer.Write([]byte("failed to read cards data"))
+		return
+	}
+	if !found {
+		c.lc.Infof("Card ID %s is not a known card", cardID)
+		writer.WriteHeader(http.StatusNotFound)
+		writer.Write([]byte("Card ID is not a known card"))
+		return
+	}
+	c.writeRecord(writer, cardsView(card)[0])
+}
+
+// PeopleGet returns every person, or only the people of the account with the
+// "accountid" query parameter
+func (c *Controller) PeopleGet(writer http.ResponseWriter, req *http.Request) {
+	accountIDstr := req.URL.Query().Get("accountid")
+	accountID, err := strconv.Atoi(accountIDstr)
+	if accountIDstr != "" && err != nil {
+		c.lc.Infof("Account ID %s is not a number", accountIDstr)
+		writer.WriteHeader(http.StatusBadRequest)
+		writer.Write([]byte("Please pass in a numeric account ID as the accountid query parameter"))
+		return
+	}
+
+	people, err := c.repo.GetPeople()
+	if err != nil {
+		c.lc.Errorf("Failed to read people data: %s", err.Error())
+		writer.WriteHeader(http.StatusInternalServerError)
+		writer.Write([]byte("failed to read people data"))
+		return
+	}
+
+	matching := []Person{}
+	for _, person := range people.People {
+		if accountIDstr == "" || person.AccountID == accountID {
+			matching = append(matching, person)
+		}
+	}
+	c.writeRecord(writer, matching)
+}
+
+// PersonGet accepts a person ID URL parameter in the form:
+// /people/1
+// It returns the person, or 404 when there is no such person
+func (c *Controller) PersonGet(writer http.ResponseWriter, req *http.Request) {
+	personID, err := personIDParameter(req)
+	if err != nil {
+		c.lc.Info(err.Error())
+		writer.WriteHeader(http.StatusBadRequest)
+		writer.Write([]byte("Please pass in a numeric person ID as a URL parameter, like this: /people/1"))
+		return
+	}
+
+	person, found, err := c.repo.GetPerson(personID)
+	if err != nil {
+		c.lc.Errorf("Failed to read people data: %s", err.Error())
+		writer.WriteHeader(http.StatusInternalServerError)
+		writer.Write([]byte("failed to read people data"))
+		return
+	}
+	if !found {
+		c.lc.Infof("Person ID %d is not a known person", personID)
+		writer.WriteHeader(http.StatusNotFound)
+		writer.Write([]byte("Person ID is not a known person"))
+		return
+	}
+	c.writeRecord(writer, person)
+}
+
+// RolesGet returns every role
+func (c *Controller) RolesGet(writer http.ResponseWriter, req *http.Request) {
+	roles, err := c.repo.GetRoles()
+	if err != nil {
+		c.lc.Errorf("Failed to read roles data: %s", err.Error())
+		writer.WriteHeader(http.StatusInternalServerError)
+		writer.Write([]byte("failed to read roles data"))
+		return
+	}
+	c.writeRecord(writer, roles.Roles)
+}
+
+// RoleGet accepts a role ID URL parameter in the form:
+// /roles/1
+// It returns the role, or 404 when there is no such role
+func (c *Controller) RoleGet(writer http.ResponseWriter, req *http.Request) {
+	roleID, err := roleIDParameter(req)
+	if err != nil {
+		c.lc.Info(err.Error())
+		writer.WriteHeader(http.StatusBadRequest)
+		writer.Write([]byte("Please pass in a numeric role ID as a URL parameter, like this: /roles/1"))
+		return
+	}
+
+	role, found, err := c.repo.GetRole(roleID)
+	if err != nil {
+		c.lc.Errorf("Failed to read roles data: %s", err.Error())
+		writer.WriteHeader(http.StatusInternalServerError)
+		writer.Write([]byte("failed to read roles data"))
+		return
+	}
+	if !found {
+		c.lc.Infof("Role ID %d is not a known role", roleID)
+		writer.WriteHeader(http.StatusNotFound)
+		writer.Write([]byte("Role ID is not a known role"))
+		return
+	}
+	c.writeRecord(writer, role)
+}
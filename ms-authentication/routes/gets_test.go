@@ -216,10 +216,12 @@ func TestAuthenticationGet(t *testing.T) {
 	cards := setupCards()
 
 	validAuthData := AuthData{
-		AccountID: accounts.Accounts[0].AccountID,
-		PersonID:  people.People[0].PersonID,
-		RoleID:    cards.Cards[0].RoleID,
-		CardID:    cards.Cards[0].CardID,
+		AccountID:   accounts.Accounts[0].AccountID,
+		PersonID:    people.People[0].PersonID,
+		RoleID:      cards.Cards[0].RoleID,
+		CardID:      cards.Cards[0].CardID,
+		RoleName:    "consumer",
+		Permissions: []string{PermissionUnlockLock1, PermissionBillable},
 	}
 
 	tests := []struct {
@@ -230,7 +232,7 @@ func TestAuthenticationGet(t *testing.T) {
 		StatusCode       int
 		ExpectedError    string
 	}{
-		{"cardID length not eq 10", true, "", AuthData{CardID: "00"}, http.StatusBadRequest, "Please pass in a 10-character card ID as a URL parameter, like this: /authentication/0001230001"},
+		{"cardID length not eq 10", true, "", AuthData{CardID: "00"}, http.StatusBadRequest, "Please pass in a card ID of one of the card ID formats as a URL parameter, like this: /authentication/0001230001"},
 		{"Successful auth sequence", true, "", validAuthData, http.StatusOK, ""},
 		{"Test inactive person", true, "", AuthData{CardID: cards.Cards[1].CardID}, http.StatusUnauthorized, "Card ID is associated with an inactive person"},
 		{"Test inactive account", true, "", AuthData{CardID: cards.Cards[2].CardID}, http.StatusUnauthorized, "Card ID is associated with an inactive account"},
@@ -287,3 +289,295 @@ func TestAuthenticationGet(t *testing.T) {
 		})
 	}
 }
+
+func TestAccountsGet(t *testing.T) {
+	tests := []struct {
+		Name             string
+		WriteInvalidFile bool
+		StatusCode       int
+	}{
+		{"Valid accounts", false, http.StatusOK},
+		{"Invalid accounts JSON", true, http.StatusInternalServerError},
+	}
+	for _, test := range tests {
+		currentTest := test
+		t.Run(currentTest.Name, func(t *testing.T) {
+			mockAppService := &mocks.ApplicationService{}
+			mockAppService.On("LoggingClient").Return(logger.NewMockClient())
+			c := NewController(mockAppService)
+
+			require.NoError(t, writeJSONFiles(setupPeople(), setupAccounts(), setupCards()))
+			if currentTest.WriteInvalidFile {
+				require.NoError(t, os.WriteFile(AccountsFileName, []byte("invalid json test"), 0644))
+			}
+
+			req := httptest.NewRequest("GET", "/accounts", nil)
+			w := httptest.NewRecorder()
+			c.AccountsGet(w, req)
+			resp := w.Result()
+			defer resp.Body.Close()
+
+			require.Equal(t, currentTest.StatusCode, resp.StatusCode)
+			if resp.StatusCode != http.StatusOK {
+				return
+			}
+			body, err := io.ReadAll(resp.Body)
+			require.NoError(t, err)
+			assert.NotContains(t, string(body), "creditCardNumber", "payment information should not be returned")
+			var accountStatuses []AccountStatus
+			require.NoError(t, json.Unmarshal(body, &accountStatuses))
+			require.Len(t, accountStatuses, 5)
+			assert.Equal(t, AccountStatus{AccountID: 3, IsActive: false}, accountStatuses[2])
+		})
+	}
+}
+
+func TestAccountGet(t *testing.T) {
+	tests := []struct {
+		Name           string
+		AccountID      string
+		StatusCode     int
+		ExpectedStatus AccountStatus
+	}{
+		{"Active account", "1", http.StatusOK, AccountStatus{AccountID: 1, IsActive: true}},
+		{"Inactive account", "3", http.StatusOK, AccountStatus{AccountID: 3, IsActive: false}},
+		{"Unknown account", "10", http.StatusNotFound, AccountStatus{}},
+		{"Invalid account ID", "one", http.StatusBadRequest, AccountStatus{}},
+	}
+	for _, test := range tests {
+		currentTest := test
+		t.Run(currentTest.Name, func(t *testing.T) {
+			mockAppService := &mocks.ApplicationService{}
+			mockAppService.On("LoggingClient").Return(logger.NewMockClient())
+			c := NewController(mockAppService)
+			require.NoError(t, writeJSONFiles(setupPeople(), setupAccounts(), setupCards()))
+
+			req := httptest.NewRequest("GET", "/accounts/"+currentTest.AccountID, nil)
+			req = mux.SetURLVars(req, map[string]string{"accountid": currentTest.AccountID})
+			w := httptest.NewRecorder()
+			c.AccountGet(w, req)
+			resp := w.Result()
+			defer resp.Body.Close()
+
+			require.Equal(t, currentTest.StatusCode, resp.StatusCode)
+			if resp.StatusCode != http.StatusOK {
+				return
+			}
+			var accountStatus AccountStatus
+			require.NoError(t, json.NewDecoder(resp.Body).Decode(&accountStatus))
+			assert.Equal(t, currentTest.ExpectedStatus, accountStatus)
+		})
+	}
+}
+
+func TestAccountsGetFullView(t *testing.T) {
+	mockAppService := &mocks.ApplicationService{}
+	mockAppService.On("LoggingClient").Return(logger.NewMockClient())
+	c := NewController(mockAppService)
+	c.pii = setupPIIProtector(t)
+	require.NoError(t, writeJSONFiles(setupPeople(), setupAccounts(), setupCards()))
+	expectedAccounts := setupAccounts().Accounts
+	for i, account := range expectedAccounts {
+		var err error
+		expectedAccounts[i], err = c.pii.openAccount(account)
+		require.NoError(t, err)
+	}
+
+	w := httptest.NewRecorder()
+	c.AccountsGet(w, httptest.NewRequest("GET", "/accounts?view=full", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+	var accounts []Account
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&accounts))
+	require.Len(t, accounts, len(expectedAccounts))
+	assert.Equal(t, maskAccount(expectedAccounts[0]), accounts[0], "the accounts should be masked without the admin scope")
+	assert.Equal(t, "t***@example.com", accounts[0].EmailAddress)
+	assert.Equal(t, "3456", accounts[0].CreditCardLast4)
+
+	req := httptest.NewRequest("GET", "/accounts?view=full", nil)
+	req.Header.Set(AdminTokenHeader, testAdminToken)
+	w = httptest.NewRecorder()
+	c.AccountsGet(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&accounts))
+	assert.Equal(t, expectedAccounts, accounts)
+
+	req = httptest.NewRequest("GET", "/accounts/2?view=full", nil)
+	req.Header.Set(AdminTokenHeader, testAdminToken)
+	req = mux.SetURLVars(req, map[string]string{"accountid": "2"})
+	w = httptest.NewRecorder()
+	c.AccountGet(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+	var account Account
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&account))
+	assert.Equal(t, expectedAccounts[1], account)
+}
+
+func TestCardsGet(t *testing.T) {
+	tests := []struct {
+		Name          string
+		Query         string
+		StatusCode    int
+		ExpectedCards int
+	}{
+		{"All cards", "", http.StatusOK, 7},
+		{"Cards of person", "?personid=1", http.StatusOK, 2},
+		{"Cards of person without cards", "?personid=7", http.StatusOK, 0},
+		{"Invalid person ID", "?personid=one", http.StatusBadRequest, 0},
+	}
+	for _, test := range tests {
+		currentTest := test
+		t.Run(currentTest.Name, func(t *testing.T) {
+			mockAppService := &mocks.ApplicationService{}
+			mockAppService.On("LoggingClient").Return(logger.NewMockClient())
+			c := NewController(mockAppService)
+			require.NoError(t, writeJSONFiles(setupPeople(), setupAccounts(), setupCards()))
+
+			w := httptest.NewRecorder()
+			c.CardsGet(w, httptest.NewRequest("GET", "/cards"+currentTest.Query, nil))
+			require.Equal(t, currentTest.StatusCode, w.Code)
+			if w.Code != http.StatusOK {
+				return
+			}
+			var cards []Card
+			require.NoError(t, json.NewDecoder(w.Body).Decode(&cards))
+			assert.Len(t, cards, currentTest.ExpectedCards)
+		})
+	}
+}
+
+func TestCardGet(t *testing.T) {
+	tests := []struct {
+		Name       string
+		CardID     string
+		StatusCode int
+	}{
+		{"Known card", "0001230002", http.StatusOK},
+		{"Unknown card", "0001230100", http.StatusNotFound},
+	}
+	for _, test := range tests {
+		currentTest := test
+		t.Run(currentTest.Name, func(t *testing.T) {
+			mockAppService := &mocks.ApplicationService{}
+			mockAppService.On("LoggingClient").Return(logger.NewMockClient())
+			c := NewController(mockAppService)
+			require.NoError(t, writeJSONFiles(setupPeople(), setupAccounts(), setupCards()))
+
+			req := httptest.NewRequest("GET", "/cards/"+currentTest.CardID, nil)
+			req = mux.SetURLVars(req, map[string]string{"cardid": currentTest.CardID})
+			w := httptest.NewRecorder()
+			c.CardGet(w, req)
+			require.Equal(t, currentTest.StatusCode, w.Code)
+			if w.Code != http.StatusOK {
+				return
+			}
+			var card Card
+			require.NoError(t, json.NewDecoder(w.Body).Decode(&card))
+			assert.Equal(t, setupCards().Cards[1], card)
+		})
+	}
+}
+
+func TestPeopleGet(t *testing.T) {
+	tests := []struct {
+		Name           string
+		Query          string
+		StatusCode     int
+		ExpectedPeople int
+	}{
+		{"All people", "", http.StatusOK, 7},
+		{"People of account", "?accountid=2", http.StatusOK, 1},
+		{"Invalid account ID", "?accountid=two", http.StatusBadRequest, 0},
+	}
+	for _, test := range tests {
+		currentTest := test
+		t.Run(currentTest.Name, func(t *testing.T) {
+			mockAppService := &mocks.ApplicationService{}
+			mockAppService.On("LoggingClient").Return(logger.NewMockClient())
+			c := NewController(mockAppService)
+			require.NoError(t, writeJSONFiles(setupPeople(), setupAccounts(), setupCards()))
+
+			w := httptest.NewRecorder()
+			c.PeopleGet(w, httptest.NewRequest("GET", "/people"+currentTest.Query, nil))
+			require.Equal(t, currentTest.StatusCode, w.Code)
+			if w.Code != http.StatusOK {
+				return
+			}
+			var people []Person
+			require.NoError(t, json.NewDecoder(w.Body).Decode(&people))
+			assert.Len(t, people, currentTest.ExpectedPeople)
+		})
+	}
+}
+
+func TestPersonGet(t *testing.T) {
+	tests := []struct {
+		Name       string
+		PersonID   string
+		StatusCode int
+	}{
+		{"Known person", "3", http.StatusOK},
+		{"Unknown person", "10", http.StatusNotFound},
+		{"Invalid person ID", "three", http.StatusBadRequest},
+	}
+	for _, test := range tests {
+		currentTest := test
+		t.Run(currentTest.Name, func(t *testing.T) {
+			mockAppService := &mocks.ApplicationService{}
+			mockAppService.On("LoggingClient").Return(logger.NewMockClient())
+			c := NewController(mockAppService)
+			require.NoError(t, writeJSONFiles(setupPeople(), setupAccounts(), setupCards()))
+
+			req := httptest.NewRequest("GET", "/people/"+currentTest.PersonID, nil)
+			req = mux.SetURLVars(req, map[string]string{"personid": currentTest.PersonID})
+			w := httptest.NewRecorder()
+			c.PersonGet(w, req)
+			require.Equal(t, currentTest.StatusCode, w.Code)
+			if w.Code != http.StatusOK {
+				return
+			}
+			var person Person
+			require.NoError(t, json.NewDecoder(w.Body).Decode(&person))
+			assert.Equal(t, setupPeople().People[2], person)
+		})
+	}
+}
+
+func TestAuthenticationGetCardLifecycle(t *testing.T) {
+	tests := []struct {
+		Name           string
+		Change         func(card *Card)
+		StatusCode     int
+		ExpectedStatus string
+	}{
+		{"Within validity window", func(card *Card) { card.ValidFrom, card.ValidUntil = 1560815799, 4102444800 }, http.StatusOK, ""},
+		{"Not valid yet", func(card *Card) { card.ValidFrom = 4102444800 }, http.StatusForbidden, CardStatusNotYetValid},
+		{"Expired", func(card *Card) { card.ValidUntil = 1560815799 }, http.StatusForbidden, CardStatusExpired},
+		{"Suspended", func(card *Card) { card.SuspendedAt, card.SuspendedReason = 1560815799, "shared" }, http.StatusForbidden, CardStatusSuspended},
+		{"Revoked", func(card *Card) { card.IsValid, card.RevokedAt = false, 1560815799 }, http.StatusForbidden, CardStatusRevoked},
+	}
+	defer writeJSONFiles(setupPeople(), setupAccounts(), setupCards())
+	for _, test := range tests {
+		currentTest := test
+		t.Run(currentTest.Name, func(t *testing.T) {
+			mockAppService := &mocks.ApplicationService{}
+			mockAppService.On("LoggingClient").Return(logger.NewMockClient())
+			c := NewController(mockAppService)
+			cards := setupCards()
+			currentTest.Change(&cards.Cards[0])
+			require.NoError(t, writeJSONFiles(setupPeople(), setupAccounts(), cards))
+
+			req := httptest.NewRequest("GET", "/authentication/"+cards.Cards[0].CardID, nil)
+			req = mux.SetURLVars(req, map[string]string{"cardid": cards.Cards[0].CardID})
+			w := httptest.NewRecorder()
+			c.AuthenticationGet(w, req)
+
+			require.Equal(t, currentTest.StatusCode, w.Code)
+			if w.Code == http.StatusOK {
+				return
+			}
+			var denial CardDenial
+			require.NoError(t, json.NewDecoder(w.Body).Decode(&denial))
+			assert.Equal(t, CardDenial{CardID: cards.Cards[0].CardID, Status: currentTest.ExpectedStatus, Message: cardStatusMessages[currentTest.ExpectedStatus]}, denial)
+		})
+	}
+}
@@ -0,0 +1,136 @@
+// Copyright © 2026 Intel Corporation. All rights reserved.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package routes
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Identity providers of the IdentityProvider application setting
+const (
+	IdentityProviderStore = "store"
+	IdentityProviderLDAP  = "ldap"
+)
+
+// Errors of a store identity provider that cannot read the person or the
+// account of a card, which are the messages of the responses
+var (
+	errReadPeople   = errors.New("failed to read people data")
+	errReadAccounts = errors.New("failed to read accounts data")
+)
+
+// Identity is who a card belongs to: the card with its role, the person the
+// card belongs to and the account of the person. Person is nil when the
+// person of the card is not known, and Account is nil when the account of
+// the person is not known
+type Identity struct {
+	Card    Card
+	Person  *Person
+	Account *Account
+}
+
+// IdentityProvider resolves the card IDs that are authenticated into
+// identities. The cards, people and accounts of the repository are one
+// provider, and a directory server is another
+type IdentityProvider interface {
+	// ResolveCard returns the identity of the card with the card ID, and
+	// false if the provider does not know the card
+	ResolveCard(cardID string) (Identity, bool, error)
+}
+
+// storeIdentityProvider resolves cards with the cards, people and accounts
+// of the repository, which is where they are managed by this service
+type storeIdentityProvider struct {
+	repo Repository
+}
+
+// ResolveCard looks up the card, then its person, then the account of the
+// person in the repository
+func (p *storeIdentityProvider) ResolveCard(cardID string) (Identity, bool, error) {
+	card, found, err := p.repo.GetCard(cardID)
+	if err != nil || !found {
+		return Identity{}, false, err
+	}
+	identity := Identity{Card: card}
+	person, found, err := p.repo.GetPerson(card.PersonID)
+	if err != nil {
+		return Identity{}, false, fmt.Errorf("%w: %s", errReadPeople, err.Error())
+	}
+	if !found {
+		return identity, true, nil
+	}
+	identity.Person = &person
+	account, found, err := p.repo.GetAccount(person.AccountID)
+	if err != nil {
+		return Identity{}, false, fmt.Errorf("%w: %s", errReadAccounts, err.Error())
+	}
+	if found {
+		identity.Account = &account
+	}
+	return identity, true, nil
+}
+
+// identityProvider returns the provider that resolves the cards that are
+// authenticated, which is the repository unless LoadIdentityProvider
+// selected another one
+func (c *Controller) identityProvider() IdentityProvider {
+	if c.identity != nil {
+		return c.identity
+	}
+	return &storeIdentityProvider{repo: c.repo}
+}
+
+// withStoredState returns the card with the PIN and the lifecycle state of
+// the stored card of the same card ID, which is where PUT /cards/{cardid}/pin
+// sets the PIN and where cards are suspended, revoked, deactivated or given
+// a validity window. A card resolved by a directory is thus refused like a
+// stored card would be. Cards resolved by the repository already have both
+func (c *Controller) withStoredState(card Card) (Card, error) {
+	if c.identity == nil {
+		return card, nil
+	}
+	stored, found, err := c.repo.GetCard(card.CardID)
+	if err != nil || !found {
+		return card, err
+	}
+	card.PINHash = stored.PINHash
+	card.PINFailedAttempts = stored.PINFailedAttempts
+	card.PINLockedAt = stored.PINLockedAt
+	card.IsValid = card.IsValid && stored.IsValid
+	card.ValidFrom = stored.ValidFrom
+	card.ValidUntil = stored.ValidUntil
+	card.SuspendedAt = stored.SuspendedAt
+	card.SuspendedReason = stored.SuspendedReason
+	card.RevokedAt = stored.RevokedAt
+	card.RevokedReason = stored.RevokedReason
+	card.ReplacedBy = stored.ReplacedBy
+	return card, nil
+}
+
+// LoadIdentityProvider selects the provider of the IdentityProvider
+// application setting. The directory provider may read its bind password
+// from the secret store, so this is called after LoadSecrets
+func (c *Controller) LoadIdentityProvider() error {
+	providerName := strings.ToLower(c.optionalAppSetting("IdentityProvider", IdentityProviderStore))
+	switch providerName {
+	case IdentityProviderStore:
+		c.identity = nil
+	case IdentityProviderLDAP:
+		settings, err := c.loadLDAPSettings()
+		if err != nil {
+			return err
+		}
+		provider, err := newLDAPIdentityProvider(settings, c.lc)
+		if err != nil {
+			return err
+		}
+		c.identity = provider
+	default:
+		return fmt.Errorf("identity provider %q is not one of %s or %s", providerName, IdentityProviderStore, IdentityProviderLDAP)
+	}
+	c.lc.Infof("Resolving cards with the %s identity provider", providerName)
+	return nil
+}
@@ -0,0 +1,268 @@
+// Copyright © 2026 Intel Corporation. All rights reserved.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package routes
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/edgexfoundry/go-mod-core-contracts/v3/clients/logger"
+	"github.com/go-ldap/ldap/v3"
+)
+
+// Defaults of the LDAP application settings, which match the attributes of
+// the inetOrgPerson and posixAccount object classes
+const (
+	DefaultLDAPSecretName         = "ldap"
+	DefaultLDAPCardFilter         = "(employeeNumber={cardID})"
+	DefaultLDAPRoleAttribute      = "employeeType"
+	DefaultLDAPPersonIDAttribute  = "uidNumber"
+	DefaultLDAPAccountIDAttribute = "departmentNumber"
+	DefaultLDAPFullNameAttribute  = "cn"
+	DefaultLDAPRoleMap            = "consumer:1,stocker:2,maintainer:3"
+	DefaultLDAPCacheDuration      = "5m"
+	DefaultLDAPMaxStaleAge        = "1h"
+	DefaultLDAPTimeout            = "5s"
+)
+
+// BindPasswordSecretKey is the key of the password of the bind DN in the
+// LDAP secret
+const BindPasswordSecretKey = "bindPassword"
+
+// cardIDPlaceholder is replaced by the escaped card ID in the card filter
+const cardIDPlaceholder = "{cardID}"
+
+// LDAPSettings are how the directory is searched for the entry of a card,
+// and which attributes of the entry are its role, person and account
+type LDAPSettings struct {
+	URL          string
+	BindDN       string
+	BindPassword string
+	BaseDN       string
+	// CardFilter is the search filter of the entry of a card, in which
+	// {cardID} is replaced by the card ID
+	CardFilter         string
+	RoleAttribute      string
+	PersonIDAttribute  string
+	AccountIDAttribute string
+	FullNameAttribute  string
+	// RoleMap maps the names of roles in the role attribute to role IDs. A
+	// role attribute that is a number is the role ID itself
+	RoleMap map[string]int
+	// CacheDuration is how long a resolved card is used without searching
+	// the directory again
+	CacheDuration time.Duration
+	// MaxStaleAge is how old a cached card may be and still be used while
+	// the directory is unreachable. Older cards are refused until the
+	// directory can be searched again
+	MaxStaleAge time.Duration
+	Timeout     time.Duration
+}
+
+// cachedIdentity is a card resolved by the directory, and when it was
+type cachedIdentity struct {
+	identity   Identity
+	resolvedAt time.Time
+}
+
+// ldapIdentityProvider resolves cards with the entries of a directory
+// server. A directory entry is an active person with an active account, so
+// directory administrators disable a card by removing it from its entry or
+// by narrowing the card filter. Resolved cards are cached, and the cached
+// identity of a card is used while the directory is unreachable, up to the
+// maximum stale age
+type ldapIdentityProvider struct {
+	settings LDAPSettings
+	lc       logger.LoggingClient
+	// now returns the current time, and is replaced by tests
+	now        func() time.Time
+	cacheMutex sync.Mutex
+	cache      map[string]cachedIdentity
+}
+
+func newLDAPIdentityProvider(settings LDAPSettings, lc logger.LoggingClient) (*ldapIdentityProvider, error) {
+	if settings.URL == "" {
+		return nil, fmt.Errorf("LDAPURL must be set to use the %s identity provider", IdentityProviderLDAP)
+	}
+	if !strings.Contains(settings.CardFilter, cardIDPlaceholder) {
+		return nil, fmt.Errorf("LDAPCardFilter must contain %s", cardIDPlaceholder)
+	}
+	if _, err := ldap.CompileFilter(strings.ReplaceAll(settings.CardFilter, cardIDPlaceholder, "0")); err != nil {
+		return nil, fmt.Errorf("LDAPCardFilter is not a valid filter: %s", err.Error())
+	}
+	return &ldapIdentityProvider{
+		settings: settings,
+		lc:       lc,
+		now:      time.Now,
+		cache:    map[string]cachedIdentity{},
+	}, nil
+}
+
+// ResolveCard returns the cached identity of the card while it is recent,
+// and otherwise searches the directory for the entry of the card. When the
+// directory cannot be searched, the cached identity of the card is returned
+// unless it is older than the maximum stale age
+func (p *ldapIdentityProvider) ResolveCard(cardID string) (Identity, bool, error) {
+	p.cacheMutex.Lock()
+	cached, isCached := p.cache[cardID]
+	p.cacheMutex.Unlock()
+	if isCached && p.now().Sub(cached.resolvedAt) < p.settings.CacheDuration {
+		return cached.identity, true, nil
+	}
+
+	entries, err := p.search(cardID)
+	if err != nil {
+		if isCached && p.now().Sub(cached.resolvedAt) <= p.settings.MaxStaleAge {
+			p.lc.Warnf("Directory is unreachable, using the identity of card %s cached at %s: %s", cardID, cached.resolvedAt.Format(time.RFC3339), err.Error())
+			return cached.identity, true, nil
+		}
+		return Identity{}, false, fmt.Errorf("failed to search the directory: %s", err.Error())
+	}
+
+	p.cacheMutex.Lock()
+	defer p.cacheMutex.Unlock()
+	switch len(entries) {
+	case 0:
+		// the directory no longer knows the card, so it must not be
+		// resolved from the cache when the directory is unreachable
+		delete(p.cache, cardID)
+		return Identity{}, false, nil
+	case 1:
+	default:
+		return Identity{}, false, fmt.Errorf("card %s matches %d directory entries", cardID, len(entries))
+	}
+	identity, err := p.mapEntry(cardID, entries[0])
+	if err != nil {
+		delete(p.cache, cardID)
+		return Identity{}, false, err
+	}
+	p.cache[cardID] = cachedIdentity{identity: identity, resolvedAt: p.now()}
+	return identity, true, nil
+}
+
+// search binds to the directory and returns the entries of the card
+func (p *ldapIdentityProvider) search(cardID string) ([]*ldap.Entry, error) {
+	conn, err := ldap.DialURL(p.settings.URL, ldap.DialWithDialer(&net.Dialer{Timeout: p.settings.Timeout}))
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	conn.SetTimeout(p.settings.Timeout)
+
+	if p.settings.BindDN != "" {
+		if err := conn.Bind(p.settings.BindDN, p.settings.BindPassword); err != nil {
+			return nil, err
+		}
+	}
+	request := ldap.NewSearchRequest(
+		p.settings.BaseDN,
+		ldap.ScopeWholeSubtree,
+		ldap.NeverDerefAliases,
+		0,
+		int(p.settings.Timeout.Seconds()),
+		false,
+		strings.ReplaceAll(p.settings.CardFilter, cardIDPlaceholder, ldap.EscapeFilter(cardID)),
+		[]string{p.settings.RoleAttribute, p.settings.PersonIDAttribute, p.settings.AccountIDAttribute, p.settings.FullNameAttribute},
+		nil,
+	)
+	result, err := conn.Search(request)
+	if err != nil {
+		return nil, err
+	}
+	return result.Entries, nil
+}
+
+// mapEntry returns the identity of the card from the attributes of its
+// directory entry
+func (p *ldapIdentityProvider) mapEntry(cardID string, entry *ldap.Entry) (Identity, error) {
+	roleValue := strings.TrimSpace(entry.GetAttributeValue(p.settings.RoleAttribute))
+	roleID, known := p.settings.RoleMap[strings.ToLower(roleValue)]
+	if !known {
+		var err error
+		if roleID, err = strconv.Atoi(roleValue); err != nil {
+			return Identity{}, fmt.Errorf("%w: directory entry %s has unknown role %q", errInvalidRecord, entry.DN, roleValue)
+		}
+	}
+	personID, err := strconv.Atoi(strings.TrimSpace(entry.GetAttributeValue(p.settings.PersonIDAttribute)))
+	if err != nil {
+		return Identity{}, fmt.Errorf("%w: %s of directory entry %s is not a number", errInvalidRecord, p.settings.PersonIDAttribute, entry.DN)
+	}
+	accountID, err := strconv.Atoi(strings.TrimSpace(entry.GetAttributeValue(p.settings.AccountIDAttribute)))
+	if err != nil {
+		return Identity{}, fmt.Errorf("%w: %s of directory entry %s is not a number", errInvalidRecord, p.settings.AccountIDAttribute, entry.DN)
+	}
+	return Identity{
+		Card: Card{CardID: cardID, RoleID: roleID, IsValid: true, PersonID: personID},
+		Person: &Person{
+			PersonID:  personID,
+			AccountID: accountID,
+			FullName:  entry.GetAttributeValue(p.settings.FullNameAttribute),
+			IsActive:  true,
+		},
+		Account: &Account{AccountID: accountID, IsActive: true},
+	}, nil
+}
+
+// loadLDAPSettings reads the LDAP application settings, and the password
+// of the bind DN from the secret store when a bind DN is set
+func (c *Controller) loadLDAPSettings() (LDAPSettings, error) {
+	settings := LDAPSettings{
+		URL:                c.optionalAppSetting("LDAPURL", ""),
+		BindDN:             c.optionalAppSetting("LDAPBindDN", ""),
+		BaseDN:             c.optionalAppSetting("LDAPBaseDN", ""),
+		CardFilter:         c.optionalAppSetting("LDAPCardFilter", DefaultLDAPCardFilter),
+		RoleAttribute:      c.optionalAppSetting("LDAPRoleAttribute", DefaultLDAPRoleAttribute),
+		PersonIDAttribute:  c.optionalAppSetting("LDAPPersonIDAttribute", DefaultLDAPPersonIDAttribute),
+		AccountIDAttribute: c.optionalAppSetting("LDAPAccountIDAttribute", DefaultLDAPAccountIDAttribute),
+		FullNameAttribute:  c.optionalAppSetting("LDAPFullNameAttribute", DefaultLDAPFullNameAttribute),
+	}
+	var err error
+	if settings.RoleMap, err = parseRoleMap(c.optionalAppSetting("LDAPRoleMap", DefaultLDAPRoleMap)); err != nil {
+		return LDAPSettings{}, fmt.Errorf("failed to parse LDAPRoleMap: %s", err.Error())
+	}
+	if settings.CacheDuration, err = time.ParseDuration(c.optionalAppSetting("LDAPCacheDuration", DefaultLDAPCacheDuration)); err != nil {
+		return LDAPSettings{}, fmt.Errorf("failed to parse LDAPCacheDuration: %s", err.Error())
+	}
+	if settings.MaxStaleAge, err = time.ParseDuration(c.optionalAppSetting("LDAPMaxStaleAge", DefaultLDAPMaxStaleAge)); err != nil || settings.MaxStaleAge < 0 {
+		return LDAPSettings{}, fmt.Errorf("LDAPMaxStaleAge must be a duration that is not negative")
+	}
+	if settings.Timeout, err = time.ParseDuration(c.optionalAppSetting("LDAPTimeout", DefaultLDAPTimeout)); err != nil || settings.Timeout <= 0 {
+		return LDAPSettings{}, fmt.Errorf("LDAPTimeout must be a positive duration")
+	}
+	if settings.BindDN != "" {
+		secretName := c.optionalAppSetting("LDAPSecretName", DefaultLDAPSecretName)
+		secrets, err := c.service.SecretProvider().GetSecret(secretName, BindPasswordSecretKey)
+		if err != nil {
+			return LDAPSettings{}, fmt.Errorf("failed to get secret %s: %s", secretName, err.Error())
+		}
+		settings.BindPassword = secrets[BindPasswordSecretKey]
+	}
+	return settings, nil
+}
+
+// parseRoleMap parses a comma separated list of role names and their role
+// IDs, such as "consumer:1,stocker:2"
+func parseRoleMap(value string) (map[string]int, error) {
+	roleMap := map[string]int{}
+	for _, field := range strings.Split(value, ",") {
+		field = strings.TrimSpace(field)
+		if field == "" {
+			continue
+		}
+		name, id, found := strings.Cut(field, ":")
+		if !found {
+			return nil, fmt.Errorf("%s is not a role name and a role ID", field)
+		}
+		roleID, err := strconv.Atoi(strings.TrimSpace(id))
+		if err != nil {
+			return nil, fmt.Errorf("role ID %s is not a number", id)
+		}
+		roleMap[strings.ToLower(strings.TrimSpace(name))] = roleID
+	}
+	return roleMap, nil
+}
@@ -0,0 +1,357 @@
+// Copyright © 2026 Intel Corporation. All rights reserved.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package routes
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/edgexfoundry/app-functions-sdk-go/v3/pkg/interfaces/mocks"
+	bootstrapMocks "github.com/edgexfoundry/go-mod-bootstrap/v3/bootstrap/interfaces/mocks"
+	"github.com/edgexfoundry/go-mod-core-contracts/v3/clients/logger"
+	ber "github.com/go-asn1-ber/asn1-ber"
+	"github.com/go-ldap/ldap/v3"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testBindDN       = "cn=vending,dc=example,dc=com"
+	testBindPassword = "vending-password"
+)
+
+// testDirectory is a stand-in LDAP server that answers simple binds and
+// searches with the employeeNumber filter of DefaultLDAPCardFilter
+type testDirectory struct {
+	listener net.Listener
+	mutex    sync.Mutex
+	// entries are the attributes of the entry of each card ID
+	entries  map[string]map[string]string
+	searches int
+}
+
+func startTestDirectory(t *testing.T) *testDirectory {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	directory := &testDirectory{
+		listener: listener,
+		entries: map[string]map[string]string{
+			"0001230001": {"employeeType": "Consumer", "uidNumber": "1", "departmentNumber": "1", "cn": "Test Person 1"},
+			"0001230003": {"employeeType": "maintainer", "uidNumber": "3", "departmentNumber": "2", "cn": "Test Person 3"},
+			"0001230005": {"employeeType": "visitor", "uidNumber": "5", "departmentNumber": "1", "cn": "Test Person 5"},
+		},
+	}
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go directory.serve(conn)
+		}
+	}()
+	t.Cleanup(directory.stop)
+	return directory
+}
+
+func (d *testDirectory) url() string {
+	return "ldap://" + d.listener.Addr().String()
+}
+
+func (d *testDirectory) stop() {
+	d.listener.Close()
+}
+
+func (d *testDirectory) searchCount() int {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	return d.searches
+}
+
+func (d *testDirectory) serve(conn net.Conn) {
+	defer conn.Close()
+	for {
+		packet, err := ber.ReadPacket(conn)
+		if err != nil || len(packet.Children) < 2 {
+			return
+		}
+		messageID := packet.Children[0].Value
+		request := packet.Children[1]
+		switch request.Tag {
+		case ldap.ApplicationBindRequest:
+			resultCode := ldap.LDAPResultSuccess
+			if request.Children[1].Data.String() != testBindDN || request.Children[2].Data.String() != testBindPassword {
+				resultCode = ldap.LDAPResultInvalidCredentials
+			}
+			conn.Write(testLDAPMessage(messageID, testLDAPResult(ldap.ApplicationBindResponse, resultCode)).Bytes())
+		case ldap.ApplicationSearchRequest:
+			filter, err := ldap.DecompileFilter(request.Children[6])
+			if err != nil {
+				return
+			}
+			d.mutex.Lock()
+			d.searches++
+			for cardID, attributes := range d.entries {
+				if filter == fmt.Sprintf("(employeeNumber=%s)", cardID) {
+					conn.Write(testLDAPMessage(messageID, testLDAPEntry("uid="+cardID+",dc=example,dc=com", attributes)).Bytes())
+				}
+			}
+			d.mutex.Unlock()
+			conn.Write(testLDAPMessage(messageID, testLDAPResult(ldap.ApplicationSearchResultDone, ldap.LDAPResultSuccess)).Bytes())
+		default:
+			return
+		}
+	}
+}
+
+func testLDAPMessage(messageID interface{}, operation *ber.Packet) *ber.Packet {
+	message := ber.NewSequence("LDAP Message")
+	message.AppendChild(ber.NewInteger(ber.ClassUniversal, ber.TypePrimitive, ber.TagInteger, messageID, "Message ID"))
+	message.AppendChild(operation)
+	return message
+}
+
+func testLDAPResult(tag ber.Tag, resultCode int) *ber.Packet {
+	result := ber.Encode(ber.ClassApplication, ber.TypeConstructed, tag, nil, "Result")
+	result.AppendChild(ber.NewInteger(ber.ClassUniversal, ber.TypePrimitive, ber.TagEnumerated, int64(resultCode), "Result Code"))
+	result.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, "", "Matched DN"))
+	result.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, "", "Diagnostic Message"))
+	return result
+}
+
+func testLDAPEntry(dn string, attributes map[string]string) *ber.Packet {
+	entry := ber.Encode(ber.ClassApplication, ber.TypeConstructed, ldap.ApplicationSearchResultEntry, nil, "Entry")
+	entry.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, dn, "DN"))
+	list := ber.NewSequence("Attributes")
+	for name, value := range attributes {
+		attribute := ber.NewSequence("Attribute")
+		attribute.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, name, "Type"))
+		values := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSet, nil, "Values")
+		values.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, value, "Value"))
+		attribute.AppendChild(values)
+		list.AppendChild(attribute)
+	}
+	entry.AppendChild(list)
+	return entry
+}
+
+func setupLDAPSettings(directory *testDirectory) LDAPSettings {
+	roleMap, _ := parseRoleMap(DefaultLDAPRoleMap)
+	return LDAPSettings{
+		URL:                directory.url(),
+		BindDN:             testBindDN,
+		BindPassword:       testBindPassword,
+		BaseDN:             "dc=example,dc=com",
+		CardFilter:         DefaultLDAPCardFilter,
+		RoleAttribute:      DefaultLDAPRoleAttribute,
+		PersonIDAttribute:  DefaultLDAPPersonIDAttribute,
+		AccountIDAttribute: DefaultLDAPAccountIDAttribute,
+		FullNameAttribute:  DefaultLDAPFullNameAttribute,
+		RoleMap:            roleMap,
+		CacheDuration:      time.Minute,
+		MaxStaleAge:        time.Hour,
+		Timeout:            time.Second,
+	}
+}
+
+func TestLDAPIdentityProviderResolveCard(t *testing.T) {
+	directory := startTestDirectory(t)
+	tests := []struct {
+		Name             string
+		CardID           string
+		BindPassword     string
+		ExpectedFound    bool
+		ExpectedIdentity Identity
+		ExpectedError    bool
+	}{
+		{"Role name", "0001230003", testBindPassword, true, Identity{
+			Card:    Card{CardID: "0001230003", RoleID: 3, IsValid: true, PersonID: 3},
+			Person:  &Person{PersonID: 3, AccountID: 2, FullName: "Test Person 3", IsActive: true},
+			Account: &Account{AccountID: 2, IsActive: true},
+		}, false},
+		{"Role name in another case", "0001230001", testBindPassword, true, Identity{
+			Card:    Card{CardID: "0001230001", RoleID: 1, IsValid: true, PersonID: 1},
+			Person:  &Person{PersonID: 1, AccountID: 1, FullName: "Test Person 1", IsActive: true},
+			Account: &Account{AccountID: 1, IsActive: true},
+		}, false},
+		{"Unknown card", "0001230100", testBindPassword, false, Identity{}, false},
+		{"Unknown role", "0001230005", testBindPassword, false, Identity{}, true},
+		{"Wrong bind password", "0001230001", "wrong", false, Identity{}, true},
+	}
+	for _, test := range tests {
+		currentTest := test
+		t.Run(currentTest.Name, func(t *testing.T) {
+			settings := setupLDAPSettings(directory)
+			settings.BindPassword = currentTest.BindPassword
+			provider, err := newLDAPIdentityProvider(settings, logger.NewMockClient())
+			require.NoError(t, err)
+
+			identity, found, err := provider.ResolveCard(currentTest.CardID)
+			if currentTest.ExpectedError {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, currentTest.ExpectedFound, found)
+			assert.Equal(t, currentTest.ExpectedIdentity, identity)
+		})
+	}
+}
+
+func TestLDAPIdentityProviderCache(t *testing.T) {
+	directory := startTestDirectory(t)
+	provider, err := newLDAPIdentityProvider(setupLDAPSettings(directory), logger.NewMockClient())
+	require.NoError(t, err)
+	now := time.Unix(1700000000, 0)
+	provider.now = func() time.Time { return now }
+
+	_, found, err := provider.ResolveCard("0001230001")
+	require.NoError(t, err)
+	require.True(t, found)
+	_, found, err = provider.ResolveCard("0001230001")
+	require.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, 1, directory.searchCount(), "a recent card should be resolved from the cache")
+
+	now = now.Add(2 * time.Minute)
+	_, _, err = provider.ResolveCard("0001230001")
+	require.NoError(t, err)
+	assert.Equal(t, 2, directory.searchCount(), "an old card should be resolved by the directory")
+	_, found, err = provider.ResolveCard("0001230003")
+	require.NoError(t, err)
+	require.True(t, found)
+
+	directory.mutex.Lock()
+	delete(directory.entries, "0001230003")
+	directory.mutex.Unlock()
+	now = now.Add(2 * time.Minute)
+	_, found, err = provider.ResolveCard("0001230003")
+	require.NoError(t, err)
+	assert.False(t, found, "a card removed from the directory should not be resolved")
+
+	directory.stop()
+	identity, found, err := provider.ResolveCard("0001230001")
+	require.NoError(t, err, "an unreachable directory should fall back to the cache")
+	assert.True(t, found)
+	assert.Equal(t, 1, identity.Card.RoleID)
+	_, _, err = provider.ResolveCard("0001230003")
+	assert.Error(t, err, "a card that was removed from the directory should not be cached")
+	_, _, err = provider.ResolveCard("0001230100")
+	assert.Error(t, err)
+
+	now = now.Add(time.Hour)
+	_, found, err = provider.ResolveCard("0001230001")
+	assert.Error(t, err, "a card cached longer than the maximum stale age should not be used")
+	assert.False(t, found)
+}
+
+func TestNewLDAPIdentityProviderInvalidSettings(t *testing.T) {
+	settings := LDAPSettings{CardFilter: DefaultLDAPCardFilter}
+	_, err := newLDAPIdentityProvider(settings, logger.NewMockClient())
+	assert.Error(t, err, "a URL is required")
+	settings.URL = "ldap://localhost:389"
+	settings.CardFilter = "(employeeNumber=0001230001)"
+	_, err = newLDAPIdentityProvider(settings, logger.NewMockClient())
+	assert.Error(t, err, "the filter must have the card ID")
+	settings.CardFilter = "(employeeNumber={cardID}"
+	_, err = newLDAPIdentityProvider(settings, logger.NewMockClient())
+	assert.Error(t, err, "the filter must be valid")
+}
+
+func TestAuthenticationGetLDAP(t *testing.T) {
+	directory := startTestDirectory(t)
+	values := map[string]string{
+		"IdentityProvider": "LDAP",
+		"LDAPURL":          directory.url(),
+		"LDAPBindDN":       testBindDN,
+		"LDAPBaseDN":       "dc=example,dc=com",
+	}
+	mockSecretProvider := &bootstrapMocks.SecretProvider{}
+	mockSecretProvider.On("GetSecret", DefaultLDAPSecretName, BindPasswordSecretKey).Return(map[string]string{
+		BindPasswordSecretKey: testBindPassword,
+	}, nil)
+	mockAppService := &mocks.ApplicationService{}
+	mockAppService.On("LoggingClient").Return(logger.NewMockClient())
+	mockAppService.On("GetAppSetting", mock.Anything).Return(func(name string) (string, error) {
+		return values[name], nil
+	})
+	mockAppService.On("SecretProvider").Return(mockSecretProvider)
+	c := NewController(mockAppService)
+	require.NoError(t, c.LoadIdentityProvider())
+
+	authenticate := func(cardID string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("GET", "/authentication/"+cardID, nil)
+		req = mux.SetURLVars(req, map[string]string{"cardid": cardID})
+		w := httptest.NewRecorder()
+		c.AuthenticationGet(w, req)
+		return w
+	}
+	w := authenticate("0001230003")
+	require.Equal(t, http.StatusOK, w.Code)
+	var authData AuthData
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&authData))
+	assert.Equal(t, AuthData{AccountID: 2, PersonID: 3, RoleID: 3, CardID: "0001230003", RoleName: "maintainer", Permissions: defaultRoles().Roles[2].Permissions}, authData)
+	assert.Equal(t, http.StatusUnauthorized, authenticate("0001230100").Code)
+	assert.Equal(t, http.StatusInternalServerError, authenticate("0001230005").Code)
+
+	defer writeJSONFiles(setupPeople(), setupAccounts(), setupCards())
+	cards := setupCards()
+	cards.Cards[2].PINHash, _ = hashPIN("1234")
+	require.NoError(t, writeJSONFiles(setupPeople(), setupAccounts(), cards))
+	c.pinRequiredRoles = map[int]bool{1: true, 3: true}
+	assert.Equal(t, http.StatusOK, authenticate("0001230003").Code, "the PIN should be the PIN of the stored card")
+	w = authenticate("0001230001")
+	require.Equal(t, http.StatusForbidden, w.Code)
+	var denial CardDenial
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&denial))
+	assert.Equal(t, CardStatusPINNotSet, denial.Status)
+	c.pinRequiredRoles = map[int]bool{}
+
+	cards = setupCards()
+	cards.Cards[2].SuspendedAt = 1700000000
+	cards.Cards[0].RevokedAt = 1700000000
+	require.NoError(t, writeJSONFiles(setupPeople(), setupAccounts(), cards))
+	w = authenticate("0001230003")
+	require.Equal(t, http.StatusForbidden, w.Code, "the suspension of the stored card should apply")
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&denial))
+	assert.Equal(t, CardStatusSuspended, denial.Status)
+	w = authenticate("0001230001")
+	require.Equal(t, http.StatusForbidden, w.Code, "the revocation of the stored card should apply")
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&denial))
+	assert.Equal(t, CardStatusRevoked, denial.Status)
+	cards = setupCards()
+	cards.Cards[2].IsValid = false
+	require.NoError(t, writeJSONFiles(setupPeople(), setupAccounts(), cards))
+	assert.Equal(t, http.StatusUnauthorized, authenticate("0001230003").Code, "a deactivated stored card should be refused")
+
+	values["IdentityProvider"] = "store"
+	require.NoError(t, c.LoadIdentityProvider())
+	assert.Nil(t, c.identity)
+	values["IdentityProvider"] = "kerberos"
+	assert.Error(t, c.LoadIdentityProvider())
+	values["IdentityProvider"] = "ldap"
+	values["LDAPMaxStaleAge"] = "-1m"
+	assert.Error(t, c.LoadIdentityProvider())
+	values["LDAPMaxStaleAge"] = ""
+	values["LDAPTimeout"] = "0s"
+	assert.Error(t, c.LoadIdentityProvider())
+}
+
+func TestParseRoleMap(t *testing.T) {
+	roleMap, err := parseRoleMap(" Consumer:1, stocker : 2,")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]int{"consumer": 1, "stocker": 2}, roleMap)
+	_, err = parseRoleMap("consumer")
+	assert.Error(t, err)
+	_, err = parseRoleMap("consumer:one")
+	assert.Error(t, err)
+}
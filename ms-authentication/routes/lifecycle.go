@@ -0,0 +1,127 @@
+// Copyright © 2026 Intel Corporation. All rights reserved.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package routes
+
+import (
+	"fmt"
+)
+
+// Lifecycle statuses of cards. Only active cards authenticate
+const (
+	CardStatusActive      = "active"
+	CardStatusNotYetValid = "notYetValid"
+	CardStatusExpired     = "expired"
+	CardStatusSuspended   = "suspended"
+	CardStatusRevoked     = "revoked"
+)
+
+// RevokedReasonLost is the reason of a card that was reported lost
+const RevokedReasonLost = "lost"
+
+// cardStatusMessages are the responses of an authentication of a card with
+// each status
+var cardStatusMessages = map[string]string{
+	CardStatusNotYetValid: "Card ID is not valid yet",
+	CardStatusExpired:     "Card ID is expired",
+	CardStatusSuspended:   "Card ID is suspended",
+	CardStatusRevoked:     "Card ID was revoked",
+	CardStatusPINNotSet:   "Card ID requires a PIN that is not set",
+	CardStatusPINLocked:   "Card ID is locked after too many wrong PINs",
+}
+
+// Status returns the lifecycle status of the card at now. A revoked card
+// stays revoked, and a suspension applies within the validity window too
+func (card *Card) Status(now int64) string {
+	switch {
+	case card.RevokedAt != 0:
+		return CardStatusRevoked
+	case card.SuspendedAt != 0:
+		return CardStatusSuspended
+	case card.ValidFrom != 0 && now < card.ValidFrom:
+		return CardStatusNotYetValid
+	case card.ValidUntil != 0 && now >= card.ValidUntil:
+		return CardStatusExpired
+	}
+	return CardStatusActive
+}
+
+// SuspendCard suspends the card with the card ID for the reason until it is
+// resumed
+func (cards *Cards) SuspendCard(cardID string, reason string, now int64) (Card, error) {
+	i := cards.cardIndex(cardID)
+	if i < 0 {
+		return Card{}, fmt.Errorf("%w: card %s", errRecordNotFound, cardID)
+	}
+	if reason == "" {
+		return Card{}, fmt.Errorf("%w: the reason of the suspension is required", errInvalidRecord)
+	}
+	card := &cards.Cards[i]
+	if card.RevokedAt != 0 {
+		return Card{}, fmt.Errorf("%w: card %s was revoked", errRecordConflict, cardID)
+	}
+	if card.SuspendedAt != 0 {
+		return Card{}, fmt.Errorf("%w: card %s is suspended already", errRecordConflict, cardID)
+	}
+	card.SuspendedAt = now
+	card.SuspendedReason = reason
+	card.UpdatedAt = now
+	return *card, nil
+}
+
+// ResumeCard lifts the suspension of the card with the card ID
+func (cards *Cards) ResumeCard(cardID string, now int64) (Card, error) {
+	i := cards.cardIndex(cardID)
+	if i < 0 {
+		return Card{}, fmt.Errorf("%w: card %s", errRecordNotFound, cardID)
+	}
+	card := &cards.Cards[i]
+	if card.SuspendedAt == 0 {
+		return Card{}, fmt.Errorf("%w: card %s is not suspended", errRecordConflict, cardID)
+	}
+	card.SuspendedAt = 0
+	card.SuspendedReason = ""
+	card.UpdatedAt = now
+	return *card, nil
+}
+
+// ReportLostCard revokes the card with the card ID because it was lost. With
+// a replacement card ID, it issues a new card to the same person with the
+// same role and expiry, which is returned as well
+func (cards *Cards) ReportLostCard(cardID string, replacementCardID string, people People, now int64) (Card, *Card, error) {
+	i := cards.cardIndex(cardID)
+	if i < 0 {
+		return Card{}, nil, fmt.Errorf("%w: card %s", errRecordNotFound, cardID)
+	}
+	if cards.Cards[i].RevokedAt != 0 {
+		return Card{}, nil, fmt.Errorf("%w: card %s was revoked already", errRecordConflict, cardID)
+	}
+
+	var replacement *Card
+	if replacementCardID != "" {
+		lost := cards.Cards[i]
+		added, err := cards.AddCard(Card{
+			CardID:     replacementCardID,
+			RoleID:     lost.RoleID,
+			IsValid:    true,
+			PersonID:   lost.PersonID,
+			ValidUntil: lost.ValidUntil,
+		}, people, now)
+		if err != nil {
+			return Card{}, nil, err
+		}
+		replacement = &added
+	}
+
+	card := &cards.Cards[i]
+	card.IsValid = false
+	card.RevokedAt = now
+	card.RevokedReason = RevokedReasonLost
+	card.SuspendedAt = 0
+	card.SuspendedReason = ""
+	card.UpdatedAt = now
+	if replacement != nil {
+		card.ReplacedBy = replacement.CardID
+	}
+	return *card, replacement, nil
+}
@@ -0,0 +1,106 @@
+// Copyright © 2026 Intel Corporation. All rights reserved.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package routes
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCardStatus(t *testing.T) {
+	tests := []struct {
+		Name           string
+		Card           Card
+		ExpectedStatus string
+	}{
+		{"No validity window", Card{}, CardStatusActive},
+		{"Within validity window", Card{ValidFrom: 100, ValidUntil: 300}, CardStatusActive},
+		{"Before validFrom", Card{ValidFrom: 300}, CardStatusNotYetValid},
+		{"At validUntil", Card{ValidUntil: 200}, CardStatusExpired},
+		{"Suspended", Card{SuspendedAt: 100}, CardStatusSuspended},
+		{"Revoked while suspended", Card{SuspendedAt: 100, RevokedAt: 150}, CardStatusRevoked},
+		{"Revoked and expired", Card{ValidUntil: 100, RevokedAt: 150}, CardStatusRevoked},
+	}
+	for _, test := range tests {
+		currentTest := test
+		t.Run(currentTest.Name, func(t *testing.T) {
+			assert.Equal(t, currentTest.ExpectedStatus, currentTest.Card.Status(200))
+		})
+	}
+}
+
+func TestSuspendAndResumeCard(t *testing.T) {
+	cards := setupCards()
+
+	_, err := cards.SuspendCard("0001230001", "", 1700000000)
+	assert.ErrorIs(t, err, errInvalidRecord, "a suspension needs a reason")
+	card, err := cards.SuspendCard("0001230001", "shared with a visitor", 1700000000)
+	require.NoError(t, err)
+	assert.Equal(t, CardStatusSuspended, card.Status(1700000001))
+	assert.Equal(t, "shared with a visitor", cards.Cards[0].SuspendedReason)
+	_, err = cards.SuspendCard("0001230001", "again", 1700000001)
+	assert.ErrorIs(t, err, errRecordConflict)
+
+	updated, err := cards.UpdateCard(Card{CardID: "0001230001", RoleID: 2, IsValid: true, PersonID: 1}, setupPeople(), 1700000002)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1700000000), updated.SuspendedAt, "an update should keep the suspension")
+
+	card, err = cards.ResumeCard("0001230001", 1700000003)
+	require.NoError(t, err)
+	assert.Equal(t, CardStatusActive, card.Status(1700000004))
+	assert.Empty(t, card.SuspendedReason)
+	_, err = cards.ResumeCard("0001230001", 1700000004)
+	assert.ErrorIs(t, err, errRecordConflict)
+	_, err = cards.SuspendCard("0001230100", "unknown", 1700000004)
+	assert.ErrorIs(t, err, errRecordNotFound)
+}
+
+func TestReportLostCard(t *testing.T) {
+	tests := []struct {
+		Name              string
+		CardID            string
+		ReplacementCardID string
+		ExpectedError     error
+	}{
+		{"Lost card", "0001230001", "", nil},
+		{"Lost card with replacement", "0001230001", "0001230100", nil},
+		{"Replacement with existing card ID", "0001230001", "0001230002", errRecordConflict},
+		{"Unknown card", "0001230100", "", errRecordNotFound},
+	}
+	for _, test := range tests {
+		currentTest := test
+		t.Run(currentTest.Name, func(t *testing.T) {
+			cards := setupCards()
+			cards.Cards[0].ValidUntil = 1800000000
+			card, replacement, err := cards.ReportLostCard(currentTest.CardID, currentTest.ReplacementCardID, setupPeople(), 1700000000)
+			if currentTest.ExpectedError != nil {
+				require.ErrorIs(t, err, currentTest.ExpectedError)
+				assert.Zero(t, cards.Cards[0].RevokedAt, "a failed report should not revoke the card")
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, CardStatusRevoked, card.Status(1700000001))
+			assert.False(t, card.IsValid)
+			assert.Equal(t, RevokedReasonLost, card.RevokedReason)
+			assert.Equal(t, card, cards.GetCardByCardID(currentTest.CardID))
+
+			_, _, err = cards.ReportLostCard(currentTest.CardID, "", setupPeople(), 1700000001)
+			assert.ErrorIs(t, err, errRecordConflict, "a revoked card cannot be reported again")
+			_, err = cards.UpdateCard(Card{CardID: currentTest.CardID, IsValid: true, PersonID: 1}, setupPeople(), 1700000001)
+			assert.ErrorIs(t, err, errRecordConflict, "a revoked card cannot be changed")
+
+			if currentTest.ReplacementCardID == "" {
+				assert.Nil(t, replacement)
+				assert.Empty(t, card.ReplacedBy)
+				return
+			}
+			require.NotNil(t, replacement)
+			assert.Equal(t, currentTest.ReplacementCardID, card.ReplacedBy)
+			assert.Equal(t, Card{CardID: currentTest.ReplacementCardID, RoleID: 1, IsValid: true, PersonID: 1, CreatedAt: 1700000000, UpdatedAt: 1700000000, ValidUntil: 1800000000}, *replacement)
+			assert.Equal(t, *replacement, cards.GetCardByCardID(currentTest.ReplacementCardID))
+		})
+	}
+}
@@ -0,0 +1,243 @@
+// Copyright © 2026 Intel Corporation. All rights reserved.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package routes
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	// errInvalidRecord is returned for a card, person or account that can
+	// never be stored, such as a card without a card ID
+	errInvalidRecord = errors.New("invalid record")
+	// errRecordNotFound is returned when the card, person or account to
+	// change does not exist
+	errRecordNotFound = errors.New("record not found")
+	// errRecordConflict is returned for a new card, person or account with
+	// the ID of one that exists already, or for a change that conflicts with
+	// the state of a record, such as a suspension of a revoked card
+	errRecordConflict = errors.New("record conflict")
+	// errUnknownReference is returned for a card of a person, or a person of
+	// an account, that does not exist
+	errUnknownReference = errors.New("unknown reference")
+)
+
+// cardIndex returns the index of the card with the card ID, or -1
+func (cards *Cards) cardIndex(cardID string) int {
+	for i, card := range cards.Cards {
+		if card.CardID == cardID {
+			return i
+		}
+	}
+	return -1
+}
+
+// personIndex returns the index of the person with the person ID, or -1
+func (people *People) personIndex(personID int) int {
+	for i, person := range people.People {
+		if person.PersonID == personID {
+			return i
+		}
+	}
+	return -1
+}
+
+// accountIndex returns the index of the account with the account ID, or -1
+func (accounts *Accounts) accountIndex(accountID int) int {
+	for i, account := range accounts.Accounts {
+		if account.AccountID == accountID {
+			return i
+		}
+	}
+	return -1
+}
+
+// validateCard checks that the card has a card ID and belongs to a person
+// that exists. The card ID must already be normalized by the card ID
+// formats
+func validateCard(card Card, people People) error {
+	if card.CardID == "" {
+		return fmt.Errorf("%w: a card ID is required", errInvalidRecord)
+	}
+	if card.ValidUntil != 0 && card.ValidFrom >= card.ValidUntil {
+		return fmt.Errorf("%w: validFrom of card %s must be before its validUntil", errInvalidRecord, card.CardID)
+	}
+	if people.personIndex(card.PersonID) < 0 {
+		return fmt.Errorf("%w: person ID %d of card %s does not exist", errUnknownReference, card.PersonID, card.CardID)
+	}
+	return nil
+}
+
+// validatePerson checks that the person has a positive person ID and belongs
+// to an account that exists
+func validatePerson(person Person, accounts Accounts) error {
+	if person.PersonID <= 0 {
+		return fmt.Errorf("%w: person ID %d must be greater than 0", errInvalidRecord, person.PersonID)
+	}
+	if accounts.accountIndex(person.AccountID) < 0 {
+		return fmt.Errorf("%w: account ID %d of person %d does not exist", errUnknownReference, person.AccountID, person.PersonID)
+	}
+	return nil
+}
+
+// AddCard adds a new card of a person of the people, created at now. A new
+// card is neither suspended nor revoked, and has no PIN until one is set
+func (cards *Cards) AddCard(card Card, people People, now int64) (Card, error) {
+	if err := validateCard(card, people); err != nil {
+		return Card{}, err
+	}
+	if cards.cardIndex(card.CardID) >= 0 {
+		return Card{}, fmt.Errorf("%w: card %s", errRecordConflict, card.CardID)
+	}
+	card.SuspendedAt, card.SuspendedReason = 0, ""
+	card.RevokedAt, card.RevokedReason, card.ReplacedBy = 0, "", ""
+	card.PINHash, card.PINSet, card.PINFailedAttempts, card.PINLockedAt = "", false, 0, 0
+	card.CreatedAt = now
+	card.UpdatedAt = now
+	cards.Cards = append(cards.Cards, card)
+	return card, nil
+}
+
+// UpdateCard replaces the card with the card ID of the update, keeping when
+// it was created, its suspension and its PIN. A revoked card cannot be
+// changed
+func (cards *Cards) UpdateCard(update Card, people People, now int64) (Card, error) {
+	i := cards.cardIndex(update.CardID)
+	if i < 0 {
+		return Card{}, fmt.Errorf("%w: card %s", errRecordNotFound, update.CardID)
+	}
+	existing := cards.Cards[i]
+	if existing.RevokedAt != 0 {
+		return Card{}, fmt.Errorf("%w: card %s was revoked", errRecordConflict, update.CardID)
+	}
+	if err := validateCard(update, people); err != nil {
+		return Card{}, err
+	}
+	update.SuspendedAt, update.SuspendedReason = existing.SuspendedAt, existing.SuspendedReason
+	update.RevokedAt, update.RevokedReason, update.ReplacedBy = 0, "", ""
+	update.PINHash, update.PINSet = existing.PINHash, false
+	update.PINFailedAttempts, update.PINLockedAt = existing.PINFailedAttempts, existing.PINLockedAt
+	update.CreatedAt = existing.CreatedAt
+	update.UpdatedAt = now
+	cards.Cards[i] = update
+	return update, nil
+}
+
+// DeactivateCard marks the card with the card ID as not valid. The card is
+// kept, so its history can still be traced
+func (cards *Cards) DeactivateCard(cardID string, now int64) (Card, error) {
+	i := cards.cardIndex(cardID)
+	if i < 0 {
+		return Card{}, fmt.Errorf("%w: card %s", errRecordNotFound, cardID)
+	}
+	cards.Cards[i].IsValid = false
+	cards.Cards[i].UpdatedAt = now
+	return cards.Cards[i], nil
+}
+
+// AddPerson adds a new person of an account of the accounts, created at
+// now. A person without a person ID gets the person ID after the greatest
+// one
+func (people *People) AddPerson(person Person, accounts Accounts, now int64) (Person, error) {
+	if person.PersonID == 0 {
+		for _, existing := range people.People {
+			if existing.PersonID > person.PersonID {
+				person.PersonID = existing.PersonID
+			}
+		}
+		person.PersonID++
+	}
+	if err := validatePerson(person, accounts); err != nil {
+		return Person{}, err
+	}
+	if people.personIndex(person.PersonID) >= 0 {
+		return Person{}, fmt.Errorf("%w: person %d", errRecordConflict, person.PersonID)
+	}
+	person.CreatedAt = now
+	person.UpdatedAt = now
+	people.People = append(people.People, person)
+	return person, nil
+}
+
+// UpdatePerson replaces the person with the person ID of the update, keeping
+// when it was created
+func (people *People) UpdatePerson(update Person, accounts Accounts, now int64) (Person, error) {
+	i := people.personIndex(update.PersonID)
+	if i < 0 {
+		return Person{}, fmt.Errorf("%w: person %d", errRecordNotFound, update.PersonID)
+	}
+	if err := validatePerson(update, accounts); err != nil {
+		return Person{}, err
+	}
+	update.CreatedAt = people.People[i].CreatedAt
+	update.UpdatedAt = now
+	people.People[i] = update
+	return update, nil
+}
+
+// DeactivatePerson marks the person with the person ID as inactive, which
+// stops every card of the person from authenticating
+func (people *People) DeactivatePerson(personID int, now int64) (Person, error) {
+	i := people.personIndex(personID)
+	if i < 0 {
+		return Person{}, fmt.Errorf("%w: person %d", errRecordNotFound, personID)
+	}
+	people.People[i].IsActive = false
+	people.People[i].UpdatedAt = now
+	return people.People[i], nil
+}
+
+// AddAccount adds a new account, created at now. An account without an
+// account ID gets the account ID after the greatest one
+func (accounts *Accounts) AddAccount(account Account, now int64) (Account, error) {
+	if account.AccountID == 0 {
+		for _, existing := range accounts.Accounts {
+			if existing.AccountID > account.AccountID {
+				account.AccountID = existing.AccountID
+			}
+		}
+		account.AccountID++
+	}
+	if account.AccountID <= 0 {
+		return Account{}, fmt.Errorf("%w: account ID %d must be greater than 0", errInvalidRecord, account.AccountID)
+	}
+	if accounts.accountIndex(account.AccountID) >= 0 {
+		return Account{}, fmt.Errorf("%w: account %d", errRecordConflict, account.AccountID)
+	}
+	account.CreatedAt = now
+	account.UpdatedAt = now
+	accounts.Accounts = append(accounts.Accounts, account)
+	return account, nil
+}
+
+// UpdateAccount replaces the account with the account ID of the update,
+// keeping when it was created. An update without a credit card keeps the
+// credit card on file
+func (accounts *Accounts) UpdateAccount(update Account, now int64) (Account, error) {
+	i := accounts.accountIndex(update.AccountID)
+	if i < 0 {
+		return Account{}, fmt.Errorf("%w: account %d", errRecordNotFound, update.AccountID)
+	}
+	if update.CreditCardNumber == "" && update.CreditCardToken == "" {
+		update.CreditCardToken = accounts.Accounts[i].CreditCardToken
+		update.CreditCardLast4 = accounts.Accounts[i].CreditCardLast4
+	}
+	update.CreatedAt = accounts.Accounts[i].CreatedAt
+	update.UpdatedAt = now
+	accounts.Accounts[i] = update
+	return update, nil
+}
+
+// DeactivateAccount marks the account with the account ID as inactive, which
+// stops every card of the people of the account from authenticating
+func (accounts *Accounts) DeactivateAccount(accountID int, now int64) (Account, error) {
+	i := accounts.accountIndex(accountID)
+	if i < 0 {
+		return Account{}, fmt.Errorf("%w: account %d", errRecordNotFound, accountID)
+	}
+	accounts.Accounts[i].IsActive = false
+	accounts.Accounts[i].UpdatedAt = now
+	return accounts.Accounts[i], nil
+}
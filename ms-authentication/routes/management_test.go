@@ -0,0 +1,136 @@
+// Copyright © 2026 Intel Corporation. All rights reserved.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package routes
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAddCard(t *testing.T) {
+	tests := []struct {
+		Name          string
+		Card          Card
+		ExpectedError error
+	}{
+		{"New card", Card{CardID: "0001230100", RoleID: 1, IsValid: true, PersonID: 1}, nil},
+		{"Existing card", Card{CardID: "0001230001", RoleID: 1, IsValid: true, PersonID: 1}, errRecordConflict},
+		{"Missing card ID", Card{RoleID: 1, IsValid: true, PersonID: 1}, errInvalidRecord},
+		{"Unknown person", Card{CardID: "0001230100", RoleID: 1, IsValid: true, PersonID: 10}, errUnknownReference},
+	}
+	for _, test := range tests {
+		currentTest := test
+		t.Run(currentTest.Name, func(t *testing.T) {
+			cards := setupCards()
+			card, err := cards.AddCard(currentTest.Card, setupPeople(), 1700000000)
+			if currentTest.ExpectedError != nil {
+				require.ErrorIs(t, err, currentTest.ExpectedError)
+				assert.Len(t, cards.Cards, len(setupCards().Cards))
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, int64(1700000000), card.CreatedAt)
+			assert.Equal(t, card, cards.GetCardByCardID(currentTest.Card.CardID))
+		})
+	}
+}
+
+func TestUpdateCard(t *testing.T) {
+	cards := setupCards()
+	card, err := cards.UpdateCard(Card{CardID: "0001230001", RoleID: 2, IsValid: true, PersonID: 2}, setupPeople(), 1700000000)
+	require.NoError(t, err)
+	assert.Equal(t, Card{CardID: "0001230001", RoleID: 2, IsValid: true, PersonID: 2, CreatedAt: 1560815799, UpdatedAt: 1700000000}, card)
+	assert.Equal(t, card, cards.Cards[0])
+
+	_, err = cards.UpdateCard(Card{CardID: "0001230001", PersonID: 10}, setupPeople(), 1700000000)
+	assert.ErrorIs(t, err, errUnknownReference)
+	_, err = cards.UpdateCard(Card{CardID: "0001230100", PersonID: 1}, setupPeople(), 1700000000)
+	assert.ErrorIs(t, err, errRecordNotFound)
+}
+
+func TestDeactivateCard(t *testing.T) {
+	cards := setupCards()
+	card, err := cards.DeactivateCard("0001230001", 1700000000)
+	require.NoError(t, err)
+	assert.False(t, card.IsValid)
+	assert.False(t, cards.Cards[0].IsValid)
+	assert.Len(t, cards.Cards, len(setupCards().Cards), "a deactivated card should be kept")
+
+	_, err = cards.DeactivateCard("0001230100", 1700000000)
+	assert.ErrorIs(t, err, errRecordNotFound)
+}
+
+func TestAddPerson(t *testing.T) {
+	tests := []struct {
+		Name             string
+		Person           Person
+		ExpectedPersonID int
+		ExpectedError    error
+	}{
+		{"New person", Person{PersonID: 10, AccountID: 1, FullName: "New Person", IsActive: true}, 10, nil},
+		{"Next person ID", Person{AccountID: 1, FullName: "New Person", IsActive: true}, 8, nil},
+		{"Existing person", Person{PersonID: 1, AccountID: 1, FullName: "New Person"}, 0, errRecordConflict},
+		{"Negative person ID", Person{PersonID: -1, AccountID: 1, FullName: "New Person"}, 0, errInvalidRecord},
+		{"Unknown account", Person{AccountID: 10, FullName: "New Person"}, 0, errUnknownReference},
+	}
+	for _, test := range tests {
+		currentTest := test
+		t.Run(currentTest.Name, func(t *testing.T) {
+			people := setupPeople()
+			person, err := people.AddPerson(currentTest.Person, setupAccounts(), 1700000000)
+			if currentTest.ExpectedError != nil {
+				require.ErrorIs(t, err, currentTest.ExpectedError)
+				assert.Len(t, people.People, len(setupPeople().People))
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, currentTest.ExpectedPersonID, person.PersonID)
+			assert.Equal(t, person, people.GetPersonByPersonID(currentTest.ExpectedPersonID))
+		})
+	}
+}
+
+func TestUpdateAndDeactivatePerson(t *testing.T) {
+	people := setupPeople()
+	person, err := people.UpdatePerson(Person{PersonID: 1, AccountID: 2, FullName: "Renamed Person", IsActive: true}, setupAccounts(), 1700000000)
+	require.NoError(t, err)
+	assert.Equal(t, Person{PersonID: 1, AccountID: 2, FullName: "Renamed Person", CreatedAt: 1560815799, UpdatedAt: 1700000000, IsActive: true}, person)
+
+	_, err = people.UpdatePerson(Person{PersonID: 1, AccountID: 10}, setupAccounts(), 1700000000)
+	assert.ErrorIs(t, err, errUnknownReference)
+	_, err = people.UpdatePerson(Person{PersonID: 10, AccountID: 1}, setupAccounts(), 1700000000)
+	assert.ErrorIs(t, err, errRecordNotFound)
+
+	person, err = people.DeactivatePerson(1, 1700000001)
+	require.NoError(t, err)
+	assert.False(t, person.IsActive)
+	assert.Equal(t, int64(1700000001), people.People[0].UpdatedAt)
+	_, err = people.DeactivatePerson(10, 1700000001)
+	assert.ErrorIs(t, err, errRecordNotFound)
+}
+
+func TestAddUpdateAndDeactivateAccount(t *testing.T) {
+	accounts := setupAccounts()
+	account, err := accounts.AddAccount(Account{EmailAddress: "new@example.com", IsActive: true}, 1700000000)
+	require.NoError(t, err)
+	assert.Equal(t, 6, account.AccountID)
+	_, err = accounts.AddAccount(Account{AccountID: 1}, 1700000000)
+	assert.ErrorIs(t, err, errRecordConflict)
+	_, err = accounts.AddAccount(Account{AccountID: -1}, 1700000000)
+	assert.ErrorIs(t, err, errInvalidRecord)
+
+	account, err = accounts.UpdateAccount(Account{AccountID: 6, EmailAddress: "changed@example.com", IsActive: true}, 1700000001)
+	require.NoError(t, err)
+	assert.Equal(t, Account{AccountID: 6, EmailAddress: "changed@example.com", CreatedAt: 1700000000, UpdatedAt: 1700000001, IsActive: true}, account)
+	_, err = accounts.UpdateAccount(Account{AccountID: 10}, 1700000001)
+	assert.ErrorIs(t, err, errRecordNotFound)
+
+	account, err = accounts.DeactivateAccount(6, 1700000002)
+	require.NoError(t, err)
+	assert.False(t, account.IsActive)
+	_, err = accounts.DeactivateAccount(10, 1700000002)
+	assert.ErrorIs(t, err, errRecordNotFound)
+}
@@ -19,17 +19,49 @@ type Accounts struct {
 	Accounts []Account `json:"accounts"`
 }
 
+// Roles is a struct that simply holds a list of roles
+type Roles struct {
+	Roles []Role `json:"roles"`
+}
+
+// Role is a named set of permissions, such as unlock:lock1 or billable. Every
+// card has a role, and the authentication of a card returns the permissions
+// of its role
+type Role struct {
+	RoleID      int      `json:"roleID"`
+	Name        string   `json:"name"`
+	Permissions []string `json:"permissions"`
+	CreatedAt   int64    `json:"createdAt,string"`
+	UpdatedAt   int64    `json:"updatedAt,string"`
+}
+
 // Card contains role, person and card associations. A person can have multiple
 // cards, but a card can only be associated with one role. If a person needs to
 // take on a different role, they must use a different card with the desired
-// role
+// role. A card only authenticates from ValidFrom until ValidUntil, when they
+// are set, and not while it is suspended. A revoked card, such as a card that
+// was reported lost, never authenticates again and may be ReplacedBy a new
+// card of the same person. All times are in seconds
 type Card struct {
-	CardID    string `json:"cardID"`
-	RoleID    int    `json:"roleID"`
-	IsValid   bool   `json:"isValid"`
-	PersonID  int    `json:"personID"`
-	CreatedAt int64  `json:"createdAt,string"`
-	UpdatedAt int64  `json:"updatedAt,string"`
+	CardID          string `json:"cardID"`
+	RoleID          int    `json:"roleID"`
+	IsValid         bool   `json:"isValid"`
+	PersonID        int    `json:"personID"`
+	CreatedAt       int64  `json:"createdAt,string"`
+	UpdatedAt       int64  `json:"updatedAt,string"`
+	ValidFrom       int64  `json:"validFrom,string,omitempty"`
+	ValidUntil      int64  `json:"validUntil,string,omitempty"`
+	SuspendedAt     int64  `json:"suspendedAt,string,omitempty"`
+	SuspendedReason string `json:"suspendedReason,omitempty"`
+	RevokedAt       int64  `json:"revokedAt,string,omitempty"`
+	RevokedReason   string `json:"revokedReason,omitempty"`
+	ReplacedBy      string `json:"replacedBy,omitempty"`
+	// PINHash is the salted hash of the PIN of the card, which is never
+	// sent in responses. PINSet tells whether it is set instead
+	PINHash           string `json:"pinHash,omitempty"`
+	PINSet            bool   `json:"pinSet,omitempty"`
+	PINFailedAttempts int    `json:"pinFailedAttempts,omitempty"`
+	PINLockedAt       int64  `json:"pinLockedAt,string,omitempty"`
 }
 
 // Person contains person, account, and full name associations. A person
@@ -45,11 +77,16 @@ type Person struct {
 }
 
 // Account contains payment and billing information. Multiple people can
-// be associated with a single account
+// be associated with a single account. A CreditCardNumber is only accepted
+// when an account is created or changed; it is replaced by its token and
+// last four digits before the account is stored. The address, phone number
+// and email address are stored encrypted
 type Account struct {
 	AccountID        int    `json:"accountID"`
 	Address          string `json:"address"`
-	CreditCardNumber string `json:"creditCardNumber"`
+	CreditCardNumber string `json:"creditCardNumber,omitempty"`
+	CreditCardToken  string `json:"creditCardToken,omitempty"`
+	CreditCardLast4  string `json:"creditCardLast4,omitempty"`
 	PhoneNumber      string `json:"phoneNumber"`
 	EmailAddress     string `json:"emailAddress"`
 	CreatedAt        int64  `json:"createdAt,string"`
@@ -57,6 +94,13 @@ type Account struct {
 	IsActive         bool   `json:"isActive"`
 }
 
+// AccountStatus is the state of an account that other services are allowed
+// to see. It leaves out the payment and billing information of the account
+type AccountStatus struct {
+	AccountID int  `json:"accountID"`
+	IsActive  bool `json:"isActive"`
+}
+
 // AuthData is what is expected to be sent back as a response when something
 // hits this endpoint. A card number is passed in, and this code will
 // resolve the card's corresponding role, person, and account
@@ -65,4 +109,38 @@ type AuthData struct {
 	PersonID  int    `json:"personID"`
 	RoleID    int    `json:"roleID"`
 	CardID    string `json:"cardID"`
+	// PINRequired is true when the role of the card requires the PIN of the
+	// card to be verified before access is granted
+	PINRequired bool `json:"pinRequired,omitempty"`
+	// RoleName and Permissions are the name and permissions of the role of
+	// the card. Permissions is empty, and never left out, when the role has
+	// no permissions
+	RoleName    string   `json:"roleName"`
+	Permissions []string `json:"permissions"`
+}
+
+// CardDenial is the response of an authentication of a known card that is
+// not allowed to authenticate at the moment, with the Status of the card
+type CardDenial struct {
+	CardID  string `json:"cardID"`
+	Status  string `json:"status"`
+	Message string `json:"message"`
+}
+
+// suspensionRequest is the body of a suspension of a card
+type suspensionRequest struct {
+	Reason string `json:"reason"`
+}
+
+// lostCardRequest is the body of a report of a lost card. A report with a
+// ReplacementCardID issues a new card with that ID to the same person
+type lostCardRequest struct {
+	ReplacementCardID string `json:"replacementCardID,omitempty"`
+}
+
+// LostCardReport is the response of a report of a lost card: the revoked
+// card and its replacement, if one was issued
+type LostCardReport struct {
+	Card        Card  `json:"card"`
+	Replacement *Card `json:"replacement,omitempty"`
 }
@@ -0,0 +1,241 @@
+// Copyright © 2026 Intel Corporation. All rights reserved.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package routes
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+const (
+	// DefaultPIISecretName is the secret in the secret store with the key
+	// that encrypts the personal and payment data of accounts, unless the
+	// PIISecretName application setting names another one
+	DefaultPIISecretName = "pii"
+	// EncryptionKeySecretKey is the base64 encoded 32 byte AES-256 key in the
+	// PII secret
+	EncryptionKeySecretKey = "encryptionKey"
+	// AdminTokenSecretKey is the token in the PII secret that grants the
+	// admin scope
+	AdminTokenSecretKey = "adminToken"
+	// AdminTokenHeader is the request header with the admin token. Responses
+	// to requests without it have the personal data of accounts masked
+	AdminTokenHeader = "X-Admin-Token"
+
+	encryptedPrefix = "enc:v1:"
+	tokenPrefix     = "tok_"
+)
+
+// developmentEncryptionKeys are base64 encoded encryption keys that were
+// published, such as in an earlier res/configuration.yaml, and so protect
+// nothing
+var developmentEncryptionKeys = []string{
+	"Buv8tVZ+ZUdqLE2F3c0EFW9litjyO6FoMDhoWwIiOJo=",
+	base64.StdEncoding.EncodeToString(make([]byte, 32)),
+}
+
+// placeholderAdminTokens are admin tokens that were published as examples,
+// which anyone could send
+var placeholderAdminTokens = []string{"change-me-admin-token", "change-me", "changeme", "admin"}
+
+// errPIIKeyNotLoaded is returned when accounts are read or written before
+// the encryption key was loaded from the secret store
+var errPIIKeyNotLoaded = errors.New("PII encryption key is not loaded")
+
+// piiProtector encrypts the personal data of accounts at rest and replaces
+// credit card numbers with tokens
+type piiProtector struct {
+	aead       cipher.AEAD
+	tokenKey   []byte
+	adminToken string
+}
+
+// newPIIProtector returns a piiProtector with the AES-256 key. Card tokens
+// are keyed with a key derived from it, so the same card number always has
+// the same token
+func newPIIProtector(key []byte, adminToken string) (*piiProtector, error) {
+	if len(key) != 32 {
+		return nil, fmt.Errorf("PII encryption key must have 32 bytes, not %d", len(key))
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create PII cipher: %s", err.Error())
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create PII cipher: %s", err.Error())
+	}
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte("card token"))
+	return &piiProtector{aead: aead, tokenKey: mac.Sum(nil), adminToken: adminToken}, nil
+}
+
+// parsePIISecrets returns the encryption key of the base64 encoded key, and
+// refuses a missing key or admin token, or one of their published
+// development values
+func parsePIISecrets(encodedKey string, adminToken string) ([]byte, error) {
+	if strings.TrimSpace(encodedKey) == "" {
+		return nil, fmt.Errorf("%s is not set", EncryptionKeySecretKey)
+	}
+	key, err := base64.StdEncoding.DecodeString(encodedKey)
+	if err != nil {
+		return nil, fmt.Errorf("%s is not base64 encoded: %s", EncryptionKeySecretKey, err.Error())
+	}
+	for _, developmentKey := range developmentEncryptionKeys {
+		if known, _ := base64.StdEncoding.DecodeString(developmentKey); subtle.ConstantTimeCompare(key, known) == 1 {
+			return nil, fmt.Errorf("%s is a published development key, generate a new one", EncryptionKeySecretKey)
+		}
+	}
+	if strings.TrimSpace(adminToken) == "" {
+		return nil, fmt.Errorf("%s is not set", AdminTokenSecretKey)
+	}
+	for _, placeholder := range placeholderAdminTokens {
+		if strings.EqualFold(adminToken, placeholder) {
+			return nil, fmt.Errorf("%s is a placeholder, generate a new one", AdminTokenSecretKey)
+		}
+	}
+	return key, nil
+}
+
+// encrypt returns the value encrypted with a random nonce. Empty and already
+// encrypted values are returned as they are
+func (p *piiProtector) encrypt(value string) (string, error) {
+	if value == "" || strings.HasPrefix(value, encryptedPrefix) {
+		return value, nil
+	}
+	nonce := make([]byte, p.aead.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return "", fmt.Errorf("failed to create nonce: %s", err.Error())
+	}
+	sealed := p.aead.Seal(nonce, nonce, []byte(value), nil)
+	return encryptedPrefix + base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// decrypt returns the plaintext of an encrypted value. A value that is not
+// encrypted, such as one written before encryption was enabled, is returned
+// as it is
+func (p *piiProtector) decrypt(value string) (string, error) {
+	if !strings.HasPrefix(value, encryptedPrefix) {
+		return value, nil
+	}
+	sealed, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(value, encryptedPrefix))
+	if err != nil || len(sealed) < p.aead.NonceSize() {
+		return "", errors.New("encrypted value is malformed")
+	}
+	nonceSize := p.aead.NonceSize()
+	plaintext, err := p.aead.Open(nil, sealed[:nonceSize], sealed[nonceSize:], nil)
+	if err != nil {
+		return "", errors.New("failed to decrypt value, the PII encryption key may have changed")
+	}
+	return string(plaintext), nil
+}
+
+// tokenize returns the token of the credit card number and its last four
+// digits. Spaces and dashes in the number are ignored
+func (p *piiProtector) tokenize(number string) (string, string, error) {
+	digits := strings.NewReplacer(" ", "", "-", "").Replace(number)
+	if len(digits) < 12 || len(digits) > 19 || strings.Trim(digits, "0123456789") != "" {
+		return "", "", fmt.Errorf("%w: credit card number must have 12 to 19 digits", errInvalidRecord)
+	}
+	mac := hmac.New(sha256.New, p.tokenKey)
+	mac.Write([]byte(digits))
+	return tokenPrefix + hex.EncodeToString(mac.Sum(nil)[:16]), digits[len(digits)-4:], nil
+}
+
+// tokenizeAccount replaces the credit card number of the account with its
+// token. An account without a credit card number has no token either, so
+// that a new account cannot bring its own token and an update keeps the
+// token on file
+func (p *piiProtector) tokenizeAccount(account Account) (Account, error) {
+	account.CreditCardToken, account.CreditCardLast4 = "", ""
+	if account.CreditCardNumber == "" {
+		return account, nil
+	}
+	token, last4, err := p.tokenize(account.CreditCardNumber)
+	if err != nil {
+		return Account{}, err
+	}
+	account.CreditCardNumber = ""
+	account.CreditCardToken, account.CreditCardLast4 = token, last4
+	return account, nil
+}
+
+// sealAccount returns the account as it is stored, with its address, phone
+// number and email address encrypted and a credit card number tokenized
+func (p *piiProtector) sealAccount(account Account) (Account, error) {
+	if account.CreditCardNumber != "" {
+		var err error
+		if account, err = p.tokenizeAccount(account); err != nil {
+			return Account{}, err
+		}
+	}
+	for _, field := range []*string{&account.Address, &account.PhoneNumber, &account.EmailAddress} {
+		encrypted, err := p.encrypt(*field)
+		if err != nil {
+			return Account{}, fmt.Errorf("failed to encrypt account %d: %s", account.AccountID, err.Error())
+		}
+		*field = encrypted
+	}
+	return account, nil
+}
+
+// openAccount returns the stored account with its fields decrypted. A credit
+// card number that was stored before tokenization is tokenized
+func (p *piiProtector) openAccount(account Account) (Account, error) {
+	for _, field := range []*string{&account.Address, &account.PhoneNumber, &account.EmailAddress} {
+		decrypted, err := p.decrypt(*field)
+		if err != nil {
+			return Account{}, fmt.Errorf("failed to decrypt account %d: %s", account.AccountID, err.Error())
+		}
+		*field = decrypted
+	}
+	if account.CreditCardNumber != "" {
+		var err error
+		if account, err = p.tokenizeAccount(account); err != nil {
+			return Account{}, fmt.Errorf("failed to tokenize account %d: %s", account.AccountID, err.Error())
+		}
+	}
+	return account, nil
+}
+
+// hasAdminScope returns true if the request has the admin token
+func (p *piiProtector) hasAdminScope(req *http.Request) bool {
+	token := req.Header.Get(AdminTokenHeader)
+	return p.adminToken != "" && subtle.ConstantTimeCompare([]byte(token), []byte(p.adminToken)) == 1
+}
+
+// maskAccount returns the account with its personal data masked, keeping
+// enough of it to recognize the account. The last four digits of the credit
+// card are not masked, and the token is left out
+func maskAccount(account Account) Account {
+	account.Address = maskValue(account.Address, 0)
+	account.PhoneNumber = maskValue(account.PhoneNumber, 4)
+	if at := strings.LastIndex(account.EmailAddress, "@"); at > 0 {
+		account.EmailAddress = account.EmailAddress[:1] + "***" + account.EmailAddress[at:]
+	} else {
+		account.EmailAddress = maskValue(account.EmailAddress, 0)
+	}
+	account.CreditCardToken = ""
+	return account
+}
+
+// maskValue replaces every character of the value but the last visible ones
+// with asterisks
+func maskValue(value string, visible int) string {
+	if len(value) <= visible {
+		return strings.Repeat("*", len(value))
+	}
+	return strings.Repeat("*", len(value)-visible) + value[len(value)-visible:]
+}
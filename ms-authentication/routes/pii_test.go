@@ -0,0 +1,229 @@
+// Copyright © 2026 Intel Corporation. All rights reserved.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package routes
+
+import (
+	"encoding/base64"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/edgexfoundry/app-functions-sdk-go/v3/pkg/interfaces/mocks"
+	bootstrapMocks "github.com/edgexfoundry/go-mod-bootstrap/v3/bootstrap/interfaces/mocks"
+	"github.com/edgexfoundry/go-mod-core-contracts/v3/clients/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testAdminToken = "admin-token"
+
+var testPIIKey = []byte("0123456789abcdef0123456789abcdef")
+
+func setupPIIProtector(t *testing.T) *piiProtector {
+	pii, err := newPIIProtector(testPIIKey, testAdminToken)
+	require.NoError(t, err)
+	return pii
+}
+
+func TestNewPIIProtectorKeyLength(t *testing.T) {
+	_, err := newPIIProtector([]byte("short"), testAdminToken)
+	assert.Error(t, err)
+}
+
+func TestParsePIISecrets(t *testing.T) {
+	validKey := base64.StdEncoding.EncodeToString(testPIIKey)
+	tests := []struct {
+		Name          string
+		Key           string
+		AdminToken    string
+		ExpectedError bool
+	}{
+		{"Valid secrets", validKey, testAdminToken, false},
+		{"Missing key", "", testAdminToken, true},
+		{"Key not base64", "not base64", testAdminToken, true},
+		{"Published development key", developmentEncryptionKeys[0], testAdminToken, true},
+		{"All zero key", base64.StdEncoding.EncodeToString(make([]byte, 32)), testAdminToken, true},
+		{"Missing admin token", validKey, "", true},
+		{"Placeholder admin token", validKey, "change-me-admin-token", true},
+	}
+	for _, test := range tests {
+		currentTest := test
+		t.Run(currentTest.Name, func(t *testing.T) {
+			key, err := parsePIISecrets(currentTest.Key, currentTest.AdminToken)
+			if currentTest.ExpectedError {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, testPIIKey, key)
+		})
+	}
+}
+
+func TestEncryptAndDecrypt(t *testing.T) {
+	pii := setupPIIProtector(t)
+
+	encrypted, err := pii.encrypt("1 Test Lane")
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(encrypted, encryptedPrefix))
+	again, err := pii.encrypt("1 Test Lane")
+	require.NoError(t, err)
+	assert.NotEqual(t, encrypted, again, "every encryption should use a new nonce")
+	reencrypted, err := pii.encrypt(encrypted)
+	require.NoError(t, err)
+	assert.Equal(t, encrypted, reencrypted, "an encrypted value should not be encrypted twice")
+
+	decrypted, err := pii.decrypt(encrypted)
+	require.NoError(t, err)
+	assert.Equal(t, "1 Test Lane", decrypted)
+	decrypted, err = pii.decrypt("1 Test Lane")
+	require.NoError(t, err)
+	assert.Equal(t, "1 Test Lane", decrypted, "a value stored before encryption should be read as it is")
+
+	otherPII, err := newPIIProtector([]byte("fedcba9876543210fedcba9876543210"), testAdminToken)
+	require.NoError(t, err)
+	_, err = otherPII.decrypt(encrypted)
+	assert.Error(t, err, "a value should not decrypt with another key")
+	_, err = pii.decrypt(encryptedPrefix + "not base64")
+	assert.Error(t, err)
+}
+
+func TestTokenize(t *testing.T) {
+	tests := []struct {
+		Name          string
+		Number        string
+		ExpectedLast4 string
+		ExpectedError error
+	}{
+		{"Card number with spaces", "1234 4567 9012 3456", "3456", nil},
+		{"Card number with dashes", "4111-1111-1111-1111", "1111", nil},
+		{"Too short", "1234", "", errInvalidRecord},
+		{"Not a number", "1234 4567 9012 345x", "", errInvalidRecord},
+	}
+	pii := setupPIIProtector(t)
+	for _, test := range tests {
+		currentTest := test
+		t.Run(currentTest.Name, func(t *testing.T) {
+			token, last4, err := pii.tokenize(currentTest.Number)
+			if currentTest.ExpectedError != nil {
+				require.ErrorIs(t, err, currentTest.ExpectedError)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, currentTest.ExpectedLast4, last4)
+			assert.True(t, strings.HasPrefix(token, tokenPrefix))
+			assert.NotContains(t, token, strings.ReplaceAll(currentTest.Number, " ", ""))
+			again, _, err := pii.tokenize(strings.NewReplacer(" ", "", "-", "").Replace(currentTest.Number))
+			require.NoError(t, err)
+			assert.Equal(t, token, again, "the same card number should have the same token")
+		})
+	}
+}
+
+func TestSealAndOpenAccount(t *testing.T) {
+	pii := setupPIIProtector(t)
+	account := setupAccounts().Accounts[0]
+
+	sealed, err := pii.sealAccount(account)
+	require.NoError(t, err)
+	assert.Empty(t, sealed.CreditCardNumber)
+	assert.Equal(t, "3456", sealed.CreditCardLast4)
+	for _, value := range []string{sealed.Address, sealed.PhoneNumber, sealed.EmailAddress} {
+		assert.True(t, strings.HasPrefix(value, encryptedPrefix))
+	}
+
+	opened, err := pii.openAccount(sealed)
+	require.NoError(t, err)
+	expected := account
+	expected.CreditCardNumber = ""
+	expected.CreditCardToken, expected.CreditCardLast4 = sealed.CreditCardToken, sealed.CreditCardLast4
+	assert.Equal(t, expected, opened)
+
+	legacy, err := pii.openAccount(account)
+	require.NoError(t, err)
+	assert.Equal(t, expected, legacy, "an account stored before encryption should be tokenized when it is read")
+}
+
+func TestMaskAccount(t *testing.T) {
+	account := Account{
+		AccountID:       1,
+		Address:         "1 Test Lane",
+		CreditCardToken: "tok_0123",
+		CreditCardLast4: "3456",
+		PhoneNumber:     "1234567890",
+		EmailAddress:    "test1@example.com",
+		IsActive:        true,
+	}
+	assert.Equal(t, Account{
+		AccountID:       1,
+		Address:         "***********",
+		CreditCardLast4: "3456",
+		PhoneNumber:     "******7890",
+		EmailAddress:    "t***@example.com",
+		IsActive:        true,
+	}, maskAccount(account))
+}
+
+func TestHasAdminScope(t *testing.T) {
+	pii := setupPIIProtector(t)
+	req := httptest.NewRequest("GET", "/accounts?view=full", nil)
+	assert.False(t, pii.hasAdminScope(req))
+	req.Header.Set(AdminTokenHeader, "wrong")
+	assert.False(t, pii.hasAdminScope(req))
+	req.Header.Set(AdminTokenHeader, testAdminToken)
+	assert.True(t, pii.hasAdminScope(req))
+
+	withoutToken, err := newPIIProtector(testPIIKey, "")
+	require.NoError(t, err)
+	req.Header.Set(AdminTokenHeader, "")
+	assert.False(t, withoutToken.hasAdminScope(req), "no caller should have the admin scope without an admin token")
+}
+
+func TestLoadSecrets(t *testing.T) {
+	defer writeJSONFiles(setupPeople(), setupAccounts(), setupCards())
+	require.NoError(t, writeJSONFiles(setupPeople(), setupAccounts(), setupCards()))
+
+	mockSecretProvider := &bootstrapMocks.SecretProvider{}
+	mockSecretProvider.On("GetSecret", DefaultPIISecretName, EncryptionKeySecretKey, AdminTokenSecretKey).Return(map[string]string{
+		EncryptionKeySecretKey: base64.StdEncoding.EncodeToString(testPIIKey),
+		AdminTokenSecretKey:    testAdminToken,
+	}, nil)
+	mockAppService := &mocks.ApplicationService{}
+	mockAppService.On("LoggingClient").Return(logger.NewMockClient())
+	mockAppService.On("GetAppSetting", "PIISecretName").Return("", nil)
+	mockAppService.On("SecretProvider").Return(mockSecretProvider)
+	c := NewController(mockAppService)
+
+	require.NoError(t, c.LoadSecrets())
+	data, err := os.ReadFile(AccountsFileName)
+	require.NoError(t, err)
+	for _, plaintext := range []string{"1 Test Lane", "1234 4567 9012 3456", "1234567890", "test1@example.com"} {
+		assert.NotContains(t, string(data), plaintext, "the accounts should be encrypted when the secrets are loaded")
+	}
+	info, err := os.Stat(AccountsFileName)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+
+	accounts, err := c.readAccounts()
+	require.NoError(t, err)
+	assert.Equal(t, "test1@example.com", accounts.Accounts[0].EmailAddress)
+}
+
+func TestLoadSecretsInvalidKey(t *testing.T) {
+	mockSecretProvider := &bootstrapMocks.SecretProvider{}
+	mockSecretProvider.On("GetSecret", "custom", EncryptionKeySecretKey, AdminTokenSecretKey).Return(map[string]string{
+		EncryptionKeySecretKey: "not base64",
+		AdminTokenSecretKey:    testAdminToken,
+	}, nil)
+	mockAppService := &mocks.ApplicationService{}
+	mockAppService.On("LoggingClient").Return(logger.NewMockClient())
+	mockAppService.On("GetAppSetting", "PIISecretName").Return("custom", nil)
+	mockAppService.On("SecretProvider").Return(mockSecretProvider)
+	c := NewController(mockAppService)
+
+	assert.Error(t, c.LoadSecrets())
+	_, err := c.readAccounts()
+	assert.ErrorIs(t, err, errPIIKeyNotLoaded)
+}
@@ -0,0 +1,191 @@
+// Copyright © 2026 Intel Corporation. All rights reserved.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package routes
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"golang.org/x/crypto/pbkdf2"
+)
+
+// Statuses of a card of a role that requires a PIN, which cannot
+// authenticate until its PIN is set or reset
+const (
+	CardStatusPINNotSet = "pinNotSet"
+	CardStatusPINLocked = "pinLocked"
+)
+
+// DefaultPINMaxAttempts is the number of wrong PINs in a row that lock the
+// PIN of a card, unless the PINMaxAttempts application setting is set
+const DefaultPINMaxAttempts = 3
+
+const (
+	pinMinLength = 4
+	pinMaxLength = 8
+	// pinHashScheme prefixes every PIN hash, with its iterations, salt and
+	// key, so that the parameters can change without invalidating stored PINs
+	pinHashScheme     = "pbkdf2-sha256"
+	pinHashIterations = 100000
+	pinSaltLength     = 16
+	pinKeyLength      = 32
+)
+
+// pinRequest is the body of a change or a verification of the PIN of a card
+type pinRequest struct {
+	PIN string `json:"pin"`
+}
+
+// PINVerification is the response of a verification of the PIN of a card,
+// with the number of wrong PINs that may still be entered before it locks
+type PINVerification struct {
+	CardID            string `json:"cardID"`
+	Verified          bool   `json:"verified"`
+	AttemptsRemaining int    `json:"attemptsRemaining"`
+	Status            string `json:"status,omitempty"`
+}
+
+// validatePIN returns an error unless the PIN is 4 to 8 digits, which can be
+// entered on the keypad of the card reader
+func validatePIN(pin string) error {
+	if len(pin) < pinMinLength || len(pin) > pinMaxLength {
+		return fmt.Errorf("%w: a PIN must have %d to %d digits", errInvalidRecord, pinMinLength, pinMaxLength)
+	}
+	for _, digit := range pin {
+		if digit < '0' || digit > '9' {
+			return fmt.Errorf("%w: a PIN must only have digits", errInvalidRecord)
+		}
+	}
+	return nil
+}
+
+// hashPIN returns the salted hash of the PIN in the form
+// pbkdf2-sha256$<iterations>$<salt>$<key>
+func hashPIN(pin string) (string, error) {
+	salt := make([]byte, pinSaltLength)
+	if _, err := rand.Read(salt); err != nil {
+		return "", fmt.Errorf("failed to generate PIN salt: %s", err.Error())
+	}
+	key := pbkdf2.Key([]byte(pin), salt, pinHashIterations, pinKeyLength, sha256.New)
+	return strings.Join([]string{
+		pinHashScheme,
+		strconv.Itoa(pinHashIterations),
+		base64.RawStdEncoding.EncodeToString(salt),
+		base64.RawStdEncoding.EncodeToString(key),
+	}, "$"), nil
+}
+
+// pinMatches returns true if the PIN has the hash. A hash that cannot be
+// parsed matches no PIN
+func pinMatches(pin string, pinHash string) bool {
+	parts := strings.Split(pinHash, "$")
+	if len(parts) != 4 || parts[0] != pinHashScheme {
+		return false
+	}
+	iterations, err := strconv.Atoi(parts[1])
+	if err != nil || iterations <= 0 {
+		return false
+	}
+	salt, err := base64.RawStdEncoding.DecodeString(parts[2])
+	if err != nil {
+		return false
+	}
+	expected, err := base64.RawStdEncoding.DecodeString(parts[3])
+	if err != nil || len(expected) == 0 {
+		return false
+	}
+	key := pbkdf2.Key([]byte(pin), salt, iterations, len(expected), sha256.New)
+	return subtle.ConstantTimeCompare(key, expected) == 1
+}
+
+// pinStatus returns the status of the PIN of a card of a role that requires
+// one, which is active once the PIN is set and while it is not locked
+func (card *Card) pinStatus() string {
+	switch {
+	case card.PINHash == "":
+		return CardStatusPINNotSet
+	case card.PINLockedAt != 0:
+		return CardStatusPINLocked
+	}
+	return CardStatusActive
+}
+
+// SetCardPIN sets the PIN of the card with the card ID, which also unlocks
+// a PIN that was locked by wrong PINs. A revoked card cannot be changed
+func (cards *Cards) SetCardPIN(cardID string, pin string, now int64) (Card, error) {
+	i := cards.cardIndex(cardID)
+	if i < 0 {
+		return Card{}, fmt.Errorf("%w: card %s", errRecordNotFound, cardID)
+	}
+	if err := validatePIN(pin); err != nil {
+		return Card{}, err
+	}
+	card := &cards.Cards[i]
+	if card.RevokedAt != 0 {
+		return Card{}, fmt.Errorf("%w: card %s was revoked", errRecordConflict, cardID)
+	}
+	pinHash, err := hashPIN(pin)
+	if err != nil {
+		return Card{}, err
+	}
+	card.PINHash = pinHash
+	card.PINFailedAttempts = 0
+	card.PINLockedAt = 0
+	card.UpdatedAt = now
+	return *card, nil
+}
+
+// VerifyCardPIN checks the PIN of the card with the card ID. A wrong PIN
+// counts as a failed attempt, and the PIN locks once maxAttempts wrong PINs
+// were entered in a row. A right PIN resets the failed attempts. The cards
+// change whenever the attempts of the card change, so they must be written
+// unless the returned error is not nil
+func (cards *Cards) VerifyCardPIN(cardID string, pin string, maxAttempts int, now int64) (PINVerification, error) {
+	i := cards.cardIndex(cardID)
+	if i < 0 {
+		return PINVerification{}, fmt.Errorf("%w: card %s", errRecordNotFound, cardID)
+	}
+	card := &cards.Cards[i]
+	verification := PINVerification{CardID: cardID}
+	if status := card.pinStatus(); status != CardStatusActive {
+		verification.Status = status
+		return verification, nil
+	}
+
+	if pinMatches(pin, card.PINHash) {
+		card.PINFailedAttempts = 0
+		verification.Verified = true
+		verification.AttemptsRemaining = maxAttempts
+		verification.Status = CardStatusActive
+		return verification, nil
+	}
+
+	card.PINFailedAttempts++
+	card.UpdatedAt = now
+	if card.PINFailedAttempts >= maxAttempts {
+		card.PINLockedAt = now
+		verification.Status = CardStatusPINLocked
+		return verification, nil
+	}
+	verification.AttemptsRemaining = maxAttempts - card.PINFailedAttempts
+	verification.Status = CardStatusActive
+	return verification, nil
+}
+
+// cardsView returns the cards as they are sent in responses, with whether
+// their PIN is set instead of their PIN hashes
+func cardsView(cards ...Card) []Card {
+	view := make([]Card, len(cards))
+	for i, card := range cards {
+		card.PINSet = card.PINHash != ""
+		card.PINHash = ""
+		view[i] = card
+	}
+	return view
+}
@@ -0,0 +1,229 @@
+// Copyright © 2026 Intel Corporation. All rights reserved.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package routes
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/edgexfoundry/app-functions-sdk-go/v3/pkg/interfaces/mocks"
+	"github.com/edgexfoundry/go-mod-core-contracts/v3/clients/logger"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidatePIN(t *testing.T) {
+	tests := []struct {
+		Name          string
+		PIN           string
+		ExpectedError error
+	}{
+		{"Four digits", "1234", nil},
+		{"Eight digits", "12345678", nil},
+		{"Too short", "123", errInvalidRecord},
+		{"Too long", "123456789", errInvalidRecord},
+		{"Not digits", "12a4", errInvalidRecord},
+	}
+	for _, test := range tests {
+		currentTest := test
+		t.Run(currentTest.Name, func(t *testing.T) {
+			err := validatePIN(currentTest.PIN)
+			if currentTest.ExpectedError != nil {
+				assert.ErrorIs(t, err, currentTest.ExpectedError)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestHashPIN(t *testing.T) {
+	pinHash, err := hashPIN("1234")
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(pinHash, pinHashScheme+"$"))
+	assert.NotContains(t, pinHash, "1234")
+	again, err := hashPIN("1234")
+	require.NoError(t, err)
+	assert.NotEqual(t, pinHash, again, "every PIN should have its own salt")
+
+	assert.True(t, pinMatches("1234", pinHash))
+	assert.True(t, pinMatches("1234", again))
+	assert.False(t, pinMatches("4321", pinHash))
+	assert.False(t, pinMatches("1234", ""))
+	assert.False(t, pinMatches("1234", "sha1$1$c2FsdA$a2V5"))
+}
+
+func TestSetAndVerifyCardPIN(t *testing.T) {
+	cards := setupCards()
+
+	verification, err := cards.VerifyCardPIN("0001230001", "1234", 3, 1700000000)
+	require.NoError(t, err)
+	assert.Equal(t, CardStatusPINNotSet, verification.Status)
+	_, err = cards.SetCardPIN("0001230001", "12", 1700000000)
+	assert.ErrorIs(t, err, errInvalidRecord)
+	card, err := cards.SetCardPIN("0001230001", "1234", 1700000000)
+	require.NoError(t, err)
+	assert.NotEmpty(t, card.PINHash)
+
+	verification, err = cards.VerifyCardPIN("0001230001", "1111", 3, 1700000001)
+	require.NoError(t, err)
+	assert.Equal(t, PINVerification{CardID: "0001230001", AttemptsRemaining: 2, Status: CardStatusActive}, verification)
+	verification, err = cards.VerifyCardPIN("0001230001", "1234", 3, 1700000002)
+	require.NoError(t, err)
+	assert.True(t, verification.Verified)
+	assert.Equal(t, 3, verification.AttemptsRemaining, "the right PIN should reset the failed attempts")
+
+	for i := 0; i < 3; i++ {
+		verification, err = cards.VerifyCardPIN("0001230001", "1111", 3, 1700000003)
+		require.NoError(t, err)
+	}
+	assert.Equal(t, CardStatusPINLocked, verification.Status)
+	assert.Equal(t, int64(1700000003), cards.Cards[0].PINLockedAt)
+	verification, err = cards.VerifyCardPIN("0001230001", "1234", 3, 1700000004)
+	require.NoError(t, err)
+	assert.False(t, verification.Verified, "a locked PIN should not verify even when it is right")
+
+	updated, err := cards.UpdateCard(Card{CardID: "0001230001", RoleID: 2, IsValid: true, PersonID: 1, PINHash: "replaced"}, setupPeople(), 1700000005)
+	require.NoError(t, err)
+	assert.Equal(t, card.PINHash, updated.PINHash, "an update should keep the PIN")
+	assert.Equal(t, int64(1700000003), updated.PINLockedAt, "an update should not unlock the PIN")
+
+	_, err = cards.SetCardPIN("0001230001", "5678", 1700000006)
+	require.NoError(t, err)
+	verification, err = cards.VerifyCardPIN("0001230001", "5678", 3, 1700000007)
+	require.NoError(t, err)
+	assert.True(t, verification.Verified, "setting the PIN again should unlock it")
+
+	_, err = cards.VerifyCardPIN("0001230100", "1234", 3, 1700000008)
+	assert.ErrorIs(t, err, errRecordNotFound)
+	added, err := cards.AddCard(Card{CardID: "0001230100", RoleID: 1, IsValid: true, PersonID: 1, PINHash: card.PINHash}, setupPeople(), 1700000008)
+	require.NoError(t, err)
+	assert.Empty(t, added.PINHash, "a new card should not take its PIN from the request")
+}
+
+func TestCardPINHandlers(t *testing.T) {
+	defer writeJSONFiles(setupPeople(), setupAccounts(), setupCards())
+	mockAppService := &mocks.ApplicationService{}
+	mockAppService.On("LoggingClient").Return(logger.NewMockClient())
+	c := NewController(mockAppService)
+	c.pii = setupPIIProtector(t)
+	require.NoError(t, writeJSONFiles(setupPeople(), setupAccounts(), setupCards()))
+
+	sendWithToken := func(token string, handler http.HandlerFunc, method string, cardID string, path string, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, "/cards/"+cardID+"/"+path, bytes.NewBufferString(body))
+		req = mux.SetURLVars(req, map[string]string{"cardid": cardID})
+		if token != "" {
+			req.Header.Set(AdminTokenHeader, token)
+		}
+		w := httptest.NewRecorder()
+		handler(w, req)
+		return w
+	}
+	send := func(handler http.HandlerFunc, method string, cardID string, path string, body string) *httptest.ResponseRecorder {
+		return sendWithToken(testAdminToken, handler, method, cardID, path, body)
+	}
+
+	assert.Equal(t, http.StatusUnauthorized, sendWithToken("", c.CardPINPut, "PUT", "0001230001", "pin", `{"pin":"1234"}`).Code, "a PIN should not be set without the admin scope")
+	assert.Equal(t, http.StatusUnauthorized, sendWithToken("wrong", c.CardPINPut, "PUT", "0001230001", "pin", `{"pin":"1234"}`).Code)
+
+	w := send(c.CardPINVerifyPost, "POST", "0001230001", "pin/verify", `{"pin":"1234"}`)
+	assert.Equal(t, http.StatusForbidden, w.Code, "a card without a PIN cannot verify one")
+	assert.Equal(t, http.StatusBadRequest, send(c.CardPINPut, "PUT", "0001230001", "pin", `{"pin":"12"}`).Code)
+	assert.Equal(t, http.StatusNotFound, send(c.CardPINPut, "PUT", "0001230100", "pin", `{"pin":"1234"}`).Code)
+	w = send(c.CardPINPut, "PUT", "0001230001", "pin", `{"pin":"1234"}`)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.NotContains(t, w.Body.String(), "pinHash", "the PIN hash should never be sent")
+	var card Card
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&card))
+	assert.True(t, card.PINSet)
+
+	var verification PINVerification
+	w = send(c.CardPINVerifyPost, "POST", "0001230001", "pin/verify", `{"pin":"1234"}`)
+	require.Equal(t, http.StatusOK, w.Code)
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&verification))
+	assert.True(t, verification.Verified)
+
+	w = send(c.CardPINVerifyPost, "POST", "0001230001", "pin/verify", `{"pin":"0000"}`)
+	require.Equal(t, http.StatusUnauthorized, w.Code)
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&verification))
+	assert.Equal(t, DefaultPINMaxAttempts-1, verification.AttemptsRemaining)
+	send(c.CardPINVerifyPost, "POST", "0001230001", "pin/verify", `{"pin":"0000"}`)
+	assert.Equal(t, http.StatusLocked, send(c.CardPINVerifyPost, "POST", "0001230001", "pin/verify", `{"pin":"0000"}`).Code)
+	assert.Equal(t, http.StatusLocked, send(c.CardPINVerifyPost, "POST", "0001230001", "pin/verify", `{"pin":"1234"}`).Code)
+	assert.Equal(t, http.StatusUnauthorized, sendWithToken("", c.CardPINPut, "PUT", "0001230001", "pin", `{"pin":"5678"}`).Code, "a locked PIN should only be reset with the admin scope")
+	assert.Equal(t, http.StatusLocked, send(c.CardPINVerifyPost, "POST", "0001230001", "pin/verify", `{"pin":"1234"}`).Code)
+
+	cards, err := GetCardsData()
+	require.NoError(t, err)
+	stored := cards.GetCardByCardID("0001230001")
+	assert.NotZero(t, stored.PINLockedAt, "the lock should be stored")
+	assert.True(t, strings.HasPrefix(stored.PINHash, pinHashScheme))
+
+	w = send(c.CardGet, "GET", "0001230001", "", "")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.NotContains(t, w.Body.String(), "pinHash")
+	assert.Equal(t, http.StatusUnauthorized, send(c.CardPINVerifyPost, "POST", "0001230004", "pin/verify", `{"pin":"1234"}`).Code, "a card that is not valid cannot verify a PIN")
+}
+
+func TestAuthenticationGetPINRequired(t *testing.T) {
+	tests := []struct {
+		Name           string
+		PINRequired    bool
+		Change         func(card *Card)
+		StatusCode     int
+		ExpectedStatus string
+	}{
+		{"PIN not required", false, func(card *Card) {}, http.StatusOK, ""},
+		{"PIN set", true, func(card *Card) { card.PINHash, _ = hashPIN("1234") }, http.StatusOK, ""},
+		{"PIN not set", true, func(card *Card) {}, http.StatusForbidden, CardStatusPINNotSet},
+		{"PIN locked", true, func(card *Card) { card.PINHash, card.PINLockedAt = "hash", 1560815799 }, http.StatusForbidden, CardStatusPINLocked},
+	}
+	defer writeJSONFiles(setupPeople(), setupAccounts(), setupCards())
+	for _, test := range tests {
+		currentTest := test
+		t.Run(currentTest.Name, func(t *testing.T) {
+			mockAppService := &mocks.ApplicationService{}
+			mockAppService.On("LoggingClient").Return(logger.NewMockClient())
+			c := NewController(mockAppService)
+			if currentTest.PINRequired {
+				c.pinRequiredRoles = map[int]bool{1: true}
+			}
+			cards := setupCards()
+			currentTest.Change(&cards.Cards[0])
+			require.NoError(t, writeJSONFiles(setupPeople(), setupAccounts(), cards))
+
+			req := httptest.NewRequest("GET", "/authentication/"+cards.Cards[0].CardID, nil)
+			req = mux.SetURLVars(req, map[string]string{"cardid": cards.Cards[0].CardID})
+			w := httptest.NewRecorder()
+			c.AuthenticationGet(w, req)
+
+			require.Equal(t, currentTest.StatusCode, w.Code)
+			if w.Code == http.StatusOK {
+				var authData AuthData
+				require.NoError(t, json.NewDecoder(w.Body).Decode(&authData))
+				assert.Equal(t, currentTest.PINRequired, authData.PINRequired)
+				return
+			}
+			var denial CardDenial
+			require.NoError(t, json.NewDecoder(w.Body).Decode(&denial))
+			assert.Equal(t, currentTest.ExpectedStatus, denial.Status)
+		})
+	}
+}
+
+func TestParseRoleIDs(t *testing.T) {
+	roleIDs, err := parseRoleIDs(" 1, 3,")
+	require.NoError(t, err)
+	assert.Equal(t, map[int]bool{1: true, 3: true}, roleIDs)
+	roleIDs, err = parseRoleIDs("")
+	require.NoError(t, err)
+	assert.Empty(t, roleIDs)
+	_, err = parseRoleIDs("1,maintainer")
+	assert.Error(t, err)
+}
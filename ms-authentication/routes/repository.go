@@ -0,0 +1,128 @@
+// Copyright © 2026 Intel Corporation. All rights reserved.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package routes
+
+import (
+	"fmt"
+)
+
+// Storage types of the StorageType application setting
+const (
+	StorageTypeFile = "file"
+	StorageTypeBolt = "bolt"
+)
+
+// DatabaseFileName is the name of the embedded database of the bolt storage
+// type, unless the DatabaseFileName application setting names another file
+const DatabaseFileName = "authentication.db"
+
+// Repository stores the cards, people, accounts and roles. The accounts are
+// stored as they are given, so they must be sealed before they are written.
+// Every list that is returned is a copy that the caller may change
+type Repository interface {
+	// GetCards returns every card
+	GetCards() (Cards, error)
+	// GetPeople returns every person
+	GetPeople() (People, error)
+	// GetAccounts returns every account
+	GetAccounts() (Accounts, error)
+	// GetCard returns the card with the card ID, and false if there is none
+	GetCard(cardID string) (Card, bool, error)
+	// GetPerson returns the person with the person ID, and false if there is
+	// none
+	GetPerson(personID int) (Person, bool, error)
+	// GetAccount returns the account with the account ID, and false if there
+	// is none
+	GetAccount(accountID int) (Account, bool, error)
+	// GetRoles returns every role
+	GetRoles() (Roles, error)
+	// GetRole returns the role with the role ID, and false if there is none
+	GetRole(roleID int) (Role, bool, error)
+	// WriteCards replaces every card with the cards
+	WriteCards(cards Cards) error
+	// WritePeople replaces every person with the people
+	WritePeople(people People) error
+	// WriteAccounts replaces every account with the accounts
+	WriteAccounts(accounts Accounts) error
+	// WriteRoles replaces every role with the roles
+	WriteRoles(roles Roles) error
+	// Close releases the storage
+	Close() error
+}
+
+// StorageSettings selects where the cards, people, accounts and roles are
+// stored. The JSON files are the storage of the file storage type, and are
+// imported into an empty embedded database of the bolt storage type
+type StorageSettings struct {
+	StorageType      string
+	CardsFileName    string
+	PeopleFileName   string
+	AccountsFileName string
+	RolesFileName    string
+	DatabaseFileName string
+}
+
+// NewRepository returns the repository of the storage type of the settings
+func NewRepository(settings StorageSettings) (Repository, error) {
+	switch settings.StorageType {
+	case StorageTypeFile, "":
+		return newFileRepository(settings.CardsFileName, settings.PeopleFileName, settings.AccountsFileName, settings.RolesFileName), nil
+	case StorageTypeBolt:
+		repo, err := newBoltRepository(settings.DatabaseFileName)
+		if err != nil {
+			return nil, err
+		}
+		if err := repo.importFiles(settings.CardsFileName, settings.PeopleFileName, settings.AccountsFileName, settings.RolesFileName); err != nil {
+			repo.Close()
+			return nil, err
+		}
+		return repo, nil
+	}
+	return nil, fmt.Errorf("storage type %q is not one of %s or %s", settings.StorageType, StorageTypeFile, StorageTypeBolt)
+}
+
+// indexCards returns the index of every card by its card ID. Like
+// GetCardByCardID, the first of cards with the same card ID is found
+func indexCards(cards Cards) map[string]int {
+	index := make(map[string]int, len(cards.Cards))
+	for i, card := range cards.Cards {
+		if _, ok := index[card.CardID]; !ok {
+			index[card.CardID] = i
+		}
+	}
+	return index
+}
+
+// indexPeople returns the index of every person by its person ID
+func indexPeople(people People) map[int]int {
+	index := make(map[int]int, len(people.People))
+	for i, person := range people.People {
+		if _, ok := index[person.PersonID]; !ok {
+			index[person.PersonID] = i
+		}
+	}
+	return index
+}
+
+// indexRoles returns the index of every role by its role ID
+func indexRoles(roles Roles) map[int]int {
+	index := make(map[int]int, len(roles.Roles))
+	for i, role := range roles.Roles {
+		if _, ok := index[role.RoleID]; !ok {
+			index[role.RoleID] = i
+		}
+	}
+	return index
+}
+
+// indexAccounts returns the index of every account by its account ID
+func indexAccounts(accounts Accounts) map[int]int {
+	index := make(map[int]int, len(accounts.Accounts))
+	for i, account := range accounts.Accounts {
+		if _, ok := index[account.AccountID]; !ok {
+			index[account.AccountID] = i
+		}
+	}
+	return index
+}
@@ -0,0 +1,213 @@
+// Copyright © 2026 Intel Corporation. All rights reserved.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package routes
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/edgexfoundry/app-functions-sdk-go/v3/pkg/interfaces/mocks"
+	"github.com/edgexfoundry/go-mod-core-contracts/v3/clients/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// setupStorageSettings returns settings of the storage type with the JSON
+// files written to a temporary directory
+func setupStorageSettings(t *testing.T, storageType string) StorageSettings {
+	dir := t.TempDir()
+	settings := StorageSettings{
+		StorageType:      storageType,
+		CardsFileName:    filepath.Join(dir, "cards.json"),
+		PeopleFileName:   filepath.Join(dir, "people.json"),
+		AccountsFileName: filepath.Join(dir, "accounts.json"),
+		RolesFileName:    filepath.Join(dir, "roles.json"),
+		DatabaseFileName: filepath.Join(dir, "authentication.db"),
+	}
+	cards, people, accounts := setupCards(), setupPeople(), setupAccounts()
+	require.NoError(t, writeDataFile(settings.CardsFileName, "cards", cards, 0644))
+	require.NoError(t, writeDataFile(settings.PeopleFileName, "people", people, 0644))
+	require.NoError(t, writeDataFile(settings.AccountsFileName, "accounts", accounts, 0600))
+	return settings
+}
+
+func TestRepositories(t *testing.T) {
+	for _, storageType := range []string{StorageTypeFile, StorageTypeBolt} {
+		currentType := storageType
+		t.Run(currentType, func(t *testing.T) {
+			repo, err := NewRepository(setupStorageSettings(t, currentType))
+			require.NoError(t, err)
+			defer repo.Close()
+
+			cards, err := repo.GetCards()
+			require.NoError(t, err)
+			assert.ElementsMatch(t, setupCards().Cards, cards.Cards)
+			people, err := repo.GetPeople()
+			require.NoError(t, err)
+			assert.ElementsMatch(t, setupPeople().People, people.People)
+			accounts, err := repo.GetAccounts()
+			require.NoError(t, err)
+			assert.ElementsMatch(t, setupAccounts().Accounts, accounts.Accounts)
+
+			card, found, err := repo.GetCard("0001230003")
+			require.NoError(t, err)
+			require.True(t, found)
+			expectedCards := setupCards()
+			assert.Equal(t, expectedCards.GetCardByCardID("0001230003"), card)
+			_, found, err = repo.GetCard("0001230100")
+			require.NoError(t, err)
+			assert.False(t, found)
+			person, found, err := repo.GetPerson(3)
+			require.NoError(t, err)
+			require.True(t, found)
+			assert.Equal(t, 3, person.PersonID)
+			account, found, err := repo.GetAccount(2)
+			require.NoError(t, err)
+			require.True(t, found)
+			assert.Equal(t, 2, account.AccountID)
+			_, found, err = repo.GetAccount(10)
+			require.NoError(t, err)
+			assert.False(t, found)
+
+			cards.Cards[0].RoleID = 3
+			_, err = cards.AddCard(Card{CardID: "0001230100", RoleID: 1, IsValid: true, PersonID: 1}, setupPeople(), 1700000000)
+			require.NoError(t, err)
+			card, _, err = repo.GetCard(cards.Cards[0].CardID)
+			require.NoError(t, err)
+			assert.NotEqual(t, 3, card.RoleID, "a change to the returned cards should not change the repository before it is written")
+
+			require.NoError(t, repo.WriteCards(cards))
+			card, found, err = repo.GetCard("0001230100")
+			require.NoError(t, err)
+			assert.True(t, found)
+			card, _, err = repo.GetCard(cards.Cards[0].CardID)
+			require.NoError(t, err)
+			assert.Equal(t, 3, card.RoleID)
+
+			people.People = people.People[1:]
+			require.NoError(t, repo.WritePeople(people))
+			_, found, err = repo.GetPerson(1)
+			require.NoError(t, err)
+			assert.False(t, found, "a person that is not written should be removed")
+
+			accounts.Accounts[1].IsActive = false
+			require.NoError(t, repo.WriteAccounts(accounts))
+			account, _, err = repo.GetAccount(2)
+			require.NoError(t, err)
+			assert.False(t, account.IsActive)
+
+			roles, err := repo.GetRoles()
+			require.NoError(t, err)
+			assert.ElementsMatch(t, defaultRoles().Roles, roles.Roles, "there should be the default roles until roles are written")
+			_, err = roles.AddRole(Role{RoleID: 4, Name: "auditor"}, 1700000000)
+			require.NoError(t, err)
+			require.NoError(t, repo.WriteRoles(roles))
+			role, found, err := repo.GetRole(4)
+			require.NoError(t, err)
+			require.True(t, found)
+			assert.Equal(t, "auditor", role.Name)
+			_, found, err = repo.GetRole(5)
+			require.NoError(t, err)
+			assert.False(t, found)
+		})
+	}
+}
+
+func TestFileRepositoryReloadsChangedFiles(t *testing.T) {
+	settings := setupStorageSettings(t, StorageTypeFile)
+	repo, err := NewRepository(settings)
+	require.NoError(t, err)
+
+	_, found, err := repo.GetCard("0001230100")
+	require.NoError(t, err)
+	require.False(t, found)
+
+	cards := setupCards()
+	cards.Cards = append(cards.Cards, Card{CardID: "0001230100", RoleID: 1, IsValid: true, PersonID: 1})
+	require.NoError(t, writeDataFile(settings.CardsFileName, "cards", cards, 0644))
+	// make sure the change is seen even on file systems with coarse times
+	later := time.Now().Add(time.Minute)
+	require.NoError(t, os.Chtimes(settings.CardsFileName, later, later))
+
+	_, found, err = repo.GetCard("0001230100")
+	require.NoError(t, err)
+	assert.True(t, found, "a card added to the file should be found")
+
+	require.NoError(t, os.Remove(settings.CardsFileName))
+	_, err = repo.GetCards()
+	assert.Error(t, err)
+}
+
+func TestFileRepositoryAccountsPermissions(t *testing.T) {
+	settings := setupStorageSettings(t, StorageTypeFile)
+	require.NoError(t, os.Chmod(settings.AccountsFileName, 0644))
+	repo, err := NewRepository(settings)
+	require.NoError(t, err)
+
+	require.NoError(t, repo.WriteAccounts(setupAccounts()))
+	info, err := os.Stat(settings.AccountsFileName)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+}
+
+func TestBoltRepositoryImportsFilesOnce(t *testing.T) {
+	settings := setupStorageSettings(t, StorageTypeBolt)
+	repo, err := NewRepository(settings)
+	require.NoError(t, err)
+	require.NoError(t, repo.WriteCards(Cards{Cards: []Card{setupCards().Cards[0]}}))
+	require.NoError(t, repo.Close())
+
+	repo, err = NewRepository(settings)
+	require.NoError(t, err)
+	defer repo.Close()
+	cards, err := repo.GetCards()
+	require.NoError(t, err)
+	assert.Len(t, cards.Cards, 1, "the files should not be imported into a database that has data")
+}
+
+func TestNewRepositoryUnknownStorageType(t *testing.T) {
+	_, err := NewRepository(StorageSettings{StorageType: "sql"})
+	assert.Error(t, err)
+}
+
+func TestLoadSettings(t *testing.T) {
+	settings := setupStorageSettings(t, StorageTypeBolt)
+	values := map[string]string{
+		"StorageType":      settings.StorageType,
+		"CardsFileName":    settings.CardsFileName,
+		"PeopleFileName":   settings.PeopleFileName,
+		"AccountsFileName": settings.AccountsFileName,
+		"DatabaseFileName": settings.DatabaseFileName,
+		"PINRequiredRoles": "3",
+		"PINMaxAttempts":   "5",
+	}
+	mockAppService := &mocks.ApplicationService{}
+	mockAppService.On("LoggingClient").Return(logger.NewMockClient())
+	mockAppService.On("GetAppSetting", mock.Anything).Return(func(name string) (string, error) {
+		return values[name], nil
+	})
+	c := NewController(mockAppService)
+
+	require.NoError(t, c.LoadSettings())
+	defer c.Close()
+	_, ok := c.repo.(*boltRepository)
+	require.True(t, ok)
+	card, found, err := c.repo.GetCard("0001230001")
+	require.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, setupCards().Cards[0], card)
+	_, err = os.Stat(settings.DatabaseFileName)
+	assert.NoError(t, err)
+	assert.Equal(t, map[int]bool{3: true}, c.pinRequiredRoles)
+	assert.Equal(t, 5, c.pinMaxAttempts)
+
+	values["PINMaxAttempts"] = "0"
+	assert.Error(t, c.LoadSettings())
+	values["PINMaxAttempts"] = "5"
+	values["StorageType"] = "sql"
+	assert.Error(t, c.LoadSettings())
+}
@@ -0,0 +1,139 @@
+// Copyright © 2026 Intel Corporation. All rights reserved.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package routes
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Permissions of roles, which are returned with the authentication of a
+// card so that other services act on them instead of on role IDs
+const (
+	PermissionUnlockLock1      = "unlock:lock1"
+	PermissionUnlockLock2      = "unlock:lock2"
+	PermissionBillable         = "billable"
+	PermissionRestock          = "restock"
+	PermissionMaintenanceClear = "maintenance:clear"
+	PermissionAdminInventory   = "admin:inventory"
+)
+
+// Permissions are every permission a role can have
+var Permissions = []string{
+	PermissionUnlockLock1,
+	PermissionUnlockLock2,
+	PermissionBillable,
+	PermissionRestock,
+	PermissionMaintenanceClear,
+	PermissionAdminInventory,
+}
+
+// RolesFileName is the name of the JSON file of the roles, unless the
+// RolesFileName application setting names another file. Until the file
+// exists, the roles are the default roles
+const RolesFileName = "roles.json"
+
+// defaultRoles returns the roles of the role IDs that cards had before roles
+// were stored: customers are charged for what they take, stockers restock
+// and maintainers end maintenance mode
+func defaultRoles() Roles {
+	return Roles{Roles: []Role{
+		{RoleID: 1, Name: "consumer", Permissions: []string{PermissionUnlockLock1, PermissionBillable}},
+		{RoleID: 2, Name: "stocker", Permissions: []string{PermissionUnlockLock1, PermissionRestock}},
+		{RoleID: 3, Name: "maintainer", Permissions: []string{PermissionUnlockLock1, PermissionMaintenanceClear, PermissionAdminInventory}},
+	}}
+}
+
+// roleIndex returns the index of the role with the role ID, or -1
+func (roles *Roles) roleIndex(roleID int) int {
+	for i, role := range roles.Roles {
+		if role.RoleID == roleID {
+			return i
+		}
+	}
+	return -1
+}
+
+// validateRole checks that the role has a positive role ID and a name, and
+// returns its permissions in lower case without duplicates. Permissions
+// that are not one of Permissions are refused
+func validateRole(role Role) ([]string, error) {
+	if role.RoleID <= 0 {
+		return nil, fmt.Errorf("%w: role ID %d must be greater than 0", errInvalidRecord, role.RoleID)
+	}
+	if strings.TrimSpace(role.Name) == "" {
+		return nil, fmt.Errorf("%w: role %d must have a name", errInvalidRecord, role.RoleID)
+	}
+	permissions := []string{}
+	for _, permission := range role.Permissions {
+		permission = strings.ToLower(strings.TrimSpace(permission))
+		if !containsString(Permissions, permission) {
+			return nil, fmt.Errorf("%w: permission %q of role %d is not one of %s", errInvalidRecord, permission, role.RoleID, strings.Join(Permissions, ", "))
+		}
+		if !containsString(permissions, permission) {
+			permissions = append(permissions, permission)
+		}
+	}
+	return permissions, nil
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// AddRole adds a new role, created at now
+func (roles *Roles) AddRole(role Role, now int64) (Role, error) {
+	permissions, err := validateRole(role)
+	if err != nil {
+		return Role{}, err
+	}
+	if roles.roleIndex(role.RoleID) >= 0 {
+		return Role{}, fmt.Errorf("%w: role %d", errRecordConflict, role.RoleID)
+	}
+	role.Permissions = permissions
+	role.CreatedAt = now
+	role.UpdatedAt = now
+	roles.Roles = append(roles.Roles, role)
+	return role, nil
+}
+
+// UpdateRole replaces the role with the role ID of the update, keeping when
+// it was created
+func (roles *Roles) UpdateRole(update Role, now int64) (Role, error) {
+	i := roles.roleIndex(update.RoleID)
+	if i < 0 {
+		return Role{}, fmt.Errorf("%w: role %d", errRecordNotFound, update.RoleID)
+	}
+	permissions, err := validateRole(update)
+	if err != nil {
+		return Role{}, err
+	}
+	update.Permissions = permissions
+	update.CreatedAt = roles.Roles[i].CreatedAt
+	update.UpdatedAt = now
+	roles.Roles[i] = update
+	return update, nil
+}
+
+// DeleteRole removes the role with the role ID. A role of any of the cards
+// cannot be removed, since those cards would no longer authenticate
+func (roles *Roles) DeleteRole(roleID int, cards Cards) (Role, error) {
+	i := roles.roleIndex(roleID)
+	if i < 0 {
+		return Role{}, fmt.Errorf("%w: role %d", errRecordNotFound, roleID)
+	}
+	for _, card := range cards.Cards {
+		if card.RoleID == roleID {
+			return Role{}, fmt.Errorf("%w: role %d is the role of card %s", errRecordConflict, roleID, card.CardID)
+		}
+	}
+	role := roles.Roles[i]
+	roles.Roles = append(roles.Roles[:i], roles.Roles[i+1:]...)
+	return role, nil
+}
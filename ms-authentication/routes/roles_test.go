@@ -0,0 +1,144 @@
+// Copyright © 2026 Intel Corporation. All rights reserved.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package routes
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/edgexfoundry/app-functions-sdk-go/v3/pkg/interfaces/mocks"
+	"github.com/edgexfoundry/go-mod-core-contracts/v3/clients/logger"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAddRole(t *testing.T) {
+	tests := []struct {
+		Name          string
+		Role          Role
+		ExpectedError error
+	}{
+		{"New role", Role{RoleID: 4, Name: "auditor", Permissions: []string{" Unlock:Lock2", "unlock:lock2"}}, nil},
+		{"Role without permissions", Role{RoleID: 4, Name: "visitor"}, nil},
+		{"Existing role", Role{RoleID: 1, Name: "consumer"}, errRecordConflict},
+		{"Invalid role ID", Role{RoleID: 0, Name: "auditor"}, errInvalidRecord},
+		{"Missing name", Role{RoleID: 4, Name: " "}, errInvalidRecord},
+		{"Unknown permission", Role{RoleID: 4, Name: "auditor", Permissions: []string{"unlock:lock3"}}, errInvalidRecord},
+	}
+	for _, test := range tests {
+		currentTest := test
+		t.Run(currentTest.Name, func(t *testing.T) {
+			roles := defaultRoles()
+			role, err := roles.AddRole(currentTest.Role, 1700000000)
+			if currentTest.ExpectedError != nil {
+				assert.ErrorIs(t, err, currentTest.ExpectedError)
+				assert.Len(t, roles.Roles, 3)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, int64(1700000000), role.CreatedAt)
+			assert.NotNil(t, role.Permissions)
+			assert.Equal(t, role, roles.Roles[3])
+		})
+	}
+	roles := defaultRoles()
+	role, err := roles.AddRole(Role{RoleID: 4, Name: "auditor", Permissions: []string{" Unlock:Lock2", "unlock:lock2"}}, 1700000000)
+	require.NoError(t, err)
+	assert.Equal(t, []string{PermissionUnlockLock2}, role.Permissions, "permissions should be normalized without duplicates")
+}
+
+func TestUpdateAndDeleteRole(t *testing.T) {
+	roles := defaultRoles()
+	roles.Roles[0].CreatedAt = 1560815799
+	role, err := roles.UpdateRole(Role{RoleID: 1, Name: "customer", Permissions: []string{PermissionUnlockLock1}, CreatedAt: 1}, 1700000000)
+	require.NoError(t, err)
+	assert.Equal(t, Role{RoleID: 1, Name: "customer", Permissions: []string{PermissionUnlockLock1}, CreatedAt: 1560815799, UpdatedAt: 1700000000}, role)
+	_, err = roles.UpdateRole(Role{RoleID: 10, Name: "unknown"}, 1700000000)
+	assert.ErrorIs(t, err, errRecordNotFound)
+	_, err = roles.UpdateRole(Role{RoleID: 1, Name: "customer", Permissions: []string{"everything"}}, 1700000000)
+	assert.ErrorIs(t, err, errInvalidRecord)
+
+	_, err = roles.DeleteRole(2, setupCards())
+	assert.ErrorIs(t, err, errRecordConflict, "the role of a card should not be removed")
+	_, err = roles.DeleteRole(10, setupCards())
+	assert.ErrorIs(t, err, errRecordNotFound)
+	role, err = roles.DeleteRole(2, Cards{})
+	require.NoError(t, err)
+	assert.Equal(t, "stocker", role.Name)
+	assert.Equal(t, -1, roles.roleIndex(2))
+}
+
+func TestRoleHandlers(t *testing.T) {
+	defer writeJSONFiles(setupPeople(), setupAccounts(), setupCards())
+	defer os.Remove(RolesFileName)
+	mockAppService := &mocks.ApplicationService{}
+	mockAppService.On("LoggingClient").Return(logger.NewMockClient())
+	c := NewController(mockAppService)
+	c.pii = setupPIIProtector(t)
+	require.NoError(t, writeJSONFiles(setupPeople(), setupAccounts(), setupCards()))
+
+	send := func(handler http.HandlerFunc, method string, roleID string, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, "/roles/"+roleID, bytes.NewBufferString(body))
+		req.Header.Set(AdminTokenHeader, testAdminToken)
+		req = mux.SetURLVars(req, map[string]string{"roleid": roleID})
+		w := httptest.NewRecorder()
+		handler(w, req)
+		return w
+	}
+	authenticate := func(cardID string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("GET", "/authentication/"+cardID, nil)
+		req = mux.SetURLVars(req, map[string]string{"cardid": cardID})
+		w := httptest.NewRecorder()
+		c.AuthenticationGet(w, req)
+		return w
+	}
+
+	w := send(c.RolesGet, "GET", "", "")
+	require.Equal(t, http.StatusOK, w.Code)
+	var roles []Role
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&roles))
+	assert.Equal(t, defaultRoles().Roles, roles)
+	assert.Equal(t, http.StatusNotFound, send(c.RoleGet, "GET", "4", "").Code)
+	assert.Equal(t, http.StatusBadRequest, send(c.RoleGet, "GET", "auditor", "").Code)
+
+	assert.Equal(t, http.StatusBadRequest, send(c.RolePost, "POST", "", `{"roleID":4,"name":"auditor","permissions":["root"]}`).Code)
+	assert.Equal(t, http.StatusConflict, send(c.RolePost, "POST", "", `{"roleID":1,"name":"consumer"}`).Code)
+	require.Equal(t, http.StatusOK, send(c.RolePost, "POST", "", `{"roleID":4,"name":"auditor","permissions":["unlock:lock2"]}`).Code)
+	w = send(c.RoleGet, "GET", "4", "")
+	require.Equal(t, http.StatusOK, w.Code)
+	var role Role
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&role))
+	assert.Equal(t, []string{PermissionUnlockLock2}, role.Permissions)
+
+	assert.Equal(t, http.StatusBadRequest, send(c.RolePut, "PUT", "1", `{"roleID":2,"name":"consumer"}`).Code)
+	assert.Equal(t, http.StatusNotFound, send(c.RolePut, "PUT", "10", `{"name":"unknown"}`).Code)
+	require.Equal(t, http.StatusOK, send(c.RolePut, "PUT", "1", `{"name":"guest","permissions":["unlock:lock1"]}`).Code)
+	w = authenticate("0001230001")
+	require.Equal(t, http.StatusOK, w.Code)
+	var authData AuthData
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&authData))
+	assert.Equal(t, "guest", authData.RoleName)
+	assert.Equal(t, []string{PermissionUnlockLock1}, authData.Permissions, "the changed permissions should be returned")
+
+	require.Equal(t, http.StatusOK, send(c.RolePut, "PUT", "1", `{"name":"guest"}`).Code)
+	w = authenticate("0001230001")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"permissions":[]`, "a role without permissions should return empty permissions")
+
+	assert.Equal(t, http.StatusConflict, send(c.RoleDelete, "DELETE", "1", "").Code)
+	require.Equal(t, http.StatusOK, send(c.RoleDelete, "DELETE", "4", "").Code)
+	assert.Equal(t, http.StatusNotFound, send(c.RoleGet, "GET", "4", "").Code)
+
+	cards := setupCards()
+	cards.Cards[0].RoleID = 4
+	require.NoError(t, writeJSONFiles(setupPeople(), setupAccounts(), cards))
+	w = authenticate("0001230001")
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	assert.Contains(t, w.Body.String(), "unknown role")
+}
@@ -0,0 +1,812 @@
+// Copyright © 2026 Intel Corporation. All rights reserved.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package routes
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+// readRecord unmarshals the body of the request into the record. An empty
+// body leaves the record as it is
+func readRecord(req *http.Request, record interface{}) error {
+	body, err := io.ReadAll(req.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read request body: %s", err.Error())
+	}
+	if len(body) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(body, record); err != nil {
+		return fmt.Errorf("failed to unmarshal request body: %s", err.Error())
+	}
+	return nil
+}
+
+// writeChangeError writes the error of a change to the cards, people or
+// accounts with its status code
+func (c *Controller) writeChangeError(writer http.ResponseWriter, err error) {
+	c.lc.Errorf("Failed to change authentication data: %s", err.Error())
+	switch {
+	case errors.Is(err, errRecordNotFound):
+		writer.WriteHeader(http.StatusNotFound)
+	case errors.Is(err, errRecordConflict):
+		writer.WriteHeader(http.StatusConflict)
+	default:
+		writer.WriteHeader(http.StatusBadRequest)
+	}
+	writer.Write([]byte(err.Error()))
+}
+
+// writeRecord writes the card, person or account as JSON
+func (c *Controller) writeRecord(writer http.ResponseWriter, record interface{}) {
+	recordJSON, err := json.Marshal(record)
+	if err != nil {
+		c.lc.Errorf("Failed to marshal authentication data: %s", err.Error())
+		writer.WriteHeader(http.StatusInternalServerError)
+		writer.Write([]byte("failed to marshal authentication data"))
+		return
+	}
+	writer.Write(recordJSON)
+}
+
+// writeDataError writes an error reading or writing the JSON files
+func (c *Controller) writeDataError(writer http.ResponseWriter, err error) {
+	c.lc.Errorf("Failed to access authentication data: %s", err.Error())
+	writer.WriteHeader(http.StatusInternalServerError)
+	writer.Write([]byte("failed to access authentication data"))
+}
+
+// CardPost creates a new card of an existing person
+func (c *Controller) CardPost(writer http.ResponseWriter, req *http.Request) {
+	if !c.requireAdminScope(writer, req) {
+		return
+	}
+	var card Card
+	if err := readRecord(req, &card); err != nil {
+		c.writeChangeError(writer, err)
+		return
+	}
+	cardID, err := c.normalizeCardID(card.CardID)
+	if err != nil {
+		c.writeChangeError(writer, err)
+		return
+	}
+	card.CardID = cardID
+
+	c.dataMutex.Lock()
+	defer c.dataMutex.Unlock()
+
+	people, err := c.repo.GetPeople()
+	if err != nil {
+		c.writeDataError(writer, err)
+		return
+	}
+	cards, err := c.repo.GetCards()
+	if err != nil {
+		c.writeDataError(writer, err)
+		return
+	}
+	card, err = cards.AddCard(card, people, time.Now().Unix())
+	if err != nil {
+		c.writeChangeError(writer, err)
+		return
+	}
+	if err := c.repo.WriteCards(cards); err != nil {
+		c.writeDataError(writer, err)
+		return
+	}
+	c.lc.Infof("Created card %s of person %d", card.CardID, card.PersonID)
+	c.writeRecord(writer, cardsView(card)[0])
+}
+
+// CardPut replaces the card by its card ID URL parameter in the form:
+// /cards/0001230001
+func (c *Controller) CardPut(writer http.ResponseWriter, req *http.Request) {
+	if !c.requireAdminScope(writer, req) {
+		return
+	}
+	var card Card
+	if err := readRecord(req, &card); err != nil {
+		c.writeChangeError(writer, err)
+		return
+	}
+	cardID, err := c.normalizeCardID(mux.Vars(req)["cardid"])
+	if err != nil {
+		c.writeChangeError(writer, err)
+		return
+	}
+	if card.CardID != "" {
+		if card.CardID, err = c.normalizeCardID(card.CardID); err != nil {
+			c.writeChangeError(writer, err)
+			return
+		}
+	}
+	if card.CardID != "" && card.CardID != cardID {
+		c.writeChangeError(writer, fmt.Errorf("%w: card ID %s of the body does not match %s", errInvalidRecord, card.CardID, cardID))
+		return
+	}
+	card.CardID = cardID
+
+	c.dataMutex.Lock()
+	defer c.dataMutex.Unlock()
+
+	people, err := c.repo.GetPeople()
+	if err != nil {
+		c.writeDataError(writer, err)
+		return
+	}
+	cards, err := c.repo.GetCards()
+	if err != nil {
+		c.writeDataError(writer, err)
+		return
+	}
+	card, err = cards.UpdateCard(card, people, time.Now().Unix())
+	if err != nil {
+		c.writeChangeError(writer, err)
+		return
+	}
+	if err := c.repo.WriteCards(cards); err != nil {
+		c.writeDataError(writer, err)
+		return
+	}
+	c.lc.Infof("Updated card %s", card.CardID)
+	c.writeRecord(writer, cardsView(card)[0])
+}
+
+// CardDelete deactivates the card by its card ID URL parameter in the form:
+// /cards/0001230001
+func (c *Controller) CardDelete(writer http.ResponseWriter, req *http.Request) {
+	if !c.requireAdminScope(writer, req) {
+		return
+	}
+	cardID, err := c.normalizeCardID(mux.Vars(req)["cardid"])
+	if err != nil {
+		c.writeChangeError(writer, err)
+		return
+	}
+
+	c.dataMutex.Lock()
+	defer c.dataMutex.Unlock()
+
+	cards, err := c.repo.GetCards()
+	if err != nil {
+		c.writeDataError(writer, err)
+		return
+	}
+	card, err := cards.DeactivateCard(cardID, time.Now().Unix())
+	if err != nil {
+		c.writeChangeError(writer, err)
+		return
+	}
+	if err := c.repo.WriteCards(cards); err != nil {
+		c.writeDataError(writer, err)
+		return
+	}
+	c.lc.Infof("Deactivated card %s", card.CardID)
+	c.writeRecord(writer, cardsView(card)[0])
+}
+
+// CardSuspensionPost suspends the card by its card ID URL parameter in the
+// form: /cards/0001230001/suspension
+func (c *Controller) CardSuspensionPost(writer http.ResponseWriter, req *http.Request) {
+	if !c.requireAdminScope(writer, req) {
+		return
+	}
+	var suspension suspensionRequest
+	if err := readRecord(req, &suspension); err != nil {
+		c.writeChangeError(writer, err)
+		return
+	}
+	cardID, err := c.normalizeCardID(mux.Vars(req)["cardid"])
+	if err != nil {
+		c.writeChangeError(writer, err)
+		return
+	}
+
+	c.dataMutex.Lock()
+	defer c.dataMutex.Unlock()
+
+	cards, err := c.repo.GetCards()
+	if err != nil {
+		c.writeDataError(writer, err)
+		return
+	}
+	card, err := cards.SuspendCard(cardID, suspension.Reason, time.Now().Unix())
+	if err != nil {
+		c.writeChangeError(writer, err)
+		return
+	}
+	if err := c.repo.WriteCards(cards); err != nil {
+		c.writeDataError(writer, err)
+		return
+	}
+	c.lc.Infof("Suspended card %s: %s", card.CardID, card.SuspendedReason)
+	c.writeRecord(writer, cardsView(card)[0])
+}
+
+// CardSuspensionDelete lifts the suspension of the card by its card ID URL
+// parameter in the form: /cards/0001230001/suspension
+func (c *Controller) CardSuspensionDelete(writer http.ResponseWriter, req *http.Request) {
+	if !c.requireAdminScope(writer, req) {
+		return
+	}
+	cardID, err := c.normalizeCardID(mux.Vars(req)["cardid"])
+	if err != nil {
+		c.writeChangeError(writer, err)
+		return
+	}
+
+	c.dataMutex.Lock()
+	defer c.dataMutex.Unlock()
+
+	cards, err := c.repo.GetCards()
+	if err != nil {
+		c.writeDataError(writer, err)
+		return
+	}
+	card, err := cards.ResumeCard(cardID, time.Now().Unix())
+	if err != nil {
+		c.writeChangeError(writer, err)
+		return
+	}
+	if err := c.repo.WriteCards(cards); err != nil {
+		c.writeDataError(writer, err)
+		return
+	}
+	c.lc.Infof("Resumed card %s", card.CardID)
+	c.writeRecord(writer, cardsView(card)[0])
+}
+
+// CardLostPost revokes the lost card by its card ID URL parameter in the
+// form: /cards/0001230001/lost
+// and issues a replacement card when the body has a replacement card ID
+func (c *Controller) CardLostPost(writer http.ResponseWriter, req *http.Request) {
+	if !c.requireAdminScope(writer, req) {
+		return
+	}
+	var report lostCardRequest
+	if err := readRecord(req, &report); err != nil {
+		c.writeChangeError(writer, err)
+		return
+	}
+	cardID, err := c.normalizeCardID(mux.Vars(req)["cardid"])
+	if err != nil {
+		c.writeChangeError(writer, err)
+		return
+	}
+
+	c.dataMutex.Lock()
+	defer c.dataMutex.Unlock()
+
+	people, err := c.repo.GetPeople()
+	if err != nil {
+		c.writeDataError(writer, err)
+		return
+	}
+	cards, err := c.repo.GetCards()
+	if err != nil {
+		c.writeDataError(writer, err)
+		return
+	}
+	if report.ReplacementCardID != "" {
+		if report.ReplacementCardID, err = c.normalizeCardID(report.ReplacementCardID); err != nil {
+			c.writeChangeError(writer, err)
+			return
+		}
+	}
+	card, replacement, err := cards.ReportLostCard(cardID, report.ReplacementCardID, people, time.Now().Unix())
+	if err != nil {
+		c.writeChangeError(writer, err)
+		return
+	}
+	if err := c.repo.WriteCards(cards); err != nil {
+		c.writeDataError(writer, err)
+		return
+	}
+	if replacement != nil {
+		c.lc.Infof("Revoked lost card %s and replaced it with card %s", card.CardID, replacement.CardID)
+	} else {
+		c.lc.Infof("Revoked lost card %s", card.CardID)
+	}
+	lostReport := LostCardReport{Card: cardsView(card)[0]}
+	if replacement != nil {
+		lostReport.Replacement = &cardsView(*replacement)[0]
+	}
+	c.writeRecord(writer, lostReport)
+}
+
+// CardPINPut sets the PIN of the card by its card ID URL parameter in the
+// form: /cards/0001230001/pin
+// which also unlocks a PIN that was locked by wrong PINs. Only a caller with
+// the admin scope can set or reset a PIN
+func (c *Controller) CardPINPut(writer http.ResponseWriter, req *http.Request) {
+	if !c.requireAdminScope(writer, req) {
+		return
+	}
+	var pin pinRequest
+	if err := readRecord(req, &pin); err != nil {
+		c.writeChangeError(writer, err)
+		return
+	}
+	cardID, err := c.normalizeCardID(mux.Vars(req)["cardid"])
+	if err != nil {
+		c.writeChangeError(writer, err)
+		return
+	}
+
+	c.dataMutex.Lock()
+	defer c.dataMutex.Unlock()
+
+	cards, err := c.repo.GetCards()
+	if err != nil {
+		c.writeDataError(writer, err)
+		return
+	}
+	card, err := cards.SetCardPIN(cardID, pin.PIN, time.Now().Unix())
+	if err != nil {
+		c.writeChangeError(writer, err)
+		return
+	}
+	if err := c.repo.WriteCards(cards); err != nil {
+		c.writeDataError(writer, err)
+		return
+	}
+	c.lc.Infof("Set the PIN of card %s", card.CardID)
+	c.writeRecord(writer, cardsView(card)[0])
+}
+
+// CardPINVerifyPost verifies the PIN of the card by its card ID URL
+// parameter in the form: /cards/0001230001/pin/verify
+// It responds with 200 for the right PIN, 401 with the attempts remaining
+// for a wrong PIN, 423 when the PIN is locked after too many wrong PINs, and
+// 403 when the card cannot authenticate
+func (c *Controller) CardPINVerifyPost(writer http.ResponseWriter, req *http.Request) {
+	var pin pinRequest
+	if err := readRecord(req, &pin); err != nil {
+		c.writeChangeError(writer, err)
+		return
+	}
+	cardID, err := c.normalizeCardID(mux.Vars(req)["cardid"])
+	if err != nil {
+		c.writeChangeError(writer, err)
+		return
+	}
+	now := time.Now().Unix()
+
+	c.dataMutex.Lock()
+	defer c.dataMutex.Unlock()
+
+	cards, err := c.repo.GetCards()
+	if err != nil {
+		c.writeDataError(writer, err)
+		return
+	}
+	i := cards.cardIndex(cardID)
+	if i < 0 {
+		c.writeChangeError(writer, fmt.Errorf("%w: card %s", errRecordNotFound, cardID))
+		return
+	}
+	card := cards.Cards[i]
+	if !card.IsValid && card.RevokedAt == 0 {
+		c.lc.Infof("Card ID: %s is not an valid card", cardID)
+		writer.WriteHeader(http.StatusUnauthorized)
+		writer.Write([]byte("Card ID is not a valid card"))
+		return
+	}
+	if status := card.Status(now); status != CardStatusActive {
+		c.denyCard(writer, card, status)
+		return
+	}
+	verification, err := cards.VerifyCardPIN(cardID, pin.PIN, c.pinMaxAttempts, now)
+	if err != nil {
+		c.writeChangeError(writer, err)
+		return
+	}
+	if verification.Status == CardStatusPINNotSet {
+		c.denyCard(writer, card, verification.Status)
+		return
+	}
+	if err := c.repo.WriteCards(cards); err != nil {
+		c.writeDataError(writer, err)
+		return
+	}
+
+	switch {
+	case verification.Verified:
+		c.lc.Infof("Verified the PIN of card %s", cardID)
+	case verification.Status == CardStatusPINLocked:
+		c.lc.Infof("The PIN of card %s is locked", cardID)
+		writer.WriteHeader(http.StatusLocked)
+	default:
+		c.lc.Infof("Wrong PIN for card %s, %d attempts remaining", cardID, verification.AttemptsRemaining)
+		writer.WriteHeader(http.StatusUnauthorized)
+	}
+	c.writeRecord(writer, verification)
+}
+
+// personIDParameter returns the person ID URL parameter of the request
+func personIDParameter(req *http.Request) (int, error) {
+	personID, err := strconv.Atoi(mux.Vars(req)["personid"])
+	if err != nil {
+		return 0, fmt.Errorf("%w: person ID %q is not a number", errInvalidRecord, mux.Vars(req)["personid"])
+	}
+	return personID, nil
+}
+
+// PersonPost creates a new person of an existing account
+func (c *Controller) PersonPost(writer http.ResponseWriter, req *http.Request) {
+	if !c.requireAdminScope(writer, req) {
+		return
+	}
+	var person Person
+	if err := readRecord(req, &person); err != nil {
+		c.writeChangeError(writer, err)
+		return
+	}
+
+	c.dataMutex.Lock()
+	defer c.dataMutex.Unlock()
+
+	accounts, err := c.repo.GetAccounts()
+	if err != nil {
+		c.writeDataError(writer, err)
+		return
+	}
+	people, err := c.repo.GetPeople()
+	if err != nil {
+		c.writeDataError(writer, err)
+		return
+	}
+	person, err = people.AddPerson(person, accounts, time.Now().Unix())
+	if err != nil {
+		c.writeChangeError(writer, err)
+		return
+	}
+	if err := c.repo.WritePeople(people); err != nil {
+		c.writeDataError(writer, err)
+		return
+	}
+	c.lc.Infof("Created person %d of account %d", person.PersonID, person.AccountID)
+	c.writeRecord(writer, person)
+}
+
+// PersonPut replaces the person by its person ID URL parameter in the form:
+// /people/1
+func (c *Controller) PersonPut(writer http.ResponseWriter, req *http.Request) {
+	if !c.requireAdminScope(writer, req) {
+		return
+	}
+	var person Person
+	if err := readRecord(req, &person); err != nil {
+		c.writeChangeError(writer, err)
+		return
+	}
+	personID, err := personIDParameter(req)
+	if err != nil {
+		c.writeChangeError(writer, err)
+		return
+	}
+	if person.PersonID != 0 && person.PersonID != personID {
+		c.writeChangeError(writer, fmt.Errorf("%w: person ID %d of the body does not match %d", errInvalidRecord, person.PersonID, personID))
+		return
+	}
+	person.PersonID = personID
+
+	c.dataMutex.Lock()
+	defer c.dataMutex.Unlock()
+
+	accounts, err := c.repo.GetAccounts()
+	if err != nil {
+		c.writeDataError(writer, err)
+		return
+	}
+	people, err := c.repo.GetPeople()
+	if err != nil {
+		c.writeDataError(writer, err)
+		return
+	}
+	person, err = people.UpdatePerson(person, accounts, time.Now().Unix())
+	if err != nil {
+		c.writeChangeError(writer, err)
+		return
+	}
+	if err := c.repo.WritePeople(people); err != nil {
+		c.writeDataError(writer, err)
+		return
+	}
+	c.lc.Infof("Updated person %d", person.PersonID)
+	c.writeRecord(writer, person)
+}
+
+// PersonDelete deactivates the person by its person ID URL parameter in the
+// form: /people/1
+func (c *Controller) PersonDelete(writer http.ResponseWriter, req *http.Request) {
+	if !c.requireAdminScope(writer, req) {
+		return
+	}
+	personID, err := personIDParameter(req)
+	if err != nil {
+		c.writeChangeError(writer, err)
+		return
+	}
+
+	c.dataMutex.Lock()
+	defer c.dataMutex.Unlock()
+
+	people, err := c.repo.GetPeople()
+	if err != nil {
+		c.writeDataError(writer, err)
+		return
+	}
+	person, err := people.DeactivatePerson(personID, time.Now().Unix())
+	if err != nil {
+		c.writeChangeError(writer, err)
+		return
+	}
+	if err := c.repo.WritePeople(people); err != nil {
+		c.writeDataError(writer, err)
+		return
+	}
+	c.lc.Infof("Deactivated person %d", person.PersonID)
+	c.writeRecord(writer, person)
+}
+
+// accountIDParameter returns the account ID URL parameter of the request
+func accountIDParameter(req *http.Request) (int, error) {
+	accountID, err := strconv.Atoi(mux.Vars(req)["accountid"])
+	if err != nil {
+		return 0, fmt.Errorf("%w: account ID %q is not a number", errInvalidRecord, mux.Vars(req)["accountid"])
+	}
+	return accountID, nil
+}
+
+// AccountPost creates a new account. Its credit card number is replaced by
+// a token before it is stored
+func (c *Controller) AccountPost(writer http.ResponseWriter, req *http.Request) {
+	if !c.requireAdminScope(writer, req) {
+		return
+	}
+	var account Account
+	if err := readRecord(req, &account); err != nil {
+		c.writeChangeError(writer, err)
+		return
+	}
+
+	c.dataMutex.Lock()
+	defer c.dataMutex.Unlock()
+
+	accounts, err := c.readAccounts()
+	if err != nil {
+		c.writeDataError(writer, err)
+		return
+	}
+	account, err = c.pii.tokenizeAccount(account)
+	if err != nil {
+		c.writeChangeError(writer, err)
+		return
+	}
+	account, err = accounts.AddAccount(account, time.Now().Unix())
+	if err != nil {
+		c.writeChangeError(writer, err)
+		return
+	}
+	if err := c.writeAccounts(accounts); err != nil {
+		c.writeDataError(writer, err)
+		return
+	}
+	c.lc.Infof("Created account %d", account.AccountID)
+	c.writeRecord(writer, c.accountsView(req, account)[0])
+}
+
+// AccountPut replaces the account by its account ID URL parameter in the
+// form: /accounts/1
+func (c *Controller) AccountPut(writer http.ResponseWriter, req *http.Request) {
+	if !c.requireAdminScope(writer, req) {
+		return
+	}
+	var account Account
+	if err := readRecord(req, &account); err != nil {
+		c.writeChangeError(writer, err)
+		return
+	}
+	accountID, err := accountIDParameter(req)
+	if err != nil {
+		c.writeChangeError(writer, err)
+		return
+	}
+	if account.AccountID != 0 && account.AccountID != accountID {
+		c.writeChangeError(writer, fmt.Errorf("%w: account ID %d of the body does not match %d", errInvalidRecord, account.AccountID, accountID))
+		return
+	}
+	account.AccountID = accountID
+
+	c.dataMutex.Lock()
+	defer c.dataMutex.Unlock()
+
+	accounts, err := c.readAccounts()
+	if err != nil {
+		c.writeDataError(writer, err)
+		return
+	}
+	account, err = c.pii.tokenizeAccount(account)
+	if err != nil {
+		c.writeChangeError(writer, err)
+		return
+	}
+	account, err = accounts.UpdateAccount(account, time.Now().Unix())
+	if err != nil {
+		c.writeChangeError(writer, err)
+		return
+	}
+	if err := c.writeAccounts(accounts); err != nil {
+		c.writeDataError(writer, err)
+		return
+	}
+	c.lc.Infof("Updated account %d", account.AccountID)
+	c.writeRecord(writer, c.accountsView(req, account)[0])
+}
+
+// AccountDelete deactivates the account by its account ID URL parameter in
+// the form: /accounts/1
+func (c *Controller) AccountDelete(writer http.ResponseWriter, req *http.Request) {
+	if !c.requireAdminScope(writer, req) {
+		return
+	}
+	accountID, err := accountIDParameter(req)
+	if err != nil {
+		c.writeChangeError(writer, err)
+		return
+	}
+
+	c.dataMutex.Lock()
+	defer c.dataMutex.Unlock()
+
+	accounts, err := c.readAccounts()
+	if err != nil {
+		c.writeDataError(writer, err)
+		return
+	}
+	account, err := accounts.DeactivateAccount(accountID, time.Now().Unix())
+	if err != nil {
+		c.writeChangeError(writer, err)
+		return
+	}
+	if err := c.writeAccounts(accounts); err != nil {
+		c.writeDataError(writer, err)
+		return
+	}
+	c.lc.Infof("Deactivated account %d", account.AccountID)
+	c.writeRecord(writer, c.accountsView(req, account)[0])
+}
+
+// roleIDParameter returns the role ID URL parameter of the request
+func roleIDParameter(req *http.Request) (int, error) {
+	roleID, err := strconv.Atoi(mux.Vars(req)["roleid"])
+	if err != nil {
+		return 0, fmt.Errorf("%w: role ID %q is not a number", errInvalidRecord, mux.Vars(req)["roleid"])
+	}
+	return roleID, nil
+}
+
+// RolePost creates a new role
+func (c *Controller) RolePost(writer http.ResponseWriter, req *http.Request) {
+	if !c.requireAdminScope(writer, req) {
+		return
+	}
+	var role Role
+	if err := readRecord(req, &role); err != nil {
+		c.writeChangeError(writer, err)
+		return
+	}
+
+	c.dataMutex.Lock()
+	defer c.dataMutex.Unlock()
+
+	roles, err := c.repo.GetRoles()
+	if err != nil {
+		c.writeDataError(writer, err)
+		return
+	}
+	role, err = roles.AddRole(role, time.Now().Unix())
+	if err != nil {
+		c.writeChangeError(writer, err)
+		return
+	}
+	if err := c.repo.WriteRoles(roles); err != nil {
+		c.writeDataError(writer, err)
+		return
+	}
+	c.lc.Infof("Created role %d %s", role.RoleID, role.Name)
+	c.writeRecord(writer, role)
+}
+
+// RolePut replaces the role by its role ID URL parameter in the form:
+// /roles/1
+func (c *Controller) RolePut(writer http.ResponseWriter, req *http.Request) {
+	if !c.requireAdminScope(writer, req) {
+		return
+	}
+	var role Role
+	if err := readRecord(req, &role); err != nil {
+		c.writeChangeError(writer, err)
+		return
+	}
+	roleID, err := roleIDParameter(req)
+	if err != nil {
+		c.writeChangeError(writer, err)
+		return
+	}
+	if role.RoleID != 0 && role.RoleID != roleID {
+		c.writeChangeError(writer, fmt.Errorf("%w: role ID %d of the body does not match %d", errInvalidRecord, role.RoleID, roleID))
+		return
+	}
+	role.RoleID = roleID
+
+	c.dataMutex.Lock()
+	defer c.dataMutex.Unlock()
+
+	roles, err := c.repo.GetRoles()
+	if err != nil {
+		c.writeDataError(writer, err)
+		return
+	}
+	role, err = roles.UpdateRole(role, time.Now().Unix())
+	if err != nil {
+		c.writeChangeError(writer, err)
+		return
+	}
+	if err := c.repo.WriteRoles(roles); err != nil {
+		c.writeDataError(writer, err)
+		return
+	}
+	c.lc.Infof("Updated role %d", role.RoleID)
+	c.writeRecord(writer, role)
+}
+
+// RoleDelete removes the role by its role ID URL parameter in the form:
+// /roles/1
+// A role that is the role of a card is not removed
+func (c *Controller) RoleDelete(writer http.ResponseWriter, req *http.Request) {
+	if !c.requireAdminScope(writer, req) {
+		return
+	}
+	roleID, err := roleIDParameter(req)
+	if err != nil {
+		c.writeChangeError(writer, err)
+		return
+	}
+
+	c.dataMutex.Lock()
+	defer c.dataMutex.Unlock()
+
+	cards, err := c.repo.GetCards()
+	if err != nil {
+		c.writeDataError(writer, err)
+		return
+	}
+	roles, err := c.repo.GetRoles()
+	if err != nil {
+		c.writeDataError(writer, err)
+		return
+	}
+	role, err := roles.DeleteRole(roleID, cards)
+	if err != nil {
+		c.writeChangeError(writer, err)
+		return
+	}
+	if err := c.repo.WriteRoles(roles); err != nil {
+		c.writeDataError(writer, err)
+		return
+	}
+	c.lc.Infof("Removed role %d", role.RoleID)
+	c.writeRecord(writer, role)
+}
@@ -0,0 +1,278 @@
+// Copyright © 2026 Intel Corporation. All rights reserved.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package routes
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/edgexfoundry/app-functions-sdk-go/v3/pkg/interfaces/mocks"
+	"github.com/edgexfoundry/go-mod-core-contracts/v3/clients/logger"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCardHandlers(t *testing.T) {
+	tests := []struct {
+		Name       string
+		Method     string
+		CardID     string
+		Body       string
+		StatusCode int
+	}{
+		{"Create card", "POST", "", `{"cardID":"0001230100","roleID":1,"isValid":true,"personID":1}`, http.StatusOK},
+		{"Create existing card", "POST", "", `{"cardID":"0001230001","roleID":1,"isValid":true,"personID":1}`, http.StatusConflict},
+		{"Create card of unknown person", "POST", "", `{"cardID":"0001230100","roleID":1,"isValid":true,"personID":10}`, http.StatusBadRequest},
+		{"Create invalid card JSON", "POST", "", `{"cardID":`, http.StatusBadRequest},
+		{"Create card with invalid card ID", "POST", "", `{"cardID":"100","roleID":1,"isValid":true,"personID":1}`, http.StatusBadRequest},
+		{"Update card", "PUT", "0001230001", `{"roleID":2,"isValid":true,"personID":2}`, http.StatusOK},
+		{"Update card with other card ID", "PUT", "0001230001", `{"cardID":"0001230002","roleID":2,"isValid":true,"personID":2}`, http.StatusBadRequest},
+		{"Update unknown card", "PUT", "0001230100", `{"roleID":2,"isValid":true,"personID":2}`, http.StatusNotFound},
+		{"Deactivate card", "DELETE", "0001230001", "", http.StatusOK},
+		{"Deactivate unknown card", "DELETE", "0001230100", "", http.StatusNotFound},
+	}
+	defer writeJSONFiles(setupPeople(), setupAccounts(), setupCards())
+	for _, test := range tests {
+		currentTest := test
+		t.Run(currentTest.Name, func(t *testing.T) {
+			mockAppService := &mocks.ApplicationService{}
+			mockAppService.On("LoggingClient").Return(logger.NewMockClient())
+			c := NewController(mockAppService)
+			c.pii = setupPIIProtector(t)
+			require.NoError(t, writeJSONFiles(setupPeople(), setupAccounts(), setupCards()))
+
+			req := httptest.NewRequest(currentTest.Method, "/cards/"+currentTest.CardID, bytes.NewBufferString(currentTest.Body))
+			req.Header.Set(AdminTokenHeader, testAdminToken)
+			req = mux.SetURLVars(req, map[string]string{"cardid": currentTest.CardID})
+			w := httptest.NewRecorder()
+			switch currentTest.Method {
+			case "POST":
+				c.CardPost(w, req)
+			case "PUT":
+				c.CardPut(w, req)
+			case "DELETE":
+				c.CardDelete(w, req)
+			}
+			resp := w.Result()
+			defer resp.Body.Close()
+
+			require.Equal(t, currentTest.StatusCode, resp.StatusCode)
+			if resp.StatusCode != http.StatusOK {
+				return
+			}
+			var card Card
+			require.NoError(t, json.NewDecoder(resp.Body).Decode(&card))
+			cards, err := GetCardsData()
+			require.NoError(t, err)
+			assert.Equal(t, card, cards.GetCardByCardID(card.CardID), "the change should be written")
+		})
+	}
+}
+
+func TestPersonHandlers(t *testing.T) {
+	tests := []struct {
+		Name       string
+		Method     string
+		PersonID   string
+		Body       string
+		StatusCode int
+	}{
+		{"Create person", "POST", "", `{"accountID":1,"fullName":"New Person","isActive":true}`, http.StatusOK},
+		{"Create person of unknown account", "POST", "", `{"accountID":10,"fullName":"New Person","isActive":true}`, http.StatusBadRequest},
+		{"Update person", "PUT", "1", `{"accountID":2,"fullName":"Renamed Person","isActive":true}`, http.StatusOK},
+		{"Update person of unknown account", "PUT", "1", `{"accountID":10,"fullName":"Renamed Person","isActive":true}`, http.StatusBadRequest},
+		{"Update invalid person ID", "PUT", "one", `{"accountID":2}`, http.StatusBadRequest},
+		{"Deactivate person", "DELETE", "1", "", http.StatusOK},
+		{"Deactivate unknown person", "DELETE", "10", "", http.StatusNotFound},
+	}
+	defer writeJSONFiles(setupPeople(), setupAccounts(), setupCards())
+	for _, test := range tests {
+		currentTest := test
+		t.Run(currentTest.Name, func(t *testing.T) {
+			mockAppService := &mocks.ApplicationService{}
+			mockAppService.On("LoggingClient").Return(logger.NewMockClient())
+			c := NewController(mockAppService)
+			c.pii = setupPIIProtector(t)
+			require.NoError(t, writeJSONFiles(setupPeople(), setupAccounts(), setupCards()))
+
+			req := httptest.NewRequest(currentTest.Method, "/people/"+currentTest.PersonID, bytes.NewBufferString(currentTest.Body))
+			req.Header.Set(AdminTokenHeader, testAdminToken)
+			req = mux.SetURLVars(req, map[string]string{"personid": currentTest.PersonID})
+			w := httptest.NewRecorder()
+			switch currentTest.Method {
+			case "POST":
+				c.PersonPost(w, req)
+			case "PUT":
+				c.PersonPut(w, req)
+			case "DELETE":
+				c.PersonDelete(w, req)
+			}
+			resp := w.Result()
+			defer resp.Body.Close()
+
+			require.Equal(t, currentTest.StatusCode, resp.StatusCode)
+			if resp.StatusCode != http.StatusOK {
+				return
+			}
+			var person Person
+			require.NoError(t, json.NewDecoder(resp.Body).Decode(&person))
+			people, err := GetPeopleData()
+			require.NoError(t, err)
+			assert.Equal(t, person, people.GetPersonByPersonID(person.PersonID), "the change should be written")
+		})
+	}
+}
+
+func TestAccountHandlers(t *testing.T) {
+	tests := []struct {
+		Name       string
+		Method     string
+		AccountID  string
+		Body       string
+		StatusCode int
+	}{
+		{"Create account", "POST", "", `{"emailAddress":"new@example.com","isActive":true}`, http.StatusOK},
+		{"Create existing account", "POST", "", `{"accountID":1,"isActive":true}`, http.StatusConflict},
+		{"Update account", "PUT", "1", `{"emailAddress":"changed@example.com","isActive":true}`, http.StatusOK},
+		{"Update unknown account", "PUT", "10", `{"isActive":true}`, http.StatusNotFound},
+		{"Deactivate account", "DELETE", "1", "", http.StatusOK},
+		{"Deactivate invalid account ID", "DELETE", "one", "", http.StatusBadRequest},
+	}
+	defer writeJSONFiles(setupPeople(), setupAccounts(), setupCards())
+	for _, test := range tests {
+		currentTest := test
+		t.Run(currentTest.Name, func(t *testing.T) {
+			mockAppService := &mocks.ApplicationService{}
+			mockAppService.On("LoggingClient").Return(logger.NewMockClient())
+			c := NewController(mockAppService)
+			c.pii = setupPIIProtector(t)
+			require.NoError(t, writeJSONFiles(setupPeople(), setupAccounts(), setupCards()))
+
+			req := httptest.NewRequest(currentTest.Method, "/accounts/"+currentTest.AccountID, bytes.NewBufferString(currentTest.Body))
+			req.Header.Set(AdminTokenHeader, testAdminToken)
+			req = mux.SetURLVars(req, map[string]string{"accountid": currentTest.AccountID})
+			w := httptest.NewRecorder()
+			switch currentTest.Method {
+			case "POST":
+				c.AccountPost(w, req)
+			case "PUT":
+				c.AccountPut(w, req)
+			case "DELETE":
+				c.AccountDelete(w, req)
+			}
+			resp := w.Result()
+			defer resp.Body.Close()
+
+			require.Equal(t, currentTest.StatusCode, resp.StatusCode)
+			if resp.StatusCode != http.StatusOK {
+				return
+			}
+			var account Account
+			require.NoError(t, json.NewDecoder(resp.Body).Decode(&account))
+			assert.Empty(t, account.CreditCardNumber, "the credit card number should be tokenized")
+			accounts, err := c.readAccounts()
+			require.NoError(t, err)
+			assert.Equal(t, account, accounts.GetAccountByAccountID(account.AccountID), "the change should be written")
+
+			stored, err := GetAccountsData()
+			require.NoError(t, err)
+			assert.NotEqual(t, account.EmailAddress, stored.GetAccountByAccountID(account.AccountID).EmailAddress, "the email address should be encrypted")
+		})
+	}
+}
+
+func TestCardLifecycleHandlers(t *testing.T) {
+	defer writeJSONFiles(setupPeople(), setupAccounts(), setupCards())
+	mockAppService := &mocks.ApplicationService{}
+	mockAppService.On("LoggingClient").Return(logger.NewMockClient())
+	c := NewController(mockAppService)
+	c.pii = setupPIIProtector(t)
+	require.NoError(t, writeJSONFiles(setupPeople(), setupAccounts(), setupCards()))
+
+	send := func(handler http.HandlerFunc, method string, path string, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, "/cards/0001230001/"+path, bytes.NewBufferString(body))
+		req.Header.Set(AdminTokenHeader, testAdminToken)
+		req = mux.SetURLVars(req, map[string]string{"cardid": "0001230001"})
+		w := httptest.NewRecorder()
+		handler(w, req)
+		return w
+	}
+
+	assert.Equal(t, http.StatusBadRequest, send(c.CardSuspensionPost, "POST", "suspension", `{}`).Code, "a suspension needs a reason")
+	w := send(c.CardSuspensionPost, "POST", "suspension", `{"reason":"shared with a visitor"}`)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, http.StatusConflict, send(c.CardSuspensionPost, "POST", "suspension", `{"reason":"again"}`).Code)
+	require.Equal(t, http.StatusOK, send(c.CardSuspensionDelete, "DELETE", "suspension", "").Code)
+	assert.Equal(t, http.StatusConflict, send(c.CardSuspensionDelete, "DELETE", "suspension", "").Code)
+
+	assert.Equal(t, http.StatusBadRequest, send(c.CardLostPost, "POST", "lost", `{"replacementCardID":"100"}`).Code)
+	assert.Equal(t, http.StatusConflict, send(c.CardLostPost, "POST", "lost", `{"replacementCardID":"0001230002"}`).Code)
+	w = send(c.CardLostPost, "POST", "lost", `{"replacementCardID":"0001230100"}`)
+	require.Equal(t, http.StatusOK, w.Code)
+	var report LostCardReport
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&report))
+	require.NotNil(t, report.Replacement)
+	assert.Equal(t, "0001230100", report.Card.ReplacedBy)
+
+	cards, err := GetCardsData()
+	require.NoError(t, err)
+	assert.Equal(t, report.Card, cards.GetCardByCardID("0001230001"))
+	assert.Equal(t, *report.Replacement, cards.GetCardByCardID("0001230100"))
+	assert.Equal(t, http.StatusConflict, send(c.CardLostPost, "POST", "lost", "").Code, "a revoked card cannot be reported again")
+}
+
+func TestWriteHandlersRequireAdminScope(t *testing.T) {
+	defer writeJSONFiles(setupPeople(), setupAccounts(), setupCards())
+	defer os.Remove(RolesFileName)
+	mockAppService := &mocks.ApplicationService{}
+	mockAppService.On("LoggingClient").Return(logger.NewMockClient())
+	c := NewController(mockAppService)
+	c.pii = setupPIIProtector(t)
+	require.NoError(t, writeJSONFiles(setupPeople(), setupAccounts(), setupCards()))
+	cardsBefore, err := os.ReadFile(CardsFileName)
+	require.NoError(t, err)
+
+	vars := map[string]string{"cardid": "0001230001", "personid": "1", "accountid": "1", "roleid": "1"}
+	handlers := map[string]http.HandlerFunc{
+		"CardPost":             c.CardPost,
+		"CardPut":              c.CardPut,
+		"CardDelete":           c.CardDelete,
+		"CardSuspensionPost":   c.CardSuspensionPost,
+		"CardSuspensionDelete": c.CardSuspensionDelete,
+		"CardLostPost":         c.CardLostPost,
+		"CardPINPut":           c.CardPINPut,
+		"PersonPost":           c.PersonPost,
+		"PersonPut":            c.PersonPut,
+		"PersonDelete":         c.PersonDelete,
+		"AccountPost":          c.AccountPost,
+		"AccountPut":           c.AccountPut,
+		"AccountDelete":        c.AccountDelete,
+		"RolePost":             c.RolePost,
+		"RolePut":              c.RolePut,
+		"RoleDelete":           c.RoleDelete,
+	}
+	for name, handler := range handlers {
+		for _, token := range []string{"", "wrong"} {
+			req := httptest.NewRequest("POST", "/", bytes.NewBufferString(`{"cardID":"0001230100","roleID":3,"isValid":true,"personID":1,"permissions":["maintenance:clear"],"reason":"test","pin":"1234"}`))
+			if token != "" {
+				req.Header.Set(AdminTokenHeader, token)
+			}
+			req = mux.SetURLVars(req, vars)
+			w := httptest.NewRecorder()
+			handler(w, req)
+			assert.Equal(t, http.StatusUnauthorized, w.Code, "%s should require the admin scope", name)
+		}
+	}
+
+	cardsAfter, err := os.ReadFile(CardsFileName)
+	require.NoError(t, err)
+	assert.Equal(t, string(cardsBefore), string(cardsAfter), "the cards should not change without the admin scope")
+	_, err = os.Stat(RolesFileName)
+	assert.True(t, os.IsNotExist(err), "the roles should not change without the admin scope")
+}
@@ -46,12 +46,54 @@ func main() {
 		os.Exit(1)
 	}
 
-	controller := routes.NewController(lc, service, auditLogFileName, inventoryFileName)
+	pricingFileName, err := service.GetAppSetting("PricingFileName")
+	if err != nil {
+		lc.Errorf("failed load PricingFileName from ApplicationSettings: %s", err.Error())
+		os.Exit(1)
+	}
+
+	if len(pricingFileName) == 0 {
+		lc.Error("PricingFileName configuration setting is empty")
+		os.Exit(1)
+	}
+
+	stockHistoryFileName, err := service.GetAppSetting("StockHistoryFileName")
+	if err != nil {
+		lc.Errorf("failed load StockHistoryFileName from ApplicationSettings: %s", err.Error())
+		os.Exit(1)
+	}
+
+	if len(stockHistoryFileName) == 0 {
+		lc.Error("StockHistoryFileName configuration setting is empty")
+		os.Exit(1)
+	}
+
+	anomalyFileName, err := service.GetAppSetting("AnomalyFileName")
+	if err != nil {
+		lc.Errorf("failed load AnomalyFileName from ApplicationSettings: %s", err.Error())
+		os.Exit(1)
+	}
+
+	if len(anomalyFileName) == 0 {
+		lc.Error("AnomalyFileName configuration setting is empty")
+		os.Exit(1)
+	}
+
+	controller := routes.NewController(lc, service, auditLogFileName, inventoryFileName, pricingFileName, stockHistoryFileName, anomalyFileName)
+	if err := controller.LoadSettings(); err != nil {
+		lc.Errorf("failed to load ApplicationSettings: %s", err.Error())
+		os.Exit(1)
+	}
+
 	err = controller.AddAllRoutes()
 	if err != nil {
 		lc.Errorf("failed to add all Routes: %s", err.Error())
 		os.Exit(1)
 	}
+
+	go controller.MonitorLotExpiry()
+	go controller.MonitorAuditLogRetention()
+
 	if err := service.Run(); err != nil {
 		lc.Errorf("Run returned error: %s", err.Error())
 		os.Exit(1)
@@ -0,0 +1,129 @@
+// Copyright © 2026 Intel Corporation. All rights reserved.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package routes
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+
+	"github.com/google/uuid"
+)
+
+// Handling of stock deltas that would drive the units on hand below zero
+const (
+	// NegativeStockFlag applies the delta and queues an anomaly
+	NegativeStockFlag = "flag"
+	// NegativeStockClamp applies as much of the delta as there are units on
+	// hand and queues an anomaly
+	NegativeStockClamp = "clamp"
+	// NegativeStockReject does not apply the delta and queues an anomaly
+	NegativeStockReject = "reject"
+)
+
+// Actions taken on the delta of a stock anomaly
+const (
+	AnomalyActionFlagged  = "flagged"
+	AnomalyActionClamped  = "clamped"
+	AnomalyActionRejected = "rejected"
+)
+
+// validNegativeStockHandling returns true if the value is one of the
+// NegativeStockHandling settings
+func validNegativeStockHandling(value string) bool {
+	switch value {
+	case NegativeStockFlag, NegativeStockClamp, NegativeStockReject:
+		return true
+	}
+	return false
+}
+
+// checkStockDelta returns the part of the delta that may be applied to the
+// units on hand of the product in the machine, and an anomaly when the delta
+// would drive them below zero. How much of the delta is applied depends on
+// the NegativeStockHandling setting, and defaults to all of it
+func (c *Controller) checkStockDelta(product Product, machineID string, delta int, auditEntryID string, now int64) (int, *StockAnomaly) {
+	unitsOnHand := product.unitsOnHand(machineID)
+	if delta >= 0 || unitsOnHand+delta >= 0 {
+		return delta, nil
+	}
+
+	anomaly := &StockAnomaly{
+		AnomalyID:         uuid.New().String(),
+		SKU:               product.SKU,
+		ProductName:       product.ProductName,
+		MachineID:         normalizeMachineID(machineID),
+		AuditEntryID:      auditEntryID,
+		Delta:             delta,
+		AppliedDelta:      delta,
+		UnitsOnHandBefore: unitsOnHand,
+		Action:            AnomalyActionFlagged,
+		CreatedAt:         now,
+	}
+	switch c.negativeStockHandling {
+	case NegativeStockClamp:
+		anomaly.AppliedDelta = min(-unitsOnHand, 0)
+		anomaly.Action = AnomalyActionClamped
+	case NegativeStockReject:
+		anomaly.AppliedDelta = 0
+		anomaly.Action = AnomalyActionRejected
+	}
+	return anomaly.AppliedDelta, anomaly
+}
+
+// message returns the notification message of the anomaly
+func (anomaly *StockAnomaly) message() string {
+	machine := ""
+	if anomaly.MachineID != "" {
+		machine = " in machine " + anomaly.MachineID
+	}
+	return fmt.Sprintf("Delta %d of product %s (%s)%s would drive its %d units on hand below 0 and was %s, which may be caused by a misdetection or a missed restock",
+		anomaly.Delta, anomaly.SKU, anomaly.ProductName, machine, anomaly.UnitsOnHandBefore, anomaly.Action)
+}
+
+// GetAnomalies returns the stock anomaly queue by reading the anomalies JSON
+// file. An anomalies file that does not exist yet is an empty queue
+func (c *Controller) GetAnomalies() (anomalies StockAnomalies, err error) {
+	data, err := os.ReadFile(c.anomalyFileName)
+	if errors.Is(err, fs.ErrNotExist) {
+		return StockAnomalies{Data: []StockAnomaly{}}, nil
+	}
+	if err != nil {
+		return anomalies, fmt.Errorf("failed to read from anomalies file: %s", err.Error())
+	}
+	if err := json.Unmarshal(data, &anomalies); err != nil {
+		return anomalies, fmt.Errorf("failed to unmarshal anomalies file: %s", err.Error())
+	}
+
+	return
+}
+
+// queueAnomalies appends anomalies to the anomalies JSON file. Like the stock
+// history, a failure is only logged so that it does not fail the mutation.
+// Callers must hold the inventory mutex, and notify the anomalies with
+// notifyAnomalies once they released it
+func (c *Controller) queueAnomalies(anomalies ...StockAnomaly) {
+	if len(anomalies) == 0 {
+		return
+	}
+
+	queue, err := c.GetAnomalies()
+	if err != nil {
+		c.lc.Errorf("Failed to queue stock anomalies: %s", err.Error())
+		return
+	}
+	queue.Data = append(queue.Data, anomalies...)
+	if err := c.WriteJSON(c.anomalyFileName, queue); err != nil {
+		c.lc.Errorf("Failed to queue stock anomalies: %s", err.Error())
+	}
+}
+
+// notifyAnomalies sends a notification for each of the queued anomalies
+func (c *Controller) notifyAnomalies(anomalies []StockAnomaly) {
+	for _, anomaly := range anomalies {
+		c.sendNotification(anomaly.message())
+	}
+}
@@ -0,0 +1,327 @@
+// Copyright © 2026 Intel Corporation. All rights reserved.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package routes
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	auditLogArchivePrefix     = "auditlog-"
+	auditLogArchiveSuffix     = ".json.gz"
+	auditLogArchiveDateLayout = "2006-01-02"
+)
+
+// auditLogArchive is a compressed archive of the audit log entries of a
+// single day
+type auditLogArchive struct {
+	day      time.Time
+	fileName string
+}
+
+// archiveDay returns the UTC day of a timestamp in nanoseconds
+func archiveDay(timestamp int64) time.Time {
+	return time.Unix(0, timestamp).UTC().Truncate(24 * time.Hour)
+}
+
+// auditLogArchiveFileName returns the file name of the archive of a day
+func (c *Controller) auditLogArchiveFileName(day time.Time) string {
+	return filepath.Join(c.auditLogArchiveDir, auditLogArchivePrefix+day.Format(auditLogArchiveDateLayout)+auditLogArchiveSuffix)
+}
+
+// getAuditLogArchives returns the audit log archives, oldest first. An
+// archive directory that does not exist yet has no archives
+func (c *Controller) getAuditLogArchives() ([]auditLogArchive, error) {
+	if c.auditLogArchiveDir == "" {
+		return nil, nil
+	}
+	dirEntries, err := os.ReadDir(c.auditLogArchiveDir)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read audit log archive directory: %s", err.Error())
+	}
+
+	var archives []auditLogArchive
+	for _, dirEntry := range dirEntries {
+		name := dirEntry.Name()
+		if dirEntry.IsDir() || !strings.HasPrefix(name, auditLogArchivePrefix) || !strings.HasSuffix(name, auditLogArchiveSuffix) {
+			continue
+		}
+		day, err := time.Parse(auditLogArchiveDateLayout, strings.TrimSuffix(strings.TrimPrefix(name, auditLogArchivePrefix), auditLogArchiveSuffix))
+		if err != nil {
+			continue
+		}
+		archives = append(archives, auditLogArchive{day: day, fileName: filepath.Join(c.auditLogArchiveDir, name)})
+	}
+	sort.Slice(archives, func(i, j int) bool {
+		return archives[i].day.Before(archives[j].day)
+	})
+	return archives, nil
+}
+
+// readAuditLogArchive returns the audit log entries of a compressed archive
+func readAuditLogArchive(fileName string) (auditLog AuditLog, err error) {
+	file, err := os.Open(fileName)
+	if err != nil {
+		return auditLog, fmt.Errorf("failed to open audit log archive %s: %s", fileName, err.Error())
+	}
+	defer file.Close()
+
+	reader, err := gzip.NewReader(file)
+	if err != nil {
+		return auditLog, fmt.Errorf("failed to decompress audit log archive %s: %s", fileName, err.Error())
+	}
+	defer reader.Close()
+
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return auditLog, fmt.Errorf("failed to decompress audit log archive %s: %s", fileName, err.Error())
+	}
+	if err := json.Unmarshal(data, &auditLog); err != nil {
+		return auditLog, fmt.Errorf("failed to unmarshal audit log archive %s: %s", fileName, err.Error())
+	}
+	return auditLog, nil
+}
+
+// writeAuditLogArchive compresses the audit log entries into an archive
+func writeAuditLogArchive(fileName string, auditLog AuditLog) error {
+	data, err := json.Marshal(auditLog)
+	if err != nil {
+		return fmt.Errorf("failed to marshal audit log archive %s: %s", fileName, err.Error())
+	}
+
+	var compressed bytes.Buffer
+	writer := gzip.NewWriter(&compressed)
+	if _, err := writer.Write(data); err != nil {
+		return fmt.Errorf("failed to compress audit log archive %s: %s", fileName, err.Error())
+	}
+	if err := writer.Close(); err != nil {
+		return fmt.Errorf("failed to compress audit log archive %s: %s", fileName, err.Error())
+	}
+
+	if err := os.WriteFile(fileName, compressed.Bytes(), 0644); err != nil {
+		return fmt.Errorf("failed to write audit log archive %s: %s", fileName, err.Error())
+	}
+	return nil
+}
+
+// ArchiveAuditLog rolls the audit log entries that are older than the
+// retention period into the archive of the day they were created on, and
+// returns the number of archived entries. Only the oldest entries of the
+// audit log are archived, so that the hash chain continues from the archives
+// into the audit log file. An entry is never archived into an older archive
+// than the entry before it, so reading the archives oldest first always
+// follows the chain
+func (c *Controller) ArchiveAuditLog(now int64) (int, error) {
+	if c.auditLogRetentionDays <= 0 {
+		return 0, nil
+	}
+
+	c.auditLogMutex.Lock()
+	defer c.auditLogMutex.Unlock()
+
+	auditLog, err := c.GetAuditLog()
+	if err != nil {
+		return 0, err
+	}
+	cutoff := now - int64(c.auditLogRetentionDays)*nanosecondsPerDay
+	archived := 0
+	for archived < len(auditLog.Data) && auditLog.Data[archived].CreatedAt < cutoff {
+		archived++
+	}
+	if archived == 0 {
+		return 0, nil
+	}
+
+	archives, err := c.getAuditLogArchives()
+	if err != nil {
+		return 0, err
+	}
+	var lastDay time.Time
+	if len(archives) > 0 {
+		lastDay = archives[len(archives)-1].day
+	}
+
+	// group the entries by archive, keeping the order of the chain
+	var days []time.Time
+	entriesByDay := map[time.Time][]AuditLogEntry{}
+	for _, auditLogEntry := range auditLog.Data[:archived] {
+		day := archiveDay(auditLogEntry.CreatedAt)
+		if day.Before(lastDay) {
+			day = lastDay
+		}
+		lastDay = day
+		if _, ok := entriesByDay[day]; !ok {
+			days = append(days, day)
+		}
+		entriesByDay[day] = append(entriesByDay[day], auditLogEntry)
+	}
+
+	if err := os.MkdirAll(c.auditLogArchiveDir, 0755); err != nil {
+		return 0, fmt.Errorf("failed to create audit log archive directory: %s", err.Error())
+	}
+	for _, day := range days {
+		fileName := c.auditLogArchiveFileName(day)
+		archive := AuditLog{Data: []AuditLogEntry{}}
+		if _, err := os.Stat(fileName); err == nil {
+			if archive, err = readAuditLogArchive(fileName); err != nil {
+				return 0, err
+			}
+		}
+		// entries of an archival that was interrupted before the audit log
+		// file was written are already in the archive
+		archivedIDs := map[string]bool{}
+		for _, auditLogEntry := range archive.Data {
+			archivedIDs[auditLogEntry.AuditEntryID] = true
+		}
+		for _, auditLogEntry := range entriesByDay[day] {
+			if !archivedIDs[auditLogEntry.AuditEntryID] {
+				archive.Data = append(archive.Data, auditLogEntry)
+			}
+		}
+		if err := writeAuditLogArchive(fileName, archive); err != nil {
+			return 0, err
+		}
+	}
+
+	c.auditLog = AuditLog{Data: append([]AuditLogEntry{}, auditLog.Data[archived:]...)}
+	if err := c.WriteAuditLog(); err != nil {
+		return 0, err
+	}
+	c.lc.Infof("Archived %d audit log entries older than %d days", archived, c.auditLogRetentionDays)
+	return archived, nil
+}
+
+// MonitorAuditLogRetention archives the audit log entries that are older
+// than the retention period on every AuditLogArchiveInterval until the
+// service exits. It returns right away when no retention period is set
+func (c *Controller) MonitorAuditLogRetention() {
+	if c.auditLogRetentionDays <= 0 {
+		return
+	}
+	ticker := time.NewTicker(c.auditLogArchiveInterval)
+	defer ticker.Stop()
+	for {
+		if _, err := c.ArchiveAuditLog(time.Now().UnixNano()); err != nil {
+			c.lc.Errorf("Failed to archive the audit log: %s", err.Error())
+		}
+		<-ticker.C
+	}
+}
+
+// auditLogSegment is a part of the audit log chain, either an archive or the
+// audit log file
+type auditLogSegment struct {
+	fileName string
+	auditLog AuditLog
+}
+
+// getAuditLogChain returns the archives created on or after the day of from,
+// oldest first, followed by the audit log file. Since entries are only ever
+// archived into the archive of their own day or a newer one, older archives
+// do not hold any entry created on or after from
+func (c *Controller) getAuditLogChain(from int64) ([]auditLogSegment, error) {
+	archives, err := c.getAuditLogArchives()
+	if err != nil {
+		return nil, err
+	}
+
+	var segments []auditLogSegment
+	fromDay := archiveDay(from)
+	for _, archive := range archives {
+		if archive.day.Before(fromDay) {
+			continue
+		}
+		auditLog, err := readAuditLogArchive(archive.fileName)
+		if err != nil {
+			return nil, err
+		}
+		segments = append(segments, auditLogSegment{fileName: archive.fileName, auditLog: auditLog})
+	}
+
+	auditLog, err := c.GetAuditLog()
+	if err != nil {
+		return nil, err
+	}
+	return append(segments, auditLogSegment{fileName: c.auditLogFileName, auditLog: auditLog}), nil
+}
+
+// joinAuditLogSegments returns the entries of every segment in chain order
+func joinAuditLogSegments(segments []auditLogSegment) AuditLog {
+	auditLog := AuditLog{Data: []AuditLogEntry{}}
+	for _, segment := range segments {
+		auditLog.Data = append(auditLog.Data, segment.auditLog.Data...)
+	}
+	return auditLog
+}
+
+// liveArchivedEntries returns the archived entries that are not deleted by a
+// tombstone of the archives or of the given audit log. Archived entries are
+// deleted like any other, by appending their tombstone to the audit log, so
+// the archives themselves are never rewritten
+func (c *Controller) liveArchivedEntries(auditLog AuditLog) ([]AuditLogEntry, error) {
+	archives, err := c.getAuditLogArchives()
+	if err != nil {
+		return nil, err
+	}
+
+	chain := AuditLog{Data: []AuditLogEntry{}}
+	archivedIDs := map[string]bool{}
+	for _, archive := range archives {
+		archiveLog, err := readAuditLogArchive(archive.fileName)
+		if err != nil {
+			return nil, err
+		}
+		for _, auditLogEntry := range archiveLog.Data {
+			archivedIDs[auditLogEntry.AuditEntryID] = true
+		}
+		chain.Data = append(chain.Data, archiveLog.Data...)
+	}
+	chain.Data = append(chain.Data, auditLog.Data...)
+
+	entries := []AuditLogEntry{}
+	for _, auditLogEntry := range chain.liveEntries() {
+		if archivedIDs[auditLogEntry.AuditEntryID] {
+			entries = append(entries, auditLogEntry)
+		}
+	}
+	return entries, nil
+}
+
+// GetAuditLogRange returns the audit log entries created between from and
+// to, searching the archives as well as the audit log file. Deleted entries
+// and their tombstones are left out unless includeDeleted is set
+func (c *Controller) GetAuditLogRange(from int64, to int64, includeDeleted bool) (AuditLog, error) {
+	segments, err := c.getAuditLogChain(from)
+	if err != nil {
+		return AuditLog{}, err
+	}
+
+	// a tombstone may be created after to, so deleted entries are left out
+	// before the entries are limited to the range
+	chain := joinAuditLogSegments(segments)
+	if !includeDeleted {
+		chain = AuditLog{Data: chain.liveEntries()}
+	}
+	auditLog := AuditLog{Data: []AuditLogEntry{}}
+	for _, auditLogEntry := range chain.Data {
+		if auditLogEntry.CreatedAt >= from && auditLogEntry.CreatedAt < to {
+			auditLog.Data = append(auditLog.Data, auditLogEntry)
+		}
+	}
+	return auditLog, nil
+}
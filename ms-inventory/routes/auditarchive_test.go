@@ -0,0 +1,180 @@
+// Copyright © 2026 Intel Corporation. All rights reserved.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package routes
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/edgexfoundry/go-mod-core-contracts/v3/clients/logger"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// getArchivableAuditLog returns a chained audit log with entries created 40,
+// 35 and 1 day before testNow, and an entry backdated to 50 days before
+// testNow that was posted after the one of 35 days
+func getArchivableAuditLog(t *testing.T) AuditLog {
+	auditLog := AuditLog{Data: []AuditLogEntry{}}
+	for i, daysAgo := range []int64{40, 35, 50, 1} {
+		auditLogEntry := getDefaultAuditsList().Data[0]
+		auditLogEntry.AuditEntryID = string(rune('a' + i))
+		auditLogEntry.CreatedAt = testNow - daysAgo*nanosecondsPerDay
+		_, err := auditLog.appendEntry(auditLogEntry)
+		require.NoError(t, err)
+	}
+	return auditLog
+}
+
+// newArchiveTestController returns a controller that keeps audit log
+// entries for 30 days, with the given audit log written to its file
+func newArchiveTestController(t *testing.T, auditLog AuditLog) *Controller {
+	c := &Controller{
+		lc:                    logger.NewMockClient(),
+		service:               nil,
+		auditLog:              auditLog,
+		auditLogFileName:      AuditLogFileName,
+		auditLogArchiveDir:    t.TempDir(),
+		auditLogRetentionDays: 30,
+	}
+	require.NoError(t, c.WriteAuditLog())
+	t.Cleanup(func() {
+		_ = os.Remove(AuditLogFileName)
+	})
+	return c
+}
+
+// TestArchiveAuditLog tests that old entries are rolled into daily archives
+// that continue the hash chain of the audit log file
+func TestArchiveAuditLog(t *testing.T) {
+	c := newArchiveTestController(t, getArchivableAuditLog(t))
+
+	archived, err := c.ArchiveAuditLog(testNow)
+	require.NoError(t, err)
+	assert.Equal(t, 3, archived)
+
+	// the backdated entry is archived with the entry before it
+	archives, err := c.getAuditLogArchives()
+	require.NoError(t, err)
+	require.Len(t, archives, 2)
+	assert.Equal(t, archiveDay(testNow-40*nanosecondsPerDay), archives[0].day)
+	assert.Equal(t, archiveDay(testNow-35*nanosecondsPerDay), archives[1].day)
+	archive, err := readAuditLogArchive(archives[1].fileName)
+	require.NoError(t, err)
+	require.Len(t, archive.Data, 2)
+	assert.Equal(t, "c", archive.Data[1].AuditEntryID)
+
+	auditLog, err := c.GetAuditLog()
+	require.NoError(t, err)
+	require.Len(t, auditLog.Data, 1)
+	assert.Equal(t, "d", auditLog.Data[0].AuditEntryID)
+
+	segments, err := c.getAuditLogChain(0)
+	require.NoError(t, err)
+	chain := joinAuditLogSegments(segments)
+	assert.True(t, chain.verify().Valid)
+	assert.Equal(t, 4, chain.verify().Entries)
+
+	archived, err = c.ArchiveAuditLog(testNow)
+	require.NoError(t, err)
+	assert.Equal(t, 0, archived, "entries should only be archived once")
+
+	t.Run("retention disabled", func(t *testing.T) {
+		c := newArchiveTestController(t, getArchivableAuditLog(t))
+		c.auditLogRetentionDays = 0
+
+		archived, err := c.ArchiveAuditLog(testNow)
+		require.NoError(t, err)
+		assert.Equal(t, 0, archived)
+		archives, err := c.getAuditLogArchives()
+		require.NoError(t, err)
+		assert.Empty(t, archives)
+	})
+}
+
+// TestGetAuditLogRange tests that date ranges search the archives as well as
+// the audit log file
+func TestGetAuditLogRange(t *testing.T) {
+	auditLog := getArchivableAuditLog(t)
+	_, err := auditLog.appendTombstone(auditLog.Data[1], testNow)
+	require.NoError(t, err)
+	c := newArchiveTestController(t, auditLog)
+	_, err = c.ArchiveAuditLog(testNow)
+	require.NoError(t, err)
+
+	tests := []struct {
+		Name            string
+		FromDaysAgo     int64
+		ToDaysAgo       int64
+		IncludeDeleted  bool
+		ExpectedEntries []string
+	}{
+		{"archived and current entries", 45, 0, false, []string{"a", "d"}},
+		{"backdated entry", 51, 49, false, []string{"c"}},
+		{"deleted entry", 36, 34, true, []string{"b"}},
+		{"deleted entry left out", 36, 34, false, []string{}},
+		{"no entries", 30, 2, false, []string{}},
+	}
+
+	for _, test := range tests {
+		currentTest := test
+		t.Run(currentTest.Name, func(t *testing.T) {
+			auditLog, err := c.GetAuditLogRange(testNow-currentTest.FromDaysAgo*nanosecondsPerDay, testNow-currentTest.ToDaysAgo*nanosecondsPerDay, currentTest.IncludeDeleted)
+			require.NoError(t, err)
+			entryIDs := []string{}
+			for _, auditLogEntry := range auditLog.Data {
+				entryIDs = append(entryIDs, auditLogEntry.AuditEntryID)
+			}
+			assert.Equal(t, currentTest.ExpectedEntries, entryIDs)
+		})
+	}
+}
+
+// TestAuditLogDeleteArchivedEntries tests that archived entries are deleted
+// by tombstones in the audit log file, one by one or all at once
+func TestAuditLogDeleteArchivedEntries(t *testing.T) {
+	deleteEntry := func(c *Controller, entryID string) int {
+		req := httptest.NewRequest("DELETE", "http://localhost:48095/auditlog/"+entryID, nil)
+		req = mux.SetURLVars(req, map[string]string{"entry": entryID})
+		w := httptest.NewRecorder()
+		c.AuditLogDelete(w, req)
+		return w.Result().StatusCode
+	}
+	liveEntryIDs := func(c *Controller) []string {
+		auditLog, err := c.GetAuditLogRange(0, testNow+nanosecondsPerDay, false)
+		require.NoError(t, err)
+		entryIDs := []string{}
+		for _, auditLogEntry := range auditLog.Data {
+			entryIDs = append(entryIDs, auditLogEntry.AuditEntryID)
+		}
+		return entryIDs
+	}
+
+	c := newArchiveTestController(t, getArchivableAuditLog(t))
+	_, err := c.ArchiveAuditLog(testNow)
+	require.NoError(t, err)
+
+	require.Equal(t, http.StatusOK, deleteEntry(c, "b"))
+	assert.Equal(t, []string{"a", "c", "d"}, liveEntryIDs(c))
+	assert.Equal(t, http.StatusNotFound, deleteEntry(c, "b"), "a deleted archived entry should not be deleted again")
+
+	// the archives are left as they were archived
+	archives, err := c.getAuditLogArchives()
+	require.NoError(t, err)
+	archive, err := readAuditLogArchive(archives[1].fileName)
+	require.NoError(t, err)
+	assert.Len(t, archive.Data, 2)
+
+	require.Equal(t, http.StatusOK, deleteEntry(c, DeleteAllQueryString))
+	assert.Empty(t, liveEntryIDs(c))
+
+	segments, err := c.getAuditLogChain(0)
+	require.NoError(t, err)
+	chain := joinAuditLogSegments(segments)
+	assert.True(t, chain.verify().Valid)
+	assert.Equal(t, 8, chain.verify().Entries, "every entry should have a tombstone")
+}
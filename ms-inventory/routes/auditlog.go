@@ -0,0 +1,115 @@
+// Copyright © 2026 Intel Corporation. All rights reserved.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package routes
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+
+	"github.com/google/uuid"
+)
+
+// computeHash returns the SHA-256 hash of the entry's content, which
+// includes the hash of the entry before it in the audit log
+func (auditLogEntry AuditLogEntry) computeHash() (string, error) {
+	auditLogEntry.Hash = ""
+	data, err := json.Marshal(auditLogEntry)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal audit log entry %s: %s", auditLogEntry.AuditEntryID, err.Error())
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// appendEntry chains the entry to the last entry of the audit log and
+// appends it
+func (auditLog *AuditLog) appendEntry(auditLogEntry AuditLogEntry) (AuditLogEntry, error) {
+	auditLogEntry.PreviousHash = ""
+	if len(auditLog.Data) > 0 {
+		auditLogEntry.PreviousHash = auditLog.Data[len(auditLog.Data)-1].Hash
+	}
+	hash, err := auditLogEntry.computeHash()
+	if err != nil {
+		return auditLogEntry, err
+	}
+	auditLogEntry.Hash = hash
+	auditLog.Data = append(auditLog.Data, auditLogEntry)
+	return auditLogEntry, nil
+}
+
+// appendTombstone appends a tombstone entry that marks the entry as deleted.
+// Entries are never removed from the audit log so that the chain stays intact
+func (auditLog *AuditLog) appendTombstone(auditLogEntry AuditLogEntry, now int64) (AuditLogEntry, error) {
+	return auditLog.appendEntry(AuditLogEntry{
+		CardID:         auditLogEntry.CardID,
+		AccountID:      auditLogEntry.AccountID,
+		RoleID:         auditLogEntry.RoleID,
+		PersonID:       auditLogEntry.PersonID,
+		InventoryDelta: []DeltaInventorySKU{},
+		CreatedAt:      now,
+		AuditEntryID:   uuid.New().String(),
+		MachineID:      auditLogEntry.MachineID,
+		DeletedEntryID: auditLogEntry.AuditEntryID,
+	})
+}
+
+// liveEntries returns the entries of the audit log that are neither
+// tombstones nor deleted by one
+func (auditLog *AuditLog) liveEntries() []AuditLogEntry {
+	deleted := map[string]bool{}
+	for _, auditLogEntry := range auditLog.Data {
+		if auditLogEntry.DeletedEntryID != "" {
+			deleted[auditLogEntry.DeletedEntryID] = true
+		}
+	}
+
+	entries := []AuditLogEntry{}
+	for _, auditLogEntry := range auditLog.Data {
+		if auditLogEntry.DeletedEntryID == "" && !deleted[auditLogEntry.AuditEntryID] {
+			entries = append(entries, auditLogEntry)
+		}
+	}
+	return entries
+}
+
+// verify walks the hash chain of the audit log and reports the first entry
+// whose hash does not match its content, or whose previous hash does not
+// match the hash of the entry before it. The entries at the start of the
+// audit log that have neither a hash nor a previous hash were written before
+// entries were chained, and are skipped as pre-chain entries; the first
+// chained entry after them has no previous hash either, so any entry without
+// a hash after it breaks the chain
+func (auditLog *AuditLog) verify() AuditLogVerification {
+	verification := AuditLogVerification{Valid: true, Entries: len(auditLog.Data)}
+	previousHash := ""
+	chained := false
+	for i, auditLogEntry := range auditLog.Data {
+		if !chained && auditLogEntry.Hash == "" && auditLogEntry.PreviousHash == "" {
+			verification.PreChainEntries++
+			continue
+		}
+		chained = true
+		reason := ""
+		if auditLogEntry.PreviousHash != previousHash {
+			reason = "previous hash does not match the hash of the entry before it"
+		} else if hash, err := auditLogEntry.computeHash(); err != nil {
+			reason = err.Error()
+		} else if auditLogEntry.Hash != hash {
+			reason = "hash does not match the content of the entry"
+		}
+		if reason != "" {
+			verification.Valid = false
+			verification.BrokenLink = &AuditLogBrokenLink{
+				Index:        i,
+				AuditEntryID: auditLogEntry.AuditEntryID,
+				Reason:       reason,
+			}
+			return verification
+		}
+		previousHash = auditLogEntry.Hash
+	}
+	return verification
+}
@@ -0,0 +1,119 @@
+// Copyright © 2026 Intel Corporation. All rights reserved.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package routes
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// getChainedAuditsList returns the default audit log with every entry
+// chained to the one before it
+func getChainedAuditsList(t *testing.T) AuditLog {
+	auditLog := AuditLog{Data: []AuditLogEntry{}}
+	for _, auditLogEntry := range getDefaultAuditsList().Data {
+		_, err := auditLog.appendEntry(auditLogEntry)
+		require.NoError(t, err)
+	}
+	return auditLog
+}
+
+// TestAuditLogChain tests that appended entries are chained to the entry
+// before them
+func TestAuditLogChain(t *testing.T) {
+	auditLog := getChainedAuditsList(t)
+
+	assert.Empty(t, auditLog.Data[0].PreviousHash)
+	for i := 1; i < len(auditLog.Data); i++ {
+		assert.NotEmpty(t, auditLog.Data[i].Hash)
+		assert.Equal(t, auditLog.Data[i-1].Hash, auditLog.Data[i].PreviousHash)
+	}
+	verification := auditLog.verify()
+	assert.True(t, verification.Valid)
+	assert.Equal(t, len(auditLog.Data), verification.Entries)
+	assert.Nil(t, verification.BrokenLink)
+}
+
+// TestAuditLogVerify tests that tampering with, removing or inserting
+// entries breaks the chain at the first tampered entry
+func TestAuditLogVerify(t *testing.T) {
+	tests := []struct {
+		Name           string
+		Tamper         func(auditLog *AuditLog)
+		ExpectedIndex  int
+		ExpectedReason string
+	}{
+		{"edited entry", func(auditLog *AuditLog) { auditLog.Data[1].InventoryDelta[0].Delta = -10 }, 1, "hash does not match the content of the entry"},
+		{"removed entry", func(auditLog *AuditLog) { auditLog.Data = append(auditLog.Data[:1], auditLog.Data[2:]...) }, 1, "previous hash does not match the hash of the entry before it"},
+		{"entry without hash", func(auditLog *AuditLog) {
+			auditLog.Data = append(auditLog.Data, AuditLogEntry{AuditEntryID: "unchained"})
+		}, len(getDefaultAuditsList().Data), "previous hash does not match the hash of the entry before it"},
+		{"rehashed entry", func(auditLog *AuditLog) {
+			auditLog.Data[0].CardID = "0000000000"
+			auditLog.Data[0].Hash, _ = auditLog.Data[0].computeHash()
+		}, 1, "previous hash does not match the hash of the entry before it"},
+	}
+
+	for _, test := range tests {
+		currentTest := test
+		t.Run(currentTest.Name, func(t *testing.T) {
+			auditLog := getChainedAuditsList(t)
+			currentTest.Tamper(&auditLog)
+
+			verification := auditLog.verify()
+			assert.False(t, verification.Valid)
+			require.NotNil(t, verification.BrokenLink)
+			assert.Equal(t, currentTest.ExpectedIndex, verification.BrokenLink.Index)
+			assert.Equal(t, auditLog.Data[currentTest.ExpectedIndex].AuditEntryID, verification.BrokenLink.AuditEntryID)
+			assert.Equal(t, currentTest.ExpectedReason, verification.BrokenLink.Reason)
+		})
+	}
+}
+
+// TestAuditLogVerifyPreChainEntries tests that the entries written before
+// entries were chained are skipped, and that removing the hashes of chained
+// entries is still detected
+func TestAuditLogVerifyPreChainEntries(t *testing.T) {
+	auditLog := getDefaultAuditsList()
+	preChainEntries := len(auditLog.Data)
+	_, err := auditLog.appendEntry(AuditLogEntry{AuditEntryID: "chained-1", InventoryDelta: []DeltaInventorySKU{}, CreatedAt: testNow})
+	require.NoError(t, err)
+	_, err = auditLog.appendEntry(AuditLogEntry{AuditEntryID: "chained-2", InventoryDelta: []DeltaInventorySKU{}, CreatedAt: testNow})
+	require.NoError(t, err)
+	assert.Empty(t, auditLog.Data[preChainEntries].PreviousHash, "the first chained entry should start from an empty hash")
+
+	verification := auditLog.verify()
+	assert.True(t, verification.Valid)
+	assert.Equal(t, preChainEntries+2, verification.Entries)
+	assert.Equal(t, preChainEntries, verification.PreChainEntries)
+
+	auditLog.Data[preChainEntries].Hash = ""
+	verification = auditLog.verify()
+	assert.False(t, verification.Valid, "a chained entry without its hash should not pass as a pre-chain entry")
+	require.NotNil(t, verification.BrokenLink)
+	assert.Equal(t, preChainEntries+1, verification.BrokenLink.Index)
+}
+
+// TestAuditLogTombstone tests that deleted entries are kept in the chain and
+// left out of the live entries
+func TestAuditLogTombstone(t *testing.T) {
+	auditLog := getChainedAuditsList(t)
+	deleted := auditLog.Data[0]
+
+	tombstone, err := auditLog.appendTombstone(deleted, testNow)
+	require.NoError(t, err)
+	assert.Equal(t, deleted.AuditEntryID, tombstone.DeletedEntryID)
+	assert.Equal(t, testNow, tombstone.CreatedAt)
+	assert.NotEmpty(t, tombstone.AuditEntryID)
+	assert.True(t, auditLog.verify().Valid)
+
+	liveEntries := auditLog.liveEntries()
+	assert.Len(t, liveEntries, len(auditLog.Data)-2)
+	for _, auditLogEntry := range liveEntries {
+		assert.NotEqual(t, deleted.AuditEntryID, auditLogEntry.AuditEntryID)
+		assert.NotEqual(t, tombstone.AuditEntryID, auditLogEntry.AuditEntryID)
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"time"
 )
 
 // DeleteAllQueryString is a string used across this module to enable
@@ -64,7 +65,7 @@ func (c *Controller) GetAuditLog() (auditLog AuditLog, err error) {
 }
 
 // GetAuditLogEntryByID returns an audit log entry by reading from the
-// audit log JSON file
+// audit log JSON file. Tombstones and the entries they deleted are not found
 func (c *Controller) GetAuditLogEntryByID(auditEntryID string) (auditLogEntry AuditLogEntry, auditLogEntries AuditLog, err error) {
 	auditLogEntries, err = c.GetAuditLog()
 	if err != nil {
@@ -73,7 +74,7 @@ func (c *Controller) GetAuditLogEntryByID(auditEntryID string) (auditLogEntry Au
 			"Failed to get audit log items: " + err.Error(),
 		)
 	}
-	for _, auditLogEntry := range auditLogEntries.Data {
+	for _, auditLogEntry := range auditLogEntries.liveEntries() {
 		if auditEntryID == auditLogEntry.AuditEntryID {
 			return auditLogEntry, auditLogEntries, nil
 		}
@@ -132,13 +133,11 @@ func (c *Controller) DeleteInventoryItem(inventoryItem Product) {
 }
 
 // DeleteAuditLogEntry deletes an audit log entry item matching the
-// specified EntryID
-func (c *Controller) DeleteAuditLogEntry(auditLogEntry AuditLogEntry) {
-	for i, item := range c.auditLog.Data {
-		if item.AuditEntryID == auditLogEntry.AuditEntryID {
-			c.auditLog.Data = append(c.auditLog.Data[:i], c.auditLog.Data[i+1:]...)
-			c.lc.Debugf("Deleted: %s from audit log", auditLogEntry.AuditEntryID)
-			break
-		}
+// specified EntryID by appending a tombstone for it
+func (c *Controller) DeleteAuditLogEntry(auditLogEntry AuditLogEntry) error {
+	if _, err := c.auditLog.appendTombstone(auditLogEntry, time.Now().UnixNano()); err != nil {
+		return err
 	}
+	c.lc.Debugf("Deleted: %s from audit log", auditLogEntry.AuditEntryID)
+	return nil
 }
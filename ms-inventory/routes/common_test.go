@@ -12,8 +12,11 @@ import (
 )
 
 const (
-	AuditLogFileName  = "test-auditlog.json"
-	InventoryFileName = "test-inventory.json"
+	AuditLogFileName     = "test-auditlog.json"
+	InventoryFileName    = "test-inventory.json"
+	PricingFileName      = "test-pricing.json"
+	StockHistoryFileName = "test-stockhistory.json"
+	AnomalyFileName      = "test-anomalies.json"
 )
 
 func getDefaultProductsList() Products {
@@ -404,9 +407,16 @@ func TestDeleteAuditLogEntry(t *testing.T) {
 	}()
 
 	deletedAuditID := audits.Data[0].AuditEntryID
-	c.DeleteAuditLogEntry(audits.Data[0])
+	err = c.DeleteAuditLogEntry(audits.Data[0])
+	require.NoError(t, err)
+
+	// the entry is kept and a tombstone is appended for it
+	require.Len(t, c.auditLog.Data, len(audits.Data)+1)
+	tombstone := c.auditLog.Data[len(c.auditLog.Data)-1]
+	require.Equal(t, deletedAuditID, tombstone.DeletedEntryID)
+	require.NotEmpty(t, tombstone.Hash)
 
-	for _, audit := range audits.Data {
+	for _, audit := range c.auditLog.liveEntries() {
 		if audit.AuditEntryID == deletedAuditID {
 			t.Fatalf("Deleted person with ID " + (audit.AuditEntryID) + " but it still exists in the test list")
 		}
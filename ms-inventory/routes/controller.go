@@ -6,27 +6,175 @@ package routes
 import (
 	"fmt"
 	"net/http"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"time"
 
 	"github.com/edgexfoundry/app-functions-sdk-go/v3/pkg/interfaces"
+	clientInterfaces "github.com/edgexfoundry/go-mod-core-contracts/v3/clients/interfaces"
 	"github.com/edgexfoundry/go-mod-core-contracts/v3/clients/logger"
 )
 
+const (
+	defaultExpiryWarningDays       = 3
+	defaultExpiryCheckInterval     = time.Hour
+	defaultNotificationCategory    = "INVENTORY"
+	defaultNotificationSender      = "AutomatedVendingInventoryNotification"
+	defaultNotificationSeverity    = "NORMAL"
+	defaultAuditLogArchiveDir      = "auditlog-archive"
+	defaultAuditLogArchiveInterval = 24 * time.Hour
+	defaultStockHistoryRetention   = 90
+)
+
 type Controller struct {
-	lc                logger.LoggingClient
-	service           interfaces.ApplicationService
-	inventoryItems    Products
-	auditLog          AuditLog
-	auditLogFileName  string
-	inventoryFileName string
+	lc                   logger.LoggingClient
+	service              interfaces.ApplicationService
+	notificationClient   clientInterfaces.NotificationClient
+	inventoryItems       Products
+	auditLog             AuditLog
+	auditLogFileName     string
+	auditLogArchiveDir   string
+	inventoryFileName    string
+	pricingFileName      string
+	stockHistoryFileName string
+	anomalyFileName      string
+	// currency is the ISO 4217 currency of products that do not have one
+	currency       string
+	inventoryMutex sync.Mutex
+	auditLogMutex  sync.Mutex
+	pricingMutex   sync.Mutex
+	// negativeStockHandling is how stock deltas that would drive the units
+	// on hand below zero are handled, see NegativeStockFlag
+	negativeStockHandling string
+	expiryWarningDays     int
+	expiryCheckInterval   time.Duration
+	// auditLogRetentionDays is the number of days audit log entries are
+	// kept in the audit log file before they are archived, 0 keeps them
+	auditLogRetentionDays   int
+	auditLogArchiveInterval time.Duration
+	// stockHistoryRetentionDays is the number of days stock history points
+	// are kept, 0 keeps them
+	stockHistoryRetentionDays int
+	notificationCategory      string
+	notificationLabels        []string
+	notificationSender        string
+	notificationSeverity      string
 }
 
-func NewController(lc logger.LoggingClient, service interfaces.ApplicationService, auditLogFileName string, inventoryFileName string) Controller {
+func NewController(lc logger.LoggingClient, service interfaces.ApplicationService, auditLogFileName string, inventoryFileName string, pricingFileName string, stockHistoryFileName string, anomalyFileName string) Controller {
 	return Controller{
-		lc:                lc,
-		service:           service,
-		inventoryFileName: inventoryFileName,
-		auditLogFileName:  auditLogFileName,
+		lc:                        lc,
+		service:                   service,
+		inventoryFileName:         inventoryFileName,
+		auditLogFileName:          auditLogFileName,
+		auditLogArchiveDir:        filepath.Join(filepath.Dir(auditLogFileName), defaultAuditLogArchiveDir),
+		pricingFileName:           pricingFileName,
+		stockHistoryFileName:      stockHistoryFileName,
+		anomalyFileName:           anomalyFileName,
+		currency:                  defaultCurrency,
+		negativeStockHandling:     NegativeStockFlag,
+		expiryWarningDays:         defaultExpiryWarningDays,
+		expiryCheckInterval:       defaultExpiryCheckInterval,
+		auditLogArchiveInterval:   defaultAuditLogArchiveInterval,
+		stockHistoryRetentionDays: defaultStockHistoryRetention,
+		notificationCategory:      defaultNotificationCategory,
+		notificationLabels:        []string{defaultNotificationCategory},
+		notificationSender:        defaultNotificationSender,
+		notificationSeverity:      defaultNotificationSeverity,
+	}
+}
+
+// LoadSettings reads the optional ApplicationSettings of this service. Every
+// setting that is not present keeps the default assigned by NewController
+func (c *Controller) LoadSettings() error {
+	c.notificationClient = c.service.NotificationClient()
+	if c.notificationClient == nil {
+		c.lc.Warn("support-notifications client is not configured, inventory notifications will only be logged")
+	}
+
+	if value, ok := c.optionalAppSetting("Currency"); ok {
+		if !validCurrency(value) {
+			return fmt.Errorf("Currency from ApplicationSettings is not a supported ISO 4217 currency code: %s", value)
+		}
+		c.currency = value
+	}
+
+	if value, ok := c.optionalAppSetting("NegativeStockHandling"); ok {
+		if !validNegativeStockHandling(value) {
+			return fmt.Errorf("NegativeStockHandling from ApplicationSettings must be one of %s, %s or %s: %s", NegativeStockFlag, NegativeStockClamp, NegativeStockReject, value)
+		}
+		c.negativeStockHandling = value
+	}
+
+	if value, ok := c.optionalAppSetting("ExpiryWarningDays"); ok {
+		days, err := strconv.Atoi(value)
+		if err != nil || days < 0 {
+			return fmt.Errorf("ExpiryWarningDays from ApplicationSettings is not a valid number of days: %s", value)
+		}
+		c.expiryWarningDays = days
+	}
+
+	if value, ok := c.optionalAppSetting("ExpiryCheckInterval"); ok {
+		interval, err := time.ParseDuration(value)
+		if err != nil || interval <= 0 {
+			return fmt.Errorf("ExpiryCheckInterval from ApplicationSettings is not a valid duration: %s", value)
+		}
+		c.expiryCheckInterval = interval
+	}
+
+	if value, ok := c.optionalAppSetting("AuditLogRetentionDays"); ok {
+		days, err := strconv.Atoi(value)
+		if err != nil || days < 0 {
+			return fmt.Errorf("AuditLogRetentionDays from ApplicationSettings is not a valid number of days: %s", value)
+		}
+		c.auditLogRetentionDays = days
+	}
+
+	if value, ok := c.optionalAppSetting("AuditLogArchiveDir"); ok {
+		c.auditLogArchiveDir = value
+	}
+
+	if value, ok := c.optionalAppSetting("AuditLogArchiveInterval"); ok {
+		interval, err := time.ParseDuration(value)
+		if err != nil || interval <= 0 {
+			return fmt.Errorf("AuditLogArchiveInterval from ApplicationSettings is not a valid duration: %s", value)
+		}
+		c.auditLogArchiveInterval = interval
+	}
+
+	if value, ok := c.optionalAppSetting("StockHistoryRetentionDays"); ok {
+		days, err := strconv.Atoi(value)
+		if err != nil || days < 0 {
+			return fmt.Errorf("StockHistoryRetentionDays from ApplicationSettings is not a valid number of days: %s", value)
+		}
+		c.stockHistoryRetentionDays = days
 	}
+
+	if value, ok := c.optionalAppSetting("NotificationCategory"); ok {
+		c.notificationCategory = value
+	}
+	if labels, err := c.service.GetAppSettingStrings("NotificationLabels"); err == nil && len(labels) > 0 {
+		c.notificationLabels = labels
+	}
+	if value, ok := c.optionalAppSetting("NotificationSender"); ok {
+		c.notificationSender = value
+	}
+	if value, ok := c.optionalAppSetting("NotificationSeverity"); ok {
+		c.notificationSeverity = value
+	}
+
+	return nil
+}
+
+// optionalAppSetting returns the value of an ApplicationSettings entry and
+// whether it is set to a non-empty value
+func (c *Controller) optionalAppSetting(name string) (string, bool) {
+	value, err := c.service.GetAppSetting(name)
+	if err != nil || len(value) == 0 {
+		return "", false
+	}
+	return value, true
 }
 
 func (c *Controller) AddAllRoutes() error {
@@ -47,6 +195,12 @@ func (c *Controller) AddAllRoutes() error {
 		return errWithMsg
 	}
 
+	// must be added before /inventory/{sku} so that it is not matched as a SKU
+	err = c.service.AddRoute("/inventory/expiring", c.InventoryExpiringGet, http.MethodGet)
+	if errWithMsg := c.errorAddRouteHandler(err); errWithMsg != nil {
+		return errWithMsg
+	}
+
 	err = c.service.AddRoute("/inventory/{sku}", c.InventoryItemGet, http.MethodGet)
 	if errWithMsg := c.errorAddRouteHandler(err); errWithMsg != nil {
 		return errWithMsg
@@ -57,6 +211,61 @@ func (c *Controller) AddAllRoutes() error {
 		return errWithMsg
 	}
 
+	err = c.service.AddRoute("/inventory/{sku}/history", c.InventoryHistoryGet, http.MethodGet)
+	if errWithMsg := c.errorAddRouteHandler(err); errWithMsg != nil {
+		return errWithMsg
+	}
+
+	err = c.service.AddRoute("/inventory/{sku}/lots", c.LotsPost, http.MethodPost)
+	if errWithMsg := c.errorAddRouteHandler(err); errWithMsg != nil {
+		return errWithMsg
+	}
+
+	err = c.service.AddRoute("/inventory/{sku}/count", c.InventoryCountPost, http.MethodPost)
+	if errWithMsg := c.errorAddRouteHandler(err); errWithMsg != nil {
+		return errWithMsg
+	}
+
+	err = c.service.AddRoute("/fleet/stock", c.FleetStockGet, http.MethodGet)
+	if errWithMsg := c.errorAddRouteHandler(err); errWithMsg != nil {
+		return errWithMsg
+	}
+
+	err = c.service.AddRoute("/fleet/restock", c.FleetRestockGet, http.MethodGet)
+	if errWithMsg := c.errorAddRouteHandler(err); errWithMsg != nil {
+		return errWithMsg
+	}
+
+	err = c.service.AddRoute("/anomalies", c.AnomaliesGet, http.MethodGet)
+	if errWithMsg := c.errorAddRouteHandler(err); errWithMsg != nil {
+		return errWithMsg
+	}
+
+	err = c.service.AddRoute("/anomalies/{anomalyid}", c.AnomalyDelete, http.MethodDelete)
+	if errWithMsg := c.errorAddRouteHandler(err); errWithMsg != nil {
+		return errWithMsg
+	}
+
+	err = c.service.AddRoute("/pricing/rules", c.PricingRulesGet, http.MethodGet)
+	if errWithMsg := c.errorAddRouteHandler(err); errWithMsg != nil {
+		return errWithMsg
+	}
+
+	err = c.service.AddRoute("/pricing/rules", c.PricingRulesPost, http.MethodPost)
+	if errWithMsg := c.errorAddRouteHandler(err); errWithMsg != nil {
+		return errWithMsg
+	}
+
+	err = c.service.AddRoute("/pricing/rules/{ruleid}", c.PricingRuleDelete, http.MethodDelete)
+	if errWithMsg := c.errorAddRouteHandler(err); errWithMsg != nil {
+		return errWithMsg
+	}
+
+	err = c.service.AddRoute("/pricing/basket", c.BasketPricePost, http.MethodPost)
+	if errWithMsg := c.errorAddRouteHandler(err); errWithMsg != nil {
+		return errWithMsg
+	}
+
 	err = c.service.AddRoute("/auditlog", c.AuditLogGetAll, http.MethodGet)
 	if errWithMsg := c.errorAddRouteHandler(err); errWithMsg != nil {
 		return errWithMsg
@@ -67,6 +276,11 @@ func (c *Controller) AddAllRoutes() error {
 		return errWithMsg
 	}
 
+	err = c.service.AddRoute("/auditlog/verify", c.AuditLogVerifyGet, http.MethodGet)
+	if errWithMsg := c.errorAddRouteHandler(err); errWithMsg != nil {
+		return errWithMsg
+	}
+
 	err = c.service.AddRoute("/auditlog/{entry}", c.AuditLogGetEntry, http.MethodGet)
 	if errWithMsg := c.errorAddRouteHandler(err); errWithMsg != nil {
 		return errWithMsg
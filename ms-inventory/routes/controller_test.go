@@ -6,6 +6,7 @@ package routes
 import (
 	"fmt"
 	"testing"
+	"time"
 
 	"github.com/edgexfoundry/app-functions-sdk-go/v3/pkg/interfaces/mocks"
 	"github.com/edgexfoundry/go-mod-core-contracts/v3/clients/logger"
@@ -57,3 +58,88 @@ func TestController_AddAllRoutes(t *testing.T) {
 		})
 	}
 }
+
+func TestController_LoadSettings(t *testing.T) {
+	tests := []struct {
+		name             string
+		settings         map[string]string
+		expectError      bool
+		expectedDays     int
+		expectedInterval time.Duration
+		expectedSeverity string
+		expectedHandling string
+		expectedCurrency string
+	}{
+		{
+			name:             "defaults",
+			settings:         map[string]string{},
+			expectedDays:     defaultExpiryWarningDays,
+			expectedInterval: defaultExpiryCheckInterval,
+			expectedSeverity: defaultNotificationSeverity,
+			expectedHandling: NegativeStockFlag,
+			expectedCurrency: defaultCurrency,
+		},
+		{
+			name:             "valid settings",
+			settings:         map[string]string{"ExpiryWarningDays": "7", "ExpiryCheckInterval": "10m", "NotificationSeverity": "CRITICAL", "NegativeStockHandling": "reject", "Currency": "EUR"},
+			expectedDays:     7,
+			expectedInterval: 10 * time.Minute,
+			expectedSeverity: "CRITICAL",
+			expectedHandling: NegativeStockReject,
+			expectedCurrency: "EUR",
+		},
+		{
+			name:        "invalid ExpiryWarningDays",
+			settings:    map[string]string{"ExpiryWarningDays": "soon"},
+			expectError: true,
+		},
+		{
+			name:        "invalid ExpiryCheckInterval",
+			settings:    map[string]string{"ExpiryCheckInterval": "0s"},
+			expectError: true,
+		},
+		{
+			name:        "invalid Currency",
+			settings:    map[string]string{"Currency": "dollars"},
+			expectError: true,
+		},
+		{
+			name:        "invalid StockHistoryRetentionDays",
+			settings:    map[string]string{"StockHistoryRetentionDays": "-1"},
+			expectError: true,
+		},
+		{
+			name:        "invalid NegativeStockHandling",
+			settings:    map[string]string{"NegativeStockHandling": "ignore"},
+			expectError: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockAppService := &mocks.ApplicationService{}
+			mockAppService.On("NotificationClient").Return(nil)
+			mockAppService.On("GetAppSettingStrings", mock.Anything).Return(nil, fmt.Errorf("not found"))
+			mockAppService.On("GetAppSetting", mock.Anything).Return(func(name string) (string, error) {
+				if value, ok := tt.settings[name]; ok {
+					return value, nil
+				}
+				return "", fmt.Errorf("%s not found", name)
+			})
+
+			c := NewController(logger.NewMockClient(), mockAppService, AuditLogFileName, InventoryFileName, PricingFileName, StockHistoryFileName, AnomalyFileName)
+			err := c.LoadSettings()
+
+			if tt.expectError {
+				require.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, tt.expectedDays, c.expiryWarningDays)
+			require.Equal(t, tt.expectedInterval, c.expiryCheckInterval)
+			require.Equal(t, tt.expectedSeverity, c.notificationSeverity)
+			require.Equal(t, tt.expectedHandling, c.negativeStockHandling)
+			require.Equal(t, tt.expectedCurrency, c.currency)
+		})
+	}
+}
@@ -0,0 +1,44 @@
+// Copyright © 2026 Intel Corporation. All rights reserved.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package routes
+
+import (
+	"errors"
+
+	"golang.org/x/text/currency"
+)
+
+// defaultCurrency is the ISO 4217 currency of products that do not have one
+const defaultCurrency = "USD"
+
+// errMixedCurrencies is returned when a basket contains products that are
+// priced in different currencies
+var errMixedCurrencies = errors.New("products are priced in different currencies")
+
+// minorUnitDigits returns the number of decimal places of the minor unit of
+// an ISO 4217 currency, such as 2 for the cents of USD, from the CLDR
+// currency data that the ledger and vending services use as well
+func minorUnitDigits(code string) (int, bool) {
+	unit, err := currency.ParseISO(code)
+	if err != nil || unit.String() != code {
+		return 0, false
+	}
+	digits, _ := currency.Standard.Rounding(unit)
+	return digits, true
+}
+
+// validCurrency returns true if the code is a known ISO 4217 currency
+func validCurrency(code string) bool {
+	_, ok := minorUnitDigits(code)
+	return ok
+}
+
+// currencyOf returns the currency of the product, which is the default
+// currency when the product does not have one
+func (product *Product) currencyOf(fallback string) string {
+	if product.Currency != "" {
+		return product.Currency
+	}
+	return fallback
+}
@@ -0,0 +1,39 @@
+// Copyright © 2026 Intel Corporation. All rights reserved.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package routes
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestMinorUnitDigits tests the minor units of ISO 4217 currencies and that
+// codes which are not ISO 4217 currencies are rejected
+func TestMinorUnitDigits(t *testing.T) {
+	tests := []struct {
+		Code           string
+		ExpectedDigits int
+		ExpectedValid  bool
+	}{
+		{"USD", 2, true},
+		{"EUR", 2, true},
+		{"JPY", 0, true},
+		{"KRW", 0, true},
+		{"KWD", 3, true},
+		{"usd", 0, false},
+		{"dollars", 0, false},
+		{"ABC", 0, false},
+		{"", 0, false},
+	}
+
+	for _, test := range tests {
+		currentTest := test
+		t.Run(currentTest.Code, func(t *testing.T) {
+			digits, ok := minorUnitDigits(currentTest.Code)
+			assert.Equal(t, currentTest.ExpectedValid, ok)
+			assert.Equal(t, currentTest.ExpectedDigits, digits)
+		})
+	}
+}
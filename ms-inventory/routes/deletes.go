@@ -7,6 +7,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"time"
 
 	"github.com/gorilla/mux"
 )
@@ -22,15 +23,29 @@ func (c *Controller) InventoryDelete(writer http.ResponseWriter, req *http.Reque
 		writer.Write([]byte("Please enter a valid inventory item in the form of /inventory/{sku}"))
 		return
 	}
+
+	c.inventoryMutex.Lock()
+	defer c.inventoryMutex.Unlock()
+
 	// if the user wants to delete all inventory, do it
 	if SKU == DeleteAllQueryString {
-		err := c.DeleteInventory()
+		inventoryItems, err := c.GetInventoryItems()
+		if err != nil {
+			c.lc.Warnf("Failed to read the inventory before resetting it, its stock history will not be recorded: %s", err.Error())
+		}
+		err = c.DeleteInventory()
 		if err != nil {
 			c.lc.Errorf("Failed to properly reset inventory: %s", err.Error())
 			writer.WriteHeader(http.StatusInternalServerError)
 			writer.Write([]byte("Failed to properly reset inventory: " + err.Error()))
 			return
 		}
+		now := time.Now().UnixNano()
+		var stockPoints []StockHistoryPoint
+		for _, inventoryItem := range inventoryItems.Data {
+			stockPoints = append(stockPoints, deletedStockPoints(inventoryItem, now)...)
+		}
+		c.recordStockHistory(stockPoints...)
 		emptyInventoryResponseJSON, err := json.Marshal(Products{Data: []Product{}})
 		if err != nil {
 			c.lc.Errorf("Failed to serialize empty inventory response: %s", err.Error())
@@ -67,6 +82,7 @@ func (c *Controller) InventoryDelete(writer http.ResponseWriter, req *http.Reque
 		writer.Write([]byte("Failed to write updated inventory"))
 		return
 	}
+	c.recordStockHistory(deletedStockPoints(inventoryItemToDelete, time.Now().UnixNano())...)
 	inventoryItemToDeleteJSON, err := json.Marshal(inventoryItemToDelete)
 	if err != nil {
 		c.lc.Errorf("Successfully deleted the item from inventory, but failed to serialize it so that it could be sent back to the requester: %s", err.Error())
@@ -77,7 +93,9 @@ func (c *Controller) InventoryDelete(writer http.ResponseWriter, req *http.Reque
 	writer.Write(inventoryItemToDeleteJSON)
 }
 
-// AuditLogDelete allows deletion of one or more audit log entry items
+// AuditLogDelete allows deletion of one or more audit log entry items.
+// Entries are deleted by appending tombstones so that the hash chain of the
+// audit log stays intact
 func (c *Controller) AuditLogDelete(writer http.ResponseWriter, req *http.Request) {
 	// find the requested SKU and exit if it's invalid
 	vars := mux.Vars(req)
@@ -88,15 +106,42 @@ func (c *Controller) AuditLogDelete(writer http.ResponseWriter, req *http.Reques
 		writer.Write([]byte("Please enter a valid audit log entry ID in the form of /auditlog/{entryId}"))
 		return
 	}
+
+	c.auditLogMutex.Lock()
+	defer c.auditLogMutex.Unlock()
+
 	// if the user wants to delete all inventory, do it
 	if entryID == DeleteAllQueryString {
-		err := c.DeleteAuditLog()
+		auditLog, err := c.GetAuditLog()
 		if err != nil {
 			c.lc.Errorf("Failed to reset audit log: %s", err.Error())
 			writer.WriteHeader(http.StatusInternalServerError)
 			writer.Write([]byte("Failed to properly reset audit log: " + err.Error()))
 			return
 		}
+		c.auditLog = auditLog
+		// archived entries are deleted as well, oldest first
+		archivedEntries, err := c.liveArchivedEntries(auditLog)
+		if err != nil {
+			c.lc.Errorf("Failed to reset audit log: %s", err.Error())
+			writer.WriteHeader(http.StatusInternalServerError)
+			writer.Write([]byte("Failed to properly reset audit log: " + err.Error()))
+			return
+		}
+		for _, auditLogEntry := range append(archivedEntries, auditLog.liveEntries()...) {
+			if err := c.DeleteAuditLogEntry(auditLogEntry); err != nil {
+				c.lc.Errorf("Failed to reset audit log: %s", err.Error())
+				writer.WriteHeader(http.StatusInternalServerError)
+				writer.Write([]byte("Failed to properly reset audit log: " + err.Error()))
+				return
+			}
+		}
+		if err := c.WriteAuditLog(); err != nil {
+			c.lc.Errorf("Failed to reset audit log: %s", err.Error())
+			writer.WriteHeader(http.StatusInternalServerError)
+			writer.Write([]byte("Failed to properly reset audit log: " + err.Error()))
+			return
+		}
 		emptyAuditLogResponseJSON, err := json.Marshal(AuditLog{Data: []AuditLogEntry{}})
 		if err != nil {
 			c.lc.Errorf("Failed to serialize empty audit log response: %s", err.Error())
@@ -117,15 +162,37 @@ func (c *Controller) AuditLogDelete(writer http.ResponseWriter, req *http.Reques
 		return
 	}
 
-	// check if GetAuditLogEntryByID found an audit log entry to delete
+	// an entry that is not in the audit log file may have been archived
 	if auditLogEntryToDelete.AuditEntryID == "" {
-		c.lc.Errorf("Item with entry ID: %s does not exist", auditLogEntryToDelete.AuditEntryID)
+		archivedEntries, err := c.liveArchivedEntries(auditLog)
+		if err != nil {
+			c.lc.Errorf("Failed to get archived audit log entry ID: %s with error: %s", entryID, err.Error())
+			writer.WriteHeader(http.StatusInternalServerError)
+			writer.Write([]byte("Failed to get requested audit log entry by ID: " + err.Error()))
+			return
+		}
+		for _, auditLogEntry := range archivedEntries {
+			if auditLogEntry.AuditEntryID == entryID {
+				auditLogEntryToDelete = auditLogEntry
+				break
+			}
+		}
+	}
+
+	// check if an audit log entry to delete was found
+	if auditLogEntryToDelete.AuditEntryID == "" {
+		c.lc.Errorf("Item with entry ID: %s does not exist", entryID)
 		writer.WriteHeader(http.StatusNotFound)
 		writer.Write([]byte("Item does not exist"))
 		return
 	}
 	// delete the audit log entry & write the modified audit log
-	c.DeleteAuditLogEntry(auditLogEntryToDelete)
+	if err := c.DeleteAuditLogEntry(auditLogEntryToDelete); err != nil {
+		c.lc.Errorf("Failed to delete audit log entry: %s", err.Error())
+		writer.WriteHeader(http.StatusInternalServerError)
+		writer.Write([]byte("Failed to delete audit log entry: " + err.Error()))
+		return
+	}
 	err = c.WriteAuditLog()
 	if err != nil {
 		c.lc.Error("Failed to write updated audit log")
@@ -142,3 +209,132 @@ func (c *Controller) AuditLogDelete(writer http.ResponseWriter, req *http.Reques
 	c.lc.Infof("Succssfully deleted item: %s from audit log", auditLogEntryToDelete.AuditEntryID)
 	writer.Write(auditLogEntryToDeleteJSON)
 }
+
+// PricingRuleDelete allows deletion of a pricing rule, or of all pricing rules
+func (c *Controller) PricingRuleDelete(writer http.ResponseWriter, req *http.Request) {
+	vars := mux.Vars(req)
+	ruleID := vars["ruleid"]
+	if ruleID == "" {
+		c.lc.Error("Pricing rule ID is empty")
+		writer.WriteHeader(http.StatusBadRequest)
+		writer.Write([]byte("Please enter a valid pricing rule ID in the form of /pricing/rules/{ruleid}"))
+		return
+	}
+
+	c.pricingMutex.Lock()
+	defer c.pricingMutex.Unlock()
+
+	// if the user wants to delete all pricing rules, do it
+	if ruleID == DeleteAllQueryString {
+		if err := c.DeletePricingRules(); err != nil {
+			c.lc.Errorf("Failed to reset pricing rules: %s", err.Error())
+			writer.WriteHeader(http.StatusInternalServerError)
+			writer.Write([]byte("Failed to properly reset pricing rules: " + err.Error()))
+			return
+		}
+		emptyPricingRulesJSON, _ := json.Marshal(PricingRules{Data: []PricingRule{}})
+		c.lc.Info("Successfully deleted all pricing rules")
+		writer.Write(emptyPricingRulesJSON)
+		return
+	}
+
+	pricingRules, err := c.GetPricingRules()
+	if err != nil {
+		c.lc.Errorf("Failed to retrieve all pricing rules: %s", err.Error())
+		writer.WriteHeader(http.StatusInternalServerError)
+		writer.Write([]byte("Failed to retrieve all pricing rules: " + err.Error()))
+		return
+	}
+
+	for i, rule := range pricingRules.Data {
+		if rule.RuleID != ruleID {
+			continue
+		}
+		pricingRules.Data = append(pricingRules.Data[:i], pricingRules.Data[i+1:]...)
+		if err := c.WriteJSON(c.pricingFileName, pricingRules); err != nil {
+			c.lc.Errorf("Failed to write updated pricing rules: %s", err.Error())
+			writer.WriteHeader(http.StatusInternalServerError)
+			writer.Write([]byte("Failed to write updated pricing rules"))
+			return
+		}
+		ruleJSON, err := json.Marshal(rule)
+		if err != nil {
+			c.lc.Errorf("Successfully deleted pricing rule %s, but failed to serialize it so that it could be sent back to the requester: %s", ruleID, err.Error())
+			writer.WriteHeader(http.StatusInternalServerError)
+			writer.Write([]byte(fmt.Sprintf("Successfully deleted the pricing rule, but failed to serialize it so that it could be sent back to the requester: %v", err.Error())))
+			return
+		}
+		c.lc.Infof("Successfully deleted pricing rule %s", ruleID)
+		writer.Write(ruleJSON)
+		return
+	}
+
+	c.lc.Infof("Pricing rule %s does not exist", ruleID)
+	writer.WriteHeader(http.StatusNotFound)
+	writer.Write([]byte("Pricing rule does not exist"))
+}
+
+// AnomalyDelete removes a stock anomaly from the queue once it has been
+// reviewed, or every anomaly when the anomaly ID is "all"
+func (c *Controller) AnomalyDelete(writer http.ResponseWriter, req *http.Request) {
+	vars := mux.Vars(req)
+	anomalyID := vars["anomalyid"]
+	if anomalyID == "" {
+		c.lc.Error("Anomaly ID is empty")
+		writer.WriteHeader(http.StatusBadRequest)
+		writer.Write([]byte("Please enter a valid anomaly ID in the form of /anomalies/{anomalyid}"))
+		return
+	}
+
+	c.inventoryMutex.Lock()
+	defer c.inventoryMutex.Unlock()
+
+	// if the user wants to delete all anomalies, do it
+	if anomalyID == DeleteAllQueryString {
+		if err := c.WriteJSON(c.anomalyFileName, StockAnomalies{Data: []StockAnomaly{}}); err != nil {
+			c.lc.Errorf("Failed to reset stock anomalies: %s", err.Error())
+			writer.WriteHeader(http.StatusInternalServerError)
+			writer.Write([]byte("Failed to properly reset stock anomalies: " + err.Error()))
+			return
+		}
+		emptyAnomaliesJSON, _ := json.Marshal(StockAnomalies{Data: []StockAnomaly{}})
+		c.lc.Info("Successfully deleted all stock anomalies")
+		writer.Write(emptyAnomaliesJSON)
+		return
+	}
+
+	anomalies, err := c.GetAnomalies()
+	if err != nil {
+		c.lc.Errorf("Failed to retrieve stock anomalies: %s", err.Error())
+		writer.WriteHeader(http.StatusInternalServerError)
+		writer.Write([]byte("Failed to retrieve stock anomalies: " + err.Error()))
+		return
+	}
+
+	for i, anomaly := range anomalies.Data {
+		if anomaly.AnomalyID != anomalyID {
+			continue
+		}
+		anomalies.Data = append(anomalies.Data[:i], anomalies.Data[i+1:]...)
+		if err := c.WriteJSON(c.anomalyFileName, anomalies); err != nil {
+			c.lc.Errorf("Failed to write updated stock anomalies: %s", err.Error())
+			writer.WriteHeader(http.StatusInternalServerError)
+			writer.Write([]byte("Failed to write updated stock anomalies"))
+			return
+		}
+		anomalyJSON, err := json.Marshal(anomaly)
+		if err != nil {
+			c.lc.Errorf("Successfully deleted stock anomaly %s, but failed to serialize it so that it could be sent back to the requester: %s", anomalyID, err.Error())
+			writer.WriteHeader(http.StatusInternalServerError)
+			writer.Write([]byte(fmt.Sprintf("Successfully deleted the stock anomaly, but failed to serialize it so that it could be sent back to the requester: %v", err.Error())))
+			return
+		}
+		c.lc.Infof("Successfully deleted stock anomaly %s", anomalyID)
+		writer.Write(anomalyJSON)
+		return
+	}
+
+	c.lc.Infof("Stock anomaly %s does not exist", anomalyID)
+	writer.WriteHeader(http.StatusNotFound)
+	writer.Write([]byte("Stock anomaly does not exist"))
+}
@@ -159,3 +159,92 @@ func TestAuditLogDelete(t *testing.T) {
 		})
 	}
 }
+
+// TestPricingRuleDelete tests the function PricingRuleDelete
+func TestPricingRuleDelete(t *testing.T) {
+	c := Controller{
+		lc:              logger.NewMockClient(),
+		service:         nil,
+		pricingFileName: PricingFileName,
+	}
+	tests := []struct {
+		Name               string
+		RuleID             string
+		ExpectedStatusCode int
+		ExpectedRules      int
+	}{
+		{"delete rule", "happy-hour", http.StatusOK, 1},
+		{"delete all rules", DeleteAllQueryString, http.StatusOK, 0},
+		{"unknown rule", "unknown", http.StatusNotFound, 2},
+		{"empty rule ID", "", http.StatusBadRequest, 2},
+	}
+
+	for _, test := range tests {
+		currentTest := test
+		t.Run(currentTest.Name, func(t *testing.T) {
+			err := c.WriteJSON(c.pricingFileName, PricingRules{Data: []PricingRule{
+				{RuleID: "happy-hour", Type: PercentDiscountRuleType, Percent: 20},
+				{RuleID: "2-for-3", Type: BundleRuleType, BundleQuantity: 2, Price: 3},
+			}})
+			require.NoError(t, err)
+			defer func() {
+				_ = os.Remove(c.pricingFileName)
+			}()
+
+			req := httptest.NewRequest("DELETE", "http://localhost:48095/pricing/rules/"+currentTest.RuleID, nil)
+			req = mux.SetURLVars(req, map[string]string{"ruleid": currentTest.RuleID})
+			w := httptest.NewRecorder()
+			c.PricingRuleDelete(w, req)
+			resp := w.Result()
+			defer resp.Body.Close()
+
+			require.Equal(t, currentTest.ExpectedStatusCode, resp.StatusCode, "invalid status code")
+			pricingRules, err := c.GetPricingRules()
+			require.NoError(t, err)
+			require.Len(t, pricingRules.Data, currentTest.ExpectedRules)
+		})
+	}
+}
+
+// TestAnomalyDelete tests the function AnomalyDelete
+func TestAnomalyDelete(t *testing.T) {
+	c := Controller{
+		lc:              logger.NewMockClient(),
+		service:         nil,
+		anomalyFileName: AnomalyFileName,
+	}
+	tests := []struct {
+		Name               string
+		AnomalyID          string
+		ExpectedStatusCode int
+		ExpectedAnomalies  int
+	}{
+		{"with valid anomaly ID", "1", http.StatusOK, 1},
+		{"with unknown anomaly ID", "3", http.StatusNotFound, 2},
+		{"with missing anomaly ID", "", http.StatusBadRequest, 2},
+		{"with all parameter", "all", http.StatusOK, 0},
+	}
+
+	for _, test := range tests {
+		currentTest := test
+		t.Run(currentTest.Name, func(t *testing.T) {
+			err := c.WriteJSON(c.anomalyFileName, StockAnomalies{Data: []StockAnomaly{{AnomalyID: "1"}, {AnomalyID: "2"}}})
+			require.NoError(t, err)
+			defer func() {
+				_ = os.Remove(c.anomalyFileName)
+			}()
+
+			req := httptest.NewRequest("DELETE", "http://localhost:48095/anomalies/"+currentTest.AnomalyID, nil)
+			req = mux.SetURLVars(req, map[string]string{"anomalyid": currentTest.AnomalyID})
+			w := httptest.NewRecorder()
+			c.AnomalyDelete(w, req)
+			resp := w.Result()
+			defer resp.Body.Close()
+
+			require.Equal(t, currentTest.ExpectedStatusCode, resp.StatusCode, "invalid status code")
+			anomalies, err := c.GetAnomalies()
+			require.NoError(t, err)
+			require.Len(t, anomalies.Data, currentTest.ExpectedAnomalies)
+		})
+	}
+}
@@ -0,0 +1,176 @@
+// Copyright © 2026 Intel Corporation. All rights reserved.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package routes
+
+import (
+	"sort"
+)
+
+// DefaultMachineID identifies the default machine in fleet reports. Its stock
+// is kept in the UnitsOnHand and Lots of the Product itself, so that
+// deployments with a single machine do not need a machine ID
+const DefaultMachineID = "default"
+
+// normalizeMachineID maps the default machine ID to the empty machine ID that
+// is used to store the default machine's stock
+func normalizeMachineID(machineID string) string {
+	if machineID == DefaultMachineID {
+		return ""
+	}
+	return machineID
+}
+
+// reportedMachineID maps the empty machine ID of the default machine to
+// DefaultMachineID for fleet reports
+func reportedMachineID(machineID string) string {
+	if machineID == "" {
+		return DefaultMachineID
+	}
+	return machineID
+}
+
+// machineStock returns the stock of the product in the machine, or nil if
+// the machine has never been stocked with it
+func (product *Product) machineStock(machineID string) *MachineStock {
+	for i := range product.Machines {
+		if product.Machines[i].MachineID == machineID {
+			return &product.Machines[i]
+		}
+	}
+	return nil
+}
+
+// unitsOnHand returns the units on hand of the product in the machine
+func (product *Product) unitsOnHand(machineID string) int {
+	machineID = normalizeMachineID(machineID)
+	if machineID == "" {
+		return product.UnitsOnHand
+	}
+	if stock := product.machineStock(machineID); stock != nil {
+		return stock.UnitsOnHand
+	}
+	return 0
+}
+
+// applyStockDelta changes the units on hand of the product in the machine,
+// and keeps the lots of that machine in line with the change
+func (product *Product) applyStockDelta(machineID string, delta int, now int64) {
+	machineID = normalizeMachineID(machineID)
+	if machineID == "" {
+		product.UnitsOnHand += delta
+		product.applyLotDelta(delta, now)
+		return
+	}
+
+	stock := product.machineStock(machineID)
+	if stock == nil {
+		product.Machines = append(product.Machines, MachineStock{MachineID: machineID})
+		stock = &product.Machines[len(product.Machines)-1]
+	}
+	// the lots of every machine follow the shelf life of the catalog product
+	machineProduct := Product{SKU: product.SKU, ShelfLifeDays: product.ShelfLifeDays, Lots: stock.Lots}
+	machineProduct.applyLotDelta(delta, now)
+	stock.Lots = machineProduct.Lots
+	stock.UnitsOnHand += delta
+	stock.UpdatedAt = now
+}
+
+// receiveMachineLot receives a lot of the product in the machine
+func (product *Product) receiveMachineLot(machineID string, lot Lot, now int64) {
+	machineID = normalizeMachineID(machineID)
+	if machineID == "" {
+		product.receiveLot(lot, now)
+		product.UnitsOnHand += lot.Quantity
+		return
+	}
+
+	stock := product.machineStock(machineID)
+	if stock == nil {
+		product.Machines = append(product.Machines, MachineStock{MachineID: machineID})
+		stock = &product.Machines[len(product.Machines)-1]
+	}
+	machineProduct := Product{SKU: product.SKU, ShelfLifeDays: product.ShelfLifeDays, Lots: stock.Lots}
+	machineProduct.receiveLot(lot, now)
+	stock.Lots = machineProduct.Lots
+	stock.UnitsOnHand += lot.Quantity
+	stock.UpdatedAt = now
+}
+
+// machineLots is the lots of a product in a single machine
+type machineLots struct {
+	machineID string
+	lots      []Lot
+}
+
+// lotsByMachine returns the lots of the product in every machine, starting
+// with the default machine. The returned slices share their elements with
+// the product, so lots can be updated through them
+func (product *Product) lotsByMachine() []machineLots {
+	allLots := []machineLots{{machineID: "", lots: product.Lots}}
+	for _, stock := range product.Machines {
+		allLots = append(allLots, machineLots{machineID: stock.MachineID, lots: stock.Lots})
+	}
+	return allLots
+}
+
+// stockLevels returns the units on hand of the product in every machine,
+// starting with the default machine. The default machine is left out when
+// it has never been stocked with the product and other machines have been
+func (product *Product) stockLevels() []MachineStockLevel {
+	var levels []MachineStockLevel
+	if product.UnitsOnHand != 0 || len(product.Lots) > 0 || len(product.Machines) == 0 {
+		levels = append(levels, MachineStockLevel{MachineID: DefaultMachineID, UnitsOnHand: product.UnitsOnHand})
+	}
+	for _, stock := range product.Machines {
+		levels = append(levels, MachineStockLevel{MachineID: stock.MachineID, UnitsOnHand: stock.UnitsOnHand})
+	}
+	return levels
+}
+
+// fleetStock returns the total units on hand of every product across the
+// fleet
+func (products *Products) fleetStock() FleetStock {
+	fleetStock := FleetStock{Data: []FleetStockItem{}}
+	for _, product := range products.Data {
+		item := FleetStockItem{
+			SKU:         product.SKU,
+			ProductName: product.ProductName,
+			Machines:    product.stockLevels(),
+		}
+		for _, level := range item.Machines {
+			item.TotalUnitsOnHand += level.UnitsOnHand
+		}
+		fleetStock.Data = append(fleetStock.Data, item)
+	}
+	return fleetStock
+}
+
+// fleetRestock returns every product whose units on hand in a machine are at
+// or below its minimum restocking level, grouped by machine. Only products
+// that a machine has been stocked with are considered
+func (products *Products) fleetRestock() FleetRestock {
+	fleetRestock := FleetRestock{Data: []MachineRestock{}}
+	for _, product := range products.Data {
+		if !product.IsActive {
+			continue
+		}
+		for _, level := range product.stockLevels() {
+			if level.UnitsOnHand > product.MinRestockingLevel {
+				continue
+			}
+			fleetRestock.Data = append(fleetRestock.Data, MachineRestock{
+				MachineID:          level.MachineID,
+				SKU:                product.SKU,
+				ProductName:        product.ProductName,
+				UnitsOnHand:        level.UnitsOnHand,
+				MinRestockingLevel: product.MinRestockingLevel,
+				MaxRestockingLevel: product.MaxRestockingLevel,
+			})
+		}
+	}
+	sort.SliceStable(fleetRestock.Data, func(i, j int) bool {
+		return fleetRestock.Data[i].MachineID < fleetRestock.Data[j].MachineID
+	})
+	return fleetRestock
+}
@@ -0,0 +1,64 @@
+// Copyright © 2026 Intel Corporation. All rights reserved.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package routes
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestApplyStockDelta tests that stock changes only affect the given machine
+func TestApplyStockDelta(t *testing.T) {
+	product := Product{SKU: "4900002470", UnitsOnHand: 3, ShelfLifeDays: 7}
+
+	product.applyStockDelta("machine-7", 5, testNow)
+	product.applyStockDelta(DefaultMachineID, -1, testNow)
+	product.applyStockDelta("machine-7", -2, testNow)
+
+	assert.Equal(t, 2, product.UnitsOnHand)
+	assert.Equal(t, 2, product.unitsOnHand(""))
+	assert.Equal(t, 3, product.unitsOnHand("machine-7"))
+	assert.Equal(t, 0, product.unitsOnHand("machine-8"))
+	require.Len(t, product.Machines, 1)
+	require.Len(t, product.Machines[0].Lots, 1, "the machine's lots should follow the product's shelf life")
+	assert.Equal(t, 3, product.Machines[0].Lots[0].Quantity)
+	assert.Equal(t, testNow+7*nanosecondsPerDay, product.Machines[0].Lots[0].ExpiresAt)
+	assert.Empty(t, product.Lots, "the default machine has no tracked lots")
+}
+
+// TestFleetStock tests the total stock per SKU across the fleet
+func TestFleetStock(t *testing.T) {
+	products := getDefaultProductsList()
+	products.Data[0].UnitsOnHand = 2
+	products.Data[0].Machines = []MachineStock{{MachineID: "machine-7", UnitsOnHand: 4}, {MachineID: "machine-8", UnitsOnHand: 1}}
+	products.Data[1].Machines = []MachineStock{{MachineID: "machine-7", UnitsOnHand: 6}}
+
+	fleetStock := products.fleetStock()
+	require.Len(t, fleetStock.Data, len(products.Data))
+	assert.Equal(t, 7, fleetStock.Data[0].TotalUnitsOnHand)
+	assert.Equal(t, []MachineStockLevel{{DefaultMachineID, 2}, {"machine-7", 4}, {"machine-8", 1}}, fleetStock.Data[0].Machines)
+	assert.Equal(t, 6, fleetStock.Data[1].TotalUnitsOnHand)
+	assert.Equal(t, []MachineStockLevel{{"machine-7", 6}}, fleetStock.Data[1].Machines, "an unstocked default machine should be left out")
+}
+
+// TestFleetRestock tests finding the machines at or below minimum stock
+func TestFleetRestock(t *testing.T) {
+	products := Products{Data: []Product{
+		{SKU: "4900002470", ProductName: "Sprite", IsActive: true, MinRestockingLevel: 2, UnitsOnHand: 5,
+			Machines: []MachineStock{{MachineID: "machine-8", UnitsOnHand: 2}, {MachineID: "machine-7", UnitsOnHand: 3}}},
+		{SKU: "1200010735", ProductName: "Mountain Dew", IsActive: true, MinRestockingLevel: 1,
+			Machines: []MachineStock{{MachineID: "machine-7", UnitsOnHand: 0}}},
+		{SKU: "1200050408", ProductName: "Discontinued", IsActive: false, MinRestockingLevel: 1},
+	}}
+
+	fleetRestock := products.fleetRestock()
+	require.Len(t, fleetRestock.Data, 2)
+	assert.Equal(t, "machine-7", fleetRestock.Data[0].MachineID)
+	assert.Equal(t, "1200010735", fleetRestock.Data[0].SKU)
+	assert.Equal(t, "machine-8", fleetRestock.Data[1].MachineID)
+	assert.Equal(t, "4900002470", fleetRestock.Data[1].SKU)
+	assert.Equal(t, 2, fleetRestock.Data[1].UnitsOnHand)
+}
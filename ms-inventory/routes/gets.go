@@ -5,7 +5,11 @@ package routes
 
 import (
 	"encoding/json"
+	"fmt"
 	"net/http"
+	"path/filepath"
+	"strconv"
+	"time"
 
 	"github.com/gorilla/mux"
 )
@@ -69,10 +73,89 @@ func (c *Controller) InventoryItemGet(writer http.ResponseWriter, req *http.Requ
 	writer.Write([]byte("Please enter a valid inventory item in the form of /inventory/{sku}"))
 }
 
-// AuditLogGetAll allows all audit log entries to be retrieved
+// InventoryExpiringGet returns the lots that expire within the number of days
+// given by the optional "days" query parameter, or within ExpiryWarningDays
+// if it is not set. Expired lots are always included. The optional
+// "machineId" query parameter limits the lots to a single machine
+func (c *Controller) InventoryExpiringGet(writer http.ResponseWriter, req *http.Request) {
+	days := c.expiryWarningDays
+	if daysStr := req.URL.Query().Get("days"); daysStr != "" {
+		var err error
+		days, err = strconv.Atoi(daysStr)
+		if err != nil || days < 0 {
+			c.lc.Errorf("Invalid number of days for the expiring inventory report: %s", daysStr)
+			writer.WriteHeader(http.StatusBadRequest)
+			writer.Write([]byte("Please enter a valid number of days in the form of /inventory/expiring?days={days}"))
+			return
+		}
+	}
+
+	inventoryItems, err := c.CheckLotExpiry()
+	if err != nil {
+		c.lc.Errorf("Failed to check inventory lots for expiry: %s", err.Error())
+		writer.WriteHeader(http.StatusInternalServerError)
+		writer.Write([]byte("Failed to check inventory lots for expiry: " + err.Error()))
+		return
+	}
+
+	expiringLots := inventoryItems.expiringLots(time.Now().UnixNano(), days)
+	if machineIDs, ok := req.URL.Query()["machineId"]; ok {
+		machineID := normalizeMachineID(machineIDs[0])
+		machineLots := []ExpiringLot{}
+		for _, lot := range expiringLots {
+			if lot.MachineID == machineID {
+				machineLots = append(machineLots, lot)
+			}
+		}
+		expiringLots = machineLots
+	}
+
+	expiringLotsJSON, err := json.Marshal(ExpiringLots{Data: expiringLots})
+	if err != nil {
+		c.lc.Errorf("Failed to process expiring inventory lots: %s", err.Error())
+		writer.WriteHeader(http.StatusInternalServerError)
+		writer.Write([]byte("Failed to process expiring inventory lots: " + err.Error()))
+		return
+	}
+	c.lc.Infof("Successfully retrieved inventory lots expiring within %d days", days)
+	writer.Write(expiringLotsJSON)
+}
+
+// AuditLogGetAll allows all audit log entries to be retrieved. When a from
+// or to date is given, the archives are searched as well
 func (c *Controller) AuditLogGetAll(writer http.ResponseWriter, req *http.Request) {
-	auditLog, err := c.GetAuditLog()
-	c.auditLog = auditLog
+	query := req.URL.Query()
+	includeDeleted, _ := strconv.ParseBool(query.Get("includeDeleted"))
+
+	var auditLog AuditLog
+	var err error
+	if query.Has("from") || query.Has("to") {
+		from, to := int64(0), time.Now().UnixNano()
+		if query.Has("from") {
+			if from, err = parseHistoryTime(query.Get("from")); err != nil {
+				c.lc.Errorf("Invalid from query parameter: %s", err.Error())
+				writer.WriteHeader(http.StatusBadRequest)
+				writer.Write([]byte("Invalid from query parameter: " + err.Error()))
+				return
+			}
+		}
+		if query.Has("to") {
+			if to, err = parseHistoryTime(query.Get("to")); err != nil {
+				c.lc.Errorf("Invalid to query parameter: %s", err.Error())
+				writer.WriteHeader(http.StatusBadRequest)
+				writer.Write([]byte("Invalid to query parameter: " + err.Error()))
+				return
+			}
+		}
+		auditLog, err = c.GetAuditLogRange(from, to, includeDeleted)
+	} else {
+		auditLog, err = c.GetAuditLog()
+		c.auditLog = auditLog
+		// tombstones and the entries they deleted are only returned on request
+		if err == nil && !includeDeleted {
+			auditLog = AuditLog{Data: auditLog.liveEntries()}
+		}
+	}
 	if err != nil {
 		c.lc.Errorf("Failed to retrieve all audit log entries: %s", err.Error())
 		writer.WriteHeader(http.StatusInternalServerError)
@@ -80,6 +163,18 @@ func (c *Controller) AuditLogGetAll(writer http.ResponseWriter, req *http.Reques
 		return
 	}
 
+	// limit the audit log to a single machine of the fleet if requested
+	if machineIDs, ok := req.URL.Query()["machineId"]; ok {
+		machineID := normalizeMachineID(machineIDs[0])
+		machineAuditLog := AuditLog{Data: []AuditLogEntry{}}
+		for _, auditLogEntry := range auditLog.Data {
+			if normalizeMachineID(auditLogEntry.MachineID) == machineID {
+				machineAuditLog.Data = append(machineAuditLog.Data, auditLogEntry)
+			}
+		}
+		auditLog = machineAuditLog
+	}
+
 	// No logic needs to be done here, since we are just reading the file
 	// and writing it back out. Simply marshaling it will validate its structure
 	auditLogJSON, err := json.Marshal(auditLog)
@@ -93,6 +188,46 @@ func (c *Controller) AuditLogGetAll(writer http.ResponseWriter, req *http.Reques
 	writer.Write(auditLogJSON)
 }
 
+// AuditLogVerifyGet verifies the hash chain of the audit log, from the
+// oldest archive to the audit log file, and reports the first broken link,
+// if any
+func (c *Controller) AuditLogVerifyGet(writer http.ResponseWriter, req *http.Request) {
+	segments, err := c.getAuditLogChain(0)
+	if err != nil {
+		c.lc.Errorf("Failed to retrieve all audit log entries: %s", err.Error())
+		writer.WriteHeader(http.StatusInternalServerError)
+		writer.Write([]byte("Failed to retrieve all audit log entries: " + err.Error()))
+		return
+	}
+
+	chain := joinAuditLogSegments(segments)
+	verification := chain.verify()
+	if !verification.Valid {
+		// report the archive that holds the broken link
+		offset := 0
+		for _, segment := range segments {
+			if verification.BrokenLink.Index < offset+len(segment.auditLog.Data) {
+				if segment.fileName != c.auditLogFileName {
+					verification.BrokenLink.Archive = filepath.Base(segment.fileName)
+				}
+				break
+			}
+			offset += len(segment.auditLog.Data)
+		}
+
+		c.lc.Warnf("Audit log hash chain is broken at entry %d (%s): %s", verification.BrokenLink.Index, verification.BrokenLink.AuditEntryID, verification.BrokenLink.Reason)
+	}
+	verificationJSON, err := json.Marshal(verification)
+	if err != nil {
+		c.lc.Errorf("Failed to process audit log verification: %s", err.Error())
+		writer.WriteHeader(http.StatusInternalServerError)
+		writer.Write([]byte("Failed to process audit log verification: " + err.Error()))
+		return
+	}
+	c.lc.Infof("Successfully verified audit log")
+	writer.Write(verificationJSON)
+}
+
 // AuditLogGetEntry allows a single audit log entry to be retrieved by its
 // UUID
 func (c *Controller) AuditLogGetEntry(writer http.ResponseWriter, req *http.Request) {
@@ -126,3 +261,175 @@ func (c *Controller) AuditLogGetEntry(writer http.ResponseWriter, req *http.Requ
 	writer.WriteHeader(http.StatusBadRequest)
 	writer.Write([]byte("Please enter a valid entry ID in the form of /auditlog/{entry}"))
 }
+
+// PricingRulesGet allows for the retrieval of all pricing rules
+func (c *Controller) PricingRulesGet(writer http.ResponseWriter, req *http.Request) {
+	pricingRules, err := c.GetPricingRules()
+	if err != nil {
+		c.lc.Errorf("Failed to retrieve all pricing rules: %s", err.Error())
+		writer.WriteHeader(http.StatusInternalServerError)
+		writer.Write([]byte("Failed to retrieve all pricing rules: " + err.Error()))
+		return
+	}
+
+	pricingRulesJSON, err := json.Marshal(pricingRules)
+	if err != nil {
+		c.lc.Errorf("Failed to process all pricing rules: %s", err.Error())
+		writer.WriteHeader(http.StatusInternalServerError)
+		writer.Write([]byte("Failed to process pricing rules: " + err.Error()))
+		return
+	}
+	c.lc.Infof("Successfully retrieved all pricing rules")
+	writer.Write(pricingRulesJSON)
+}
+
+// InventoryHistoryGet returns the stock history of a single SKU in the machine
+// given by the optional "machineId" query parameter. The optional
+// "from" and "to" query parameters bound the history and default to the week
+// up to now. When the optional "interval" query parameter is set, the points
+// are also summarized in buckets of that duration
+func (c *Controller) InventoryHistoryGet(writer http.ResponseWriter, req *http.Request) {
+	vars := mux.Vars(req)
+	sku := vars["sku"]
+	if sku == "" {
+		c.lc.Error("Valid inventory item not in the form of /inventory/{sku}/history")
+		writer.WriteHeader(http.StatusBadRequest)
+		writer.Write([]byte("Please enter a valid inventory item in the form of /inventory/{sku}/history"))
+		return
+	}
+
+	query := req.URL.Query()
+	to := time.Now().UnixNano()
+	if toStr := query.Get("to"); toStr != "" {
+		var err error
+		if to, err = parseHistoryTime(toStr); err != nil {
+			c.lc.Errorf("Invalid end of the stock history: %s", err.Error())
+			writer.WriteHeader(http.StatusBadRequest)
+			writer.Write([]byte("Invalid to query parameter: " + err.Error()))
+			return
+		}
+	}
+	from := to - int64(defaultHistoryWindow)
+	if fromStr := query.Get("from"); fromStr != "" {
+		var err error
+		if from, err = parseHistoryTime(fromStr); err != nil {
+			c.lc.Errorf("Invalid start of the stock history: %s", err.Error())
+			writer.WriteHeader(http.StatusBadRequest)
+			writer.Write([]byte("Invalid from query parameter: " + err.Error()))
+			return
+		}
+	}
+	if from >= to {
+		c.lc.Errorf("Invalid stock history range from %d to %d", from, to)
+		writer.WriteHeader(http.StatusBadRequest)
+		writer.Write([]byte("The from query parameter must be before the to query parameter"))
+		return
+	}
+
+	var interval time.Duration
+	if intervalStr := query.Get("interval"); intervalStr != "" {
+		var err error
+		interval, err = time.ParseDuration(intervalStr)
+		if err != nil || interval <= 0 || (to-from)/int64(interval) >= maxHistoryBuckets {
+			c.lc.Errorf("Invalid stock history interval: %s", intervalStr)
+			writer.WriteHeader(http.StatusBadRequest)
+			writer.Write([]byte(fmt.Sprintf("Please enter a valid interval duration, such as 1h, that results in less than %d buckets", maxHistoryBuckets)))
+			return
+		}
+	}
+
+	stockHistory, err := c.GetStockHistory()
+	if err != nil {
+		c.lc.Errorf("Failed to retrieve the stock history: %s", err.Error())
+		writer.WriteHeader(http.StatusInternalServerError)
+		writer.Write([]byte("Failed to retrieve the stock history: " + err.Error()))
+		return
+	}
+
+	reportJSON, err := json.Marshal(stockHistory.report(sku, query.Get("machineId"), from, to, interval))
+	if err != nil {
+		c.lc.Errorf("Failed to process the stock history of %s: %s", sku, err.Error())
+		writer.WriteHeader(http.StatusInternalServerError)
+		writer.Write([]byte("Failed to process the stock history: " + err.Error()))
+		return
+	}
+	c.lc.Infof("Successfully retrieved the stock history of %s", sku)
+	writer.Write(reportJSON)
+}
+
+// FleetStockGet returns the total units on hand of every product across all
+// machines of the fleet, broken down by machine
+func (c *Controller) FleetStockGet(writer http.ResponseWriter, req *http.Request) {
+	inventoryItems, err := c.GetInventoryItems()
+	if err != nil {
+		c.lc.Errorf("Failed to retrieve all inventory items: %s", err.Error())
+		writer.WriteHeader(http.StatusInternalServerError)
+		writer.Write([]byte("Failed to retrieve all inventory items: " + err.Error()))
+		return
+	}
+
+	fleetStockJSON, err := json.Marshal(inventoryItems.fleetStock())
+	if err != nil {
+		c.lc.Errorf("Failed to process the fleet stock: %s", err.Error())
+		writer.WriteHeader(http.StatusInternalServerError)
+		writer.Write([]byte("Failed to process the fleet stock: " + err.Error()))
+		return
+	}
+	c.lc.Infof("Successfully retrieved the fleet stock")
+	writer.Write(fleetStockJSON)
+}
+
+// FleetRestockGet returns every active product that a machine of the fleet
+// holds at or below its minimum restocking level
+func (c *Controller) FleetRestockGet(writer http.ResponseWriter, req *http.Request) {
+	inventoryItems, err := c.GetInventoryItems()
+	if err != nil {
+		c.lc.Errorf("Failed to retrieve all inventory items: %s", err.Error())
+		writer.WriteHeader(http.StatusInternalServerError)
+		writer.Write([]byte("Failed to retrieve all inventory items: " + err.Error()))
+		return
+	}
+
+	fleetRestockJSON, err := json.Marshal(inventoryItems.fleetRestock())
+	if err != nil {
+		c.lc.Errorf("Failed to process the machines below minimum: %s", err.Error())
+		writer.WriteHeader(http.StatusInternalServerError)
+		writer.Write([]byte("Failed to process the machines below minimum: " + err.Error()))
+		return
+	}
+	c.lc.Infof("Successfully retrieved the machines below minimum")
+	writer.Write(fleetRestockJSON)
+}
+
+// AnomaliesGet allows for the retrieval of the stock anomaly queue. The
+// optional "machineId" query parameter limits the queue to a single machine
+func (c *Controller) AnomaliesGet(writer http.ResponseWriter, req *http.Request) {
+	anomalies, err := c.GetAnomalies()
+	if err != nil {
+		c.lc.Errorf("Failed to retrieve stock anomalies: %s", err.Error())
+		writer.WriteHeader(http.StatusInternalServerError)
+		writer.Write([]byte("Failed to retrieve stock anomalies: " + err.Error()))
+		return
+	}
+
+	if machineIDs, ok := req.URL.Query()["machineId"]; ok {
+		machineID := normalizeMachineID(machineIDs[0])
+		machineAnomalies := StockAnomalies{Data: []StockAnomaly{}}
+		for _, anomaly := range anomalies.Data {
+			if anomaly.MachineID == machineID {
+				machineAnomalies.Data = append(machineAnomalies.Data, anomaly)
+			}
+		}
+		anomalies = machineAnomalies
+	}
+
+	anomaliesJSON, err := json.Marshal(anomalies)
+	if err != nil {
+		c.lc.Errorf("Failed to process stock anomalies: %s", err.Error())
+		writer.WriteHeader(http.StatusInternalServerError)
+		writer.Write([]byte("Failed to process stock anomalies: " + err.Error()))
+		return
+	}
+	c.lc.Infof("Successfully retrieved stock anomalies")
+	writer.Write(anomaliesJSON)
+}
@@ -4,9 +4,12 @@
 package routes
 
 import (
+	"encoding/json"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/edgexfoundry/go-mod-core-contracts/v3/clients/logger"
@@ -116,12 +119,74 @@ func TestInventoryItemGet(t *testing.T) {
 	}
 }
 
+// TestInventoryExpiringGet tests the function InventoryExpiringGet
+func TestInventoryExpiringGet(t *testing.T) {
+	// Product slice
+	products := getDefaultProductsList()
+	products.Data[0].Lots = []Lot{{LotID: "expired", Quantity: 1, ReceivedAt: 1, ExpiresAt: 2}}
+	c := Controller{
+		lc:                logger.NewMockClient(),
+		service:           nil,
+		inventoryItems:    products,
+		inventoryFileName: InventoryFileName,
+	}
+	tests := []struct {
+		Name               string
+		BadInventory       bool
+		Query              string
+		ExpectedStatusCode int
+		ExpectedLots       int
+	}{
+		{"with default days", false, "", http.StatusOK, 1},
+		{"with days query", false, "?days=10", http.StatusOK, 1},
+		{"with invalid days query", false, "?days=soon", http.StatusBadRequest, 0},
+		{"with negative days query", false, "?days=-1", http.StatusBadRequest, 0},
+		{"with invalid inventory json", true, "", http.StatusInternalServerError, 0},
+	}
+
+	for _, test := range tests {
+		currentTest := test
+		t.Run(currentTest.Name, func(t *testing.T) {
+			err := c.DeleteInventory()
+			require.NoError(t, err)
+
+			if currentTest.BadInventory {
+				err := os.WriteFile(c.inventoryFileName, []byte("invalid json test"), 0644)
+				require.NoError(t, err)
+			} else {
+				err := c.WriteInventory()
+				require.NoError(t, err)
+			}
+			defer func() {
+				_ = os.Remove(c.inventoryFileName)
+			}()
+
+			req := httptest.NewRequest("GET", "http://localhost:48095/inventory/expiring"+currentTest.Query, nil)
+			w := httptest.NewRecorder()
+			c.InventoryExpiringGet(w, req)
+			resp := w.Result()
+			defer resp.Body.Close()
+
+			require.Equal(t, currentTest.ExpectedStatusCode, resp.StatusCode, "invalid status code")
+			if resp.StatusCode == http.StatusOK {
+				var expiringLots ExpiringLots
+				err := json.NewDecoder(resp.Body).Decode(&expiringLots)
+				require.NoError(t, err)
+				require.Len(t, expiringLots.Data, currentTest.ExpectedLots)
+				require.True(t, expiringLots.Data[0].Expired)
+				require.True(t, expiringLots.Data[0].FlaggedForRemoval)
+			}
+		})
+	}
+}
+
 // TestAuditLogGetAll tests the ability to get all audit logs
 // related functions
 func TestAuditLogGetAll(t *testing.T) {
 	// Audit slice
 
 	audits := getDefaultAuditsList()
+	audits.Data[0].MachineID = "machine-7"
 	c := Controller{
 		lc:               logger.NewMockClient(),
 		service:          nil,
@@ -131,10 +196,14 @@ func TestAuditLogGetAll(t *testing.T) {
 	tests := []struct {
 		Name               string
 		BadAuditLog        bool
+		Query              string
 		ExpectedStatusCode int
+		ExpectedEntries    int
 	}{
-		{"AuditLogGetAll", false, http.StatusOK},
-		{"with invalid audit log json", true, http.StatusInternalServerError},
+		{"AuditLogGetAll", false, "", http.StatusOK, len(audits.Data)},
+		{"for a machine", false, "?machineId=machine-7", http.StatusOK, 1},
+		{"for the default machine", false, "?machineId=default", http.StatusOK, len(audits.Data) - 1},
+		{"with invalid audit log json", true, "", http.StatusInternalServerError, 0},
 	}
 
 	for _, test := range tests {
@@ -154,17 +223,84 @@ func TestAuditLogGetAll(t *testing.T) {
 				_ = os.Remove(c.auditLogFileName)
 			}()
 
-			req := httptest.NewRequest("GET", "http://localhost:48096/auditlog", nil)
+			req := httptest.NewRequest("GET", "http://localhost:48096/auditlog"+currentTest.Query, nil)
 			w := httptest.NewRecorder()
 			c.AuditLogGetAll(w, req)
 			resp := w.Result()
 			defer resp.Body.Close()
 
 			require.Equal(t, currentTest.ExpectedStatusCode, resp.StatusCode, "invalid status code")
+			if resp.StatusCode == http.StatusOK {
+				var auditLog AuditLog
+				require.NoError(t, json.NewDecoder(resp.Body).Decode(&auditLog))
+				require.Len(t, auditLog.Data, currentTest.ExpectedEntries)
+			}
 		})
 	}
 }
 
+// TestAuditLogGetAllArchived tests that date ranges of the audit log include
+// archived entries, and that the chain is verified across the archives
+func TestAuditLogGetAllArchived(t *testing.T) {
+	c := newArchiveTestController(t, getArchivableAuditLog(t))
+	_, err := c.ArchiveAuditLog(testNow)
+	require.NoError(t, err)
+
+	tests := []struct {
+		Name               string
+		Query              string
+		ExpectedStatusCode int
+		ExpectedEntries    int
+	}{
+		{"audit log file only", "", http.StatusOK, 1},
+		{"from date", fmt.Sprintf("?from=%d", testNow-45*nanosecondsPerDay), http.StatusOK, 3},
+		{"to date", fmt.Sprintf("?to=%d", testNow), http.StatusOK, 4},
+		{"invalid from date", "?from=yesterday", http.StatusBadRequest, 0},
+		{"invalid to date", "?to=tomorrow", http.StatusBadRequest, 0},
+	}
+
+	for _, test := range tests {
+		currentTest := test
+		t.Run(currentTest.Name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "http://localhost:48096/auditlog"+currentTest.Query, nil)
+			w := httptest.NewRecorder()
+			c.AuditLogGetAll(w, req)
+			resp := w.Result()
+			defer resp.Body.Close()
+
+			require.Equal(t, currentTest.ExpectedStatusCode, resp.StatusCode, "invalid status code")
+			if resp.StatusCode == http.StatusOK {
+				var auditLog AuditLog
+				require.NoError(t, json.NewDecoder(resp.Body).Decode(&auditLog))
+				require.Len(t, auditLog.Data, currentTest.ExpectedEntries)
+			}
+		})
+	}
+
+	t.Run("verify across archives", func(t *testing.T) {
+		archives, err := c.getAuditLogArchives()
+		require.NoError(t, err)
+		archive, err := readAuditLogArchive(archives[1].fileName)
+		require.NoError(t, err)
+		archive.Data[0].CardID = "0000000000"
+		require.NoError(t, writeAuditLogArchive(archives[1].fileName, archive))
+
+		req := httptest.NewRequest("GET", "http://localhost:48096/auditlog/verify", nil)
+		w := httptest.NewRecorder()
+		c.AuditLogVerifyGet(w, req)
+		resp := w.Result()
+		defer resp.Body.Close()
+
+		require.Equal(t, http.StatusOK, resp.StatusCode, "invalid status code")
+		var verification AuditLogVerification
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&verification))
+		require.False(t, verification.Valid)
+		require.NotNil(t, verification.BrokenLink)
+		require.Equal(t, 1, verification.BrokenLink.Index)
+		require.Equal(t, filepath.Base(archives[1].fileName), verification.BrokenLink.Archive)
+	})
+}
+
 // TestAuditLogGetEntry tests the ability to get all audit logs
 // related functions
 func TestAuditLogGetEntry(t *testing.T) {
@@ -222,3 +358,250 @@ func TestAuditLogGetEntry(t *testing.T) {
 		})
 	}
 }
+
+// TestAuditLogVerifyGet tests the function AuditLogVerifyGet
+func TestAuditLogVerifyGet(t *testing.T) {
+	c := Controller{
+		lc:               logger.NewMockClient(),
+		service:          nil,
+		auditLogFileName: AuditLogFileName,
+	}
+	tampered := getChainedAuditsList(t)
+	tampered.Data[1].CardID = "0000000000"
+
+	tests := []struct {
+		Name               string
+		AuditLog           AuditLog
+		BadAuditLog        bool
+		ExpectedStatusCode int
+		ExpectedValid      bool
+	}{
+		{"valid chain", getChainedAuditsList(t), false, http.StatusOK, true},
+		{"tampered chain", tampered, false, http.StatusOK, false},
+		{"with invalid audit log json", AuditLog{}, true, http.StatusInternalServerError, false},
+	}
+
+	for _, test := range tests {
+		currentTest := test
+		t.Run(currentTest.Name, func(t *testing.T) {
+			c.auditLog = currentTest.AuditLog
+			if currentTest.BadAuditLog {
+				err := os.WriteFile(c.auditLogFileName, []byte("invalid json test"), 0644)
+				require.NoError(t, err)
+			} else {
+				err := c.WriteAuditLog()
+				require.NoError(t, err)
+			}
+			defer func() {
+				_ = os.Remove(c.auditLogFileName)
+			}()
+
+			req := httptest.NewRequest("GET", "http://localhost:48096/auditlog/verify", nil)
+			w := httptest.NewRecorder()
+			c.AuditLogVerifyGet(w, req)
+			resp := w.Result()
+			defer resp.Body.Close()
+
+			require.Equal(t, currentTest.ExpectedStatusCode, resp.StatusCode, "invalid status code")
+			if resp.StatusCode == http.StatusOK {
+				var verification AuditLogVerification
+				require.NoError(t, json.NewDecoder(resp.Body).Decode(&verification))
+				require.Equal(t, currentTest.ExpectedValid, verification.Valid)
+				if !currentTest.ExpectedValid {
+					require.NotNil(t, verification.BrokenLink)
+					require.Equal(t, 1, verification.BrokenLink.Index)
+				}
+			}
+		})
+	}
+}
+
+// TestAnomaliesGet tests the function AnomaliesGet
+func TestAnomaliesGet(t *testing.T) {
+	c := Controller{
+		lc:              logger.NewMockClient(),
+		service:         nil,
+		anomalyFileName: AnomalyFileName,
+	}
+	err := c.WriteJSON(c.anomalyFileName, StockAnomalies{Data: []StockAnomaly{
+		{AnomalyID: "1", SKU: "4900002470", Delta: -8, Action: AnomalyActionFlagged},
+		{AnomalyID: "2", SKU: "4900002470", MachineID: "machine-7", Delta: -2, Action: AnomalyActionRejected},
+	}})
+	require.NoError(t, err)
+	defer func() {
+		_ = os.Remove(c.anomalyFileName)
+	}()
+
+	tests := []struct {
+		Name              string
+		Query             string
+		ExpectedAnomalies int
+	}{
+		{"all anomalies", "", 2},
+		{"for a machine", "?machineId=machine-7", 1},
+		{"for the default machine", "?machineId=default", 1},
+	}
+
+	for _, test := range tests {
+		currentTest := test
+		t.Run(currentTest.Name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "http://localhost:48095/anomalies"+currentTest.Query, nil)
+			w := httptest.NewRecorder()
+			c.AnomaliesGet(w, req)
+			resp := w.Result()
+			defer resp.Body.Close()
+
+			require.Equal(t, http.StatusOK, resp.StatusCode, "invalid status code")
+			var anomalies StockAnomalies
+			require.NoError(t, json.NewDecoder(resp.Body).Decode(&anomalies))
+			require.Len(t, anomalies.Data, currentTest.ExpectedAnomalies)
+		})
+	}
+}
+
+// TestPricingRulesGet tests the function PricingRulesGet
+func TestPricingRulesGet(t *testing.T) {
+	c := Controller{
+		lc:              logger.NewMockClient(),
+		service:         nil,
+		pricingFileName: PricingFileName,
+	}
+	tests := []struct {
+		Name               string
+		BadPricingRules    bool
+		ExpectedStatusCode int
+	}{
+		{"PricingRulesGet", false, http.StatusOK},
+		{"with invalid pricing rules json", true, http.StatusInternalServerError},
+	}
+
+	for _, test := range tests {
+		currentTest := test
+		t.Run(currentTest.Name, func(t *testing.T) {
+			if currentTest.BadPricingRules {
+				err := os.WriteFile(c.pricingFileName, []byte("invalid json test"), 0644)
+				require.NoError(t, err)
+			} else {
+				err := c.DeletePricingRules()
+				require.NoError(t, err)
+			}
+			defer func() {
+				_ = os.Remove(c.pricingFileName)
+			}()
+
+			req := httptest.NewRequest("GET", "http://localhost:48095/pricing/rules", nil)
+			w := httptest.NewRecorder()
+			c.PricingRulesGet(w, req)
+			resp := w.Result()
+			defer resp.Body.Close()
+
+			require.Equal(t, currentTest.ExpectedStatusCode, resp.StatusCode, "invalid status code")
+		})
+	}
+}
+
+// TestInventoryHistoryGet tests the function InventoryHistoryGet
+func TestInventoryHistoryGet(t *testing.T) {
+	c := Controller{
+		lc:                   logger.NewMockClient(),
+		service:              nil,
+		stockHistoryFileName: StockHistoryFileName,
+	}
+	tests := []struct {
+		Name               string
+		BadStockHistory    bool
+		SKU                string
+		Query              string
+		ExpectedStatusCode int
+		ExpectedPoints     int
+		ExpectedBuckets    int
+	}{
+		{"raw points", false, "4900002470", "?from=100&to=400", http.StatusOK, 3, 0},
+		{"with buckets", false, "4900002470", "?from=100&to=400&interval=100ns", http.StatusOK, 3, 3},
+		{"default range", false, "4900002470", "", http.StatusOK, 0, 0},
+		{"missing SKU", false, "", "", http.StatusBadRequest, 0, 0},
+		{"invalid from", false, "4900002470", "?from=yesterday", http.StatusBadRequest, 0, 0},
+		{"from after to", false, "4900002470", "?from=400&to=100", http.StatusBadRequest, 0, 0},
+		{"invalid interval", false, "4900002470", "?from=100&to=400&interval=hourly", http.StatusBadRequest, 0, 0},
+		{"too many buckets", false, "4900002470", "?from=0&to=1000000&interval=1ns", http.StatusBadRequest, 0, 0},
+		{"invalid stock history json", true, "4900002470", "?from=100&to=400", http.StatusInternalServerError, 0, 0},
+	}
+
+	for _, test := range tests {
+		currentTest := test
+		t.Run(currentTest.Name, func(t *testing.T) {
+			if currentTest.BadStockHistory {
+				err := os.WriteFile(c.stockHistoryFileName, []byte("invalid json test"), 0644)
+				require.NoError(t, err)
+			} else {
+				err := c.WriteJSON(c.stockHistoryFileName, getDefaultStockHistory())
+				require.NoError(t, err)
+			}
+			defer func() {
+				_ = os.Remove(c.stockHistoryFileName)
+			}()
+
+			req := httptest.NewRequest("GET", "http://localhost:48095/inventory/"+currentTest.SKU+"/history"+currentTest.Query, nil)
+			req = mux.SetURLVars(req, map[string]string{"sku": currentTest.SKU})
+			w := httptest.NewRecorder()
+			c.InventoryHistoryGet(w, req)
+			resp := w.Result()
+			defer resp.Body.Close()
+
+			require.Equal(t, currentTest.ExpectedStatusCode, resp.StatusCode, "invalid status code")
+			if resp.StatusCode == http.StatusOK {
+				var report StockHistoryReport
+				require.NoError(t, json.NewDecoder(resp.Body).Decode(&report))
+				require.Len(t, report.Points, currentTest.ExpectedPoints)
+				require.Len(t, report.Buckets, currentTest.ExpectedBuckets)
+			}
+		})
+	}
+}
+
+// TestFleetGets tests the functions FleetStockGet and FleetRestockGet
+func TestFleetGets(t *testing.T) {
+	products := getDefaultProductsList()
+	products.Data[0].Machines = []MachineStock{{MachineID: "machine-7", UnitsOnHand: 4}}
+	c := Controller{
+		lc:                logger.NewMockClient(),
+		service:           nil,
+		inventoryItems:    products,
+		inventoryFileName: InventoryFileName,
+	}
+	tests := []struct {
+		Name               string
+		Handler            func(http.ResponseWriter, *http.Request)
+		BadInventory       bool
+		ExpectedStatusCode int
+	}{
+		{"FleetStockGet", c.FleetStockGet, false, http.StatusOK},
+		{"FleetStockGet with invalid inventory json", c.FleetStockGet, true, http.StatusInternalServerError},
+		{"FleetRestockGet", c.FleetRestockGet, false, http.StatusOK},
+		{"FleetRestockGet with invalid inventory json", c.FleetRestockGet, true, http.StatusInternalServerError},
+	}
+
+	for _, test := range tests {
+		currentTest := test
+		t.Run(currentTest.Name, func(t *testing.T) {
+			if currentTest.BadInventory {
+				err := os.WriteFile(c.inventoryFileName, []byte("invalid json test"), 0644)
+				require.NoError(t, err)
+			} else {
+				err := c.WriteInventory()
+				require.NoError(t, err)
+			}
+			defer func() {
+				_ = os.Remove(c.inventoryFileName)
+			}()
+
+			req := httptest.NewRequest("GET", "http://localhost:48095/fleet", nil)
+			w := httptest.NewRecorder()
+			currentTest.Handler(w, req)
+			resp := w.Result()
+			defer resp.Body.Close()
+
+			require.Equal(t, currentTest.ExpectedStatusCode, resp.StatusCode, "invalid status code")
+		})
+	}
+}
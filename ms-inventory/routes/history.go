@@ -0,0 +1,199 @@
+// Copyright © 2026 Intel Corporation. All rights reserved.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package routes
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// Sources of stock history points
+const (
+	StockSourceDelta   = "delta"
+	StockSourcePost    = "post"
+	StockSourceCount   = "count"
+	StockSourceRestock = "restock"
+	StockSourceDelete  = "delete"
+)
+
+const (
+	defaultHistoryWindow = 7 * 24 * time.Hour
+	maxHistoryBuckets    = 10000
+)
+
+// newStockPoint returns a stock history point for the units on hand of the
+// product in the machine right after they changed by delta
+func newStockPoint(product Product, machineID string, delta int, source string, now int64) StockHistoryPoint {
+	return StockHistoryPoint{
+		SKU:         product.SKU,
+		MachineID:   normalizeMachineID(machineID),
+		Timestamp:   now,
+		UnitsOnHand: product.unitsOnHand(machineID),
+		Delta:       delta,
+		Source:      source,
+	}
+}
+
+// deletedStockPoints returns the stock history points of a product that is
+// deleted from the catalog, which empties it in every machine
+func deletedStockPoints(product Product, now int64) []StockHistoryPoint {
+	points := []StockHistoryPoint{newStockPoint(Product{SKU: product.SKU}, "", -product.UnitsOnHand, StockSourceDelete, now)}
+	for _, stock := range product.Machines {
+		points = append(points, newStockPoint(Product{SKU: product.SKU}, stock.MachineID, -stock.UnitsOnHand, StockSourceDelete, now))
+	}
+	return points
+}
+
+// GetStockHistory returns the stock history by reading the stock history JSON
+// file. A stock history file that does not exist yet is an empty history
+func (c *Controller) GetStockHistory() (stockHistory StockHistory, err error) {
+	data, err := os.ReadFile(c.stockHistoryFileName)
+	if errors.Is(err, fs.ErrNotExist) {
+		return StockHistory{Data: []StockHistoryPoint{}}, nil
+	}
+	if err != nil {
+		return stockHistory, fmt.Errorf("failed to read from stock history file: %s", err.Error())
+	}
+	if err := json.Unmarshal(data, &stockHistory); err != nil {
+		return stockHistory, fmt.Errorf("failed to unmarshal stock history file: %s", err.Error())
+	}
+
+	return
+}
+
+// recordStockHistory appends points to the stock history JSON file, and drops
+// the points older than the retention. It is called after the inventory has
+// been written, so a failure is only logged and does not fail the mutation.
+// Callers must hold the inventory mutex
+func (c *Controller) recordStockHistory(points ...StockHistoryPoint) {
+	if len(points) == 0 {
+		return
+	}
+
+	stockHistory, err := c.GetStockHistory()
+	if err != nil {
+		c.lc.Errorf("Failed to record stock history: %s", err.Error())
+		return
+	}
+	stockHistory.Data = append(stockHistory.Data, points...)
+	if c.stockHistoryRetentionDays > 0 {
+		stockHistory.prune(time.Now().AddDate(0, 0, -c.stockHistoryRetentionDays).UnixNano())
+	}
+	if err := c.WriteJSON(c.stockHistoryFileName, stockHistory); err != nil {
+		c.lc.Errorf("Failed to record stock history: %s", err.Error())
+	}
+}
+
+// prune drops the points before cutoff, except for the last one of every SKU
+// in every machine, which is the level that a report starting after cutoff
+// carries over
+func (stockHistory *StockHistory) prune(cutoff int64) {
+	type stockKey struct{ sku, machineID string }
+	last := map[stockKey]int{}
+	for i, point := range stockHistory.Data {
+		if point.Timestamp >= cutoff {
+			continue
+		}
+		key := stockKey{point.SKU, point.MachineID}
+		if j, found := last[key]; !found || point.Timestamp >= stockHistory.Data[j].Timestamp {
+			last[key] = i
+		}
+	}
+	kept := make([]StockHistoryPoint, 0, len(stockHistory.Data))
+	for i, point := range stockHistory.Data {
+		if point.Timestamp >= cutoff || last[stockKey{point.SKU, point.MachineID}] == i {
+			kept = append(kept, point)
+		}
+	}
+	stockHistory.Data = kept
+}
+
+// parseHistoryTime parses a stock history query time, given either as
+// nanoseconds since the epoch like every other timestamp of this service, or
+// as an RFC 3339 date
+func parseHistoryTime(value string) (int64, error) {
+	if nanos, err := strconv.ParseInt(value, 10, 64); err == nil {
+		return nanos, nil
+	}
+	parsed, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return 0, fmt.Errorf("%s is neither a timestamp in nanoseconds nor an RFC 3339 date", value)
+	}
+	return parsed.UnixNano(), nil
+}
+
+// report returns the points of the SKU in the machine between from and to,
+// and when interval is greater than 0, the points summarized in buckets of
+// interval. Buckets are aligned to from. A bucket before the first known
+// level of the SKU is left out since there is nothing to summarize
+func (stockHistory *StockHistory) report(sku string, machineID string, from int64, to int64, interval time.Duration) StockHistoryReport {
+	machineID = normalizeMachineID(machineID)
+	report := StockHistoryReport{
+		SKU:       sku,
+		MachineID: machineID,
+		From:      from,
+		To:        to,
+		Points:    []StockHistoryPoint{},
+	}
+
+	// the level at the start of the report is the last one before it
+	var level *int
+	var levelTimestamp int64
+	for _, point := range stockHistory.Data {
+		if point.SKU != sku || point.MachineID != machineID {
+			continue
+		}
+		if point.Timestamp < from {
+			if level == nil || point.Timestamp >= levelTimestamp {
+				unitsOnHand := point.UnitsOnHand
+				level = &unitsOnHand
+				levelTimestamp = point.Timestamp
+			}
+			continue
+		}
+		if point.Timestamp < to {
+			report.Points = append(report.Points, point)
+		}
+	}
+
+	sort.SliceStable(report.Points, func(i, j int) bool {
+		return report.Points[i].Timestamp < report.Points[j].Timestamp
+	})
+
+	if interval <= 0 {
+		return report
+	}
+	report.Interval = interval.String()
+	report.Buckets = []StockHistoryBucket{}
+
+	next := 0
+	for start := from; start < to; start += int64(interval) {
+		bucket := StockHistoryBucket{Start: start, End: min(start+int64(interval), to)}
+		if level != nil {
+			bucket.Min, bucket.Max, bucket.Close = *level, *level, *level
+		}
+		for ; next < len(report.Points) && report.Points[next].Timestamp < bucket.End; next++ {
+			unitsOnHand := report.Points[next].UnitsOnHand
+			if level == nil {
+				bucket.Min, bucket.Max = unitsOnHand, unitsOnHand
+			}
+			bucket.Min = min(bucket.Min, unitsOnHand)
+			bucket.Max = max(bucket.Max, unitsOnHand)
+			bucket.Close = unitsOnHand
+			bucket.Points++
+			level = &unitsOnHand
+		}
+		if level != nil {
+			report.Buckets = append(report.Buckets, bucket)
+		}
+	}
+
+	return report
+}
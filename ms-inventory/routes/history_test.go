@@ -0,0 +1,90 @@
+// Copyright © 2026 Intel Corporation. All rights reserved.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package routes
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func getDefaultStockHistory() StockHistory {
+	return StockHistory{Data: []StockHistoryPoint{
+		{SKU: "4900002470", Timestamp: 50, UnitsOnHand: 10, Delta: 10, Source: StockSourceRestock},
+		{SKU: "4900002470", Timestamp: 120, UnitsOnHand: 8, Delta: -2, Source: StockSourceDelta},
+		{SKU: "1200010735", Timestamp: 130, UnitsOnHand: 3, Delta: -1, Source: StockSourceDelta},
+		{SKU: "4900002470", Timestamp: 150, UnitsOnHand: 0, Delta: -8, Source: StockSourceDelta},
+		{SKU: "4900002470", Timestamp: 310, UnitsOnHand: 12, Delta: 12, Source: StockSourceRestock},
+	}}
+}
+
+// TestStockHistoryReport tests the raw points and buckets of a stock history
+// report
+func TestStockHistoryReport(t *testing.T) {
+	stockHistory := getDefaultStockHistory()
+
+	t.Run("raw points only", func(t *testing.T) {
+		report := stockHistory.report("4900002470", "", 100, 400, 0)
+		require.Len(t, report.Points, 3)
+		assert.Equal(t, int64(120), report.Points[0].Timestamp)
+		assert.Empty(t, report.Buckets)
+		assert.Empty(t, report.Interval)
+	})
+
+	t.Run("buckets carry the level over", func(t *testing.T) {
+		report := stockHistory.report("4900002470", "", 100, 400, 100)
+		assert.Equal(t, []StockHistoryBucket{
+			{Start: 100, End: 200, Min: 0, Max: 10, Close: 0, Points: 2},
+			{Start: 200, End: 300, Min: 0, Max: 0, Close: 0, Points: 0},
+			{Start: 300, End: 400, Min: 0, Max: 12, Close: 12, Points: 1},
+		}, report.Buckets)
+	})
+
+	t.Run("buckets before the first level are left out", func(t *testing.T) {
+		report := stockHistory.report("1200010735", "", 0, 200, 100)
+		assert.Equal(t, []StockHistoryBucket{
+			{Start: 100, End: 200, Min: 3, Max: 3, Close: 3, Points: 1},
+		}, report.Buckets)
+	})
+
+	t.Run("unknown SKU", func(t *testing.T) {
+		report := stockHistory.report("0000000000", "", 0, 400, 100)
+		assert.Empty(t, report.Points)
+		assert.Empty(t, report.Buckets)
+	})
+}
+
+// TestStockHistoryPrune tests that old points are dropped, except for the
+// level that a later report carries over
+func TestStockHistoryPrune(t *testing.T) {
+	stockHistory := getDefaultStockHistory()
+	stockHistory.prune(200)
+	assert.Equal(t, []StockHistoryPoint{
+		{SKU: "1200010735", Timestamp: 130, UnitsOnHand: 3, Delta: -1, Source: StockSourceDelta},
+		{SKU: "4900002470", Timestamp: 150, UnitsOnHand: 0, Delta: -8, Source: StockSourceDelta},
+		{SKU: "4900002470", Timestamp: 310, UnitsOnHand: 12, Delta: 12, Source: StockSourceRestock},
+	}, stockHistory.Data)
+
+	report := stockHistory.report("4900002470", "", 200, 400, 100)
+	assert.Equal(t, []StockHistoryBucket{
+		{Start: 200, End: 300, Min: 0, Max: 0, Close: 0, Points: 0},
+		{Start: 300, End: 400, Min: 0, Max: 12, Close: 12, Points: 1},
+	}, report.Buckets, "the level before the cutoff should be carried over")
+}
+
+// TestParseHistoryTime tests parsing timestamps and RFC 3339 dates
+func TestParseHistoryTime(t *testing.T) {
+	nanos, err := parseHistoryTime("1700000000000000000")
+	require.NoError(t, err)
+	assert.Equal(t, int64(1700000000000000000), nanos)
+
+	nanos, err = parseHistoryTime("2026-03-03T16:30:00Z")
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2026, time.March, 3, 16, 30, 0, 0, time.UTC).UnixNano(), nanos)
+
+	_, err = parseHistoryTime("last week")
+	require.Error(t, err)
+}
@@ -0,0 +1,197 @@
+// Copyright © 2026 Intel Corporation. All rights reserved.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package routes
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	nanosecondsPerDay = int64(24 * time.Hour)
+)
+
+// sortLotsFEFO orders lots first-expired-first-out. Lots without an expiry
+// date are depleted last, and ties are broken by the date they were received
+func sortLotsFEFO(lots []Lot) {
+	sort.SliceStable(lots, func(i, j int) bool {
+		if lots[i].ExpiresAt != lots[j].ExpiresAt {
+			if lots[i].ExpiresAt == 0 {
+				return false
+			}
+			if lots[j].ExpiresAt == 0 {
+				return true
+			}
+			return lots[i].ExpiresAt < lots[j].ExpiresAt
+		}
+		return lots[i].ReceivedAt < lots[j].ReceivedAt
+	})
+}
+
+// tracksLots returns true if the product's stock is broken down into lots
+func (product *Product) tracksLots() bool {
+	return product.ShelfLifeDays > 0 || len(product.Lots) > 0
+}
+
+// applyLotDelta keeps the product's lots in line with a change to its units
+// on hand. Removals deplete lots first-expired-first-out, and additions are
+// received as a new lot whose expiry is derived from the product's shelf life
+func (product *Product) applyLotDelta(delta int, now int64) {
+	if delta == 0 || !product.tracksLots() {
+		return
+	}
+	if delta > 0 {
+		product.receiveLot(Lot{Quantity: delta}, now)
+		return
+	}
+	product.depleteLots(-delta)
+}
+
+// receiveLot adds a lot to the product, filling in the lot ID, received date
+// and expiry date when they are not provided
+func (product *Product) receiveLot(lot Lot, now int64) Lot {
+	if lot.LotID == "" {
+		lot.LotID = uuid.New().String()
+	}
+	if lot.ReceivedAt == 0 {
+		lot.ReceivedAt = now
+	}
+	if lot.ExpiresAt == 0 && product.ShelfLifeDays > 0 {
+		lot.ExpiresAt = lot.ReceivedAt + int64(product.ShelfLifeDays)*nanosecondsPerDay
+	}
+	product.Lots = append(product.Lots, lot)
+	sortLotsFEFO(product.Lots)
+	return lot
+}
+
+// depleteLots removes units from the product's lots first-expired-first-out
+// and drops every lot that gets emptied. It returns the number of units that
+// could not be taken from any lot
+func (product *Product) depleteLots(units int) int {
+	sortLotsFEFO(product.Lots)
+	remainingLots := product.Lots[:0]
+	for _, lot := range product.Lots {
+		taken := min(units, lot.Quantity)
+		lot.Quantity -= taken
+		units -= taken
+		if lot.Quantity > 0 {
+			remainingLots = append(remainingLots, lot)
+		}
+	}
+	product.Lots = remainingLots
+	return units
+}
+
+// flagExpiringLots flags expired lots for removal during the next stocker
+// session and marks the lots that entered the expiry warning window. A
+// message is returned for every lot that changed so it can be notified
+func (products *Products) flagExpiringLots(now int64, warningDays int) []string {
+	horizon := now + int64(warningDays)*nanosecondsPerDay
+	var messages []string
+	for i := range products.Data {
+		product := &products.Data[i]
+		for _, machine := range product.lotsByMachine() {
+			location := ""
+			if machine.machineID != "" {
+				location = " in machine " + machine.machineID
+			}
+			for j := range machine.lots {
+				lot := &machine.lots[j]
+				switch {
+				case lot.ExpiresAt == 0:
+					continue
+				case lot.ExpiresAt <= now && !lot.FlaggedForRemoval:
+					lot.FlaggedForRemoval = true
+					messages = append(messages, fmt.Sprintf("Lot %s of %s (%s)%s has expired and its %d units are flagged for removal during the next stocker session",
+						lot.LotID, product.ProductName, product.SKU, location, lot.Quantity))
+				case lot.ExpiresAt > now && lot.ExpiresAt <= horizon && !lot.ExpiringSoonNotified:
+					lot.ExpiringSoonNotified = true
+					messages = append(messages, fmt.Sprintf("Lot %s of %s (%s)%s with %d units expires on %s",
+						lot.LotID, product.ProductName, product.SKU, location, lot.Quantity, time.Unix(0, lot.ExpiresAt).UTC().Format(time.RFC3339)))
+				}
+			}
+		}
+	}
+	return messages
+}
+
+// expiringLots returns every lot that expires within the given number of
+// days, including lots that have already expired, soonest expiry first.
+// Lots of machines other than the default machine carry their machine ID
+func (products *Products) expiringLots(now int64, days int) []ExpiringLot {
+	horizon := now + int64(days)*nanosecondsPerDay
+	report := []ExpiringLot{}
+	for _, product := range products.Data {
+		for _, machine := range product.lotsByMachine() {
+			for _, lot := range machine.lots {
+				if lot.ExpiresAt == 0 || lot.ExpiresAt > horizon {
+					continue
+				}
+				report = append(report, ExpiringLot{
+					SKU:         product.SKU,
+					ProductName: product.ProductName,
+					MachineID:   machine.machineID,
+					Lot:         lot,
+					Expired:     lot.ExpiresAt <= now,
+				})
+			}
+		}
+	}
+	sort.SliceStable(report, func(i, j int) bool {
+		return report[i].ExpiresAt < report[j].ExpiresAt
+	})
+	return report
+}
+
+// CheckLotExpiry flags expired and expiring lots in the inventory JSON file
+// and sends a notification for every lot that changed since the last check.
+// The notifications are sent once the inventory is unlocked
+func (c *Controller) CheckLotExpiry() (Products, error) {
+	inventoryItems, messages, err := c.writeExpiringLots()
+	if err != nil {
+		return Products{}, err
+	}
+	for _, message := range messages {
+		c.sendNotification(message)
+	}
+	return inventoryItems, nil
+}
+
+// writeExpiringLots flags expired and expiring lots in the inventory JSON
+// file, and returns the inventory with the messages of the flagged lots
+func (c *Controller) writeExpiringLots() (Products, []string, error) {
+	c.inventoryMutex.Lock()
+	defer c.inventoryMutex.Unlock()
+
+	inventoryItems, err := c.GetInventoryItems()
+	if err != nil {
+		return Products{}, nil, err
+	}
+
+	messages := inventoryItems.flagExpiringLots(time.Now().UnixNano(), c.expiryWarningDays)
+	if len(messages) == 0 {
+		return inventoryItems, nil, nil
+	}
+
+	if err := c.WriteJSON(c.inventoryFileName, inventoryItems); err != nil {
+		return Products{}, nil, err
+	}
+	return inventoryItems, messages, nil
+}
+
+// MonitorLotExpiry checks the inventory lots for expiry on every
+// ExpiryCheckInterval until the service exits
+func (c *Controller) MonitorLotExpiry() {
+	ticker := time.NewTicker(c.expiryCheckInterval)
+	defer ticker.Stop()
+	for {
+		if _, err := c.CheckLotExpiry(); err != nil {
+			c.lc.Errorf("Failed to check inventory lots for expiry: %s", err.Error())
+		}
+		<-ticker.C
+	}
+}
@@ -0,0 +1,212 @@
+// Copyright © 2026 Intel Corporation. All rights reserved.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package routes
+
+import (
+	"os"
+	"testing"
+
+	"github.com/edgexfoundry/go-mod-core-contracts/v3/clients/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testNow = int64(1700000000000000000)
+)
+
+func getDefaultLots() []Lot {
+	return []Lot{
+		{LotID: "no-expiry", Quantity: 2, ReceivedAt: testNow - 5*nanosecondsPerDay},
+		{LotID: "expires-late", Quantity: 3, ReceivedAt: testNow - 2*nanosecondsPerDay, ExpiresAt: testNow + 10*nanosecondsPerDay},
+		{LotID: "expires-soon", Quantity: 4, ReceivedAt: testNow - 4*nanosecondsPerDay, ExpiresAt: testNow + nanosecondsPerDay},
+		{LotID: "expired", Quantity: 1, ReceivedAt: testNow - 9*nanosecondsPerDay, ExpiresAt: testNow - nanosecondsPerDay},
+	}
+}
+
+// TestDepleteLots tests that lots are depleted first-expired-first-out
+func TestDepleteLots(t *testing.T) {
+	tests := []struct {
+		Name              string
+		Units             int
+		ExpectedLots      []string
+		ExpectedQuantity  int
+		ExpectedUntracked int
+	}{
+		{"deplete no units", 0, []string{"expired", "expires-soon", "expires-late", "no-expiry"}, 1, 0},
+		{"deplete the expired lot", 1, []string{"expires-soon", "expires-late", "no-expiry"}, 4, 0},
+		{"deplete across lots", 3, []string{"expires-soon", "expires-late", "no-expiry"}, 2, 0},
+		{"lots without expiry are depleted last", 8, []string{"no-expiry"}, 2, 0},
+		{"deplete more than tracked", 12, []string{}, 0, 2},
+	}
+
+	for _, test := range tests {
+		currentTest := test
+		t.Run(currentTest.Name, func(t *testing.T) {
+			product := Product{Lots: getDefaultLots()}
+			untracked := product.depleteLots(currentTest.Units)
+			assert.Equal(t, currentTest.ExpectedUntracked, untracked)
+
+			lotIDs := []string{}
+			for _, lot := range product.Lots {
+				lotIDs = append(lotIDs, lot.LotID)
+			}
+			require.Equal(t, currentTest.ExpectedLots, lotIDs)
+			if len(product.Lots) > 0 {
+				assert.Equal(t, currentTest.ExpectedQuantity, product.Lots[0].Quantity)
+			}
+		})
+	}
+
+	t.Run("deplete part of the expired lot", func(t *testing.T) {
+		lots := getDefaultLots()
+		lots[3].Quantity = 3
+		product := Product{Lots: lots}
+		assert.Equal(t, 0, product.depleteLots(2))
+		require.Len(t, product.Lots, 4)
+		assert.Equal(t, "expired", product.Lots[0].LotID)
+		assert.Equal(t, 1, product.Lots[0].Quantity)
+	})
+}
+
+// TestApplyLotDelta tests that deltas are only tracked in lots for
+// perishable products
+func TestApplyLotDelta(t *testing.T) {
+	t.Run("non-perishable product", func(t *testing.T) {
+		product := Product{}
+		product.applyLotDelta(5, testNow)
+		assert.Empty(t, product.Lots)
+	})
+
+	t.Run("received lot expires after the shelf life", func(t *testing.T) {
+		product := Product{ShelfLifeDays: 7}
+		product.applyLotDelta(5, testNow)
+		require.Len(t, product.Lots, 1)
+		assert.Equal(t, 5, product.Lots[0].Quantity)
+		assert.Equal(t, testNow, product.Lots[0].ReceivedAt)
+		assert.Equal(t, testNow+7*nanosecondsPerDay, product.Lots[0].ExpiresAt)
+		assert.NotEmpty(t, product.Lots[0].LotID)
+
+		product.applyLotDelta(-2, testNow)
+		require.Len(t, product.Lots, 1)
+		assert.Equal(t, 3, product.Lots[0].Quantity)
+	})
+}
+
+// TestFlagExpiringLots tests that expired lots are flagged for removal and
+// that every lot is only reported once
+func TestFlagExpiringLots(t *testing.T) {
+	products := Products{Data: []Product{{SKU: "4900002470", ProductName: "Sprite (Lemon-Lime) - 16.9 oz", Lots: getDefaultLots()}}}
+
+	messages := products.flagExpiringLots(testNow, 3)
+	require.Len(t, messages, 2)
+	assert.Contains(t, messages[0], "expires-soon")
+	assert.Contains(t, messages[1], "expired")
+
+	for _, lot := range products.Data[0].Lots {
+		assert.Equal(t, lot.LotID == "expired", lot.FlaggedForRemoval, lot.LotID)
+		assert.Equal(t, lot.LotID == "expires-soon", lot.ExpiringSoonNotified, lot.LotID)
+	}
+
+	assert.Empty(t, products.flagExpiringLots(testNow, 3), "lots should only be reported once")
+}
+
+// TestExpiringLots tests the expiring-soon report
+func TestExpiringLots(t *testing.T) {
+	products := Products{Data: []Product{{SKU: "4900002470", ProductName: "Sprite (Lemon-Lime) - 16.9 oz", Lots: getDefaultLots()}}}
+
+	tests := []struct {
+		Name         string
+		Days         int
+		ExpectedLots []string
+	}{
+		{"only expired lots", 0, []string{"expired"}},
+		{"lots expiring within 3 days", 3, []string{"expired", "expires-soon"}},
+		{"lots expiring within 30 days", 30, []string{"expired", "expires-soon", "expires-late"}},
+	}
+
+	for _, test := range tests {
+		currentTest := test
+		t.Run(currentTest.Name, func(t *testing.T) {
+			report := products.expiringLots(testNow, currentTest.Days)
+			lotIDs := []string{}
+			for _, lot := range report {
+				assert.Equal(t, "4900002470", lot.SKU)
+				assert.Equal(t, lot.LotID == "expired", lot.Expired)
+				lotIDs = append(lotIDs, lot.LotID)
+			}
+			assert.Equal(t, currentTest.ExpectedLots, lotIDs)
+		})
+	}
+}
+
+// TestCheckLotExpiry tests that flagged lots are written to the inventory file
+func TestCheckLotExpiry(t *testing.T) {
+	products := getDefaultProductsList()
+	products.Data[0].Lots = []Lot{{LotID: "expired", Quantity: 1, ReceivedAt: 1, ExpiresAt: 2}}
+	c := Controller{
+		lc:                logger.NewMockClient(),
+		service:           nil,
+		inventoryItems:    products,
+		inventoryFileName: InventoryFileName,
+	}
+	err := c.WriteInventory()
+	require.NoError(t, err)
+	defer func() {
+		_ = os.Remove(c.inventoryFileName)
+	}()
+
+	checkedProducts, err := c.CheckLotExpiry()
+	require.NoError(t, err)
+	assert.True(t, checkedProducts.Data[0].Lots[0].FlaggedForRemoval)
+
+	productsFromFile, err := c.GetInventoryItems()
+	require.NoError(t, err)
+	assert.Equal(t, checkedProducts, productsFromFile)
+}
+
+// TestCheckLotExpiryNotifications tests that a notification is sent for a
+// flagged lot once the inventory is unlocked, and only once
+func TestCheckLotExpiryNotifications(t *testing.T) {
+	products := getDefaultProductsList()
+	products.Data[0].Lots = []Lot{{LotID: "expired", Quantity: 1, ReceivedAt: 1, ExpiresAt: 2}}
+	c := &Controller{
+		lc:                logger.NewMockClient(),
+		inventoryItems:    products,
+		inventoryFileName: InventoryFileName,
+	}
+	var messages []string
+	c.notificationClient = newUnlockedNotificationClient(t, c, &messages)
+	require.NoError(t, c.WriteInventory())
+	defer func() {
+		_ = os.Remove(c.inventoryFileName)
+	}()
+
+	_, err := c.CheckLotExpiry()
+	require.NoError(t, err)
+	require.Len(t, messages, 1)
+
+	_, err = c.CheckLotExpiry()
+	require.NoError(t, err)
+	assert.Len(t, messages, 1, "a lot that is already flagged should not be notified again")
+}
+
+// TestExpiringLotsByMachine tests that the lots of every machine are flagged
+// and reported with their machine ID
+func TestExpiringLotsByMachine(t *testing.T) {
+	products := Products{Data: []Product{{
+		SKU:         "4900002470",
+		ProductName: "Sprite (Lemon-Lime) - 16.9 oz",
+		Machines:    []MachineStock{{MachineID: "machine-7", UnitsOnHand: 1, Lots: []Lot{{LotID: "expired", Quantity: 1, ExpiresAt: testNow - 1}}}},
+	}}}
+
+	messages := products.flagExpiringLots(testNow, 3)
+	require.Len(t, messages, 1)
+	assert.Contains(t, messages[0], "machine-7")
+	assert.True(t, products.Data[0].Machines[0].Lots[0].FlaggedForRemoval)
+
+	report := products.expiringLots(testNow, 0)
+	require.Len(t, report, 1)
+	assert.Equal(t, "machine-7", report[0].MachineID)
+}
@@ -11,22 +11,65 @@ type Products struct {
 
 // Product is the schema for a single inventory item
 type Product struct {
-	SKU                string  `json:"sku"`
-	ItemPrice          float64 `json:"itemPrice"`
-	ProductName        string  `json:"productName"`
-	UnitsOnHand        int     `json:"unitsOnHand"`
-	MaxRestockingLevel int     `json:"maxRestockingLevel"`
-	MinRestockingLevel int     `json:"minRestockingLevel"`
-	CreatedAt          int64   `json:"createdAt,string"`
-	UpdatedAt          int64   `json:"updatedAt,string"`
-	IsActive           bool    `json:"isActive"`
+	SKU                string         `json:"sku"`
+	ItemPrice          float64        `json:"itemPrice"`
+	Currency           string         `json:"currency,omitempty"`
+	TaxCategory        string         `json:"taxCategory,omitempty"`
+	ProductName        string         `json:"productName"`
+	UnitsOnHand        int            `json:"unitsOnHand"`
+	MaxRestockingLevel int            `json:"maxRestockingLevel"`
+	MinRestockingLevel int            `json:"minRestockingLevel"`
+	CreatedAt          int64          `json:"createdAt,string"`
+	UpdatedAt          int64          `json:"updatedAt,string"`
+	IsActive           bool           `json:"isActive"`
+	ShelfLifeDays      int            `json:"shelfLifeDays,omitempty"`
+	Lots               []Lot          `json:"lots,omitempty"`
+	Machines           []MachineStock `json:"machines,omitempty"`
+}
+
+// MachineStock is the stock of a catalog product in one machine of the fleet.
+// The UnitsOnHand and Lots of the Product itself are the stock of the
+// default machine, which is used when no machine ID is given
+type MachineStock struct {
+	MachineID   string `json:"machineId"`
+	UnitsOnHand int    `json:"unitsOnHand"`
+	Lots        []Lot  `json:"lots,omitempty"`
+	UpdatedAt   int64  `json:"updatedAt,string"`
+}
+
+// Lot is a quantity of a single SKU that was received at the same time and
+// shares an expiry date. An ExpiresAt of 0 means the lot does not expire
+type Lot struct {
+	LotID                string `json:"lotId"`
+	Quantity             int    `json:"quantity"`
+	ReceivedAt           int64  `json:"receivedAt,string"`
+	ExpiresAt            int64  `json:"expiresAt,string"`
+	FlaggedForRemoval    bool   `json:"flaggedForRemoval"`
+	ExpiringSoonNotified bool   `json:"expiringSoonNotified,omitempty"`
+}
+
+// ExpiringLots is the schema for the data that will be returned to the user
+// when hitting the expiring inventory endpoint
+type ExpiringLots struct {
+	Data []ExpiringLot `json:"data"`
+}
+
+// ExpiringLot is a single lot in the expiring-soon report, along with the
+// product it belongs to
+type ExpiringLot struct {
+	SKU         string `json:"sku"`
+	ProductName string `json:"productName"`
+	MachineID   string `json:"machineId,omitempty"`
+	Lot
+	Expired bool `json:"expired"`
 }
 
 // DeltaInventorySKU is required because we cannot unmarshal a delta
 // into Product struct, and the API endpoints needs to accept a delta
 type DeltaInventorySKU struct {
-	SKU   string `json:"SKU"`
-	Delta int    `json:"delta"`
+	SKU       string `json:"SKU"`
+	Delta     int    `json:"delta"`
+	MachineID string `json:"machineId,omitempty"`
 }
 
 // AuditLog is similar to Products in that it is the schema for the data
@@ -44,4 +87,198 @@ type AuditLogEntry struct {
 	InventoryDelta []DeltaInventorySKU `json:"inventoryDelta"`
 	CreatedAt      int64               `json:"createdAt,string"`
 	AuditEntryID   string              `json:"auditEntryId"`
+	MachineID      string              `json:"machineId,omitempty"`
+	DeletedEntryID string              `json:"deletedEntryId,omitempty"`
+	PreviousHash   string              `json:"previousHash,omitempty"`
+	Hash           string              `json:"hash,omitempty"`
+}
+
+// StockAnomalies is the schema for the data that will be returned to the
+// user when hitting the anomalies endpoint
+type StockAnomalies struct {
+	Data []StockAnomaly `json:"data"`
+}
+
+// StockAnomaly is a stock delta that would have driven the units on hand of
+// a product below zero, along with the action taken on it. AuditEntryID is
+// the audit log entry of the session that posted the delta, when known
+type StockAnomaly struct {
+	AnomalyID         string `json:"anomalyId"`
+	SKU               string `json:"sku"`
+	ProductName       string `json:"productName"`
+	MachineID         string `json:"machineId,omitempty"`
+	AuditEntryID      string `json:"auditEntryId,omitempty"`
+	Delta             int    `json:"delta"`
+	AppliedDelta      int    `json:"appliedDelta"`
+	UnitsOnHandBefore int    `json:"unitsOnHandBefore"`
+	Action            string `json:"action"`
+	CreatedAt         int64  `json:"createdAt,string"`
+}
+
+// AuditLogVerification is the result of verifying the hash chain of the
+// audit log. PreChainEntries is the number of entries at the start of the
+// audit log that were written before entries were chained, which are not
+// verified. BrokenLink is only set when the chain is not valid
+type AuditLogVerification struct {
+	Valid           bool                `json:"valid"`
+	Entries         int                 `json:"entries"`
+	PreChainEntries int                 `json:"preChainEntries"`
+	BrokenLink      *AuditLogBrokenLink `json:"brokenLink,omitempty"`
+}
+
+// AuditLogBrokenLink is the first audit log entry whose hash does not match
+// its content or the hash of the entry before it
+type AuditLogBrokenLink struct {
+	Index        int    `json:"index"`
+	AuditEntryID string `json:"auditEntryId"`
+	Archive      string `json:"archive,omitempty"`
+	Reason       string `json:"reason"`
+}
+
+// PricingRules is the schema for the data that will be returned to the user
+// when hitting the pricing rules endpoint
+type PricingRules struct {
+	Data []PricingRule `json:"data"`
+}
+
+// PricingRule changes the price of one or more SKUs while it is active. The
+// rule's Type determines which of the Price, Percent, Amount and
+// BundleQuantity fields are used:
+//   - price: the SKUs are sold for Price per unit, such as a new list price
+//     that becomes effective on a date
+//   - percentDiscount: Percent is taken off the unit price
+//   - fixedDiscount: Amount is taken off the unit price
+//   - bundle: any BundleQuantity units of the SKUs are sold together for
+//     Price, such as "2 for $3"
+//
+// Price and Amount are in major units of the currency of the basket, like
+// the ItemPrice of a product. A rule without SKUs applies to every SKU,
+// except for price rules. A rule is only active from EffectiveFrom until
+// EffectiveUntil, and between the StartTime and EndTime of each day in HH:MM
+// local time, if they are set
+type PricingRule struct {
+	RuleID         string   `json:"ruleId"`
+	Name           string   `json:"name"`
+	Type           string   `json:"type"`
+	SKUs           []string `json:"skus"`
+	Price          float64  `json:"price,omitempty"`
+	Percent        float64  `json:"percent,omitempty"`
+	Amount         float64  `json:"amount,omitempty"`
+	BundleQuantity int      `json:"bundleQuantity,omitempty"`
+	EffectiveFrom  int64    `json:"effectiveFrom,string,omitempty"`
+	EffectiveUntil int64    `json:"effectiveUntil,string,omitempty"`
+	StartTime      string   `json:"startTime,omitempty"`
+	EndTime        string   `json:"endTime,omitempty"`
+	IsActive       bool     `json:"isActive"`
+	CreatedAt      int64    `json:"createdAt,string"`
+	UpdatedAt      int64    `json:"updatedAt,string"`
+}
+
+// PricedBasket is the result of pricing a basket of SKU deltas with the
+// pricing rules that are active at PricedAt. Every amount is in whole minor
+// units of Currency, such as cents for USD
+type PricedBasket struct {
+	LineItems []PricedLineItem `json:"lineItems"`
+	Total     int64            `json:"total"`
+	Currency  string           `json:"currency"`
+	PricedAt  int64            `json:"pricedAt,string"`
+}
+
+// PricedLineItem is the price of all units of a single SKU in a basket.
+// ItemPrice is the regular unit price, and Discount is the amount taken off
+// ItemPrice * ItemCount by discounts and bundles to get to LineTotal
+type PricedLineItem struct {
+	SKU          string   `json:"sku"`
+	ProductName  string   `json:"productName"`
+	ItemPrice    int64    `json:"itemPrice"`
+	ItemCount    int      `json:"itemCount"`
+	Discount     int64    `json:"discount"`
+	LineTotal    int64    `json:"lineTotal"`
+	TaxCategory  string   `json:"taxCategory,omitempty"`
+	AppliedRules []string `json:"appliedRules,omitempty"`
+}
+
+// StockHistory is the schema of the stock history JSON file, which holds a
+// time-series point for every change to the units on hand of any SKU
+type StockHistory struct {
+	Data []StockHistoryPoint `json:"data"`
+}
+
+// StockHistoryPoint is the units on hand of a SKU right after a change.
+// Source is the kind of mutation that caused the change: delta, post,
+// count, restock or delete
+type StockHistoryPoint struct {
+	SKU         string `json:"sku"`
+	MachineID   string `json:"machineId,omitempty"`
+	Timestamp   int64  `json:"timestamp,string"`
+	UnitsOnHand int    `json:"unitsOnHand"`
+	Delta       int    `json:"delta"`
+	Source      string `json:"source"`
+}
+
+// StockCount is a physical count of the units on hand of a SKU, which
+// replaces the units on hand that were known. UnitsOnHand is required
+type StockCount struct {
+	UnitsOnHand *int `json:"unitsOnHand"`
+}
+
+// StockHistoryReport is the stock history of a single SKU between From and
+// To. Buckets are only computed when an interval is requested
+type StockHistoryReport struct {
+	SKU       string               `json:"sku"`
+	MachineID string               `json:"machineId,omitempty"`
+	From      int64                `json:"from,string"`
+	To        int64                `json:"to,string"`
+	Interval  string               `json:"interval,omitempty"`
+	Points    []StockHistoryPoint  `json:"points"`
+	Buckets   []StockHistoryBucket `json:"buckets,omitempty"`
+}
+
+// StockHistoryBucket summarizes the units on hand of a SKU over one interval.
+// The level carried over from before the bucket counts towards Min and Max,
+// and Close is the level at the end of the bucket
+type StockHistoryBucket struct {
+	Start  int64 `json:"start,string"`
+	End    int64 `json:"end,string"`
+	Min    int   `json:"min"`
+	Max    int   `json:"max"`
+	Close  int   `json:"close"`
+	Points int   `json:"points"`
+}
+
+// FleetStock is the total stock of every catalog product across the fleet
+type FleetStock struct {
+	Data []FleetStockItem `json:"data"`
+}
+
+// FleetStockItem is the total stock of a single product across the fleet,
+// broken down by machine
+type FleetStockItem struct {
+	SKU              string              `json:"sku"`
+	ProductName      string              `json:"productName"`
+	TotalUnitsOnHand int                 `json:"totalUnitsOnHand"`
+	Machines         []MachineStockLevel `json:"machines"`
+}
+
+// MachineStockLevel is the units on hand of a product in one machine
+type MachineStockLevel struct {
+	MachineID   string `json:"machineId"`
+	UnitsOnHand int    `json:"unitsOnHand"`
+}
+
+// FleetRestock is the list of products that machines of the fleet need to
+// be restocked with
+type FleetRestock struct {
+	Data []MachineRestock `json:"data"`
+}
+
+// MachineRestock is a product whose units on hand in a machine are at or
+// below its minimum restocking level
+type MachineRestock struct {
+	MachineID          string `json:"machineId"`
+	SKU                string `json:"sku"`
+	ProductName        string `json:"productName"`
+	UnitsOnHand        int    `json:"unitsOnHand"`
+	MinRestockingLevel int    `json:"minRestockingLevel"`
+	MaxRestockingLevel int    `json:"maxRestockingLevel"`
 }
@@ -0,0 +1,41 @@
+// Copyright © 2026 Intel Corporation. All rights reserved.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package routes
+
+import (
+	"context"
+	"time"
+
+	"github.com/edgexfoundry/go-mod-core-contracts/v3/dtos"
+	"github.com/edgexfoundry/go-mod-core-contracts/v3/dtos/requests"
+)
+
+// notificationTimeout bounds how long sending a notification may take
+const notificationTimeout = 10 * time.Second
+
+// sendNotification submits a message to the EdgeX notification service. When
+// the support-notifications client is not configured the message is only
+// logged. It waits for the notification service, so callers must not hold a
+// lock while sending
+func (c *Controller) sendNotification(message string) {
+	c.lc.Info(message)
+	if c.notificationClient == nil {
+		return
+	}
+
+	dto := dtos.NewNotification(c.notificationLabels,
+		c.notificationCategory,
+		message,
+		c.notificationSender,
+		c.notificationSeverity,
+	)
+
+	ctx, cancel := context.WithTimeout(context.Background(), notificationTimeout)
+	defer cancel()
+	req := requests.NewAddNotificationRequest(dto)
+	_, err := c.notificationClient.SendNotification(ctx, []requests.AddNotificationRequest{req})
+	if err != nil {
+		c.lc.Errorf("failed to send the notification: %s", err.Error())
+	}
+}
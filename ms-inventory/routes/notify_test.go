@@ -0,0 +1,49 @@
+// Copyright © 2026 Intel Corporation. All rights reserved.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package routes
+
+import (
+	"context"
+	"testing"
+
+	clientMocks "github.com/edgexfoundry/go-mod-core-contracts/v3/clients/interfaces/mocks"
+	"github.com/edgexfoundry/go-mod-core-contracts/v3/clients/logger"
+	"github.com/edgexfoundry/go-mod-core-contracts/v3/dtos/requests"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// newUnlockedNotificationClient returns a notification client that records
+// the messages it is sent, and checks that they are sent with a deadline
+// while the inventory of the controller is unlocked
+func newUnlockedNotificationClient(t *testing.T, c *Controller, messages *[]string) *clientMocks.NotificationClient {
+	client := &clientMocks.NotificationClient{}
+	client.On("SendNotification", mock.Anything, mock.Anything).
+		Run(func(args mock.Arguments) {
+			_, hasDeadline := args.Get(0).(context.Context).Deadline()
+			assert.True(t, hasDeadline, "a notification should be sent with a timeout")
+			locked := !c.inventoryMutex.TryLock()
+			if !locked {
+				c.inventoryMutex.Unlock()
+			}
+			assert.False(t, locked, "the inventory should not be locked while a notification is sent")
+			for _, req := range args.Get(1).([]requests.AddNotificationRequest) {
+				*messages = append(*messages, req.Notification.Content)
+			}
+		}).
+		Return(nil, nil)
+	return client
+}
+
+// TestSendNotification tests that a message is sent to the notification
+// service with a timeout
+func TestSendNotification(t *testing.T) {
+	c := &Controller{lc: logger.NewMockClient()}
+	var messages []string
+	c.notificationClient = newUnlockedNotificationClient(t, c, &messages)
+
+	c.sendNotification("lot expired")
+	require.Equal(t, []string{"lot expired"}, messages)
+}
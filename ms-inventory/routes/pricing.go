@@ -0,0 +1,351 @@
+// Copyright © 2026 Intel Corporation. All rights reserved.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package routes
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"sort"
+	"time"
+)
+
+// Pricing rule types
+const (
+	PriceRuleType           = "price"
+	PercentDiscountRuleType = "percentDiscount"
+	FixedDiscountRuleType   = "fixedDiscount"
+	BundleRuleType          = "bundle"
+)
+
+// errUnknownSKU is returned when a basket contains a SKU that is not in the
+// inventory
+var errUnknownSKU = errors.New("item does not exist")
+
+// GetPricingRules returns the pricing rules by reading the pricing JSON file
+func (c *Controller) GetPricingRules() (pricingRules PricingRules, err error) {
+	data, err := os.ReadFile(c.pricingFileName)
+	if err != nil {
+		return pricingRules, fmt.Errorf("failed to read from pricing rules file: %s", err.Error())
+	}
+	if err := json.Unmarshal(data, &pricingRules); err != nil {
+		return pricingRules, fmt.Errorf("failed to unmarshal pricing rules file: %s", err.Error())
+	}
+
+	return
+}
+
+// DeletePricingRules will reset the content of the pricing rules JSON file
+func (c *Controller) DeletePricingRules() error {
+	c.lc.Debug("Pricing rules JSON content reset")
+	return c.WriteJSON(c.pricingFileName, PricingRules{Data: []PricingRule{}})
+}
+
+// parseTimeOfDay parses an HH:MM time of day into minutes since midnight
+func parseTimeOfDay(value string) (int, error) {
+	parsed, err := time.Parse("15:04", value)
+	if err != nil {
+		return 0, fmt.Errorf("%s is not a valid HH:MM time of day", value)
+	}
+	return parsed.Hour()*60 + parsed.Minute(), nil
+}
+
+// validate checks that the rule has the fields its type requires
+func (rule *PricingRule) validate() error {
+	switch rule.Type {
+	case PriceRuleType:
+		if len(rule.SKUs) == 0 {
+			return errors.New("a price rule must list the SKUs it applies to")
+		}
+		if rule.Price < 0 {
+			return errors.New("the price of a price rule must not be negative")
+		}
+	case PercentDiscountRuleType:
+		if rule.Percent <= 0 || rule.Percent > 100 {
+			return errors.New("the percent of a percentDiscount rule must be greater than 0 and at most 100")
+		}
+	case FixedDiscountRuleType:
+		if rule.Amount <= 0 {
+			return errors.New("the amount of a fixedDiscount rule must be greater than 0")
+		}
+	case BundleRuleType:
+		if rule.BundleQuantity < 2 {
+			return errors.New("the bundleQuantity of a bundle rule must be at least 2")
+		}
+		if rule.Price < 0 {
+			return errors.New("the price of a bundle rule must not be negative")
+		}
+	default:
+		return fmt.Errorf("unknown pricing rule type %q", rule.Type)
+	}
+
+	if rule.EffectiveUntil != 0 && rule.EffectiveUntil <= rule.EffectiveFrom {
+		return errors.New("effectiveUntil must be after effectiveFrom")
+	}
+	if (rule.StartTime == "") != (rule.EndTime == "") {
+		return errors.New("startTime and endTime must be set together")
+	}
+	if rule.StartTime != "" {
+		if _, err := parseTimeOfDay(rule.StartTime); err != nil {
+			return err
+		}
+		if _, err := parseTimeOfDay(rule.EndTime); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// activeAt returns true if the rule is active and now falls within its
+// effective dates and daily time window. A window whose end is before its
+// start, such as 22:00 to 02:00, wraps past midnight
+func (rule *PricingRule) activeAt(now time.Time) bool {
+	if !rule.IsActive {
+		return false
+	}
+	if rule.EffectiveFrom != 0 && now.UnixNano() < rule.EffectiveFrom {
+		return false
+	}
+	if rule.EffectiveUntil != 0 && now.UnixNano() >= rule.EffectiveUntil {
+		return false
+	}
+	if rule.StartTime == "" {
+		return true
+	}
+
+	start, err := parseTimeOfDay(rule.StartTime)
+	if err != nil {
+		return false
+	}
+	end, err := parseTimeOfDay(rule.EndTime)
+	if err != nil {
+		return false
+	}
+	minute := now.Hour()*60 + now.Minute()
+	if start <= end {
+		return minute >= start && minute < end
+	}
+	return minute >= start || minute < end
+}
+
+// appliesTo returns true if the rule covers the SKU. Rules without SKUs cover
+// every SKU
+func (rule *PricingRule) appliesTo(sku string) bool {
+	if len(rule.SKUs) == 0 {
+		return true
+	}
+	for _, ruleSKU := range rule.SKUs {
+		if ruleSKU == sku {
+			return true
+		}
+	}
+	return false
+}
+
+// priceInMinorUnits converts a price of the rule or the catalog, in major
+// units, into minor units with the scale of the basket's currency
+func priceInMinorUnits(price float64, scale float64) int64 {
+	return int64(math.Round(price * scale))
+}
+
+// discountedPrice returns the unit price, in minor units, after applying a
+// discount rule. The scale converts the amount of a fixedDiscount rule into
+// minor units
+func (rule *PricingRule) discountedPrice(unitPrice int64, scale float64) int64 {
+	switch rule.Type {
+	case PercentDiscountRuleType:
+		return int64(math.Round(float64(unitPrice) * (100 - rule.Percent) / 100))
+	case FixedDiscountRuleType:
+		if discounted := unitPrice - priceInMinorUnits(rule.Amount, scale); discounted > 0 {
+			return discounted
+		}
+		return 0
+	}
+	return unitPrice
+}
+
+// basketLine is the working state of a single SKU while a basket is priced.
+// Its prices are in minor units
+type basketLine struct {
+	item           PricedLineItem
+	product        Product
+	unitPrice      int64
+	bundledUnits   int
+	bundledTotal   int64
+	appliedRuleIDs map[string]bool
+}
+
+func (line *basketLine) applyRule(rule PricingRule) {
+	if line.appliedRuleIDs[rule.RuleID] {
+		return
+	}
+	line.appliedRuleIDs[rule.RuleID] = true
+	line.item.AppliedRules = append(line.item.AppliedRules, rule.RuleID)
+}
+
+// priceBasket prices the units removed or added by every SKU delta with the
+// pricing rules that are active at the given time. Deltas of the same SKU
+// are combined into one line item. The regular unit price of a SKU is its
+// item price, unless a price rule overrides it, in which case the rule that
+// became effective most recently wins. Units that are not sold in a bundle
+// get the best discount that applies to them, and discounts never stack.
+// Every product in the basket must be priced in the same currency, where
+// products without a currency are priced in the given one. The catalog and
+// rule prices are converted into minor units of that currency first, so the
+// basket is priced in whole minor units
+func priceBasket(products Products, currency string, rules []PricingRule, deltas []DeltaInventorySKU, now time.Time) (PricedBasket, error) {
+	var activeRules []PricingRule
+	for _, rule := range rules {
+		if rule.activeAt(now) {
+			activeRules = append(activeRules, rule)
+		}
+	}
+
+	// combine the deltas per SKU, keeping the order of the basket
+	basketCurrency := ""
+	var lines []*basketLine
+	linesBySKU := map[string]*basketLine{}
+	for _, delta := range deltas {
+		units := delta.Delta
+		if units < 0 {
+			units = -units
+		}
+		if line, ok := linesBySKU[delta.SKU]; ok {
+			line.item.ItemCount += units
+			continue
+		}
+
+		var product Product
+		for _, inventoryItem := range products.Data {
+			if inventoryItem.SKU == delta.SKU {
+				product = inventoryItem
+				break
+			}
+		}
+		if product.SKU == "" {
+			return PricedBasket{}, fmt.Errorf("%w: %s", errUnknownSKU, delta.SKU)
+		}
+		if basketCurrency == "" {
+			basketCurrency = product.currencyOf(currency)
+		} else if product.currencyOf(currency) != basketCurrency {
+			return PricedBasket{}, fmt.Errorf("%w: %s is priced in %s instead of %s", errMixedCurrencies, product.SKU, product.currencyOf(currency), basketCurrency)
+		}
+
+		line := &basketLine{
+			item: PricedLineItem{
+				SKU:         product.SKU,
+				ProductName: product.ProductName,
+				ItemCount:   units,
+				TaxCategory: product.TaxCategory,
+			},
+			product:        product,
+			appliedRuleIDs: map[string]bool{},
+		}
+		lines = append(lines, line)
+		linesBySKU[delta.SKU] = line
+	}
+
+	if basketCurrency == "" {
+		basketCurrency = currency
+	}
+	digits, ok := minorUnitDigits(basketCurrency)
+	if !ok {
+		return PricedBasket{}, fmt.Errorf("unsupported currency %q", basketCurrency)
+	}
+	scale := math.Pow10(digits)
+
+	// determine the regular and the discounted unit price of every SKU
+	for _, line := range lines {
+		line.item.ItemPrice = priceInMinorUnits(line.product.ItemPrice, scale)
+		var priceRule *PricingRule
+		for i, rule := range activeRules {
+			if rule.Type == PriceRuleType && rule.appliesTo(line.item.SKU) &&
+				(priceRule == nil || rule.EffectiveFrom > priceRule.EffectiveFrom) {
+				priceRule = &activeRules[i]
+			}
+		}
+		if priceRule != nil {
+			line.item.ItemPrice = priceInMinorUnits(priceRule.Price, scale)
+			line.applyRule(*priceRule)
+		}
+
+		line.unitPrice = line.item.ItemPrice
+		var discountRule *PricingRule
+		for i, rule := range activeRules {
+			if (rule.Type == PercentDiscountRuleType || rule.Type == FixedDiscountRuleType) && rule.appliesTo(line.item.SKU) {
+				if price := rule.discountedPrice(line.item.ItemPrice, scale); price < line.unitPrice {
+					line.unitPrice = price
+					discountRule = &activeRules[i]
+				}
+			}
+		}
+		if discountRule != nil {
+			line.applyRule(*discountRule)
+		}
+	}
+
+	// sell units in bundles as long as a bundle is cheaper than its units
+	// at their discounted price, bundling the most expensive units first
+	for _, rule := range activeRules {
+		if rule.Type != BundleRuleType {
+			continue
+		}
+
+		var units []*basketLine
+		for _, line := range lines {
+			if rule.appliesTo(line.item.SKU) {
+				for i := line.bundledUnits; i < line.item.ItemCount; i++ {
+					units = append(units, line)
+				}
+			}
+		}
+		sort.SliceStable(units, func(i, j int) bool {
+			return units[i].unitPrice > units[j].unitPrice
+		})
+
+		bundlePrice := priceInMinorUnits(rule.Price, scale)
+		for len(units) >= rule.BundleQuantity {
+			bundle := units[:rule.BundleQuantity]
+			var regularTotal int64
+			for _, line := range bundle {
+				regularTotal += line.unitPrice
+			}
+			if bundlePrice >= regularTotal {
+				break
+			}
+			// spread the bundle price over its units in proportion to
+			// their price so that every line item carries its share. The
+			// last unit gets what is left so that the shares add up to the
+			// bundle price
+			remaining := bundlePrice
+			for i, line := range bundle {
+				share := bundlePrice * line.unitPrice / regularTotal
+				if i == len(bundle)-1 {
+					share = remaining
+				}
+				remaining -= share
+				line.bundledUnits++
+				line.bundledTotal += share
+				line.applyRule(rule)
+			}
+			units = units[rule.BundleQuantity:]
+		}
+	}
+
+	basket := PricedBasket{
+		LineItems: []PricedLineItem{},
+		Currency:  basketCurrency,
+		PricedAt:  now.UnixNano(),
+	}
+	for _, line := range lines {
+		line.item.LineTotal = int64(line.item.ItemCount-line.bundledUnits)*line.unitPrice + line.bundledTotal
+		line.item.Discount = int64(line.item.ItemCount)*line.item.ItemPrice - line.item.LineTotal
+		basket.Total += line.item.LineTotal
+		basket.LineItems = append(basket.LineItems, line.item)
+	}
+
+	return basket, nil
+}
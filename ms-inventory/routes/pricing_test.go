@@ -0,0 +1,215 @@
+// Copyright © 2026 Intel Corporation. All rights reserved.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package routes
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testPricingTime is a Tuesday at 16:30 local time
+var testPricingTime = time.Date(2026, time.March, 3, 16, 30, 0, 0, time.Local)
+
+// TestPricingRuleActiveAt tests the effective dates and daily time windows
+// of pricing rules
+func TestPricingRuleActiveAt(t *testing.T) {
+	tests := []struct {
+		Name     string
+		Rule     PricingRule
+		Expected bool
+	}{
+		{"always active", PricingRule{IsActive: true}, true},
+		{"inactive", PricingRule{}, false},
+		{"not effective yet", PricingRule{IsActive: true, EffectiveFrom: testPricingTime.Add(time.Hour).UnixNano()}, false},
+		{"effective", PricingRule{IsActive: true, EffectiveFrom: testPricingTime.Add(-time.Hour).UnixNano(), EffectiveUntil: testPricingTime.Add(time.Hour).UnixNano()}, true},
+		{"no longer effective", PricingRule{IsActive: true, EffectiveUntil: testPricingTime.UnixNano()}, false},
+		{"within happy hour", PricingRule{IsActive: true, StartTime: "16:00", EndTime: "17:00"}, true},
+		{"outside happy hour", PricingRule{IsActive: true, StartTime: "17:00", EndTime: "18:00"}, false},
+		{"within window past midnight", PricingRule{IsActive: true, StartTime: "16:00", EndTime: "02:00"}, true},
+		{"outside window past midnight", PricingRule{IsActive: true, StartTime: "22:00", EndTime: "02:00"}, false},
+	}
+
+	for _, test := range tests {
+		currentTest := test
+		t.Run(currentTest.Name, func(t *testing.T) {
+			assert.Equal(t, currentTest.Expected, currentTest.Rule.activeAt(testPricingTime))
+		})
+	}
+}
+
+// TestPricingRuleValidate tests that rules without the fields their type
+// requires are rejected
+func TestPricingRuleValidate(t *testing.T) {
+	tests := []struct {
+		Name        string
+		Rule        PricingRule
+		ExpectError bool
+	}{
+		{"valid price", PricingRule{Type: PriceRuleType, SKUs: []string{"4900002470"}, Price: 1.49}, false},
+		{"price without SKUs", PricingRule{Type: PriceRuleType, Price: 1.49}, true},
+		{"valid percent discount", PricingRule{Type: PercentDiscountRuleType, Percent: 10}, false},
+		{"percent discount over 100", PricingRule{Type: PercentDiscountRuleType, Percent: 110}, true},
+		{"fixed discount without amount", PricingRule{Type: FixedDiscountRuleType}, true},
+		{"valid bundle", PricingRule{Type: BundleRuleType, BundleQuantity: 2, Price: 3}, false},
+		{"bundle of one", PricingRule{Type: BundleRuleType, BundleQuantity: 1, Price: 3}, true},
+		{"unknown type", PricingRule{Type: "coupon"}, true},
+		{"effective dates reversed", PricingRule{Type: PercentDiscountRuleType, Percent: 10, EffectiveFrom: 2, EffectiveUntil: 1}, true},
+		{"start time without end time", PricingRule{Type: PercentDiscountRuleType, Percent: 10, StartTime: "16:00"}, true},
+		{"invalid time of day", PricingRule{Type: PercentDiscountRuleType, Percent: 10, StartTime: "4pm", EndTime: "5pm"}, true},
+	}
+
+	for _, test := range tests {
+		currentTest := test
+		t.Run(currentTest.Name, func(t *testing.T) {
+			err := currentTest.Rule.validate()
+			if currentTest.ExpectError {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+// TestPriceBasket tests pricing baskets with price overrides, discounts and
+// bundles
+func TestPriceBasket(t *testing.T) {
+	products := getDefaultProductsList()
+	sprite := products.Data[0].SKU
+	mountainDew := products.Data[1].SKU
+
+	tests := []struct {
+		Name               string
+		Rules              []PricingRule
+		Basket             []DeltaInventorySKU
+		ExpectedLineTotals []int64
+		ExpectedTotal      int64
+		ExpectedRules      [][]string
+	}{
+		{
+			Name:               "item prices without rules",
+			Basket:             []DeltaInventorySKU{{SKU: sprite, Delta: -2}, {SKU: mountainDew, Delta: -1}},
+			ExpectedLineTotals: []int64{398, 199},
+			ExpectedTotal:      597,
+		},
+		{
+			Name:               "deltas of the same SKU are combined",
+			Basket:             []DeltaInventorySKU{{SKU: sprite, Delta: -1}, {SKU: sprite, Delta: -1}},
+			ExpectedLineTotals: []int64{398},
+			ExpectedTotal:      398,
+		},
+		{
+			Name: "most recently effective price wins",
+			Rules: []PricingRule{
+				{RuleID: "old", Type: PriceRuleType, SKUs: []string{sprite}, Price: 2.49, IsActive: true, EffectiveFrom: 1},
+				{RuleID: "new", Type: PriceRuleType, SKUs: []string{sprite}, Price: 1.49, IsActive: true, EffectiveFrom: 2},
+				{RuleID: "future", Type: PriceRuleType, SKUs: []string{sprite}, Price: 0.99, IsActive: true, EffectiveFrom: testPricingTime.Add(time.Hour).UnixNano()},
+			},
+			Basket:             []DeltaInventorySKU{{SKU: sprite, Delta: -1}},
+			ExpectedLineTotals: []int64{149},
+			ExpectedTotal:      149,
+			ExpectedRules:      [][]string{{"new"}},
+		},
+		{
+			Name: "best discount applies without stacking",
+			Rules: []PricingRule{
+				{RuleID: "happy-hour", Type: PercentDiscountRuleType, Percent: 20, IsActive: true, StartTime: "16:00", EndTime: "17:00"},
+				{RuleID: "ten-cents", Type: FixedDiscountRuleType, SKUs: []string{sprite}, Amount: 0.10, IsActive: true},
+			},
+			Basket:             []DeltaInventorySKU{{SKU: sprite, Delta: -1}, {SKU: mountainDew, Delta: -1}},
+			ExpectedLineTotals: []int64{159, 159},
+			ExpectedTotal:      318,
+			ExpectedRules:      [][]string{{"happy-hour"}, {"happy-hour"}},
+		},
+		{
+			Name: "bundle across SKUs",
+			Rules: []PricingRule{
+				{RuleID: "2-for-3", Type: BundleRuleType, SKUs: []string{sprite, mountainDew}, BundleQuantity: 2, Price: 3, IsActive: true},
+			},
+			Basket:             []DeltaInventorySKU{{SKU: sprite, Delta: -1}, {SKU: mountainDew, Delta: -2}},
+			ExpectedLineTotals: []int64{150, 349},
+			ExpectedTotal:      499,
+			ExpectedRules:      [][]string{{"2-for-3"}, {"2-for-3"}},
+		},
+		{
+			Name: "bundle that is not cheaper is not applied",
+			Rules: []PricingRule{
+				{RuleID: "fifty-cents", Type: FixedDiscountRuleType, Amount: 0.50, IsActive: true},
+				{RuleID: "2-for-3", Type: BundleRuleType, BundleQuantity: 2, Price: 3, IsActive: true},
+			},
+			Basket:             []DeltaInventorySKU{{SKU: sprite, Delta: -2}},
+			ExpectedLineTotals: []int64{298},
+			ExpectedTotal:      298,
+			ExpectedRules:      [][]string{{"fifty-cents"}},
+		},
+		{
+			Name: "bundle shares add up to the bundle price",
+			Rules: []PricingRule{
+				{RuleID: "3-for-5", Type: BundleRuleType, BundleQuantity: 3, Price: 5, IsActive: true},
+			},
+			Basket:             []DeltaInventorySKU{{SKU: sprite, Delta: -2}, {SKU: mountainDew, Delta: -1}},
+			ExpectedLineTotals: []int64{332, 168},
+			ExpectedTotal:      500,
+			ExpectedRules:      [][]string{{"3-for-5"}, {"3-for-5"}},
+		},
+	}
+
+	for _, test := range tests {
+		currentTest := test
+		t.Run(currentTest.Name, func(t *testing.T) {
+			basket, err := priceBasket(products, defaultCurrency, currentTest.Rules, currentTest.Basket, testPricingTime)
+			require.NoError(t, err)
+			require.Len(t, basket.LineItems, len(currentTest.ExpectedLineTotals))
+			for i, lineItem := range basket.LineItems {
+				assert.Equal(t, currentTest.ExpectedLineTotals[i], lineItem.LineTotal, lineItem.SKU)
+				assert.Equal(t, int64(lineItem.ItemCount)*lineItem.ItemPrice-lineItem.LineTotal, lineItem.Discount)
+				if currentTest.ExpectedRules != nil {
+					assert.Equal(t, currentTest.ExpectedRules[i], lineItem.AppliedRules)
+				}
+			}
+			assert.Equal(t, currentTest.ExpectedTotal, basket.Total)
+			assert.Equal(t, defaultCurrency, basket.Currency)
+			assert.Equal(t, testPricingTime.UnixNano(), basket.PricedAt)
+		})
+	}
+
+	t.Run("unknown SKU", func(t *testing.T) {
+		_, err := priceBasket(products, defaultCurrency, nil, []DeltaInventorySKU{{SKU: "0000000000", Delta: -1}}, testPricingTime)
+		require.ErrorIs(t, err, errUnknownSKU)
+	})
+
+	t.Run("tax category", func(t *testing.T) {
+		taxedProducts := getDefaultProductsList()
+		taxedProducts.Data[0].TaxCategory = "beverage"
+		basket, err := priceBasket(taxedProducts, defaultCurrency, nil, []DeltaInventorySKU{{SKU: sprite, Delta: -1}, {SKU: mountainDew, Delta: -1}}, testPricingTime)
+		require.NoError(t, err)
+		require.Len(t, basket.LineItems, 2)
+		assert.Equal(t, "beverage", basket.LineItems[0].TaxCategory)
+		assert.Empty(t, basket.LineItems[1].TaxCategory)
+	})
+
+	t.Run("mixed currencies", func(t *testing.T) {
+		mixedProducts := getDefaultProductsList()
+		mixedProducts.Data[1].Currency = "EUR"
+		_, err := priceBasket(mixedProducts, defaultCurrency, nil, []DeltaInventorySKU{{SKU: sprite, Delta: -1}, {SKU: mountainDew, Delta: -1}}, testPricingTime)
+		require.ErrorIs(t, err, errMixedCurrencies)
+	})
+
+	t.Run("unsupported currency", func(t *testing.T) {
+		_, err := priceBasket(products, "dollars", nil, []DeltaInventorySKU{{SKU: sprite, Delta: -1}}, testPricingTime)
+		require.Error(t, err)
+	})
+
+	t.Run("currency without minor unit", func(t *testing.T) {
+		yenProducts := Products{Data: []Product{{SKU: sprite, ProductName: "Sprite", ItemPrice: 100, Currency: "JPY"}}}
+		rules := []PricingRule{{RuleID: "eighth-off", Type: PercentDiscountRuleType, Percent: 12.5, IsActive: true}}
+		basket, err := priceBasket(yenProducts, defaultCurrency, rules, []DeltaInventorySKU{{SKU: sprite, Delta: -1}}, testPricingTime)
+		require.NoError(t, err)
+		assert.Equal(t, "JPY", basket.Currency)
+		assert.Equal(t, int64(88), basket.Total)
+	})
+}
@@ -5,12 +5,15 @@ package routes
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
-	"github.com/google/uuid"
 	"io"
 	"net/http"
 	"os"
 	"time"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
 )
 
 // DeltaInventorySKUPost allows a change in inventory (a delta), via HTTP Post
@@ -36,6 +39,15 @@ func (c *Controller) DeltaInventorySKUPost(writer http.ResponseWriter, req *http
 		return
 	}
 
+	// the queued anomalies are notified once the inventory is unlocked, as
+	// deferred calls run in reverse order
+	var queuedAnomalies []StockAnomaly
+	defer func() {
+		c.notifyAnomalies(queuedAnomalies)
+	}()
+	c.inventoryMutex.Lock()
+	defer c.inventoryMutex.Unlock()
+
 	// load the inventory.json file
 	var inventoryItems Products
 	data, err := os.ReadFile(c.inventoryFileName)
@@ -52,20 +64,44 @@ func (c *Controller) DeltaInventorySKUPost(writer http.ResponseWriter, req *http
 		writer.Write([]byte(errMsg))
 	}
 
+	// the audit log entry of the session that posted the deltas, if any
+	auditEntryID := req.URL.Query().Get("auditEntryId")
+
 	// iterate over all deltaInventorySKU's and find their corresponding SKU in inventory
 	// then update the inventory with the delta
 	var updatedInventoryItems []Product // will return the inventory items that got updated
+	var stockPoints []StockHistoryPoint
+	var anomalies []StockAnomaly
 	performedUpdate := false
 	for _, deltaInventorySKU := range deltaInventorySKUList {
 		for i, inventoryItem := range inventoryItems.Data {
 			if deltaInventorySKU.SKU == inventoryItem.SKU {
-				inventoryItems.Data[i].UnitsOnHand += deltaInventorySKU.Delta
+				now := time.Now().UnixNano()
+				delta, anomaly := c.checkStockDelta(inventoryItem, deltaInventorySKU.MachineID, deltaInventorySKU.Delta, auditEntryID, now)
+				if anomaly != nil {
+					anomalies = append(anomalies, *anomaly)
+					if anomaly.Action == AnomalyActionRejected {
+						break
+					}
+				}
+				inventoryItems.Data[i].applyStockDelta(deltaInventorySKU.MachineID, delta, now)
 				updatedInventoryItems = append(updatedInventoryItems, inventoryItems.Data[i])
+				stockPoints = append(stockPoints, newStockPoint(inventoryItems.Data[i], deltaInventorySKU.MachineID, delta, StockSourceDelta, now))
 				performedUpdate = true
 				break
 			}
 		}
 	}
+	// Every delta was rejected, so return "Conflict" status. The rejections
+	// are queued, as the inventory is not written
+	if !performedUpdate && len(anomalies) > 0 {
+		c.queueAnomalies(anomalies...)
+		queuedAnomalies = anomalies
+		c.lc.Info("Every delta would drive the units on hand below 0 and was rejected")
+		writer.WriteHeader(http.StatusConflict)
+		writer.Write([]byte("Every delta would drive the units on hand below 0 and was rejected"))
+		return
+	}
 
 	// Nothing was done, so return "Not Modified" status
 	if !performedUpdate {
@@ -82,6 +118,7 @@ func (c *Controller) DeltaInventorySKUPost(writer http.ResponseWriter, req *http
 		c.lc.Error(errMsg)
 		writer.WriteHeader(http.StatusInternalServerError)
 		writer.Write([]byte(errMsg))
+		return
 	}
 	err = os.WriteFile(c.inventoryFileName, inventoryData, 0644)
 	if err != nil {
@@ -89,7 +126,11 @@ func (c *Controller) DeltaInventorySKUPost(writer http.ResponseWriter, req *http
 		c.lc.Error(errMsg)
 		writer.WriteHeader(http.StatusInternalServerError)
 		writer.Write([]byte(errMsg))
+		return
 	}
+	c.recordStockHistory(stockPoints...)
+	c.queueAnomalies(anomalies...)
+	queuedAnomalies = anomalies
 
 	// return the new/updated items as JSON, or if for some reason it cannot be processed back into
 	// JSON for returning to the user, fallback to a simple string
@@ -130,6 +171,25 @@ func (c *Controller) InventoryPost(writer http.ResponseWriter, req *http.Request
 		writer.Write([]byte("Failed to process the posted inventory item(s): " + err.Error()))
 		return
 	}
+	for _, postedInventoryItem := range deltaInventoryList {
+		if postedInventoryItem["currency"] != nil {
+			if currency, ok := postedInventoryItem["currency"].(string); !ok || !validCurrency(currency) {
+				c.lc.Errorf("Failed to process the posted inventory item(s): unsupported currency %v", postedInventoryItem["currency"])
+				writer.WriteHeader(http.StatusBadRequest)
+				writer.Write([]byte(fmt.Sprintf("Failed to process the posted inventory item(s): unsupported currency %v", postedInventoryItem["currency"])))
+				return
+			}
+		}
+	}
+
+	// the queued anomalies are notified once the inventory is unlocked, as
+	// deferred calls run in reverse order
+	var queuedAnomalies []StockAnomaly
+	defer func() {
+		c.notifyAnomalies(queuedAnomalies)
+	}()
+	c.inventoryMutex.Lock()
+	defer c.inventoryMutex.Unlock()
 
 	// load the inventory.json file
 	var inventoryItems Products
@@ -149,6 +209,8 @@ func (c *Controller) InventoryPost(writer http.ResponseWriter, req *http.Request
 
 	// Keep track of the items that get added so that the user can be informed of them in our response
 	var newInventoryItems []Product
+	var stockPoints []StockHistoryPoint
+	var anomalies []StockAnomaly
 
 	// Loop through the posted inventory item list to find matching SKUs
 	inventoryChanged := false
@@ -164,6 +226,15 @@ func (c *Controller) InventoryPost(writer http.ResponseWriter, req *http.Request
 						inventoryItems.Data[i].ItemPrice = postedInventoryItem["itemPrice"].(float64)
 					}
 				}
+				if postedInventoryItem["currency"] != nil {
+					inventoryItems.Data[i].Currency = postedInventoryItem["currency"].(string)
+				}
+				if postedInventoryItem["taxCategory"] != nil {
+					switch postedInventoryItem["taxCategory"].(type) {
+					case string:
+						inventoryItems.Data[i].TaxCategory = postedInventoryItem["taxCategory"].(string)
+					}
+				}
 				if postedInventoryItem["maxRestockingLevel"] != nil {
 					switch postedInventoryItem["maxRestockingLevel"].(type) {
 					case float64:
@@ -182,16 +253,28 @@ func (c *Controller) InventoryPost(writer http.ResponseWriter, req *http.Request
 						inventoryItems.Data[i].IsActive = postedInventoryItem["isActive"].(bool)
 					}
 				}
+				if postedInventoryItem["shelfLifeDays"] != nil {
+					switch postedInventoryItem["shelfLifeDays"].(type) {
+					case float64:
+						inventoryItems.Data[i].ShelfLifeDays = int(postedInventoryItem["shelfLifeDays"].(float64))
+					}
+				}
 				if postedInventoryItem["unitsOnHand"] != nil {
 					switch postedInventoryItem["unitsOnHand"].(type) {
 					case float64:
-						inventoryItems.Data[i].UnitsOnHand = inventoryItems.Data[i].UnitsOnHand + int(postedInventoryItem["unitsOnHand"].(float64))
+						// a delta that would drive the units on hand below 0 is
+						// handled according to the NegativeStockHandling setting
+						delta, anomaly := c.checkStockDelta(inventoryItems.Data[i], "", int(postedInventoryItem["unitsOnHand"].(float64)), "", time.Now().UnixNano())
+						if anomaly != nil {
+							anomalies = append(anomalies, *anomaly)
+						}
+						if delta != 0 || anomaly == nil {
+							inventoryItems.Data[i].UnitsOnHand = inventoryItems.Data[i].UnitsOnHand + delta
+							inventoryItems.Data[i].applyLotDelta(delta, time.Now().UnixNano())
+							stockPoints = append(stockPoints, newStockPoint(inventoryItems.Data[i], "", delta, StockSourcePost, time.Now().UnixNano()))
+						}
 					}
 
-					// Need to send an error if the product units on hand is below 0
-					if inventoryItems.Data[i].UnitsOnHand < 0 {
-						c.lc.Infof("Product %s on hand is less than 0 which was caused by a bad delta value", postedInventoryItem["sku"])
-					}
 					// Item is under minimum stock level. Send notification
 					if inventoryItems.Data[i].UnitsOnHand <= inventoryItems.Data[i].MinRestockingLevel {
 						c.lc.Infof("Product %s needs to be restocked", postedInventoryItem["sku"])
@@ -225,6 +308,15 @@ func (c *Controller) InventoryPost(writer http.ResponseWriter, req *http.Request
 			} else {
 				newProduct.ItemPrice = 0
 			}
+			if postedInventoryItem["currency"] != nil {
+				newProduct.Currency = postedInventoryItem["currency"].(string)
+			}
+			if postedInventoryItem["taxCategory"] != nil {
+				switch postedInventoryItem["taxCategory"].(type) {
+				case string:
+					newProduct.TaxCategory = postedInventoryItem["taxCategory"].(string)
+				}
+			}
 			// Set the UnitsOnHand. If the UnitsOnHand isn't provided set a default value
 			if postedInventoryItem["unitsOnHand"] != nil {
 				switch postedInventoryItem["unitsOnHand"].(type) {
@@ -258,6 +350,15 @@ func (c *Controller) InventoryPost(writer http.ResponseWriter, req *http.Request
 			} else {
 				newProduct.MinRestockingLevel = 0
 			}
+			// Set the shelfLifeDays and receive the initial units as a lot if the product is perishable
+			if postedInventoryItem["shelfLifeDays"] != nil {
+				switch postedInventoryItem["shelfLifeDays"].(type) {
+				case float64:
+					newProduct.ShelfLifeDays = int(postedInventoryItem["shelfLifeDays"].(float64))
+				}
+			}
+			newProduct.applyLotDelta(newProduct.UnitsOnHand, newProduct.CreatedAt)
+			stockPoints = append(stockPoints, newStockPoint(newProduct, "", newProduct.UnitsOnHand, StockSourcePost, newProduct.CreatedAt))
 			// Add new product to the product List
 			inventoryItems.Data = append(inventoryItems.Data, newProduct)
 			inventoryChanged = true
@@ -282,6 +383,9 @@ func (c *Controller) InventoryPost(writer http.ResponseWriter, req *http.Request
 			writer.Write([]byte("Failed to write inventory: " + err.Error()))
 			return
 		}
+		c.recordStockHistory(stockPoints...)
+		c.queueAnomalies(anomalies...)
+		queuedAnomalies = anomalies
 		// return the new/updated items as JSON, or if for some reason it cannot be processed back into
 		// JSON for returning to the user, fallback to a simple string
 		newInventoryItemsJSON, err := json.Marshal(newInventoryItems)
@@ -295,6 +399,175 @@ func (c *Controller) InventoryPost(writer http.ResponseWriter, req *http.Request
 	}
 }
 
+// LotsPost receives one or more lots of an existing inventory item. The units
+// of every lot are added to the item's units on hand. A lot without an expiry
+// date is given one based on the item's shelf life. The lots are received in
+// the machine given by the optional "machineId" query parameter
+func (c *Controller) LotsPost(writer http.ResponseWriter, req *http.Request) {
+	vars := mux.Vars(req)
+	sku := vars["sku"]
+	machineID := req.URL.Query().Get("machineId")
+	if sku == "" {
+		c.lc.Error("Valid inventory item not in the form of /inventory/{sku}/lots")
+		writer.WriteHeader(http.StatusBadRequest)
+		writer.Write([]byte("Please enter a valid inventory item in the form of /inventory/{sku}/lots"))
+		return
+	}
+
+	// Read request body
+	body := make([]byte, req.ContentLength)
+	if _, err := io.ReadFull(req.Body, body); err != nil {
+		c.lc.Errorf("Failed to process the posted lot(s): %s", err.Error())
+		writer.WriteHeader(http.StatusBadRequest)
+		writer.Write([]byte("Failed to process the posted lot(s): " + err.Error()))
+		return
+	}
+
+	var postedLots []Lot
+	if err := json.Unmarshal(body, &postedLots); err != nil {
+		c.lc.Errorf("Failed to process the posted lot(s): %s", err.Error())
+		writer.WriteHeader(http.StatusBadRequest)
+		writer.Write([]byte("Failed to process the posted lot(s): " + err.Error()))
+		return
+	}
+	for _, lot := range postedLots {
+		if lot.Quantity <= 0 {
+			c.lc.Errorf("Posted lot for SKU %s has a quantity of %d", sku, lot.Quantity)
+			writer.WriteHeader(http.StatusBadRequest)
+			writer.Write([]byte("Every posted lot must have a quantity greater than 0"))
+			return
+		}
+	}
+
+	c.inventoryMutex.Lock()
+	defer c.inventoryMutex.Unlock()
+
+	inventoryItem, inventoryItems, err := c.GetInventoryItemBySKU(sku)
+	if err != nil {
+		c.lc.Errorf("Failed to get inventory item by SKU: %s with error: %s", sku, err.Error())
+		writer.WriteHeader(http.StatusInternalServerError)
+		writer.Write([]byte("Failed to get inventory item by SKU: " + err.Error()))
+		return
+	}
+	if inventoryItem.SKU == "" {
+		c.lc.Infof("Item %s does not exist", sku)
+		writer.WriteHeader(http.StatusNotFound)
+		writer.Write([]byte("Item does not exist"))
+		return
+	}
+
+	now := time.Now().UnixNano()
+	received := 0
+	for i := range inventoryItems.Data {
+		if inventoryItems.Data[i].SKU != sku {
+			continue
+		}
+		for _, lot := range postedLots {
+			inventoryItems.Data[i].receiveMachineLot(machineID, lot, now)
+			received += lot.Quantity
+		}
+		inventoryItems.Data[i].UpdatedAt = now
+		inventoryItem = inventoryItems.Data[i]
+		break
+	}
+
+	if err := c.WriteJSON(c.inventoryFileName, inventoryItems); err != nil {
+		c.lc.Errorf("Failed to write inventory: %s", err.Error())
+		writer.WriteHeader(http.StatusInternalServerError)
+		writer.Write([]byte("Failed to write inventory: " + err.Error()))
+		return
+	}
+	c.recordStockHistory(newStockPoint(inventoryItem, machineID, received, StockSourceRestock, now))
+
+	inventoryItemJSON, err := json.Marshal(inventoryItem)
+	if err != nil {
+		c.lc.Errorf("Received lot(s) for %s, but failed to serialize the inventory item: %s", sku, err.Error())
+		writer.WriteHeader(http.StatusInternalServerError)
+		writer.Write([]byte("Received lot(s), but failed to serialize the inventory item: " + err.Error()))
+		return
+	}
+	c.lc.Infof("Successfully received %d lot(s) for %s", len(postedLots), sku)
+	writer.Write(inventoryItemJSON)
+}
+
+// InventoryCountPost records a physical count of an inventory item: the
+// units on hand are set to the counted units, and the lots are kept in line
+// with the difference. The count is of the machine given by the optional
+// "machineId" query parameter
+func (c *Controller) InventoryCountPost(writer http.ResponseWriter, req *http.Request) {
+	vars := mux.Vars(req)
+	sku := vars["sku"]
+	machineID := req.URL.Query().Get("machineId")
+	if sku == "" {
+		c.lc.Error("Valid inventory item not in the form of /inventory/{sku}/count")
+		writer.WriteHeader(http.StatusBadRequest)
+		writer.Write([]byte("Please enter a valid inventory item in the form of /inventory/{sku}/count"))
+		return
+	}
+
+	var count StockCount
+	if err := json.NewDecoder(req.Body).Decode(&count); err != nil {
+		c.lc.Errorf("Failed to process the posted count: %s", err.Error())
+		writer.WriteHeader(http.StatusBadRequest)
+		writer.Write([]byte("Failed to process the posted count: " + err.Error()))
+		return
+	}
+	if count.UnitsOnHand == nil || *count.UnitsOnHand < 0 {
+		c.lc.Errorf("Posted count for SKU %s has no units on hand, or fewer than 0", sku)
+		writer.WriteHeader(http.StatusBadRequest)
+		writer.Write([]byte("The posted count must have units on hand of 0 or more"))
+		return
+	}
+
+	c.inventoryMutex.Lock()
+	defer c.inventoryMutex.Unlock()
+
+	inventoryItem, inventoryItems, err := c.GetInventoryItemBySKU(sku)
+	if err != nil {
+		c.lc.Errorf("Failed to get inventory item by SKU: %s with error: %s", sku, err.Error())
+		writer.WriteHeader(http.StatusInternalServerError)
+		writer.Write([]byte("Failed to get inventory item by SKU: " + err.Error()))
+		return
+	}
+	if inventoryItem.SKU == "" {
+		c.lc.Infof("Item %s does not exist", sku)
+		writer.WriteHeader(http.StatusNotFound)
+		writer.Write([]byte("Item does not exist"))
+		return
+	}
+
+	now := time.Now().UnixNano()
+	delta := 0
+	for i := range inventoryItems.Data {
+		if inventoryItems.Data[i].SKU != sku {
+			continue
+		}
+		delta = *count.UnitsOnHand - inventoryItems.Data[i].unitsOnHand(machineID)
+		inventoryItems.Data[i].applyStockDelta(machineID, delta, now)
+		inventoryItems.Data[i].UpdatedAt = now
+		inventoryItem = inventoryItems.Data[i]
+		break
+	}
+
+	if err := c.WriteJSON(c.inventoryFileName, inventoryItems); err != nil {
+		c.lc.Errorf("Failed to write inventory: %s", err.Error())
+		writer.WriteHeader(http.StatusInternalServerError)
+		writer.Write([]byte("Failed to write inventory: " + err.Error()))
+		return
+	}
+	c.recordStockHistory(newStockPoint(inventoryItem, machineID, delta, StockSourceCount, now))
+
+	inventoryItemJSON, err := json.Marshal(inventoryItem)
+	if err != nil {
+		c.lc.Errorf("Counted %s, but failed to serialize the inventory item: %s", sku, err.Error())
+		writer.WriteHeader(http.StatusInternalServerError)
+		writer.Write([]byte("Counted the inventory item, but failed to serialize it: " + err.Error()))
+		return
+	}
+	c.lc.Infof("Successfully counted %d units of %s", *count.UnitsOnHand, sku)
+	writer.Write(inventoryItemJSON)
+}
+
 // AuditLogPost allows for a new audit log entry to be added
 func (c *Controller) AuditLogPost(writer http.ResponseWriter, req *http.Request) {
 
@@ -326,6 +599,15 @@ func (c *Controller) AuditLogPost(writer http.ResponseWriter, req *http.Request)
 		return
 	}
 
+	// the chain fields are set by this service, and tombstones are only
+	// appended by deleting an entry
+	if postedAuditLogEntry.Hash != "" || postedAuditLogEntry.PreviousHash != "" || postedAuditLogEntry.DeletedEntryID != "" {
+		c.lc.Error("The posted audit log entry has chain fields defined")
+		writer.WriteHeader(http.StatusBadRequest)
+		writer.Write([]byte("The submitted audit log entry must not have a hash, previousHash or deletedEntryId defined."))
+		return
+	}
+
 	// assign a new UUID to our new audit log entry
 	postedAuditLogEntry.AuditEntryID = uuid.New().String()
 
@@ -334,6 +616,9 @@ func (c *Controller) AuditLogPost(writer http.ResponseWriter, req *http.Request)
 		postedAuditLogEntry.CreatedAt = time.Now().UnixNano()
 	}
 
+	c.auditLogMutex.Lock()
+	defer c.auditLogMutex.Unlock()
+
 	// load the auditlog.json file
 	data, err := os.ReadFile(c.auditLogFileName)
 	if err != nil {
@@ -370,8 +655,14 @@ func (c *Controller) AuditLogPost(writer http.ResponseWriter, req *http.Request)
 	// If we haven't found any conflicting UUID's and there have been no errors,
 	// proceed to update the list
 	if !foundEntry {
-		// write the result
-		auditLog.Data = append(auditLog.Data, postedAuditLogEntry)
+		// chain the entry to the audit log and write the result
+		postedAuditLogEntry, err = auditLog.appendEntry(postedAuditLogEntry)
+		if err != nil {
+			errMsg := fmt.Sprintf("failed to chain audit log entry: %s", err.Error())
+			writer.WriteHeader(http.StatusInternalServerError)
+			writer.Write([]byte(errMsg))
+			return
+		}
 
 		auditLogData, err := json.Marshal(auditLog)
 		if err != nil {
@@ -401,3 +692,165 @@ func (c *Controller) AuditLogPost(writer http.ResponseWriter, req *http.Request)
 		writer.Write(result)
 	}
 }
+
+// PricingRulesPost adds new pricing rules and replaces existing rules that
+// have the same rule ID. Every rule is validated before any of them is saved
+func (c *Controller) PricingRulesPost(writer http.ResponseWriter, req *http.Request) {
+
+	// Read request body
+	body := make([]byte, req.ContentLength)
+	if _, err := io.ReadFull(req.Body, body); err != nil {
+		c.lc.Errorf("Failed to process the posted pricing rule(s): %s", err.Error())
+		writer.WriteHeader(http.StatusBadRequest)
+		writer.Write([]byte("Failed to process the posted pricing rule(s): " + err.Error()))
+		return
+	}
+
+	var postedRules []PricingRule
+	if err := json.Unmarshal(body, &postedRules); err != nil {
+		c.lc.Errorf("Failed to process the posted pricing rule(s): %s", err.Error())
+		writer.WriteHeader(http.StatusBadRequest)
+		writer.Write([]byte("Failed to process the posted pricing rule(s): " + err.Error()))
+		return
+	}
+	for _, rule := range postedRules {
+		if err := rule.validate(); err != nil {
+			c.lc.Errorf("Posted pricing rule %s is invalid: %s", rule.RuleID, err.Error())
+			writer.WriteHeader(http.StatusBadRequest)
+			writer.Write([]byte("Posted pricing rule is invalid: " + err.Error()))
+			return
+		}
+	}
+
+	c.pricingMutex.Lock()
+	defer c.pricingMutex.Unlock()
+
+	pricingRules, err := c.GetPricingRules()
+	if err != nil {
+		c.lc.Errorf("Failed to retrieve all pricing rules: %s", err.Error())
+		writer.WriteHeader(http.StatusInternalServerError)
+		writer.Write([]byte("Failed to retrieve all pricing rules: " + err.Error()))
+		return
+	}
+
+	now := time.Now().UnixNano()
+	for i := range postedRules {
+		rule := &postedRules[i]
+		if rule.RuleID == "" {
+			rule.RuleID = uuid.New().String()
+		}
+		rule.CreatedAt = now
+		rule.UpdatedAt = now
+
+		replaced := false
+		for j, existingRule := range pricingRules.Data {
+			if existingRule.RuleID == rule.RuleID {
+				rule.CreatedAt = existingRule.CreatedAt
+				pricingRules.Data[j] = *rule
+				replaced = true
+				break
+			}
+		}
+		if !replaced {
+			pricingRules.Data = append(pricingRules.Data, *rule)
+		}
+	}
+
+	if err := c.WriteJSON(c.pricingFileName, pricingRules); err != nil {
+		c.lc.Errorf("Failed to write pricing rules: %s", err.Error())
+		writer.WriteHeader(http.StatusInternalServerError)
+		writer.Write([]byte("Failed to write pricing rules: " + err.Error()))
+		return
+	}
+
+	postedRulesJSON, err := json.Marshal(postedRules)
+	if err != nil {
+		c.lc.Errorf("Saved the pricing rule(s), but failed to serialize them: %s", err.Error())
+		writer.WriteHeader(http.StatusInternalServerError)
+		writer.Write([]byte("Saved the pricing rule(s), but failed to serialize them: " + err.Error()))
+		return
+	}
+	c.lc.Infof("Successfully saved %d pricing rule(s)", len(postedRules))
+	writer.Write(postedRulesJSON)
+}
+
+// BasketPricePost prices a basket of SKU deltas, such as the units removed
+// during a vend, with the pricing rules that are active right now, or at the
+// optional pricedAt query time
+func (c *Controller) BasketPricePost(writer http.ResponseWriter, req *http.Request) {
+	pricedAt := time.Now()
+	if value := req.URL.Query().Get("pricedAt"); value != "" {
+		nanos, err := parseHistoryTime(value)
+		if err != nil {
+			c.lc.Errorf("Invalid pricedAt: %s", err.Error())
+			writer.WriteHeader(http.StatusBadRequest)
+			writer.Write([]byte("Invalid pricedAt: " + err.Error()))
+			return
+		}
+		pricedAt = time.Unix(0, nanos)
+	}
+
+	// Read request body
+	body := make([]byte, req.ContentLength)
+	if _, err := io.ReadFull(req.Body, body); err != nil {
+		c.lc.Errorf("Failed to process the posted basket: %s", err.Error())
+		writer.WriteHeader(http.StatusBadRequest)
+		writer.Write([]byte("Failed to process the posted basket: " + err.Error()))
+		return
+	}
+
+	var basket []DeltaInventorySKU
+	if err := json.Unmarshal(body, &basket); err != nil {
+		c.lc.Errorf("Failed to process the posted basket: %s", err.Error())
+		writer.WriteHeader(http.StatusBadRequest)
+		writer.Write([]byte("Failed to process the posted basket: " + err.Error()))
+		return
+	}
+
+	// read the inventory and the pricing rules under their locks so that a
+	// basket is never priced from a file that is being written
+	c.inventoryMutex.Lock()
+	inventoryItems, err := c.GetInventoryItems()
+	c.inventoryMutex.Unlock()
+	if err != nil {
+		c.lc.Errorf("Failed to retrieve all inventory items: %s", err.Error())
+		writer.WriteHeader(http.StatusInternalServerError)
+		writer.Write([]byte("Failed to retrieve all inventory items: " + err.Error()))
+		return
+	}
+	c.pricingMutex.Lock()
+	pricingRules, err := c.GetPricingRules()
+	c.pricingMutex.Unlock()
+	if err != nil {
+		c.lc.Errorf("Failed to retrieve all pricing rules: %s", err.Error())
+		writer.WriteHeader(http.StatusInternalServerError)
+		writer.Write([]byte("Failed to retrieve all pricing rules: " + err.Error()))
+		return
+	}
+
+	currency := c.currency
+	if currency == "" {
+		currency = defaultCurrency
+	}
+	pricedBasket, err := priceBasket(inventoryItems, currency, pricingRules.Data, basket, pricedAt)
+	if err != nil {
+		c.lc.Errorf("Failed to price the posted basket: %s", err.Error())
+		if errors.Is(err, errUnknownSKU) {
+			writer.WriteHeader(http.StatusNotFound)
+		} else {
+			writer.WriteHeader(http.StatusBadRequest)
+		}
+		writer.Write([]byte("Failed to price the posted basket: " + err.Error()))
+		return
+	}
+
+	pricedBasketJSON, err := json.Marshal(pricedBasket)
+	if err != nil {
+		c.lc.Errorf("Failed to serialize the priced basket: %s", err.Error())
+		writer.WriteHeader(http.StatusInternalServerError)
+		writer.Write([]byte("Failed to serialize the priced basket: " + err.Error()))
+		return
+	}
+	c.lc.Infof("Successfully priced basket of %d item(s): %d %s", len(pricedBasket.LineItems), pricedBasket.Total, pricedBasket.Currency)
+	writer.Write(pricedBasketJSON)
+}
@@ -5,12 +5,15 @@ package routes
 
 import (
 	"bytes"
+	"encoding/json"
+	"io"
 	"net/http"
 	"net/http/httptest"
 	"os"
 	"testing"
 
 	"github.com/edgexfoundry/go-mod-core-contracts/v3/clients/logger"
+	"github.com/gorilla/mux"
 	"github.com/stretchr/testify/require"
 )
 
@@ -39,6 +42,8 @@ func TestInventoryPost(t *testing.T) {
 		{"modify inventory item with strings instead of float values", false, `[{"sku": "7777777777","itemPrice": "zero","unitsOnHand": "zero","maxRestockingLevel": "zero","minRestockingLevel": "zero","isActive": false}]`, http.StatusOK, false},
 		{"reduce inventory below 0", false, `[{"sku": "4900002470","itemPrice": 10.5,"unitsOnHand": -10,"maxRestockingLevel": 9,"minRestockingLevel": 1,"isActive": false}]`, http.StatusOK, false},
 		{"raise inventory above max threshold", false, `[{"sku": "4900002470","itemPrice": 10.5,"unitsOnHand": 20,"maxRestockingLevel": 9,"minRestockingLevel": 1,"isActive": false}]`, http.StatusOK, false},
+		{"set inventory item currency", false, `[{"sku": "4900002470","currency": "EUR"}]`, http.StatusOK, false},
+		{"unsupported currency", false, `[{"sku": "4900002470","currency": "dollars"}]`, http.StatusBadRequest, true},
 		{"invalid inventory item", false, `invalid item`, http.StatusBadRequest, true},
 		{"invalid inventory item", true, `[{"sku": "4900002470","itemPrice": 10.5,"unitsOnHand": 2,"maxRestockingLevel": 9,"minRestockingLevel": 1,"isActive": false}]`, http.StatusInternalServerError, true},
 	}
@@ -84,6 +89,167 @@ func TestInventoryPost(t *testing.T) {
 	}
 }
 
+// TestLotsPost tests the function LotsPost
+func TestLotsPost(t *testing.T) {
+	// Product slice
+	products := getDefaultProductsList()
+	products.Data[0].ShelfLifeDays = 14
+	c := Controller{
+		lc:                logger.NewMockClient(),
+		service:           nil,
+		inventoryItems:    products,
+		inventoryFileName: InventoryFileName,
+	}
+
+	tests := []struct {
+		Name               string
+		BadInventory       bool
+		SKU                string
+		LotsString         string
+		ExpectedStatusCode int
+		ExpectedUnits      int
+		ExpectedLots       int
+	}{
+		{"receive lots", false, products.Data[0].SKU, `[{"quantity": 6, "expiresAt": "1900000000000000000"},{"quantity": 2}]`, http.StatusOK, 8, 2},
+		{"receive lot for product without shelf life", false, products.Data[1].SKU, `[{"quantity": 6, "expiresAt": "1900000000000000000"}]`, http.StatusOK, 6, 1},
+		{"missing SKU", false, "", `[{"quantity": 6}]`, http.StatusBadRequest, 0, 0},
+		{"unknown SKU", false, "0000000000", `[{"quantity": 6}]`, http.StatusNotFound, 0, 0},
+		{"invalid lots json", false, products.Data[0].SKU, `invalid lots`, http.StatusBadRequest, 0, 0},
+		{"lot without quantity", false, products.Data[0].SKU, `[{"expiresAt": "1900000000000000000"}]`, http.StatusBadRequest, 0, 0},
+		{"invalid inventory json", true, products.Data[0].SKU, `[{"quantity": 6}]`, http.StatusInternalServerError, 0, 0},
+	}
+
+	for _, test := range tests {
+		currentTest := test
+		t.Run(currentTest.Name, func(t *testing.T) {
+			err := c.DeleteInventory()
+			require.NoError(t, err)
+
+			if currentTest.BadInventory {
+				err := os.WriteFile(c.inventoryFileName, []byte("invalid json test"), 0644)
+				require.NoError(t, err)
+			} else {
+				err := c.WriteInventory()
+				require.NoError(t, err)
+			}
+			defer func() {
+				_ = os.Remove(c.inventoryFileName)
+			}()
+
+			req := httptest.NewRequest("POST", "http://localhost:48095/inventory/"+currentTest.SKU+"/lots", bytes.NewBuffer([]byte(currentTest.LotsString)))
+			req = mux.SetURLVars(req, map[string]string{"sku": currentTest.SKU})
+			w := httptest.NewRecorder()
+			c.LotsPost(w, req)
+			resp := w.Result()
+			defer resp.Body.Close()
+
+			require.Equal(t, currentTest.ExpectedStatusCode, resp.StatusCode, "invalid status code")
+
+			if resp.StatusCode == http.StatusOK {
+				product, _, err := c.GetInventoryItemBySKU(currentTest.SKU)
+				require.NoError(t, err)
+				require.Equal(t, currentTest.ExpectedUnits, product.UnitsOnHand)
+				require.Len(t, product.Lots, currentTest.ExpectedLots)
+				for _, lot := range product.Lots {
+					require.NotZero(t, lot.ExpiresAt, "every lot should have an expiry date")
+				}
+			}
+		})
+	}
+}
+
+// TestInventoryCountPost tests the function InventoryCountPost
+func TestInventoryCountPost(t *testing.T) {
+	products := getDefaultProductsList()
+	products.Data[0].UnitsOnHand = 5
+	c := Controller{
+		lc:                logger.NewMockClient(),
+		service:           nil,
+		inventoryItems:    products,
+		inventoryFileName: InventoryFileName,
+	}
+
+	tests := []struct {
+		Name               string
+		SKU                string
+		MachineID          string
+		CountString        string
+		ExpectedStatusCode int
+		ExpectedUnits      int
+	}{
+		{"count fewer units", products.Data[0].SKU, "", `{"unitsOnHand": 3}`, http.StatusOK, 3},
+		{"count more units", products.Data[0].SKU, "", `{"unitsOnHand": 9}`, http.StatusOK, 9},
+		{"count a machine", products.Data[0].SKU, "machine-7", `{"unitsOnHand": 4}`, http.StatusOK, 4},
+		{"missing SKU", "", "", `{"unitsOnHand": 3}`, http.StatusBadRequest, 0},
+		{"unknown SKU", "0000000000", "", `{"unitsOnHand": 3}`, http.StatusNotFound, 0},
+		{"invalid count json", products.Data[0].SKU, "", `invalid count`, http.StatusBadRequest, 0},
+		{"count without units", products.Data[0].SKU, "", `{}`, http.StatusBadRequest, 0},
+		{"negative count", products.Data[0].SKU, "", `{"unitsOnHand": -1}`, http.StatusBadRequest, 0},
+	}
+
+	for _, test := range tests {
+		currentTest := test
+		t.Run(currentTest.Name, func(t *testing.T) {
+			err := c.WriteInventory()
+			require.NoError(t, err)
+			defer func() {
+				_ = os.Remove(c.inventoryFileName)
+			}()
+
+			req := httptest.NewRequest("POST", "http://localhost:48095/inventory/"+currentTest.SKU+"/count?machineId="+currentTest.MachineID, bytes.NewBuffer([]byte(currentTest.CountString)))
+			req = mux.SetURLVars(req, map[string]string{"sku": currentTest.SKU})
+			w := httptest.NewRecorder()
+			c.InventoryCountPost(w, req)
+			resp := w.Result()
+			defer resp.Body.Close()
+
+			require.Equal(t, currentTest.ExpectedStatusCode, resp.StatusCode, "invalid status code")
+			if resp.StatusCode == http.StatusOK {
+				product, _, err := c.GetInventoryItemBySKU(currentTest.SKU)
+				require.NoError(t, err)
+				require.Equal(t, currentTest.ExpectedUnits, product.unitsOnHand(currentTest.MachineID))
+				if currentTest.MachineID != "" {
+					require.Equal(t, 5, product.UnitsOnHand, "the default machine should not change")
+				}
+			}
+		})
+	}
+}
+
+// TestDeltaInventorySKUPostDepletesLots tests that removals deplete the lots
+// of an item first-expired-first-out
+func TestDeltaInventorySKUPostDepletesLots(t *testing.T) {
+	products := getDefaultProductsList()
+	products.Data[0].UnitsOnHand = 5
+	products.Data[0].Lots = []Lot{
+		{LotID: "late", Quantity: 3, ReceivedAt: 1, ExpiresAt: 200},
+		{LotID: "early", Quantity: 2, ReceivedAt: 2, ExpiresAt: 100},
+	}
+	c := Controller{
+		lc:                logger.NewMockClient(),
+		service:           nil,
+		inventoryItems:    products,
+		inventoryFileName: InventoryFileName,
+	}
+	err := c.WriteInventory()
+	require.NoError(t, err)
+	defer func() {
+		_ = os.Remove(c.inventoryFileName)
+	}()
+
+	req := httptest.NewRequest("POST", "http://localhost:48095/inventory/delta", bytes.NewBuffer([]byte(`[{"SKU": "4900002470","delta": -3}]`)))
+	w := httptest.NewRecorder()
+	c.DeltaInventorySKUPost(w, req)
+	resp := w.Result()
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode, "invalid status code")
+
+	product, _, err := c.GetInventoryItemBySKU("4900002470")
+	require.NoError(t, err)
+	require.Equal(t, 2, product.UnitsOnHand)
+	require.Equal(t, []Lot{{LotID: "late", Quantity: 2, ReceivedAt: 1, ExpiresAt: 200}}, product.Lots)
+}
+
 // TestAuditLogPost tests the function AuditLogPost
 // related functions
 func TestAuditLogPost(t *testing.T) {
@@ -244,3 +410,285 @@ func TestDeltaInventorySKUPost(t *testing.T) {
 		})
 	}
 }
+
+// TestPricingRulesPost tests the function PricingRulesPost
+func TestPricingRulesPost(t *testing.T) {
+	c := Controller{
+		lc:              logger.NewMockClient(),
+		service:         nil,
+		pricingFileName: PricingFileName,
+	}
+
+	tests := []struct {
+		Name               string
+		BadPricingRules    bool
+		RulesString        string
+		ExpectedStatusCode int
+		ExpectedRules      int
+	}{
+		{"add rules", false, `[{"ruleId":"existing","type":"percentDiscount","percent":20,"isActive":true},{"type":"bundle","bundleQuantity":2,"price":3,"isActive":true}]`, http.StatusOK, 2},
+		{"invalid rule", false, `[{"type":"percentDiscount","percent":120}]`, http.StatusBadRequest, 1},
+		{"invalid rules json", false, `invalid rules`, http.StatusBadRequest, 1},
+		{"invalid pricing rules json", true, `[{"type":"percentDiscount","percent":20}]`, http.StatusInternalServerError, 0},
+	}
+
+	for _, test := range tests {
+		currentTest := test
+		t.Run(currentTest.Name, func(t *testing.T) {
+			err := c.WriteJSON(c.pricingFileName, PricingRules{Data: []PricingRule{{RuleID: "existing", Type: PercentDiscountRuleType, Percent: 10, CreatedAt: 1}}})
+			require.NoError(t, err)
+			if currentTest.BadPricingRules {
+				err := os.WriteFile(c.pricingFileName, []byte("invalid json test"), 0644)
+				require.NoError(t, err)
+			}
+			defer func() {
+				_ = os.Remove(c.pricingFileName)
+			}()
+
+			req := httptest.NewRequest("POST", "http://localhost:48095/pricing/rules", bytes.NewBuffer([]byte(currentTest.RulesString)))
+			w := httptest.NewRecorder()
+			c.PricingRulesPost(w, req)
+			resp := w.Result()
+			defer resp.Body.Close()
+
+			require.Equal(t, currentTest.ExpectedStatusCode, resp.StatusCode, "invalid status code")
+			if currentTest.BadPricingRules {
+				return
+			}
+
+			pricingRules, err := c.GetPricingRules()
+			require.NoError(t, err)
+			require.Len(t, pricingRules.Data, currentTest.ExpectedRules)
+			if resp.StatusCode == http.StatusOK {
+				require.Equal(t, 20.0, pricingRules.Data[0].Percent, "the existing rule should be replaced")
+				require.Equal(t, int64(1), pricingRules.Data[0].CreatedAt)
+				require.NotEmpty(t, pricingRules.Data[1].RuleID)
+			}
+		})
+	}
+}
+
+// TestBasketPricePost tests the function BasketPricePost
+func TestBasketPricePost(t *testing.T) {
+	products := getDefaultProductsList()
+	c := Controller{
+		lc:                logger.NewMockClient(),
+		service:           nil,
+		inventoryItems:    products,
+		inventoryFileName: InventoryFileName,
+		pricingFileName:   PricingFileName,
+	}
+
+	tests := []struct {
+		Name               string
+		BadInventory       bool
+		BasketString       string
+		ExpectedStatusCode int
+		Query              string
+		ExpectedTotal      int64
+	}{
+		{"price basket", false, `[{"SKU":"4900002470","delta":-2},{"SKU":"1200010735","delta":-1}]`, http.StatusOK, "", 517},
+		{"price basket during sale", false, `[{"SKU":"4900002470","delta":-2},{"SKU":"1200010735","delta":-1}]`, http.StatusOK, "?pricedAt=2026-09-01T12:00:00Z", 517},
+		{"price basket after sale", false, `[{"SKU":"4900002470","delta":-2},{"SKU":"1200010735","delta":-1}]`, http.StatusOK, "?pricedAt=2100-06-01T12:00:00Z", 597},
+		{"invalid pricedAt", false, `[{"SKU":"4900002470","delta":-1}]`, http.StatusBadRequest, "?pricedAt=yesterday", 0},
+		{"unknown SKU", false, `[{"SKU":"0000000000","delta":-1}]`, http.StatusNotFound, "", 0},
+		{"invalid basket json", false, `invalid basket`, http.StatusBadRequest, "", 0},
+		{"invalid inventory json", true, `[{"SKU":"4900002470","delta":-1}]`, http.StatusInternalServerError, "", 0},
+	}
+
+	for _, test := range tests {
+		currentTest := test
+		t.Run(currentTest.Name, func(t *testing.T) {
+			if currentTest.BadInventory {
+				err := os.WriteFile(c.inventoryFileName, []byte("invalid json test"), 0644)
+				require.NoError(t, err)
+			} else {
+				err := c.WriteInventory()
+				require.NoError(t, err)
+			}
+			err := c.WriteJSON(c.pricingFileName, PricingRules{Data: []PricingRule{
+				{RuleID: "sprite-sale", Type: PercentDiscountRuleType, SKUs: []string{"4900002470"}, Percent: 20, EffectiveUntil: 4102444800000000000, IsActive: true},
+			}})
+			require.NoError(t, err)
+			defer func() {
+				_ = os.Remove(c.inventoryFileName)
+				_ = os.Remove(c.pricingFileName)
+			}()
+
+			req := httptest.NewRequest("POST", "http://localhost:48095/pricing/basket"+currentTest.Query, bytes.NewBuffer([]byte(currentTest.BasketString)))
+			w := httptest.NewRecorder()
+			c.BasketPricePost(w, req)
+			resp := w.Result()
+			defer resp.Body.Close()
+
+			require.Equal(t, currentTest.ExpectedStatusCode, resp.StatusCode, "invalid status code")
+			if resp.StatusCode == http.StatusOK {
+				body, err := io.ReadAll(resp.Body)
+				require.NoError(t, err)
+				var basket PricedBasket
+				require.NoError(t, json.Unmarshal(body, &basket))
+				require.Equal(t, currentTest.ExpectedTotal, basket.Total)
+				require.Len(t, basket.LineItems, 2)
+				if currentTest.ExpectedTotal == 517 {
+					require.Equal(t, []string{"sprite-sale"}, basket.LineItems[0].AppliedRules)
+				}
+			}
+		})
+	}
+}
+
+// TestStockMutationsRecordHistory tests that every stock mutation is recorded
+// in the stock history
+func TestStockMutationsRecordHistory(t *testing.T) {
+	products := getDefaultProductsList()
+	c := Controller{
+		lc:                   logger.NewMockClient(),
+		service:              nil,
+		inventoryItems:       products,
+		inventoryFileName:    InventoryFileName,
+		stockHistoryFileName: StockHistoryFileName,
+	}
+	err := c.WriteInventory()
+	require.NoError(t, err)
+	defer func() {
+		_ = os.Remove(c.inventoryFileName)
+		_ = os.Remove(c.stockHistoryFileName)
+	}()
+
+	req := httptest.NewRequest("POST", "http://localhost:48095/inventory", bytes.NewBuffer([]byte(`[{"sku": "4900002470", "unitsOnHand": 10}]`)))
+	c.InventoryPost(httptest.NewRecorder(), req)
+	req = httptest.NewRequest("POST", "http://localhost:48095/inventory/delta", bytes.NewBuffer([]byte(`[{"SKU": "4900002470","delta": -3}]`)))
+	c.DeltaInventorySKUPost(httptest.NewRecorder(), req)
+	req = httptest.NewRequest("POST", "http://localhost:48095/inventory/4900002470/lots", bytes.NewBuffer([]byte(`[{"quantity": 6}]`)))
+	req = mux.SetURLVars(req, map[string]string{"sku": "4900002470"})
+	c.LotsPost(httptest.NewRecorder(), req)
+	req = httptest.NewRequest("POST", "http://localhost:48095/inventory/4900002470/count", bytes.NewBuffer([]byte(`{"unitsOnHand": 11}`)))
+	req = mux.SetURLVars(req, map[string]string{"sku": "4900002470"})
+	c.InventoryCountPost(httptest.NewRecorder(), req)
+
+	stockHistory, err := c.GetStockHistory()
+	require.NoError(t, err)
+	require.Len(t, stockHistory.Data, 4)
+	expected := []struct {
+		Source      string
+		Delta       int
+		UnitsOnHand int
+	}{{StockSourcePost, 10, 10}, {StockSourceDelta, -3, 7}, {StockSourceRestock, 6, 13}, {StockSourceCount, -2, 11}}
+	for i, point := range stockHistory.Data {
+		require.Equal(t, "4900002470", point.SKU)
+		require.Equal(t, expected[i].Source, point.Source)
+		require.Equal(t, expected[i].Delta, point.Delta)
+		require.Equal(t, expected[i].UnitsOnHand, point.UnitsOnHand)
+		require.NotZero(t, point.Timestamp)
+	}
+}
+
+// TestDeltaInventorySKUPostForMachine tests that deltas with a machine ID only
+// change the stock of that machine
+func TestDeltaInventorySKUPostForMachine(t *testing.T) {
+	products := getDefaultProductsList()
+	products.Data[0].UnitsOnHand = 5
+	c := Controller{
+		lc:                   logger.NewMockClient(),
+		service:              nil,
+		inventoryItems:       products,
+		inventoryFileName:    InventoryFileName,
+		stockHistoryFileName: StockHistoryFileName,
+	}
+	err := c.WriteInventory()
+	require.NoError(t, err)
+	defer func() {
+		_ = os.Remove(c.inventoryFileName)
+		_ = os.Remove(c.stockHistoryFileName)
+	}()
+
+	req := httptest.NewRequest("POST", "http://localhost:48095/inventory/delta", bytes.NewBuffer([]byte(`[{"SKU": "4900002470","delta": 8,"machineId":"machine-7"},{"SKU": "4900002470","delta": -2,"machineId":"machine-7"}]`)))
+	w := httptest.NewRecorder()
+	c.DeltaInventorySKUPost(w, req)
+	resp := w.Result()
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode, "invalid status code")
+
+	product, _, err := c.GetInventoryItemBySKU("4900002470")
+	require.NoError(t, err)
+	require.Equal(t, 5, product.UnitsOnHand, "the default machine should not change")
+	require.Len(t, product.Machines, 1)
+	require.Equal(t, "machine-7", product.Machines[0].MachineID)
+	require.Equal(t, 6, product.Machines[0].UnitsOnHand)
+
+	stockHistory, err := c.GetStockHistory()
+	require.NoError(t, err)
+	require.Len(t, stockHistory.Data, 2)
+	require.Equal(t, "machine-7", stockHistory.Data[1].MachineID)
+	require.Equal(t, 6, stockHistory.Data[1].UnitsOnHand)
+}
+
+// TestDeltaInventorySKUPostNegativeStock tests that deltas that would drive
+// the units on hand below zero are handled according to the
+// NegativeStockHandling setting, and queued and notified as anomalies
+func TestDeltaInventorySKUPostNegativeStock(t *testing.T) {
+	tests := []struct {
+		Name                string
+		Handling            string
+		Body                string
+		ExpectedStatusCode  int
+		ExpectedUnitsOnHand int
+		ExpectedAction      string
+		ExpectedApplied     int
+	}{
+		{"flag", NegativeStockFlag, `[{"SKU": "4900002470","delta": -8}]`, http.StatusOK, -3, AnomalyActionFlagged, -8},
+		{"clamp", NegativeStockClamp, `[{"SKU": "4900002470","delta": -8}]`, http.StatusOK, 0, AnomalyActionClamped, -5},
+		{"reject", NegativeStockReject, `[{"SKU": "4900002470","delta": -8}]`, http.StatusConflict, 5, AnomalyActionRejected, 0},
+		{"reject with other deltas", NegativeStockReject, `[{"SKU": "4900002470","delta": -8},{"SKU": "1200010735","delta": -1}]`, http.StatusOK, 5, AnomalyActionRejected, 0},
+	}
+
+	for _, test := range tests {
+		currentTest := test
+		t.Run(currentTest.Name, func(t *testing.T) {
+			products := getDefaultProductsList()
+			products.Data[0].UnitsOnHand = 5
+			products.Data[1].UnitsOnHand = 5
+			c := &Controller{
+				lc:                    logger.NewMockClient(),
+				service:               nil,
+				inventoryItems:        products,
+				inventoryFileName:     InventoryFileName,
+				stockHistoryFileName:  StockHistoryFileName,
+				anomalyFileName:       AnomalyFileName,
+				negativeStockHandling: currentTest.Handling,
+			}
+			var messages []string
+			c.notificationClient = newUnlockedNotificationClient(t, c, &messages)
+			err := c.WriteInventory()
+			require.NoError(t, err)
+			defer func() {
+				_ = os.Remove(c.inventoryFileName)
+				_ = os.Remove(c.stockHistoryFileName)
+				_ = os.Remove(c.anomalyFileName)
+			}()
+
+			req := httptest.NewRequest("POST", "http://localhost:48095/inventory/delta?auditEntryId=session-1", bytes.NewBuffer([]byte(currentTest.Body)))
+			w := httptest.NewRecorder()
+			c.DeltaInventorySKUPost(w, req)
+			resp := w.Result()
+			defer resp.Body.Close()
+			require.Equal(t, currentTest.ExpectedStatusCode, resp.StatusCode, "invalid status code")
+
+			product, _, err := c.GetInventoryItemBySKU("4900002470")
+			require.NoError(t, err)
+			require.Equal(t, currentTest.ExpectedUnitsOnHand, product.UnitsOnHand)
+
+			anomalies, err := c.GetAnomalies()
+			require.NoError(t, err)
+			require.Len(t, anomalies.Data, 1)
+			anomaly := anomalies.Data[0]
+			require.Equal(t, "4900002470", anomaly.SKU)
+			require.Equal(t, "session-1", anomaly.AuditEntryID)
+			require.Equal(t, -8, anomaly.Delta)
+			require.Equal(t, 5, anomaly.UnitsOnHandBefore)
+			require.Equal(t, currentTest.ExpectedAction, anomaly.Action)
+			require.Equal(t, currentTest.ExpectedApplied, anomaly.AppliedDelta)
+			require.Len(t, messages, 1, "the anomaly should be notified")
+		})
+	}
+}
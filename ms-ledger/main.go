@@ -5,7 +5,6 @@ package main
 
 import (
 	"ms-ledger/routes"
-	"net/url"
 	"os"
 
 	"github.com/edgexfoundry/app-functions-sdk-go/v3/pkg"
@@ -25,19 +24,9 @@ func main() {
 
 	lc := service.LoggingClient()
 
-	inventoryEndpoint, err := service.GetAppSetting("InventoryEndpoint")
+	pricingEndpoint, err := routes.PricingEndpointSetting(service, lc)
 	if err != nil {
-		lc.Errorf("failed load ApplicationSettings: %s", err.Error())
-		os.Exit(1)
-	}
-
-	if len(inventoryEndpoint) == 0 {
-		lc.Error("InventoryEndpoint is not set in ApplicationSettings")
-		os.Exit(1)
-	}
-
-	if _, err := url.Parse(inventoryEndpoint); err != nil {
-		lc.Errorf("InventoryEndpoint from ApplicationSettings is not a valid URL: %s", err.Error())
+		lc.Error(err.Error())
 		os.Exit(1)
 	}
 
@@ -52,13 +41,28 @@ func main() {
 		os.Exit(1)
 	}
 
-	controller := routes.NewController(lc, service, inventoryEndpoint, ledgerFileName)
+	controller := routes.NewController(lc, service, pricingEndpoint, ledgerFileName)
+	if err := controller.LoadSettings(); err != nil {
+		lc.Errorf("failed to load ApplicationSettings: %s", err.Error())
+		os.Exit(1)
+	}
+	if err := controller.MigrateLedgers(); err != nil {
+		lc.Errorf("failed to migrate the ledger JSON file: %s", err.Error())
+		os.Exit(1)
+	}
+	if err := controller.LoadKnownPrices(); err != nil {
+		lc.Errorf("failed to load the last known prices: %s", err.Error())
+		os.Exit(1)
+	}
 	err = controller.AddAllRoutes()
 	if err != nil {
 		lc.Errorf("failed to add all Routes: %s", err.Error())
 		os.Exit(1)
 	}
 
+	go controller.MonitorMonthlyStatements()
+	go controller.MonitorUnverifiedPrices()
+
 	if err := service.Run(); err != nil {
 		lc.Errorf("Run returned error: %s", err.Error())
 		os.Exit(1)
@@ -0,0 +1,138 @@
+// Copyright © 2026 Intel Corporation. All rights reserved.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package routes
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+var (
+	// errAccountNotFound is returned for an account that is not known to
+	// the authentication service
+	errAccountNotFound = errors.New("account not found")
+	// errAccountInactive is returned for an account that is not active in
+	// the authentication service
+	errAccountInactive = errors.New("account is not active")
+)
+
+// getAuthAccount returns the status of an account from the AccountsEndpoint
+// of the authentication service
+func (c *Controller) getAuthAccount(accountID int) (authAccount, error) {
+	client := &http.Client{Timeout: c.requestTimeout()}
+	resp, err := client.Get(strings.TrimSuffix(c.accountsEndpoint, "/") + "/" + strconv.Itoa(accountID))
+	if err != nil {
+		return authAccount{}, fmt.Errorf("error sending request: %v", err.Error())
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return authAccount{}, fmt.Errorf("%w: %d", errAccountNotFound, accountID)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return authAccount{}, fmt.Errorf("error sending request: Received status code %v", resp.Status)
+	}
+
+	var account authAccount
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return authAccount{}, fmt.Errorf("failed to read account %d: %s", accountID, err.Error())
+	}
+	if err := json.Unmarshal(body, &account); err != nil {
+		return authAccount{}, fmt.Errorf("failed to unmarshal account %d: %s", accountID, err.Error())
+	}
+	return account, nil
+}
+
+// getAuthAccounts returns the status of every account from the
+// AccountsEndpoint of the authentication service
+func (c *Controller) getAuthAccounts() ([]authAccount, error) {
+	resp, err := c.sendCommand(http.MethodGet, c.accountsEndpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var accounts []authAccount
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read accounts: %s", err.Error())
+	}
+	if err := json.Unmarshal(body, &accounts); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal accounts: %s", err.Error())
+	}
+	return accounts, nil
+}
+
+// verifyAccount returns an error unless the account exists and is active in
+// the authentication service
+func (c *Controller) verifyAccount(accountID int) error {
+	account, err := c.getAuthAccount(accountID)
+	if err != nil {
+		return err
+	}
+	if !account.IsActive {
+		return fmt.Errorf("%w: %d", errAccountInactive, accountID)
+	}
+	return nil
+}
+
+// provisionAccount adds an empty ledger account for an account that was
+// verified with verifyAccount. The caller writes the ledgers once the account
+// has a transaction
+func (c *Controller) provisionAccount(accountLedgers *Accounts, accountID int) {
+	accountLedgers.Data = append(accountLedgers.Data, Account{AccountID: accountID, Ledgers: []Ledger{}})
+	c.lc.Infof("Provisioned ledger account %d", accountID)
+}
+
+// reconcileAccounts compares the ledger accounts with the accounts of the
+// authentication service
+func reconcileAccounts(accountLedgers Accounts, authAccounts []authAccount) AccountReconciliation {
+	reconciliation := AccountReconciliation{
+		MissingAccounts:  []int{},
+		OrphanedAccounts: []int{},
+		InactiveAccounts: []int{},
+	}
+
+	ledgerAccounts := map[int]bool{}
+	for _, account := range accountLedgers.Data {
+		ledgerAccounts[account.AccountID] = true
+	}
+	authAccountsByID := map[int]authAccount{}
+	for _, account := range authAccounts {
+		authAccountsByID[account.AccountID] = account
+		if account.IsActive && !ledgerAccounts[account.AccountID] {
+			reconciliation.MissingAccounts = append(reconciliation.MissingAccounts, account.AccountID)
+		}
+	}
+	for accountID := range ledgerAccounts {
+		account, ok := authAccountsByID[accountID]
+		if !ok {
+			reconciliation.OrphanedAccounts = append(reconciliation.OrphanedAccounts, accountID)
+		} else if !account.IsActive {
+			reconciliation.InactiveAccounts = append(reconciliation.InactiveAccounts, accountID)
+		}
+	}
+
+	sort.Ints(reconciliation.MissingAccounts)
+	sort.Ints(reconciliation.OrphanedAccounts)
+	sort.Ints(reconciliation.InactiveAccounts)
+	return reconciliation
+}
+
+// hasAccount returns true if there is a ledger account for the account ID
+func (accountLedgers *Accounts) hasAccount(accountID int) bool {
+	for _, account := range accountLedgers.Data {
+		if account.AccountID == accountID {
+			return true
+		}
+	}
+	return false
+}
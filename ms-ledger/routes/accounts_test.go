@@ -0,0 +1,196 @@
+// Copyright © 2026 Intel Corporation. All rights reserved.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package routes
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/edgexfoundry/go-mod-core-contracts/v3/clients/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newAuthenticationTestServer serves the accounts endpoints of the
+// authentication service with account 1 and 3 active and account 4 inactive
+func newAuthenticationTestServer(t *testing.T) *httptest.Server {
+	authAccounts := []authAccount{{AccountID: 1, IsActive: true}, {AccountID: 3, IsActive: true}, {AccountID: 4, IsActive: false}}
+
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/accounts" {
+			data, err := json.Marshal(authAccounts)
+			require.NoError(t, err)
+			w.Write(data)
+			return
+		}
+		accountID, err := strconv.Atoi(strings.TrimPrefix(r.URL.Path, "/accounts/"))
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		for _, account := range authAccounts {
+			if account.AccountID == accountID {
+				data, err := json.Marshal(account)
+				require.NoError(t, err)
+				w.Write(data)
+				return
+			}
+		}
+		w.WriteHeader(http.StatusNotFound)
+	}))
+}
+
+func TestReconcileAccounts(t *testing.T) {
+	// the ledger has accounts 1 and 2, the authentication service 1, 3 and 4
+	reconciliation := reconcileAccounts(getDefaultAccountLedgers(), []authAccount{{AccountID: 1, IsActive: true}, {AccountID: 3, IsActive: true}, {AccountID: 4, IsActive: false}})
+	assert.Equal(t, AccountReconciliation{MissingAccounts: []int{3}, OrphanedAccounts: []int{2}, InactiveAccounts: []int{}}, reconciliation)
+
+	reconciliation = reconcileAccounts(getDefaultAccountLedgers(), []authAccount{{AccountID: 1, IsActive: false}, {AccountID: 2, IsActive: true}})
+	assert.Equal(t, AccountReconciliation{MissingAccounts: []int{}, OrphanedAccounts: []int{}, InactiveAccounts: []int{1}}, reconciliation)
+}
+
+func TestLedgerAddTransactionProvisioning(t *testing.T) {
+	pricingServer := newPricingTestServer(t)
+	authServer := newAuthenticationTestServer(t)
+
+	tests := []struct {
+		Name               string
+		AccountsEndpoint   string
+		AccountID          int
+		ExpectedStatusCode int
+		ExpectedAccounts   int
+	}{
+		{"Active account", authServer.URL + "/accounts", 3, http.StatusOK, 3},
+		{"Inactive account", authServer.URL + "/accounts", 4, http.StatusBadRequest, 2},
+		{"Unknown account", authServer.URL + "/accounts", 10, http.StatusBadRequest, 2},
+		{"Unreachable authentication service", "http://localhost:1/accounts", 3, http.StatusServiceUnavailable, 2},
+		{"Provisioning disabled", "", 3, http.StatusBadRequest, 2},
+	}
+
+	for _, test := range tests {
+		currentTest := test
+		t.Run(currentTest.Name, func(t *testing.T) {
+			c := Controller{
+				lc:               logger.NewMockClient(),
+				pricingEndpoint:  pricingServer.URL,
+				ledgerFileName:   LedgerFileName,
+				accountsEndpoint: currentTest.AccountsEndpoint,
+			}
+			data, err := json.Marshal(getDefaultAccountLedgers())
+			require.NoError(t, err)
+			require.NoError(t, os.WriteFile(c.ledgerFileName, data, 0644))
+			defer func() {
+				os.Remove(c.ledgerFileName)
+			}()
+
+			body := `{"accountId":` + strconv.Itoa(currentTest.AccountID) + `,"deltaSKUs":[{"sku":"4900002470","delta":-1}]}`
+			req := httptest.NewRequest("POST", "http://localhost:48093/ledger", bytes.NewBuffer([]byte(body)))
+			w := httptest.NewRecorder()
+			c.LedgerAddTransaction(w, req)
+			resp := w.Result()
+			defer resp.Body.Close()
+
+			require.Equal(t, currentTest.ExpectedStatusCode, resp.StatusCode, "invalid status code")
+			accountsFromFile, err := c.GetAllLedgers()
+			require.NoError(t, err)
+			require.Len(t, accountsFromFile.Data, currentTest.ExpectedAccounts)
+			if resp.StatusCode == http.StatusOK {
+				assert.Equal(t, currentTest.AccountID, accountsFromFile.Data[2].AccountID)
+				assert.Len(t, accountsFromFile.Data[2].Ledgers, 1)
+			}
+		})
+	}
+}
+
+// TestLedgerAddTransactionProvisioningUnlocked tests that the ledgers are not
+// locked while an account is looked up in the authentication service, and
+// that the account is provisioned once
+func TestLedgerAddTransactionProvisioningUnlocked(t *testing.T) {
+	pricingServer := newPricingTestServer(t)
+	defer pricingServer.Close()
+
+	c := &Controller{
+		lc:              logger.NewMockClient(),
+		pricingEndpoint: pricingServer.URL,
+		ledgerFileName:  LedgerFileName,
+	}
+	authServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		locked := !c.ledgerMutex.TryLock()
+		if !locked {
+			c.ledgerMutex.Unlock()
+		}
+		assert.False(t, locked, "the ledgers should not be locked while the account is looked up")
+		w.Write([]byte(`{"accountID":3,"isActive":true}`))
+	}))
+	defer authServer.Close()
+	c.accountsEndpoint = authServer.URL + "/accounts"
+
+	data, err := json.Marshal(getDefaultAccountLedgers())
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(c.ledgerFileName, data, 0644))
+	defer func() {
+		os.Remove(c.ledgerFileName)
+	}()
+
+	w := httptest.NewRecorder()
+	c.LedgerAddTransaction(w, httptest.NewRequest("POST", "http://localhost:48093/ledger", bytes.NewBuffer([]byte(`{"accountId":3,"deltaSKUs":[{"sku":"4900002470","delta":-1}]}`))))
+	require.Equal(t, http.StatusOK, w.Code)
+
+	accountsFromFile, err := c.GetAllLedgers()
+	require.NoError(t, err)
+	require.Len(t, accountsFromFile.Data, 3)
+	assert.Len(t, accountsFromFile.Data[2].Ledgers, 1)
+}
+
+func TestReconciliationGet(t *testing.T) {
+	authServer := newAuthenticationTestServer(t)
+
+	tests := []struct {
+		Name               string
+		AccountsEndpoint   string
+		ExpectedStatusCode int
+	}{
+		{"Valid reconciliation", authServer.URL + "/accounts", http.StatusOK},
+		{"Unreachable authentication service", "http://localhost:1/accounts", http.StatusServiceUnavailable},
+		{"AccountsEndpoint not set", "", http.StatusServiceUnavailable},
+	}
+
+	for _, test := range tests {
+		currentTest := test
+		t.Run(currentTest.Name, func(t *testing.T) {
+			c := Controller{
+				lc:               logger.NewMockClient(),
+				ledgerFileName:   LedgerFileName,
+				accountsEndpoint: currentTest.AccountsEndpoint,
+			}
+			data, err := json.Marshal(getDefaultAccountLedgers())
+			require.NoError(t, err)
+			require.NoError(t, os.WriteFile(c.ledgerFileName, data, 0644))
+			defer func() {
+				os.Remove(c.ledgerFileName)
+			}()
+
+			req := httptest.NewRequest("GET", "http://localhost:48093/ledgerReconciliation", nil)
+			w := httptest.NewRecorder()
+			c.ReconciliationGet(w, req)
+			resp := w.Result()
+			defer resp.Body.Close()
+
+			require.Equal(t, currentTest.ExpectedStatusCode, resp.StatusCode, "invalid status code")
+			if resp.StatusCode != http.StatusOK {
+				return
+			}
+			var reconciliation AccountReconciliation
+			require.NoError(t, json.NewDecoder(resp.Body).Decode(&reconciliation))
+			assert.Equal(t, []int{3}, reconciliation.MissingAccounts)
+			assert.Equal(t, []int{2}, reconciliation.OrphanedAccounts)
+		})
+	}
+}
@@ -0,0 +1,195 @@
+// Copyright © 2026 Intel Corporation. All rights reserved.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package routes
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Types of adjustment transactions
+const (
+	// TransactionTypeRefund returns some or all units of a sale
+	TransactionTypeRefund = "refund"
+	// TransactionTypeVoid cancels an unpaid sale as a whole
+	TransactionTypeVoid = "void"
+)
+
+// Reason codes of adjustment transactions
+const (
+	ReasonMisdetection   = "misdetection"
+	ReasonDamagedProduct = "damagedProduct"
+	ReasonExpiredProduct = "expiredProduct"
+	ReasonGoodwill       = "goodwill"
+	ReasonOther          = "other"
+)
+
+var (
+	// errInvalidAdjustment is returned for an adjustment request that can
+	// never be applied, such as a refund of a SKU that was not sold
+	errInvalidAdjustment = errors.New("invalid adjustment")
+	// errAdjustmentConflict is returned for an adjustment request that
+	// conflicts with the state of the sale, such as a void of a paid sale
+	errAdjustmentConflict = errors.New("adjustment conflicts with the transaction")
+)
+
+// validReasonCode returns true if the code is one of the adjustment reason
+// codes
+func validReasonCode(code string) bool {
+	switch code {
+	case ReasonMisdetection, ReasonDamagedProduct, ReasonExpiredProduct, ReasonGoodwill, ReasonOther:
+		return true
+	}
+	return false
+}
+
+// adjustedUnits returns the number of units of every SKU of a sale that were
+// refunded or voided so far
+func (account *Account) adjustedUnits(transactionID int64) map[string]int {
+	units := map[string]int{}
+	for _, ledger := range account.Ledgers {
+		if ledger.AdjustsTransactionID != transactionID {
+			continue
+		}
+		for _, lineItem := range ledger.LineItems {
+			units[lineItem.SKU] -= lineItem.ItemCount
+		}
+	}
+	return units
+}
+
+// hasAdjustments returns true if the sale was refunded or voided before
+func (account *Account) hasAdjustments(transactionID int64) bool {
+	for _, ledger := range account.Ledgers {
+		if ledger.AdjustsTransactionID == transactionID {
+			return true
+		}
+	}
+	return false
+}
+
+// shareOf returns the share of an amount that belongs to units out of total
+// units, rounded half away from zero. The shares of consecutive ranges of
+// units always add up to the whole amount
+func shareOf(amount int64, units int, total int) int64 {
+	if total == 0 {
+		return 0
+	}
+	if amount < 0 {
+		return -shareOf(-amount, units, total)
+	}
+	return (amount*int64(units) + int64(total)/2) / int64(total)
+}
+
+// newAdjustment returns the refund or void of a sale of the account as a new
+// transaction with the transactionID. The sale itself is never changed.
+// Every line item of the adjustment takes the share of the amounts of the
+// sale's line item that belongs to the adjusted units, with the sign reversed
+func newAdjustment(account Account, sale Ledger, request adjustmentRequest, transactionID int64, now int64) (Ledger, error) {
+	if sale.Type != "" {
+		return Ledger{}, fmt.Errorf("%w: transaction %d is a %s and cannot be adjusted", errInvalidAdjustment, sale.TransactionID, sale.Type)
+	}
+	if !validReasonCode(request.ReasonCode) {
+		return Ledger{}, fmt.Errorf("%w: unknown reason code %q", errInvalidAdjustment, request.ReasonCode)
+	}
+	if request.Operator == "" {
+		return Ledger{}, fmt.Errorf("%w: the operator is required", errInvalidAdjustment)
+	}
+
+	adjustedUnits := account.adjustedUnits(sale.TransactionID)
+	unitsToAdjust := map[string]int{}
+	switch request.Type {
+	case TransactionTypeVoid:
+		if len(request.LineItems) > 0 {
+			return Ledger{}, fmt.Errorf("%w: a void cancels the whole transaction and cannot have line items", errInvalidAdjustment)
+		}
+		if sale.IsPaid {
+			return Ledger{}, fmt.Errorf("%w: transaction %d is paid and can only be refunded", errAdjustmentConflict, sale.TransactionID)
+		}
+		if account.hasAdjustments(sale.TransactionID) {
+			return Ledger{}, fmt.Errorf("%w: transaction %d was adjusted before and can only be refunded", errAdjustmentConflict, sale.TransactionID)
+		}
+		for _, lineItem := range sale.LineItems {
+			unitsToAdjust[lineItem.SKU] = lineItem.ItemCount
+		}
+	case TransactionTypeRefund:
+		if len(request.LineItems) == 0 {
+			for _, lineItem := range sale.LineItems {
+				unitsToAdjust[lineItem.SKU] = lineItem.ItemCount - adjustedUnits[lineItem.SKU]
+			}
+			break
+		}
+		for _, requested := range request.LineItems {
+			if requested.ItemCount <= 0 {
+				return Ledger{}, fmt.Errorf("%w: the itemCount of SKU %s must be greater than 0", errInvalidAdjustment, requested.SKU)
+			}
+			unitsToAdjust[requested.SKU] += requested.ItemCount
+		}
+		for sku, units := range unitsToAdjust {
+			sold := 0
+			for _, lineItem := range sale.LineItems {
+				if lineItem.SKU == sku {
+					sold = lineItem.ItemCount
+				}
+			}
+			if sold == 0 {
+				return Ledger{}, fmt.Errorf("%w: SKU %s is not part of transaction %d", errInvalidAdjustment, sku, sale.TransactionID)
+			}
+			if units > sold-adjustedUnits[sku] {
+				return Ledger{}, fmt.Errorf("%w: only %d units of SKU %s are left to refund", errAdjustmentConflict, sold-adjustedUnits[sku], sku)
+			}
+		}
+	default:
+		return Ledger{}, fmt.Errorf("%w: unknown adjustment type %q", errInvalidAdjustment, request.Type)
+	}
+
+	adjustment := Ledger{
+		TransactionID:        transactionID,
+		Type:                 request.Type,
+		AdjustsTransactionID: sale.TransactionID,
+		ReasonCode:           request.ReasonCode,
+		Operator:             request.Operator,
+		Note:                 request.Note,
+		TxTimeStamp:          now,
+		Currency:             sale.Currency,
+		TaxJurisdiction:      sale.TaxJurisdiction,
+		CreatedAt:            now,
+		UpdatedAt:            now,
+		IsPaid:               false,
+		LineItems:            []LineItem{},
+	}
+	for _, lineItem := range sale.LineItems {
+		units := unitsToAdjust[lineItem.SKU]
+		if units <= 0 {
+			continue
+		}
+		adjusted := adjustedUnits[lineItem.SKU]
+		share := func(amount int64) int64 {
+			return shareOf(amount, adjusted+units, lineItem.ItemCount) - shareOf(amount, adjusted, lineItem.ItemCount)
+		}
+		adjustment.LineItems = append(adjustment.LineItems, LineItem{
+			SKU:         lineItem.SKU,
+			ProductName: lineItem.ProductName,
+			ItemPrice:   lineItem.ItemPrice,
+			ItemCount:   -units,
+			Discount:    -share(lineItem.Discount),
+			LineTotal:   -share(lineItem.LineTotal),
+			Currency:    lineItem.Currency,
+			TaxCategory: lineItem.TaxCategory,
+			TaxRate:     lineItem.TaxRate,
+			Tax:         -share(lineItem.Tax),
+		})
+	}
+	if len(adjustment.LineItems) == 0 {
+		return Ledger{}, fmt.Errorf("%w: every unit of transaction %d was refunded already", errAdjustmentConflict, sale.TransactionID)
+	}
+
+	for _, lineItem := range adjustment.LineItems {
+		adjustment.Subtotal += lineItem.LineTotal
+		adjustment.TaxTotal += lineItem.Tax
+	}
+	adjustment.LineTotal = adjustment.Subtotal
+	adjustment.GrandTotal = adjustment.Subtotal + adjustment.TaxTotal
+	return adjustment, nil
+}
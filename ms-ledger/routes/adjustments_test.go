@@ -0,0 +1,135 @@
+// Copyright © 2026 Intel Corporation. All rights reserved.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package routes
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// getAdjustableSale returns a taxed sale of three discounted units of one
+// SKU and one unit of another
+func getAdjustableSale() Ledger {
+	return Ledger{
+		TransactionID: 1000,
+		Subtotal:      676,
+		LineTotal:     676,
+		TaxTotal:      49,
+		GrandTotal:    725,
+		Currency:      "USD",
+		LineItems: []LineItem{{
+			SKU:         "4900002470",
+			ItemPrice:   199,
+			ItemCount:   3,
+			Discount:    100,
+			LineTotal:   497,
+			Currency:    "USD",
+			TaxRate:     7.25,
+			Tax:         36,
+			ProductName: "Sprite (Lemon-Lime) - 16.9 oz",
+		}, {
+			SKU:         "1200050408",
+			ItemPrice:   179,
+			ItemCount:   1,
+			LineTotal:   179,
+			Currency:    "USD",
+			TaxRate:     7.25,
+			Tax:         13,
+			ProductName: "Mountain Dew - 16.9 oz",
+		}},
+	}
+}
+
+func TestNewAdjustment(t *testing.T) {
+	sale := getAdjustableSale()
+
+	tests := []struct {
+		Name               string
+		Sale               Ledger
+		Request            adjustmentRequest
+		ExpectedErr        error
+		ExpectedGrandTotal int64
+	}{
+		{"full refund", sale, adjustmentRequest{Type: TransactionTypeRefund, ReasonCode: ReasonGoodwill, Operator: "jdoe"}, nil, -725},
+		{"partial refund", sale, adjustmentRequest{Type: TransactionTypeRefund, ReasonCode: ReasonDamagedProduct, Operator: "jdoe", LineItems: []adjustmentLineItem{{SKU: "4900002470", ItemCount: 1}}}, nil, -178},
+		{"void", sale, adjustmentRequest{Type: TransactionTypeVoid, ReasonCode: ReasonMisdetection, Operator: "jdoe"}, nil, -725},
+		{"void of paid sale", func() Ledger { paid := getAdjustableSale(); paid.IsPaid = true; return paid }(), adjustmentRequest{Type: TransactionTypeVoid, ReasonCode: ReasonMisdetection, Operator: "jdoe"}, errAdjustmentConflict, 0},
+		{"void with line items", sale, adjustmentRequest{Type: TransactionTypeVoid, ReasonCode: ReasonMisdetection, Operator: "jdoe", LineItems: []adjustmentLineItem{{SKU: "4900002470", ItemCount: 1}}}, errInvalidAdjustment, 0},
+		{"refund of too many units", sale, adjustmentRequest{Type: TransactionTypeRefund, ReasonCode: ReasonGoodwill, Operator: "jdoe", LineItems: []adjustmentLineItem{{SKU: "4900002470", ItemCount: 4}}}, errAdjustmentConflict, 0},
+		{"refund of unsold SKU", sale, adjustmentRequest{Type: TransactionTypeRefund, ReasonCode: ReasonGoodwill, Operator: "jdoe", LineItems: []adjustmentLineItem{{SKU: "0000000000", ItemCount: 1}}}, errInvalidAdjustment, 0},
+		{"refund of no units", sale, adjustmentRequest{Type: TransactionTypeRefund, ReasonCode: ReasonGoodwill, Operator: "jdoe", LineItems: []adjustmentLineItem{{SKU: "4900002470", ItemCount: 0}}}, errInvalidAdjustment, 0},
+		{"unknown type", sale, adjustmentRequest{Type: "chargeback", ReasonCode: ReasonGoodwill, Operator: "jdoe"}, errInvalidAdjustment, 0},
+		{"unknown reason code", sale, adjustmentRequest{Type: TransactionTypeRefund, ReasonCode: "typo", Operator: "jdoe"}, errInvalidAdjustment, 0},
+		{"missing operator", sale, adjustmentRequest{Type: TransactionTypeRefund, ReasonCode: ReasonGoodwill}, errInvalidAdjustment, 0},
+		{"adjustment of an adjustment", Ledger{TransactionID: 1000, Type: TransactionTypeRefund}, adjustmentRequest{Type: TransactionTypeRefund, ReasonCode: ReasonGoodwill, Operator: "jdoe"}, errInvalidAdjustment, 0},
+	}
+
+	for _, test := range tests {
+		currentTest := test
+		t.Run(currentTest.Name, func(t *testing.T) {
+			account := Account{AccountID: 1, Ledgers: []Ledger{currentTest.Sale}}
+			adjustment, err := newAdjustment(account, currentTest.Sale, currentTest.Request, 2000, 2000)
+			if currentTest.ExpectedErr != nil {
+				require.ErrorIs(t, err, currentTest.ExpectedErr)
+				return
+			}
+			require.NoError(t, err)
+
+			assert.Equal(t, int64(2000), adjustment.TransactionID)
+			assert.Equal(t, currentTest.Request.Type, adjustment.Type)
+			assert.Equal(t, currentTest.Sale.TransactionID, adjustment.AdjustsTransactionID)
+			assert.Equal(t, currentTest.Request.ReasonCode, adjustment.ReasonCode)
+			assert.Equal(t, currentTest.Request.Operator, adjustment.Operator)
+			assert.Equal(t, currentTest.ExpectedGrandTotal, adjustment.GrandTotal)
+			assert.Equal(t, adjustment.Subtotal+adjustment.TaxTotal, adjustment.GrandTotal)
+			for _, lineItem := range adjustment.LineItems {
+				assert.Less(t, lineItem.ItemCount, 0, "adjusted units are negative")
+			}
+		})
+	}
+}
+
+// TestNewAdjustmentPartialRefunds tests that refunding every unit of a sale
+// one at a time adds up to exactly the amounts of the sale
+func TestNewAdjustmentPartialRefunds(t *testing.T) {
+	sale := getAdjustableSale()
+	account := Account{AccountID: 1, Ledgers: []Ledger{sale}}
+
+	for i := 0; i < 3; i++ {
+		refund, err := newAdjustment(account, sale, adjustmentRequest{Type: TransactionTypeRefund, ReasonCode: ReasonDamagedProduct, Operator: "jdoe", LineItems: []adjustmentLineItem{{SKU: "4900002470", ItemCount: 1}}}, int64(2000+i), int64(2000+i))
+		require.NoError(t, err)
+		account.Ledgers = append(account.Ledgers, refund)
+	}
+	_, err := newAdjustment(account, sale, adjustmentRequest{Type: TransactionTypeRefund, ReasonCode: ReasonDamagedProduct, Operator: "jdoe", LineItems: []adjustmentLineItem{{SKU: "4900002470", ItemCount: 1}}}, 3000, 3000)
+	require.ErrorIs(t, err, errAdjustmentConflict, "every unit of the SKU was refunded")
+	_, err = newAdjustment(account, sale, adjustmentRequest{Type: TransactionTypeVoid, ReasonCode: ReasonMisdetection, Operator: "jdoe"}, 3000, 3000)
+	require.ErrorIs(t, err, errAdjustmentConflict, "a partially refunded sale cannot be voided")
+
+	// the remaining SKU is refunded by a full refund
+	refund, err := newAdjustment(account, sale, adjustmentRequest{Type: TransactionTypeRefund, ReasonCode: ReasonGoodwill, Operator: "jdoe"}, 4000, 4000)
+	require.NoError(t, err)
+	require.Len(t, refund.LineItems, 1)
+	assert.Equal(t, "1200050408", refund.LineItems[0].SKU)
+	account.Ledgers = append(account.Ledgers, refund)
+
+	var lineTotal, discount, tax int64
+	for _, adjustment := range account.Ledgers[1:] {
+		for _, lineItem := range adjustment.LineItems {
+			if lineItem.SKU == "4900002470" {
+				lineTotal += lineItem.LineTotal
+				discount += lineItem.Discount
+				tax += lineItem.Tax
+			}
+		}
+	}
+	assert.Equal(t, int64(-497), lineTotal)
+	assert.Equal(t, int64(-100), discount)
+	assert.Equal(t, int64(-36), tax)
+	assert.Equal(t, map[string]int64{"USD": 0}, netCharges(account), "the sale is refunded in full")
+
+	_, err = newAdjustment(account, sale, adjustmentRequest{Type: TransactionTypeRefund, ReasonCode: ReasonGoodwill, Operator: "jdoe"}, 5000, 5000)
+	require.ErrorIs(t, err, errAdjustmentConflict)
+}
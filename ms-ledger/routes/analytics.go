@@ -0,0 +1,156 @@
+// Copyright © 2026 Intel Corporation. All rights reserved.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package routes
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+)
+
+const (
+	// defaultAnalyticsPeriod is the period of a sales report without a
+	// "from" query parameter
+	defaultAnalyticsPeriod = 7 * 24 * time.Hour
+	// defaultTopProducts is the number of top products of a sales report
+	// without a "top" query parameter
+	defaultTopProducts = 5
+)
+
+// salesBuckets sums the revenue, units and sales of the line items of a
+// period by a key, such as a SKU or a day
+type salesBuckets struct {
+	buckets map[string]*SalesBucket
+	sales   map[string]map[int64]bool
+}
+
+// add adds a line item of a transaction to the bucket of the key. Only sales
+// count as transactions of a bucket, so adjustments change its revenue and
+// units but not the number of transactions
+func (salesBuckets *salesBuckets) add(key string, ledger Ledger, lineItem LineItem) {
+	if salesBuckets.buckets == nil {
+		salesBuckets.buckets = map[string]*SalesBucket{}
+		salesBuckets.sales = map[string]map[int64]bool{}
+	}
+	bucket, ok := salesBuckets.buckets[key]
+	if !ok {
+		bucket = &SalesBucket{Key: key}
+		salesBuckets.buckets[key] = bucket
+		salesBuckets.sales[key] = map[int64]bool{}
+	}
+	bucket.Revenue += lineItem.LineTotal
+	bucket.Units += lineItem.ItemCount
+	if ledger.Type == "" && !salesBuckets.sales[key][ledger.TransactionID] {
+		salesBuckets.sales[key][ledger.TransactionID] = true
+		bucket.Transactions++
+	}
+}
+
+// sorted returns the buckets sorted by their key
+func (salesBuckets *salesBuckets) sorted() []SalesBucket {
+	sorted := []SalesBucket{}
+	for _, bucket := range salesBuckets.buckets {
+		sorted = append(sorted, *bucket)
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].Key < sorted[j].Key
+	})
+	return sorted
+}
+
+// buildSalesReport returns the sales of every account in the currency
+// between from and to, computed from the line items of the transactions.
+// Revenue is the line total before tax, net of discounts and adjustments.
+// Adjustments count when they are made, so a refund lowers the revenue of
+// the day it was made. The basket averages are taken over the sales of the
+// period that were not voided
+func buildSalesReport(accountLedgers Accounts, currency string, from int64, to int64, top int, now int64) SalesReport {
+	report := SalesReport{
+		From:        from,
+		To:          to,
+		Currency:    currency,
+		TopProducts: []SalesBucket{},
+		GeneratedAt: now,
+	}
+
+	voided := map[int64]bool{}
+	for _, account := range accountLedgers.Data {
+		for _, ledger := range account.Ledgers {
+			if ledger.Type == TransactionTypeVoid {
+				voided[ledger.AdjustsTransactionID] = true
+			}
+		}
+	}
+
+	var bySKU, byHour, byDay, byAccount salesBuckets
+	productNames := map[string]string{}
+	basketUnits := 0
+	var basketValue int64
+	for _, account := range accountLedgers.Data {
+		for _, ledger := range account.Ledgers {
+			if ledger.Currency != currency || ledger.TxTimeStamp < from || ledger.TxTimeStamp >= to {
+				continue
+			}
+			txTime := time.Unix(0, ledger.TxTimeStamp).UTC()
+			for _, lineItem := range ledger.LineItems {
+				bySKU.add(lineItem.SKU, ledger, lineItem)
+				byHour.add(fmt.Sprintf("%02d", txTime.Hour()), ledger, lineItem)
+				byDay.add(txTime.Format(statementDateLayout), ledger, lineItem)
+				byAccount.add(strconv.Itoa(account.AccountID), ledger, lineItem)
+				report.Revenue += lineItem.LineTotal
+				report.Units += lineItem.ItemCount
+				if lineItem.ProductName != "" {
+					productNames[lineItem.SKU] = lineItem.ProductName
+				}
+			}
+
+			if ledger.Type == "" && !voided[ledger.TransactionID] {
+				report.Transactions++
+				basketValue += ledger.LineTotal
+				for _, lineItem := range ledger.LineItems {
+					basketUnits += lineItem.ItemCount
+				}
+			}
+		}
+	}
+
+	if report.Transactions > 0 {
+		report.AverageBasketUnits = float64(basketUnits) / float64(report.Transactions)
+		report.AverageBasketValue = (basketValue + int64(report.Transactions)/2) / int64(report.Transactions)
+	}
+
+	report.BySKU = bySKU.sorted()
+	for i := range report.BySKU {
+		report.BySKU[i].ProductName = productNames[report.BySKU[i].Key]
+	}
+	// every hour of the day is reported, so hours without sales show up
+	report.ByHour = make([]SalesBucket, 24)
+	for hour := range report.ByHour {
+		key := fmt.Sprintf("%02d", hour)
+		report.ByHour[hour] = SalesBucket{Key: key}
+		if bucket, ok := byHour.buckets[key]; ok {
+			report.ByHour[hour] = *bucket
+		}
+	}
+	report.ByDay = byDay.sorted()
+	report.ByAccount = byAccount.sorted()
+	sort.SliceStable(report.ByAccount, func(i, j int) bool {
+		a, _ := strconv.Atoi(report.ByAccount[i].Key)
+		b, _ := strconv.Atoi(report.ByAccount[j].Key)
+		return a < b
+	})
+
+	report.TopProducts = append(report.TopProducts, report.BySKU...)
+	sort.SliceStable(report.TopProducts, func(i, j int) bool {
+		if report.TopProducts[i].Revenue != report.TopProducts[j].Revenue {
+			return report.TopProducts[i].Revenue > report.TopProducts[j].Revenue
+		}
+		return report.TopProducts[i].Units > report.TopProducts[j].Units
+	})
+	if len(report.TopProducts) > top {
+		report.TopProducts = report.TopProducts[:top]
+	}
+	return report
+}
@@ -0,0 +1,138 @@
+// Copyright © 2026 Intel Corporation. All rights reserved.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package routes
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/edgexfoundry/go-mod-core-contracts/v3/clients/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// analyticsTime returns the timestamp of an hour of a UTC day in September
+// 2026
+func analyticsTime(day int, hour int) int64 {
+	return time.Date(2026, time.September, day, hour, 0, 0, 0, time.UTC).UnixNano()
+}
+
+// getAnalyticsAccountLedgers returns two accounts with sales, a refund and a
+// void in September, a sale in another currency and a sale in October
+func getAnalyticsAccountLedgers() Accounts {
+	cola := func(count int) LineItem {
+		return LineItem{SKU: "1", ProductName: "Cola", ItemPrice: 100, ItemCount: count, LineTotal: int64(count) * 100, Currency: "USD"}
+	}
+	water := func(count int) LineItem {
+		return LineItem{SKU: "2", ProductName: "Water", ItemPrice: 150, ItemCount: count, LineTotal: int64(count) * 150, Currency: "USD"}
+	}
+	return Accounts{
+		SchemaVersion: ledgerSchemaVersion,
+		Data: []Account{{
+			AccountID: 1,
+			Ledgers: []Ledger{
+				{TransactionID: 1, TxTimeStamp: analyticsTime(10, 9), LineTotal: 350, Currency: "USD", LineItems: []LineItem{cola(2), water(1)}},
+				{TransactionID: 4, Type: TransactionTypeRefund, AdjustsTransactionID: 1, TxTimeStamp: analyticsTime(11, 10), LineTotal: -100, Currency: "USD", LineItems: []LineItem{cola(-1)}},
+				{TransactionID: 6, TxTimeStamp: analyticsTime(10, 9), LineTotal: 100, Currency: "EUR", LineItems: []LineItem{{SKU: "1", ItemCount: 1, LineTotal: 100, Currency: "EUR"}}},
+				{TransactionID: 7, TxTimeStamp: time.Date(2026, time.October, 2, 9, 0, 0, 0, time.UTC).UnixNano(), LineTotal: 100, Currency: "USD", LineItems: []LineItem{cola(1)}},
+			},
+		}, {
+			AccountID: 2,
+			Ledgers: []Ledger{
+				{TransactionID: 2, TxTimeStamp: analyticsTime(10, 14), LineTotal: 100, Currency: "USD", LineItems: []LineItem{cola(1)}},
+				{TransactionID: 3, TxTimeStamp: analyticsTime(12, 9), LineTotal: 150, Currency: "USD", LineItems: []LineItem{water(1)}},
+				{TransactionID: 5, Type: TransactionTypeVoid, AdjustsTransactionID: 3, TxTimeStamp: analyticsTime(12, 10), LineTotal: -150, Currency: "USD", LineItems: []LineItem{water(-1)}},
+			},
+		}},
+	}
+}
+
+func TestBuildSalesReport(t *testing.T) {
+	from := time.Date(2026, time.September, 1, 0, 0, 0, 0, time.UTC).UnixNano()
+	to := time.Date(2026, time.October, 1, 0, 0, 0, 0, time.UTC).UnixNano()
+
+	report := buildSalesReport(getAnalyticsAccountLedgers(), "USD", from, to, 1, to)
+
+	assert.Equal(t, int64(350), report.Revenue)
+	assert.Equal(t, 3, report.Units)
+	assert.Equal(t, 2, report.Transactions, "the voided sale should not count")
+	assert.Equal(t, 2.0, report.AverageBasketUnits)
+	assert.Equal(t, int64(225), report.AverageBasketValue)
+	assert.Equal(t, []SalesBucket{
+		{Key: "1", ProductName: "Cola", Revenue: 200, Units: 2, Transactions: 2},
+		{Key: "2", ProductName: "Water", Revenue: 150, Units: 1, Transactions: 2},
+	}, report.BySKU)
+	require.Len(t, report.ByHour, 24)
+	assert.Equal(t, SalesBucket{Key: "00"}, report.ByHour[0])
+	assert.Equal(t, SalesBucket{Key: "09", Revenue: 500, Units: 4, Transactions: 2}, report.ByHour[9])
+	assert.Equal(t, SalesBucket{Key: "10", Revenue: -250, Units: -2}, report.ByHour[10])
+	assert.Equal(t, SalesBucket{Key: "14", Revenue: 100, Units: 1, Transactions: 1}, report.ByHour[14])
+	assert.Equal(t, []SalesBucket{
+		{Key: "2026-09-10", Revenue: 450, Units: 4, Transactions: 2},
+		{Key: "2026-09-11", Revenue: -100, Units: -1},
+		{Key: "2026-09-12", Transactions: 1},
+	}, report.ByDay)
+	assert.Equal(t, []SalesBucket{
+		{Key: "1", Revenue: 250, Units: 2, Transactions: 1},
+		{Key: "2", Revenue: 100, Units: 1, Transactions: 2},
+	}, report.ByAccount)
+	assert.Equal(t, []SalesBucket{{Key: "1", ProductName: "Cola", Revenue: 200, Units: 2, Transactions: 2}}, report.TopProducts)
+
+	empty := buildSalesReport(getAnalyticsAccountLedgers(), "GBP", from, to, 5, to)
+	assert.Equal(t, 0, empty.Transactions)
+	assert.Equal(t, 0.0, empty.AverageBasketUnits)
+	assert.Empty(t, empty.BySKU)
+	assert.Empty(t, empty.TopProducts)
+}
+
+func TestSalesAnalyticsGet(t *testing.T) {
+	tests := []struct {
+		Name                 string
+		Query                string
+		ExpectedStatusCode   int
+		ExpectedTransactions int
+	}{
+		{"Valid period", "?from=2026-09-01&to=2026-10-01", http.StatusOK, 2},
+		{"Valid currency", "?from=2026-09-01&to=2026-10-01&currency=EUR&top=1", http.StatusOK, 1},
+		{"Default period", "", http.StatusOK, 0},
+		{"Invalid from", "?from=September", http.StatusBadRequest, 0},
+		{"From after to", "?from=2026-10-01&to=2026-09-01", http.StatusBadRequest, 0},
+		{"Invalid currency", "?currency=dollars", http.StatusBadRequest, 0},
+		{"Invalid top", "?top=0", http.StatusBadRequest, 0},
+	}
+
+	for _, test := range tests {
+		currentTest := test
+		t.Run(currentTest.Name, func(t *testing.T) {
+			c := Controller{
+				lc:             logger.NewMockClient(),
+				ledgerFileName: LedgerFileName,
+			}
+			data, err := json.Marshal(getAnalyticsAccountLedgers())
+			require.NoError(t, err)
+			require.NoError(t, os.WriteFile(c.ledgerFileName, data, 0644))
+			defer func() {
+				os.Remove(c.ledgerFileName)
+			}()
+
+			req := httptest.NewRequest("GET", "http://localhost:48093/ledgerAnalytics"+currentTest.Query, nil)
+			w := httptest.NewRecorder()
+			c.SalesAnalyticsGet(w, req)
+			resp := w.Result()
+			defer resp.Body.Close()
+
+			require.Equal(t, currentTest.ExpectedStatusCode, resp.StatusCode, "invalid status code")
+			if resp.StatusCode != http.StatusOK {
+				return
+			}
+			var report SalesReport
+			require.NoError(t, json.NewDecoder(resp.Body).Decode(&report))
+			assert.Equal(t, currentTest.ExpectedTransactions, report.Transactions)
+		})
+	}
+}
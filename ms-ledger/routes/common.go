@@ -8,6 +8,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io/fs"
 	"net/http"
 	"os"
 	"time"
@@ -17,29 +18,107 @@ const (
 	connectionTimeout = 15
 )
 
-// GetAllLedgers is a common function to get all ledgers for all accounts
+// errServiceUnavailable is returned when another service cannot be reached or
+// fails to handle a request
+var errServiceUnavailable = errors.New("service unavailable")
+
+// requestTimeout returns the timeout of requests to other services
+func (c *Controller) requestTimeout() time.Duration {
+	if c.timeout > 0 {
+		return c.timeout
+	}
+	return time.Duration(connectionTimeout) * time.Second
+}
+
+// GetAllLedgers is a common function to get all ledgers for all accounts.
+// A ledger JSON file in an older format is migrated to the current one in
+// memory only, since it is written back by MigrateLedgers at startup or by
+// the next change under the ledger mutex. A ledger JSON file without a
+// journal gets the journal of its ledgers
 func (c *Controller) GetAllLedgers() (Accounts, error) {
+	accountLedgers, _, err := c.readLedgers()
+	return accountLedgers, err
+}
+
+// MigrateLedgers writes a ledger JSON file of an older format back in the
+// current one. It is called once at startup, so that requests that only read
+// the ledgers never write them. A ledger JSON file that does not exist yet
+// has nothing to migrate
+func (c *Controller) MigrateLedgers() error {
+	c.ledgerMutex.Lock()
+	defer c.ledgerMutex.Unlock()
+
+	if _, err := os.Stat(c.ledgerFileName); errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	accountLedgers, migrated, err := c.readLedgers()
+	if err != nil || !migrated {
+		return err
+	}
+	if err = c.writeLedgers(accountLedgers, "migrate"); err != nil {
+		return err
+	}
+	c.lc.Infof("Migrated ledger JSON file to version %d with amounts in %s", ledgerSchemaVersion, c.currencyOrDefault())
+	return nil
+}
+
+// readLedgers reads the ledger JSON file with the ledgers of its accounts
+// projected from the journal, and returns true if it was in an older format
+// and was migrated
+func (c *Controller) readLedgers() (Accounts, bool, error) {
 	var accountLedgers Accounts
 
 	data, err := os.ReadFile(c.ledgerFileName)
 	if err != nil {
-		return Accounts{}, errors.New("failed to load ledger JSON file: " + err.Error())
+		return Accounts{}, false, errors.New("failed to load ledger JSON file: " + err.Error())
 	}
 
-	if err = json.Unmarshal(data, &accountLedgers); err != nil {
-		return Accounts{}, errors.New("Failed to unmarshal ledger JSON file: " + err.Error())
+	var schema struct {
+		SchemaVersion int `json:"schemaVersion"`
+	}
+	if err = json.Unmarshal(data, &schema); err != nil {
+		return Accounts{}, false, errors.New("Failed to unmarshal ledger JSON file: " + err.Error())
 	}
-	return accountLedgers, nil
+	if schema.SchemaVersion >= ledgerSchemaVersion {
+		if err = json.Unmarshal(data, &accountLedgers); err != nil {
+			return Accounts{}, false, errors.New("Failed to unmarshal ledger JSON file: " + err.Error())
+		}
+		if accountLedgers.Journal == nil {
+			accountLedgers.Journal = buildJournal(accountLedgers)
+		}
+		accountLedgers.projectLedgers()
+		return accountLedgers, false, nil
+	}
+
+	accountLedgers, err = migrateLedgers(data, schema.SchemaVersion, c.currencyOrDefault())
+	if err != nil {
+		return Accounts{}, false, errors.New("failed to migrate ledger JSON file: " + err.Error())
+	}
+	accountLedgers.projectLedgers()
+	return accountLedgers, true, nil
 }
 
 // DeleteAllLedgers will reset the content of the inventory JSON file
 func (c *Controller) DeleteAllLedgers() error {
-	data, err := json.Marshal(Accounts{Data: []Account{}})
+	return c.writeLedgers(Accounts{SchemaVersion: ledgerSchemaVersion, Data: []Account{}}, "delete")
+}
+
+// writeLedgers writes the accounts and the journal to the ledger JSON file.
+// The ledgers of the accounts are left out, as they are projected from the
+// journal when it is read. The action is used in error messages
+func (c *Controller) writeLedgers(accountLedgers Accounts, action string) error {
+	stored := accountLedgers
+	stored.Data = make([]Account, len(accountLedgers.Data))
+	for i, account := range accountLedgers.Data {
+		account.Ledgers = []Ledger{}
+		stored.Data[i] = account
+	}
+	data, err := json.Marshal(stored)
 	if err != nil {
-		return errors.New("failed to marshal ledger JSON file for delete: " + err.Error())
+		return errors.New("failed to marshal ledger JSON file for " + action + ": " + err.Error())
 	}
 	if err = os.WriteFile(c.ledgerFileName, data, 0644); err != nil {
-		return errors.New("failed to write ledger JSON file for delete: " + err.Error())
+		return errors.New("failed to write ledger JSON file for " + action + ": " + err.Error())
 	}
 
 	return nil
@@ -48,19 +127,23 @@ func (c *Controller) DeleteAllLedgers() error {
 func (c *Controller) sendCommand(method string, commandURL string, inputBytes []byte) (*http.Response, error) {
 	// Create the http request based on the parameters
 	request, _ := http.NewRequest(method, commandURL, bytes.NewBuffer(inputBytes))
-	timeout := time.Duration(connectionTimeout) * time.Second
 	client := &http.Client{
-		Timeout: timeout,
+		Timeout: c.requestTimeout(),
 	}
 
 	// Execute the http request
 	resp, err := client.Do(request)
 	if err != nil {
-		return nil, fmt.Errorf("error sending data: %v", err.Error())
+		return nil, fmt.Errorf("%w: error sending data: %v", errServiceUnavailable, err.Error())
 	}
 
 	// Check the status code and return any errors
+	if resp.StatusCode >= http.StatusInternalServerError {
+		resp.Body.Close()
+		return nil, fmt.Errorf("%w: error sending request: Received status code %v", errServiceUnavailable, resp.Status)
+	}
 	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
 		return nil, fmt.Errorf("error sending request: Received status code %v", resp.Status)
 	}
 
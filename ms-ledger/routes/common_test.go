@@ -19,22 +19,30 @@ const (
 	LedgerFileName = "test-ledger.json"
 )
 
+// getDefaultAccountLedgers returns two accounts with an unpaid sale each, and
+// the journal of the sales
 func getDefaultAccountLedgers() Accounts {
-	return Accounts{
+	accountLedgers := Accounts{
+		SchemaVersion: ledgerSchemaVersion,
 		Data: []Account{{
 			AccountID: 1,
 			Ledgers: []Ledger{{
 				TransactionID: 1579215712984890248,
 				TxTimeStamp:   1579215712984890363,
-				LineTotal:     1.99,
+				LineTotal:     199,
+				Subtotal:      199,
+				GrandTotal:    199,
+				Currency:      "USD",
 				CreatedAt:     1579215712984890443,
 				UpdatedAt:     1579215712984890517,
 				IsPaid:        false,
 				LineItems: []LineItem{{
 					SKU:         "1200050408",
 					ProductName: "Mountain Dew - 16.9 oz",
-					ItemPrice:   1.99,
+					ItemPrice:   199,
 					ItemCount:   1,
+					LineTotal:   199,
+					Currency:    "USD",
 				}},
 			}},
 		}, {
@@ -42,27 +50,44 @@ func getDefaultAccountLedgers() Accounts {
 			Ledgers: []Ledger{{
 				TransactionID: 2579215712984890248,
 				TxTimeStamp:   2579215712984890363,
-				LineTotal:     2.99,
+				LineTotal:     299,
+				Subtotal:      299,
+				GrandTotal:    299,
+				Currency:      "USD",
 				CreatedAt:     2579215712984890443,
 				UpdatedAt:     2579215712984890517,
 				IsPaid:        false,
 				LineItems: []LineItem{{
 					SKU:         "2200050408",
 					ProductName: "Mountain Blue - 16.9 oz",
-					ItemPrice:   2.99,
+					ItemPrice:   299,
 					ItemCount:   1,
+					LineTotal:   299,
+					Currency:    "USD",
 				}},
 			}},
 		}}}
+	accountLedgers.Journal = buildJournal(accountLedgers)
+	return accountLedgers
+}
+
+// netCharges returns the net grand total of the ledgers of the account per
+// currency, paid or not, to check the charges projected from the journal
+func netCharges(account Account) map[string]int64 {
+	charges := map[string]int64{}
+	for _, ledger := range account.Ledgers {
+		charges[ledger.Currency] += ledger.GrandTotal
+	}
+	return charges
 }
 
 func TestGetAllLedgers(t *testing.T) {
 	// Use community-recommended shorthand (known name clash)
 	c := Controller{
-		lc:                logger.NewMockClient(),
-		service:           nil,
-		inventoryEndpoint: "test.com",
-		ledgerFileName:    LedgerFileName,
+		lc:              logger.NewMockClient(),
+		service:         nil,
+		pricingEndpoint: "test.com",
+		ledgerFileName:  LedgerFileName,
 	}
 	require := require.New(t)
 	// Accounts slice
@@ -94,17 +119,17 @@ func TestDeleteAllLedgers(t *testing.T) {
 		Return(nil)
 
 	c := Controller{
-		lc:                logger.NewMockClient(),
-		service:           mockAppService,
-		inventoryEndpoint: "test.com",
-		ledgerFileName:    LedgerFileName,
+		lc:              logger.NewMockClient(),
+		service:         mockAppService,
+		pricingEndpoint: "test.com",
+		ledgerFileName:  LedgerFileName,
 	}
 
 	require := require.New(t)
 	// Accounts slice
 	accountLedgers := getDefaultAccountLedgers()
 
-	expectedLedger := Accounts{Data: []Account{}}
+	expectedLedger := Accounts{SchemaVersion: ledgerSchemaVersion, Data: []Account{}}
 
 	// Write the ledger
 	data, err := json.Marshal(accountLedgers)
@@ -124,3 +149,184 @@ func TestDeleteAllLedgers(t *testing.T) {
 	// Check that deleted Ledger has no ledger data
 	require.Equal(updatedLedger, expectedLedger, "Ledger should have no data")
 }
+
+// TestGetAllLedgersMigration tests that a version 1 ledger JSON file, with
+// amounts as floating point numbers, is migrated to minor units
+func TestGetAllLedgersMigration(t *testing.T) {
+	c := Controller{
+		lc:              logger.NewMockClient(),
+		service:         nil,
+		pricingEndpoint: "test.com",
+		ledgerFileName:  LedgerFileName,
+	}
+	legacyLedger := `{"data":[{"accountID":1,"ledgers":[{"transactionID":"1","txTimeStamp":"1","lineTotal":5.970000000000001,"createdAt":"1","updatedAt":"1","isPaid":true,` +
+		`"lineItems":[{"sku":"4900002470","productName":"Sprite (Lemon-Lime) - 16.9 oz","itemPrice":1.99,"itemCount":2},` +
+		`{"sku":"1200050408","productName":"Mountain Dew - 16.9 oz","itemPrice":1.99,"itemCount":1,"discount":0.4,"appliedRules":["happy-hour"]}]}]},` +
+		`{"accountID":2,"ledgers":[]}]}`
+	require.NoError(t, os.WriteFile(c.ledgerFileName, []byte(legacyLedger), 0644))
+	defer func() {
+		os.Remove(c.ledgerFileName)
+	}()
+
+	accountLedgers, err := c.GetAllLedgers()
+	require.NoError(t, err)
+
+	require.Equal(t, ledgerSchemaVersion, accountLedgers.SchemaVersion)
+	require.Len(t, accountLedgers.Data, 2)
+	require.Len(t, accountLedgers.Data[0].Ledgers, 1)
+	ledger := accountLedgers.Data[0].Ledgers[0]
+	require.Equal(t, int64(597), ledger.LineTotal)
+	require.Equal(t, int64(597), ledger.Subtotal)
+	require.Equal(t, int64(0), ledger.TaxTotal)
+	require.Equal(t, int64(597), ledger.GrandTotal)
+	require.Equal(t, defaultCurrency, ledger.Currency)
+	require.True(t, ledger.IsPaid)
+	require.Equal(t, []LineItem{{
+		SKU:         "4900002470",
+		ProductName: "Sprite (Lemon-Lime) - 16.9 oz",
+		ItemPrice:   199,
+		ItemCount:   2,
+		LineTotal:   398,
+		Currency:    defaultCurrency,
+	}, {
+		SKU:          "1200050408",
+		ProductName:  "Mountain Dew - 16.9 oz",
+		ItemPrice:    199,
+		ItemCount:    1,
+		Discount:     40,
+		LineTotal:    159,
+		Currency:     defaultCurrency,
+		AppliedRules: []string{"happy-hour"},
+	}}, ledger.LineItems)
+	require.Empty(t, accountLedgers.Data[1].Ledgers)
+
+	// reading the ledgers does not write them
+	data, err := os.ReadFile(c.ledgerFileName)
+	require.NoError(t, err)
+	require.Equal(t, legacyLedger, string(data))
+
+	// the migrated ledger is written back to the file at startup, where the
+	// transactions are only recorded in the journal
+	require.NoError(t, c.MigrateLedgers())
+	data, err = os.ReadFile(c.ledgerFileName)
+	require.NoError(t, err)
+	var migrated Accounts
+	require.NoError(t, json.Unmarshal(data, &migrated))
+	require.Empty(t, migrated.Data[0].Ledgers)
+	migrated, err = c.GetAllLedgers()
+	require.NoError(t, err)
+	require.Equal(t, accountLedgers, migrated)
+}
+
+// TestMigrateLedgersWithoutFile tests that a ledger JSON file that does not
+// exist yet has nothing to migrate
+func TestMigrateLedgersWithoutFile(t *testing.T) {
+	c := Controller{
+		lc:             logger.NewMockClient(),
+		ledgerFileName: "missing-" + LedgerFileName,
+	}
+	require.NoError(t, c.MigrateLedgers())
+	_, err := os.Stat(c.ledgerFileName)
+	require.True(t, os.IsNotExist(err), "no ledger JSON file should be created")
+}
+
+// TestGetAllLedgersMigrationVersion2 tests that ledgers of a version 2 ledger
+// JSON file, which were not taxed, get their totals
+func TestGetAllLedgersMigrationVersion2(t *testing.T) {
+	c := Controller{
+		lc:              logger.NewMockClient(),
+		service:         nil,
+		pricingEndpoint: "test.com",
+		ledgerFileName:  LedgerFileName,
+	}
+	ledgerV2 := `{"schemaVersion":2,"data":[{"accountID":1,"ledgers":[{"transactionID":"1","txTimeStamp":"1","lineTotal":398,"currency":"EUR","createdAt":"1","updatedAt":"1","isPaid":false,` +
+		`"lineItems":[{"sku":"4900002470","productName":"Sprite (Lemon-Lime) - 16.9 oz","itemPrice":199,"itemCount":2,"lineTotal":398,"currency":"EUR"}]}]}]}`
+	require.NoError(t, os.WriteFile(c.ledgerFileName, []byte(ledgerV2), 0644))
+	defer func() {
+		os.Remove(c.ledgerFileName)
+	}()
+
+	accountLedgers, err := c.GetAllLedgers()
+	require.NoError(t, err)
+
+	require.Equal(t, ledgerSchemaVersion, accountLedgers.SchemaVersion)
+	ledger := accountLedgers.Data[0].Ledgers[0]
+	require.Equal(t, "EUR", ledger.Currency)
+	require.Equal(t, int64(398), ledger.Subtotal)
+	require.Equal(t, int64(0), ledger.TaxTotal)
+	require.Equal(t, int64(398), ledger.GrandTotal)
+	require.Equal(t, int64(398), ledger.LineItems[0].LineTotal)
+}
+
+// TestGetAllLedgersMigrationVersion3 tests that a version 3 ledger JSON file
+// keeps its taxes and gets the journal of its ledgers
+func TestGetAllLedgersMigrationVersion3(t *testing.T) {
+	c := Controller{
+		lc:              logger.NewMockClient(),
+		service:         nil,
+		pricingEndpoint: "test.com",
+		ledgerFileName:  LedgerFileName,
+	}
+	ledgerV3 := `{"schemaVersion":3,"data":[{"accountID":1,"ledgers":[{"transactionID":"1","txTimeStamp":"1","lineTotal":398,"subtotal":398,"taxTotal":29,"grandTotal":427,"currency":"USD","createdAt":"1","updatedAt":"2","isPaid":true,"paidAt":"2",` +
+		`"lineItems":[{"sku":"4900002470","productName":"Sprite (Lemon-Lime) - 16.9 oz","itemPrice":199,"itemCount":2,"lineTotal":398,"currency":"USD","taxRate":72500,"tax":29}]}]}]}`
+	require.NoError(t, os.WriteFile(c.ledgerFileName, []byte(ledgerV3), 0644))
+	defer func() {
+		os.Remove(c.ledgerFileName)
+	}()
+
+	accountLedgers, err := c.GetAllLedgers()
+	require.NoError(t, err)
+
+	require.Equal(t, ledgerSchemaVersion, accountLedgers.SchemaVersion)
+	ledger := accountLedgers.Data[0].Ledgers[0]
+	require.Equal(t, int64(29), ledger.TaxTotal)
+	require.True(t, ledger.IsPaid)
+	require.Equal(t, int64(2), ledger.PaidAt)
+	require.Equal(t, []JournalEntry{{
+		EntryID: 1, Type: JournalEntrySale, AccountID: 1, TransactionID: 1, TimeStamp: 1, Currency: "USD",
+		Lines:       []JournalLine{{Account: JournalAccountReceivable, Debit: 427}, {Account: JournalAccountRevenue, Credit: 398}, {Account: JournalAccountTaxPayable, Credit: 29}},
+		Transaction: journalTransaction(ledger),
+	}, {
+		EntryID: 2, Type: JournalEntryPayment, AccountID: 1, TransactionID: 1, TimeStamp: 2, Currency: "USD",
+		Lines: []JournalLine{{Account: JournalAccountReceivable, Credit: 427}, {Account: JournalAccountCashClearing, Debit: 427}},
+	}}, accountLedgers.Journal)
+}
+
+// TestGetAllLedgersMigrationVersion4 tests that the ledgers of a version 4
+// ledger JSON file, which were stored beside the journal, are recorded in the
+// journal entries of their transactions
+func TestGetAllLedgersMigrationVersion4(t *testing.T) {
+	c := Controller{
+		lc:             logger.NewMockClient(),
+		ledgerFileName: LedgerFileName,
+	}
+	ledgerV4 := `{"schemaVersion":4,"data":[{"accountID":1,"ledgers":[{"transactionID":"1","txTimeStamp":"1","lineTotal":159,"subtotal":159,"taxTotal":12,"grandTotal":171,"currency":"USD","createdAt":"1","updatedAt":"3","isPaid":true,"paidAt":"3","repricedAt":"2",` +
+		`"lineItems":[{"sku":"1200050408","productName":"Mountain Dew - 16.9 oz","itemPrice":199,"itemCount":1,"discount":40,"lineTotal":159,"currency":"USD","taxRate":72500,"tax":12}]}]}],` +
+		`"journal":[{"entryID":"1","type":"sale","accountID":1,"transactionID":"1","timeStamp":"1","currency":"USD","lines":[{"account":"customerReceivable","debit":213},{"account":"revenue","credit":199},{"account":"taxPayable","credit":14}]},` +
+		`{"entryID":"2","type":"reprice","accountID":1,"transactionID":"1","timeStamp":"2","currency":"USD","lines":[{"account":"customerReceivable","credit":42},{"account":"revenue","debit":40},{"account":"taxPayable","debit":2}]},` +
+		`{"entryID":"3","type":"payment","accountID":1,"transactionID":"1","timeStamp":"3","currency":"USD","lines":[{"account":"customerReceivable","credit":171},{"account":"cashClearing","debit":171}]}]}`
+	require.NoError(t, os.WriteFile(c.ledgerFileName, []byte(ledgerV4), 0644))
+	defer func() {
+		os.Remove(c.ledgerFileName)
+	}()
+
+	accountLedgers, err := c.GetAllLedgers()
+	require.NoError(t, err)
+
+	require.Equal(t, ledgerSchemaVersion, accountLedgers.SchemaVersion)
+	require.Len(t, accountLedgers.Data[0].Ledgers, 1)
+	ledger := accountLedgers.Data[0].Ledgers[0]
+	require.Equal(t, int64(171), ledger.GrandTotal)
+	require.True(t, ledger.IsPaid)
+	require.Equal(t, int64(3), ledger.PaidAt)
+	require.Len(t, accountLedgers.Journal, 3)
+	require.Equal(t, journalTransaction(ledger), accountLedgers.Journal[0].Transaction)
+	require.Equal(t, journalTransaction(ledger), accountLedgers.Journal[1].Transaction)
+	require.Nil(t, accountLedgers.Journal[2].Transaction)
+
+	// the transactions are only kept in the journal once it is migrated
+	require.NoError(t, c.MigrateLedgers())
+	migrated, err := c.GetAllLedgers()
+	require.NoError(t, err)
+	require.Equal(t, accountLedgers, migrated)
+}
@@ -4,26 +4,230 @@
 package routes
 
 import (
+	"errors"
 	"fmt"
+	"net/url"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/edgexfoundry/app-functions-sdk-go/v3/pkg/interfaces"
 	"github.com/edgexfoundry/go-mod-core-contracts/v3/clients/logger"
 )
 
+const (
+	defaultStatementDir           = "statements"
+	defaultStatementCheckInterval = 24 * time.Hour
+	defaultIdempotencyRetention   = 24 * time.Hour
+	defaultPriceCacheTTL          = 30 * time.Second
+	defaultRepricingInterval      = time.Minute
+	// basketPricingPath is the path of the basket pricing endpoint of the
+	// inventory service
+	basketPricingPath = "/pricing/basket"
+)
+
 type Controller struct {
-	lc                logger.LoggingClient
-	service           interfaces.ApplicationService
-	inventoryEndpoint string
-	ledgerFileName    string
+	lc              logger.LoggingClient
+	service         interfaces.ApplicationService
+	pricingEndpoint string
+	ledgerFileName  string
+	// currency is the ISO 4217 currency of baskets priced without one and
+	// of the amounts of migrated ledgers
+	currency string
+	// taxJurisdiction selects the taxRules that apply to the transactions
+	// of this service
+	taxJurisdiction string
+	taxRules        []taxRule
+	// statementDir holds the monthly statements of every account, which are
+	// generated on every statementCheckInterval
+	statementDir           string
+	statementCheckInterval time.Duration
+	// transactionNodeID is the last three digits of every transaction ID
+	// this service creates, and lastTransactionID the last one it created
+	transactionNodeID int64
+	lastTransactionID int64
+	// idempotencyRetention is how long a replayed idempotency key returns
+	// the transaction it was first posted with
+	idempotencyRetention time.Duration
+	// ledgerMutex serializes changes to the ledger JSON file that create
+	// new transactions
+	ledgerMutex sync.Mutex
+	// accountsEndpoint is the accounts endpoint of the authentication
+	// service. When it is set, ledger accounts are provisioned on demand
+	accountsEndpoint string
+	// timeout of requests to the pricing and accounts endpoints
+	timeout time.Duration
+	// priceCacheTTL is how long a priced basket is reused for identical
+	// baskets. knownPrices holds the last price of every SKU that was
+	// priced or sold, and priceCache the priced baskets
+	priceCacheTTL time.Duration
+	priceCache    map[string]cachedBasket
+	knownPrices   map[string]knownPrice
+	pricingMutex  sync.Mutex
+	// degradedPricing records transactions at their last known prices
+	// when the pricing endpoint cannot be reached. They are re-priced on
+	// every repricingInterval
+	degradedPricing   bool
+	repricingInterval time.Duration
 }
 
-func NewController(lc logger.LoggingClient, service interfaces.ApplicationService, inventoryEndpoint string, ledgerFileName string) Controller {
+func NewController(lc logger.LoggingClient, service interfaces.ApplicationService, pricingEndpoint string, ledgerFileName string) Controller {
 	return Controller{
-		lc:                lc,
-		service:           service,
-		inventoryEndpoint: inventoryEndpoint,
-		ledgerFileName:    ledgerFileName,
+		lc:                     lc,
+		service:                service,
+		pricingEndpoint:        pricingEndpoint,
+		ledgerFileName:         ledgerFileName,
+		currency:               defaultCurrency,
+		statementDir:           filepath.Join(filepath.Dir(ledgerFileName), defaultStatementDir),
+		statementCheckInterval: defaultStatementCheckInterval,
+		idempotencyRetention:   defaultIdempotencyRetention,
+		priceCacheTTL:          defaultPriceCacheTTL,
+		degradedPricing:        true,
+		repricingInterval:      defaultRepricingInterval,
+	}
+}
+
+// PricingEndpointSetting returns the PricingEndpoint application setting.
+// Configurations from before transactions were priced as a basket only have
+// the deprecated InventoryEndpoint setting, which is the /inventory endpoint
+// of the same inventory service. It is still accepted, with a deprecation
+// warning, and its path is replaced by the basket pricing path
+func PricingEndpointSetting(service interfaces.ApplicationService, lc logger.LoggingClient) (string, error) {
+	if value, err := service.GetAppSetting("PricingEndpoint"); err == nil && len(value) > 0 {
+		if _, err := url.Parse(value); err != nil {
+			return "", fmt.Errorf("PricingEndpoint from ApplicationSettings is not a valid URL: %s", err.Error())
+		}
+		return value, nil
+	}
+
+	inventoryEndpoint, err := service.GetAppSetting("InventoryEndpoint")
+	if err != nil || len(inventoryEndpoint) == 0 {
+		return "", errors.New("PricingEndpoint is not set in ApplicationSettings")
+	}
+	endpoint, err := url.Parse(inventoryEndpoint)
+	if err != nil {
+		return "", fmt.Errorf("InventoryEndpoint from ApplicationSettings is not a valid URL: %s", err.Error())
 	}
+	endpoint.Path = strings.TrimSuffix(strings.TrimSuffix(endpoint.Path, "/"), "/inventory") + basketPricingPath
+	lc.Warnf("InventoryEndpoint in ApplicationSettings is deprecated, pricing baskets with %s instead. Set PricingEndpoint to the basket pricing endpoint of the inventory service", endpoint.String())
+	return endpoint.String(), nil
+}
+
+// LoadSettings reads the optional ApplicationSettings of this service. Every
+// setting that is not present keeps the default assigned by NewController
+func (c *Controller) LoadSettings() error {
+	if value, ok := c.optionalAppSetting("Currency"); ok {
+		if !validCurrency(value) {
+			return fmt.Errorf("Currency from ApplicationSettings is not a supported ISO 4217 currency code: %s", value)
+		}
+		c.currency = value
+	}
+
+	if value, ok := c.optionalAppSetting("TaxJurisdiction"); ok {
+		c.taxJurisdiction = value
+	}
+	if values, err := c.service.GetAppSettingStrings("TaxRules"); err == nil {
+		for _, value := range values {
+			if len(value) == 0 {
+				continue
+			}
+			rule, err := parseTaxRule(value)
+			if err != nil {
+				return fmt.Errorf("TaxRules from ApplicationSettings is not valid: %s", err.Error())
+			}
+			c.taxRules = append(c.taxRules, rule)
+		}
+	}
+
+	if value, ok := c.optionalAppSetting("StatementDir"); ok {
+		c.statementDir = value
+	}
+
+	if value, ok := c.optionalAppSetting("StatementCheckInterval"); ok {
+		interval, err := time.ParseDuration(value)
+		if err != nil || interval <= 0 {
+			return fmt.Errorf("StatementCheckInterval from ApplicationSettings is not a valid duration: %s", value)
+		}
+		c.statementCheckInterval = interval
+	}
+
+	if value, ok := c.optionalAppSetting("AccountsEndpoint"); ok {
+		if _, err := url.Parse(value); err != nil {
+			return fmt.Errorf("AccountsEndpoint from ApplicationSettings is not a valid URL: %s", err.Error())
+		}
+		c.accountsEndpoint = value
+	}
+
+	if value, ok := c.optionalAppSetting("RequestTimeout"); ok {
+		timeout, err := time.ParseDuration(value)
+		if err != nil || timeout <= 0 {
+			return fmt.Errorf("RequestTimeout from ApplicationSettings is not a valid duration: %s", value)
+		}
+		c.timeout = timeout
+	}
+
+	if value, ok := c.optionalAppSetting("PriceCacheTTL"); ok {
+		ttl, err := time.ParseDuration(value)
+		if err != nil || ttl < 0 {
+			return fmt.Errorf("PriceCacheTTL from ApplicationSettings is not a valid duration: %s", value)
+		}
+		c.priceCacheTTL = ttl
+	}
+
+	if value, ok := c.optionalAppSetting("DegradedPricing"); ok {
+		enabled, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("DegradedPricing from ApplicationSettings is not a valid boolean: %s", value)
+		}
+		c.degradedPricing = enabled
+	}
+
+	if value, ok := c.optionalAppSetting("RepricingInterval"); ok {
+		interval, err := time.ParseDuration(value)
+		if err != nil || interval <= 0 {
+			return fmt.Errorf("RepricingInterval from ApplicationSettings is not a valid duration: %s", value)
+		}
+		c.repricingInterval = interval
+	}
+
+	if value, ok := c.optionalAppSetting("TransactionNodeID"); ok {
+		nodeID, err := strconv.ParseInt(value, 10, 64)
+		if err != nil || nodeID < 0 || nodeID >= transactionNodeIDs {
+			return fmt.Errorf("TransactionNodeID from ApplicationSettings must be a number from 0 to %d: %s", transactionNodeIDs-1, value)
+		}
+		c.transactionNodeID = nodeID
+	}
+
+	if value, ok := c.optionalAppSetting("IdempotencyRetention"); ok {
+		retention, err := time.ParseDuration(value)
+		if err != nil || retention <= 0 {
+			return fmt.Errorf("IdempotencyRetention from ApplicationSettings is not a valid duration: %s", value)
+		}
+		c.idempotencyRetention = retention
+	}
+
+	return nil
+}
+
+// optionalAppSetting returns the value of an ApplicationSettings entry and
+// whether it is set to a non-empty value
+func (c *Controller) optionalAppSetting(name string) (string, bool) {
+	value, err := c.service.GetAppSetting(name)
+	if err != nil || len(value) == 0 {
+		return "", false
+	}
+	return value, true
+}
+
+// currencyOrDefault returns the configured currency, or the default currency
+// when none is configured
+func (c *Controller) currencyOrDefault() string {
+	if c.currency != "" {
+		return c.currency
+	}
+	return defaultCurrency
 }
 
 func (c *Controller) AddAllRoutes() error {
@@ -39,16 +243,76 @@ func (c *Controller) AddAllRoutes() error {
 		return errWithMsg
 	}
 
+	err = c.service.AddRoute("/ledger/{accountid}/statement", c.LedgerStatementGet, "OPTIONS", "GET")
+	if errWithMsg := errorAddRouteHandler(err); errWithMsg != nil {
+		return errWithMsg
+	}
+
+	err = c.service.AddRoute("/ledger/{accountid}/statements", c.LedgerStatementsGet, "OPTIONS", "GET")
+	if errWithMsg := errorAddRouteHandler(err); errWithMsg != nil {
+		return errWithMsg
+	}
+
+	err = c.service.AddRoute("/ledger/{accountid}/statements/{period}", c.LedgerStoredStatementGet, "OPTIONS", "GET")
+	if errWithMsg := errorAddRouteHandler(err); errWithMsg != nil {
+		return errWithMsg
+	}
+
+	err = c.service.AddRoute("/ledger/{accountid}/balance", c.LedgerBalanceGet, "OPTIONS", "GET")
+	if errWithMsg := errorAddRouteHandler(err); errWithMsg != nil {
+		return errWithMsg
+	}
+
+	err = c.service.AddRoute("/ledger/{accountid}/disputes", c.LedgerDisputesGet, "OPTIONS", "GET")
+	if errWithMsg := errorAddRouteHandler(err); errWithMsg != nil {
+		return errWithMsg
+	}
+
 	err = c.service.AddRoute("/ledger", c.LedgerAddTransaction, "OPTIONS", "POST")
 	if errWithMsg := errorAddRouteHandler(err); errWithMsg != nil {
 		return errWithMsg
 	}
 
+	err = c.service.AddRoute("/ledgerReconciliation", c.ReconciliationGet, "OPTIONS", "GET")
+	if errWithMsg := errorAddRouteHandler(err); errWithMsg != nil {
+		return errWithMsg
+	}
+
+	err = c.service.AddRoute("/ledgerJournal", c.JournalGet, "OPTIONS", "GET")
+	if errWithMsg := errorAddRouteHandler(err); errWithMsg != nil {
+		return errWithMsg
+	}
+
+	err = c.service.AddRoute("/ledgerTrialBalance", c.TrialBalanceGet, "OPTIONS", "GET")
+	if errWithMsg := errorAddRouteHandler(err); errWithMsg != nil {
+		return errWithMsg
+	}
+
+	err = c.service.AddRoute("/ledgerAnalytics", c.SalesAnalyticsGet, "OPTIONS", "GET")
+	if errWithMsg := errorAddRouteHandler(err); errWithMsg != nil {
+		return errWithMsg
+	}
+
 	err = c.service.AddRoute("/ledgerPaymentUpdate", c.SetPaymentStatus, "OPTIONS", "POST")
 	if errWithMsg := errorAddRouteHandler(err); errWithMsg != nil {
 		return errWithMsg
 	}
 
+	err = c.service.AddRoute("/ledger/{accountid}/{tid}/adjustment", c.LedgerAdjustmentPost, "OPTIONS", "POST")
+	if errWithMsg := errorAddRouteHandler(err); errWithMsg != nil {
+		return errWithMsg
+	}
+
+	err = c.service.AddRoute("/ledger/{accountid}/{tid}/dispute", c.LedgerDisputePost, "OPTIONS", "POST")
+	if errWithMsg := errorAddRouteHandler(err); errWithMsg != nil {
+		return errWithMsg
+	}
+
+	err = c.service.AddRoute("/ledger/{accountid}/disputes/{disputeid}/resolution", c.DisputeResolutionPost, "OPTIONS", "POST")
+	if errWithMsg := errorAddRouteHandler(err); errWithMsg != nil {
+		return errWithMsg
+	}
+
 	err = c.service.AddRoute("/ledger/{accountid}/{tid}", c.LedgerDelete, "DELETE", "OPTIONS")
 	if errWithMsg := errorAddRouteHandler(err); errWithMsg != nil {
 		return errWithMsg
@@ -5,6 +5,7 @@ package routes
 
 import (
 	"fmt"
+	"strings"
 	"testing"
 
 	"github.com/edgexfoundry/app-functions-sdk-go/v3/pkg/interfaces/mocks"
@@ -15,7 +16,7 @@ import (
 
 func TestController_AddAllRoutes(t *testing.T) {
 
-	inventoryServer := newInventoryTestServer(t)
+	pricingServer := newPricingTestServer(t)
 
 	tests := []struct {
 		name         string
@@ -45,9 +46,9 @@ func TestController_AddAllRoutes(t *testing.T) {
 			}
 
 			c := &Controller{
-				lc:                logger.NewMockClient(),
-				service:           mockAppService,
-				inventoryEndpoint: inventoryServer.URL,
+				lc:              logger.NewMockClient(),
+				service:         mockAppService,
+				pricingEndpoint: pricingServer.URL,
 			}
 
 			err := c.AddAllRoutes()
@@ -61,3 +62,155 @@ func TestController_AddAllRoutes(t *testing.T) {
 		})
 	}
 }
+
+func TestController_LoadSettings(t *testing.T) {
+	tests := []struct {
+		name             string
+		settings         map[string]string
+		expectError      bool
+		expectedCurrency string
+		expectedTaxRules []taxRule
+	}{
+		{
+			name:             "defaults",
+			settings:         map[string]string{},
+			expectedCurrency: defaultCurrency,
+		},
+		{
+			name:             "valid settings",
+			settings:         map[string]string{"Currency": "EUR", "TaxJurisdiction": "US-CA", "TaxRules": "US-CA/*=7.25, US-CA/food=0,US-OR/*=0"},
+			expectedCurrency: "EUR",
+			expectedTaxRules: []taxRule{{"US-CA", "*", 7.25}, {"US-CA", "food", 0}, {"US-OR", "*", 0}},
+		},
+		{
+			name:        "invalid TaxRules",
+			settings:    map[string]string{"TaxRules": "US-CA=7.25"},
+			expectError: true,
+		},
+		{
+			name:        "invalid StatementCheckInterval",
+			settings:    map[string]string{"StatementCheckInterval": "monthly"},
+			expectError: true,
+		},
+		{
+			name:        "invalid TransactionNodeID",
+			settings:    map[string]string{"TransactionNodeID": "1000"},
+			expectError: true,
+		},
+		{
+			name:        "invalid IdempotencyRetention",
+			settings:    map[string]string{"IdempotencyRetention": "forever"},
+			expectError: true,
+		},
+		{
+			name:        "invalid RequestTimeout",
+			settings:    map[string]string{"RequestTimeout": "0s"},
+			expectError: true,
+		},
+		{
+			name:        "invalid PriceCacheTTL",
+			settings:    map[string]string{"PriceCacheTTL": "-1s"},
+			expectError: true,
+		},
+		{
+			name:        "invalid DegradedPricing",
+			settings:    map[string]string{"DegradedPricing": "sometimes"},
+			expectError: true,
+		},
+		{
+			name:        "invalid RepricingInterval",
+			settings:    map[string]string{"RepricingInterval": "often"},
+			expectError: true,
+		},
+		{
+			name:        "invalid Currency",
+			settings:    map[string]string{"Currency": "dollars"},
+			expectError: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockAppService := &mocks.ApplicationService{}
+			mockAppService.On("GetAppSetting", mock.Anything).Return(func(name string) (string, error) {
+				if value, ok := tt.settings[name]; ok {
+					return value, nil
+				}
+				return "", fmt.Errorf("%s not found", name)
+			})
+			mockAppService.On("GetAppSettingStrings", mock.Anything).Return(func(name string) ([]string, error) {
+				if value, ok := tt.settings[name]; ok {
+					return strings.Split(value, ","), nil
+				}
+				return nil, fmt.Errorf("%s not found", name)
+			})
+
+			c := NewController(logger.NewMockClient(), mockAppService, "test.com", LedgerFileName)
+			err := c.LoadSettings()
+
+			if tt.expectError {
+				require.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, tt.expectedCurrency, c.currency)
+			require.Equal(t, tt.expectedTaxRules, c.taxRules)
+		})
+	}
+}
+
+// TestPricingEndpointSetting tests that the deprecated InventoryEndpoint is
+// still accepted when PricingEndpoint is not set
+func TestPricingEndpointSetting(t *testing.T) {
+	tests := []struct {
+		name             string
+		settings         map[string]string
+		expectError      bool
+		expectedEndpoint string
+	}{
+		{
+			name:             "pricing endpoint",
+			settings:         map[string]string{"PricingEndpoint": "http://localhost:48095/pricing/basket", "InventoryEndpoint": "http://localhost:48095/inventory"},
+			expectedEndpoint: "http://localhost:48095/pricing/basket",
+		},
+		{
+			name:             "deprecated inventory endpoint",
+			settings:         map[string]string{"InventoryEndpoint": "http://localhost:48095/inventory"},
+			expectedEndpoint: "http://localhost:48095/pricing/basket",
+		},
+		{
+			name:             "deprecated inventory endpoint with trailing slash",
+			settings:         map[string]string{"PricingEndpoint": "", "InventoryEndpoint": "http://ms-inventory:48095/inventory/"},
+			expectedEndpoint: "http://ms-inventory:48095/pricing/basket",
+		},
+		{
+			name:        "invalid inventory endpoint",
+			settings:    map[string]string{"InventoryEndpoint": "http://[::1"},
+			expectError: true,
+		},
+		{
+			name:        "no endpoint",
+			settings:    map[string]string{},
+			expectError: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockAppService := &mocks.ApplicationService{}
+			mockAppService.On("GetAppSetting", mock.Anything).Return(func(name string) (string, error) {
+				if value, ok := tt.settings[name]; ok {
+					return value, nil
+				}
+				return "", fmt.Errorf("%s not found", name)
+			})
+
+			endpoint, err := PricingEndpointSetting(mockAppService, logger.NewMockClient())
+			if tt.expectError {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.expectedEndpoint, endpoint)
+		})
+	}
+}
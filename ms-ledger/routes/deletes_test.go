@@ -4,7 +4,6 @@
 package routes
 
 import (
-	"bytes"
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
@@ -38,15 +37,19 @@ func TestLedgerDelete(t *testing.T) {
 		InvalidLedger      bool
 		AccountID          string
 		TransactionID      string
+		Query              string
 		TransactionDeleted bool
+		ExpectedOperator   string
 		ExpectedStatusCode int
 	}{
-		{"Valid AccountID and TransactionID", false, defaultAccountID, defaultTransactionID, true, http.StatusOK},
-		{"Bad data AccountID", false, "badformat", defaultTransactionID, false, http.StatusBadRequest},
-		{"Nonexistent AccountID", false, InvalidAccountID, defaultTransactionID, false, http.StatusBadRequest},
-		{"Bad data TransactionID", false, defaultAccountID, "badformat", false, http.StatusBadRequest},
-		{"Nonexistent TransactionID", false, defaultAccountID, InvalidTransactionID, false, http.StatusBadRequest},
-		{"Invalid Ledger Endpoint", true, defaultAccountID, defaultTransactionID, false, http.StatusInternalServerError},
+		{"Valid AccountID and TransactionID", false, defaultAccountID, defaultTransactionID, "?operator=jdoe&reasonCode=misdetection", true, "jdoe", http.StatusOK},
+		{"Missing operator", false, defaultAccountID, defaultTransactionID, "?reasonCode=misdetection", false, "", http.StatusBadRequest},
+		{"Unknown reason code", false, defaultAccountID, defaultTransactionID, "?operator=jdoe&reasonCode=typo", false, "", http.StatusBadRequest},
+		{"Bad data AccountID", false, "badformat", defaultTransactionID, "?operator=jdoe", false, "", http.StatusBadRequest},
+		{"Nonexistent AccountID", false, InvalidAccountID, defaultTransactionID, "?operator=jdoe", false, "", http.StatusBadRequest},
+		{"Bad data TransactionID", false, defaultAccountID, "badformat", "?operator=jdoe", false, "", http.StatusBadRequest},
+		{"Nonexistent TransactionID", false, defaultAccountID, InvalidTransactionID, "?operator=jdoe", false, "", http.StatusBadRequest},
+		{"Invalid Ledger Endpoint", true, defaultAccountID, defaultTransactionID, "?operator=jdoe", false, "", http.StatusInternalServerError},
 	}
 
 	for _, test := range tests {
@@ -56,10 +59,10 @@ func TestLedgerDelete(t *testing.T) {
 			mockAppService.On("AddRoute", mock.Anything, mock.Anything, mock.Anything, mock.Anything).
 				Return(nil)
 			c := Controller{
-				lc:                logger.NewMockClient(),
-				service:           mockAppService,
-				inventoryEndpoint: "test.com",
-				ledgerFileName:    LedgerFileName,
+				lc:              logger.NewMockClient(),
+				service:         mockAppService,
+				pricingEndpoint: "test.com",
+				ledgerFileName:  LedgerFileName,
 			}
 			err := c.DeleteAllLedgers()
 			require.NoError(err)
@@ -78,7 +81,7 @@ func TestLedgerDelete(t *testing.T) {
 				os.Remove(c.ledgerFileName)
 			}()
 
-			req := httptest.NewRequest("DELETE", "http://localhost:48093/ledger", bytes.NewBuffer([]byte(currentTest.AccountID+"/"+currentTest.TransactionID)))
+			req := httptest.NewRequest("DELETE", "http://localhost:48093/ledger/"+currentTest.AccountID+"/"+currentTest.TransactionID+currentTest.Query, nil)
 			w := httptest.NewRecorder()
 
 			URLVars := map[string]string{
@@ -100,7 +103,15 @@ func TestLedgerDelete(t *testing.T) {
 				require.NoError(err)
 
 				if currentTest.TransactionDeleted {
-					assert.NotEqual(accountLedgers, accountsFromFile, "Ledgers should not match")
+					// the transaction is kept and voided by an adjustment
+					require.Len(accountsFromFile.Data[0].Ledgers, 2)
+					assert.Equal(accountLedgers.Data[0].Ledgers[0], accountsFromFile.Data[0].Ledgers[0], "the voided transaction should not change")
+					void := accountsFromFile.Data[0].Ledgers[1]
+					assert.Equal(TransactionTypeVoid, void.Type)
+					assert.Equal(accountLedgers.Data[0].Ledgers[0].TransactionID, void.AdjustsTransactionID)
+					assert.Equal(ReasonMisdetection, void.ReasonCode)
+					assert.Equal(currentTest.ExpectedOperator, void.Operator)
+					assert.Equal(-accountLedgers.Data[0].Ledgers[0].GrandTotal, void.GrandTotal)
 				} else {
 					assert.Equal(accountLedgers, accountsFromFile, "Ledgers should match")
 				}
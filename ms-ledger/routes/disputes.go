@@ -0,0 +1,149 @@
+// Copyright © 2026 Intel Corporation. All rights reserved.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package routes
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Statuses of disputes
+const (
+	DisputeStatusOpen     = "open"
+	DisputeStatusUpheld   = "upheld"
+	DisputeStatusRejected = "rejected"
+)
+
+var (
+	// errInvalidDispute is returned for a dispute or resolution request that
+	// can never be applied, such as a dispute of a SKU that was not sold
+	errInvalidDispute = errors.New("invalid dispute")
+	// errDisputeConflict is returned for a dispute or resolution request that
+	// conflicts with the state of the disputes, such as a second open dispute
+	// of a transaction
+	errDisputeConflict = errors.New("dispute conflicts with the transaction")
+)
+
+// nextDisputeID returns the dispute ID after the greatest dispute ID of all
+// accounts. The caller must hold ledgerMutex
+func nextDisputeID(accountLedgers Accounts) int64 {
+	var disputeID int64
+	for _, account := range accountLedgers.Data {
+		for _, dispute := range account.Disputes {
+			if dispute.DisputeID > disputeID {
+				disputeID = dispute.DisputeID
+			}
+		}
+	}
+	return disputeID + 1
+}
+
+// newDispute returns a new open dispute of a sale of the account. A sale can
+// only have one open dispute at a time. The evidence is kept as it was
+// posted, see DisputeEvidence
+func newDispute(account Account, sale Ledger, request disputeRequest, disputeID int64, now int64) (Dispute, error) {
+	if sale.Type != "" {
+		return Dispute{}, fmt.Errorf("%w: transaction %d is a %s and cannot be disputed", errInvalidDispute, sale.TransactionID, sale.Type)
+	}
+	if request.Statement == "" {
+		return Dispute{}, fmt.Errorf("%w: the statement of the customer is required", errInvalidDispute)
+	}
+	for _, dispute := range account.Disputes {
+		if dispute.TransactionID == sale.TransactionID && dispute.Status == DisputeStatusOpen {
+			return Dispute{}, fmt.Errorf("%w: transaction %d has open dispute %d", errDisputeConflict, sale.TransactionID, dispute.DisputeID)
+		}
+	}
+
+	itemCount := 0
+	if request.SKU != "" {
+		sold := 0
+		for _, lineItem := range sale.LineItems {
+			if lineItem.SKU == request.SKU {
+				sold = lineItem.ItemCount
+			}
+		}
+		if sold == 0 {
+			return Dispute{}, fmt.Errorf("%w: SKU %s is not part of transaction %d", errInvalidDispute, request.SKU, sale.TransactionID)
+		}
+		itemCount = request.ItemCount
+		if itemCount == 0 {
+			itemCount = sold
+		}
+		if itemCount < 0 || itemCount > sold {
+			return Dispute{}, fmt.Errorf("%w: the itemCount of SKU %s must be between 1 and %d", errInvalidDispute, request.SKU, sold)
+		}
+	} else if request.ItemCount != 0 {
+		return Dispute{}, fmt.Errorf("%w: an itemCount requires a SKU", errInvalidDispute)
+	}
+
+	return Dispute{
+		DisputeID:     disputeID,
+		TransactionID: sale.TransactionID,
+		SKU:           request.SKU,
+		ItemCount:     itemCount,
+		Statement:     request.Statement,
+		Evidence:      request.Evidence,
+		Operator:      request.Operator,
+		Status:        DisputeStatusOpen,
+		OpenedAt:      now,
+	}, nil
+}
+
+// resolveDispute returns the dispute resolved by the resolution. An upheld
+// dispute is refunded in full: the refund of the disputed units, or of every
+// unit of the sale that was not refunded yet, is returned as a new
+// transaction with the refundTransactionID
+func resolveDispute(account Account, dispute Dispute, resolution disputeResolution, refundTransactionID int64, now int64) (Dispute, *Ledger, error) {
+	if dispute.Status != DisputeStatusOpen {
+		return Dispute{}, nil, fmt.Errorf("%w: dispute %d was %s already", errDisputeConflict, dispute.DisputeID, dispute.Status)
+	}
+	if resolution.Reviewer == "" {
+		return Dispute{}, nil, fmt.Errorf("%w: the reviewer is required", errInvalidDispute)
+	}
+
+	dispute.Reviewer = resolution.Reviewer
+	dispute.ResolutionNote = resolution.Note
+	dispute.ResolvedAt = now
+	switch resolution.Status {
+	case DisputeStatusRejected:
+		dispute.Status = DisputeStatusRejected
+		return dispute, nil, nil
+	case DisputeStatusUpheld:
+		dispute.Status = DisputeStatusUpheld
+	default:
+		return Dispute{}, nil, fmt.Errorf("%w: unknown resolution status %q", errInvalidDispute, resolution.Status)
+	}
+
+	var sale *Ledger
+	for i := range account.Ledgers {
+		if account.Ledgers[i].TransactionID == dispute.TransactionID {
+			sale = &account.Ledgers[i]
+		}
+	}
+	if sale == nil {
+		return Dispute{}, nil, fmt.Errorf("%w: transaction %d of dispute %d was deleted", errDisputeConflict, dispute.TransactionID, dispute.DisputeID)
+	}
+
+	request := adjustmentRequest{
+		Type:       TransactionTypeRefund,
+		ReasonCode: ReasonMisdetection,
+		Operator:   resolution.Reviewer,
+		Note:       fmt.Sprintf("dispute %d upheld", dispute.DisputeID),
+	}
+	if resolution.Note != "" {
+		request.Note += ": " + resolution.Note
+	}
+	if dispute.SKU != "" {
+		request.LineItems = []adjustmentLineItem{{SKU: dispute.SKU, ItemCount: dispute.ItemCount}}
+	}
+	refund, err := newAdjustment(account, *sale, request, refundTransactionID, now)
+	if err != nil {
+		if errors.Is(err, errAdjustmentConflict) {
+			return Dispute{}, nil, fmt.Errorf("%w: %s", errDisputeConflict, err.Error())
+		}
+		return Dispute{}, nil, fmt.Errorf("%w: %s", errInvalidDispute, err.Error())
+	}
+	dispute.RefundTransactionID = refund.TransactionID
+	return dispute, &refund, nil
+}
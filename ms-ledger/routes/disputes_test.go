@@ -0,0 +1,255 @@
+// Copyright © 2026 Intel Corporation. All rights reserved.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package routes
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/edgexfoundry/go-mod-core-contracts/v3/clients/logger"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// getDisputeAccount returns an account with a sale of two units of one SKU
+// and one unit of another, and a refund of a unit of the first SKU
+func getDisputeAccount() Account {
+	return Account{
+		AccountID: 1,
+		Ledgers: []Ledger{{
+			TransactionID: 10,
+			LineTotal:     500,
+			Subtotal:      500,
+			GrandTotal:    500,
+			Currency:      "USD",
+			LineItems: []LineItem{
+				{SKU: "1", ItemPrice: 100, ItemCount: 2, LineTotal: 200, Currency: "USD"},
+				{SKU: "2", ItemPrice: 300, ItemCount: 1, LineTotal: 300, Currency: "USD"},
+			},
+		}, {
+			TransactionID:        11,
+			Type:                 TransactionTypeRefund,
+			AdjustsTransactionID: 10,
+			LineTotal:            -100,
+			Subtotal:             -100,
+			GrandTotal:           -100,
+			Currency:             "USD",
+			LineItems:            []LineItem{{SKU: "1", ItemPrice: 100, ItemCount: -1, LineTotal: -100, Currency: "USD"}},
+		}},
+	}
+}
+
+func TestNewDispute(t *testing.T) {
+	evidence := DisputeEvidence{BeforeImage: "before.jpg", AfterImage: "after.jpg", AuditEntryID: "1579215712984890363"}
+	tests := []struct {
+		Name              string
+		TransactionIndex  int
+		Disputes          []Dispute
+		Request           disputeRequest
+		ExpectedItemCount int
+		ExpectedError     error
+	}{
+		{"Transaction", 0, nil, disputeRequest{Statement: "I did not take anything", Evidence: evidence}, 0, nil},
+		{"SKU", 0, nil, disputeRequest{SKU: "1", Statement: "I took one"}, 2, nil},
+		{"Units of SKU", 0, nil, disputeRequest{SKU: "1", ItemCount: 1, Statement: "I took one"}, 1, nil},
+		{"After resolved dispute", 0, []Dispute{{DisputeID: 1, TransactionID: 10, Status: DisputeStatusRejected}}, disputeRequest{Statement: "Again"}, 0, nil},
+		{"Open dispute", 0, []Dispute{{DisputeID: 1, TransactionID: 10, Status: DisputeStatusOpen}}, disputeRequest{Statement: "Again"}, 0, errDisputeConflict},
+		{"Refund", 1, nil, disputeRequest{Statement: "Wrong refund"}, 0, errInvalidDispute},
+		{"No statement", 0, nil, disputeRequest{}, 0, errInvalidDispute},
+		{"Unsold SKU", 0, nil, disputeRequest{SKU: "3", Statement: "Not mine"}, 0, errInvalidDispute},
+		{"Too many units", 0, nil, disputeRequest{SKU: "1", ItemCount: 3, Statement: "Not mine"}, 0, errInvalidDispute},
+		{"Units without SKU", 0, nil, disputeRequest{ItemCount: 1, Statement: "Not mine"}, 0, errInvalidDispute},
+	}
+
+	for _, test := range tests {
+		currentTest := test
+		t.Run(currentTest.Name, func(t *testing.T) {
+			account := getDisputeAccount()
+			account.Disputes = currentTest.Disputes
+			dispute, err := newDispute(account, account.Ledgers[currentTest.TransactionIndex], currentTest.Request, 2, 5)
+			if currentTest.ExpectedError != nil {
+				require.ErrorIs(t, err, currentTest.ExpectedError)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, Dispute{
+				DisputeID:     2,
+				TransactionID: 10,
+				SKU:           currentTest.Request.SKU,
+				ItemCount:     currentTest.ExpectedItemCount,
+				Statement:     currentTest.Request.Statement,
+				Evidence:      currentTest.Request.Evidence,
+				Status:        DisputeStatusOpen,
+				OpenedAt:      5,
+			}, dispute)
+		})
+	}
+}
+
+func TestResolveDispute(t *testing.T) {
+	tests := []struct {
+		Name              string
+		Dispute           Dispute
+		Resolution        disputeResolution
+		ExpectedStatus    string
+		ExpectedLineItems []LineItem
+		ExpectedError     error
+	}{
+		{"Rejected", Dispute{DisputeID: 1, TransactionID: 10, Status: DisputeStatusOpen}, disputeResolution{Status: DisputeStatusRejected, Reviewer: "jdoe"}, DisputeStatusRejected, nil, nil},
+		{"Upheld transaction", Dispute{DisputeID: 1, TransactionID: 10, Status: DisputeStatusOpen}, disputeResolution{Status: DisputeStatusUpheld, Reviewer: "jdoe", Note: "empty hand in after image"}, DisputeStatusUpheld,
+			[]LineItem{{SKU: "1", ItemPrice: 100, ItemCount: -1, LineTotal: -100, Currency: "USD"}, {SKU: "2", ItemPrice: 300, ItemCount: -1, LineTotal: -300, Currency: "USD"}}, nil},
+		{"Upheld SKU", Dispute{DisputeID: 1, TransactionID: 10, SKU: "2", ItemCount: 1, Status: DisputeStatusOpen}, disputeResolution{Status: DisputeStatusUpheld, Reviewer: "jdoe"}, DisputeStatusUpheld,
+			[]LineItem{{SKU: "2", ItemPrice: 300, ItemCount: -1, LineTotal: -300, Currency: "USD"}}, nil},
+		{"Upheld refunded units", Dispute{DisputeID: 1, TransactionID: 10, SKU: "1", ItemCount: 2, Status: DisputeStatusOpen}, disputeResolution{Status: DisputeStatusUpheld, Reviewer: "jdoe"}, "", nil, errDisputeConflict},
+		{"Deleted transaction", Dispute{DisputeID: 1, TransactionID: 12, Status: DisputeStatusOpen}, disputeResolution{Status: DisputeStatusUpheld, Reviewer: "jdoe"}, "", nil, errDisputeConflict},
+		{"Resolved already", Dispute{DisputeID: 1, TransactionID: 10, Status: DisputeStatusRejected}, disputeResolution{Status: DisputeStatusUpheld, Reviewer: "jdoe"}, "", nil, errDisputeConflict},
+		{"No reviewer", Dispute{DisputeID: 1, TransactionID: 10, Status: DisputeStatusOpen}, disputeResolution{Status: DisputeStatusUpheld}, "", nil, errInvalidDispute},
+		{"Unknown status", Dispute{DisputeID: 1, TransactionID: 10, Status: DisputeStatusOpen}, disputeResolution{Status: DisputeStatusOpen, Reviewer: "jdoe"}, "", nil, errInvalidDispute},
+	}
+
+	for _, test := range tests {
+		currentTest := test
+		t.Run(currentTest.Name, func(t *testing.T) {
+			resolved, refund, err := resolveDispute(getDisputeAccount(), currentTest.Dispute, currentTest.Resolution, 20, 5)
+			if currentTest.ExpectedError != nil {
+				require.ErrorIs(t, err, currentTest.ExpectedError)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, currentTest.ExpectedStatus, resolved.Status)
+			assert.Equal(t, currentTest.Resolution.Reviewer, resolved.Reviewer)
+			assert.Equal(t, int64(5), resolved.ResolvedAt)
+			if currentTest.ExpectedLineItems == nil {
+				assert.Nil(t, refund)
+				assert.Zero(t, resolved.RefundTransactionID)
+				return
+			}
+			require.NotNil(t, refund)
+			assert.Equal(t, int64(20), resolved.RefundTransactionID)
+			assert.Equal(t, TransactionTypeRefund, refund.Type)
+			assert.Equal(t, ReasonMisdetection, refund.ReasonCode)
+			assert.Equal(t, int64(10), refund.AdjustsTransactionID)
+			assert.Equal(t, currentTest.ExpectedLineItems, refund.LineItems)
+		})
+	}
+}
+
+// TestDisputeWorkflow tests that a dispute is opened with its evidence, that
+// a second dispute of the transaction conflicts while it is open, and that
+// upholding it refunds the transaction and shows on the account
+func TestDisputeWorkflow(t *testing.T) {
+	c := Controller{
+		lc:             logger.NewMockClient(),
+		ledgerFileName: LedgerFileName,
+	}
+	data, err := json.Marshal(getDefaultAccountLedgers())
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(c.ledgerFileName, data, 0644))
+	defer func() {
+		os.Remove(c.ledgerFileName)
+	}()
+
+	openDispute := func(tid string) *httptest.ResponseRecorder {
+		body := `{"statement":"I did not take that","evidence":{"beforeImage":"before.jpg","afterImage":"after.jpg","auditEntryId":"1579215712984890363"},"operator":"support"}`
+		req := httptest.NewRequest("POST", "http://localhost:48093/ledger/1/"+tid+"/dispute", bytes.NewBuffer([]byte(body)))
+		req = mux.SetURLVars(req, map[string]string{"accountid": "1", "tid": tid})
+		w := httptest.NewRecorder()
+		c.LedgerDisputePost(w, req)
+		return w
+	}
+	resolveDispute := func(disputeID string, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("POST", "http://localhost:48093/ledger/1/disputes/"+disputeID+"/resolution", bytes.NewBuffer([]byte(body)))
+		req = mux.SetURLVars(req, map[string]string{"accountid": "1", "disputeid": disputeID})
+		w := httptest.NewRecorder()
+		c.DisputeResolutionPost(w, req)
+		return w
+	}
+
+	assert.Equal(t, http.StatusBadRequest, openDispute("1").Code, "unknown transaction")
+	w := openDispute("1579215712984890248")
+	require.Equal(t, http.StatusOK, w.Code)
+	var dispute Dispute
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&dispute))
+	assert.Equal(t, int64(1), dispute.DisputeID)
+	assert.Equal(t, DisputeStatusOpen, dispute.Status)
+	assert.Equal(t, "after.jpg", dispute.Evidence.AfterImage)
+	assert.Equal(t, http.StatusConflict, openDispute("1579215712984890248").Code, "a second open dispute should conflict")
+
+	assert.Equal(t, http.StatusBadRequest, resolveDispute("2", `{"status":"upheld","reviewer":"jdoe"}`).Code, "unknown dispute")
+	assert.Equal(t, http.StatusBadRequest, resolveDispute("1", `{"status":"upheld"}`).Code, "no reviewer")
+	w = resolveDispute("1", `{"status":"upheld","reviewer":"jdoe","note":"empty hand in after image"}`)
+	require.Equal(t, http.StatusOK, w.Code)
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&dispute))
+	assert.Equal(t, DisputeStatusUpheld, dispute.Status)
+	assert.Equal(t, http.StatusConflict, resolveDispute("1", `{"status":"rejected","reviewer":"jdoe"}`).Code, "a resolved dispute should conflict")
+
+	accountLedgers, err := c.GetAllLedgers()
+	require.NoError(t, err)
+	account := accountLedgers.Data[0]
+	require.Len(t, account.Ledgers, 2)
+	refund := account.Ledgers[1]
+	assert.Equal(t, dispute.RefundTransactionID, refund.TransactionID)
+	assert.Equal(t, "dispute 1 upheld: empty hand in after image", refund.Note)
+	assert.Equal(t, int64(-199), refund.GrandTotal)
+	assert.Equal(t, []Dispute{dispute}, account.Disputes)
+	assert.Equal(t, map[string]int64{"USD": 0}, accountLedgers.charges(1), "the refund should post to the journal")
+}
+
+func TestLedgerDisputesGet(t *testing.T) {
+	tests := []struct {
+		Name               string
+		AccountID          string
+		Query              string
+		ExpectedStatusCode int
+		ExpectedDisputes   int
+	}{
+		{"All disputes", "1", "", http.StatusOK, 2},
+		{"Open disputes", "1", "?status=open", http.StatusOK, 1},
+		{"No disputes", "2", "", http.StatusOK, 0},
+		{"Invalid status", "1", "?status=closed", http.StatusBadRequest, 0},
+		{"Invalid AccountID", "one", "", http.StatusBadRequest, 0},
+		{"Nonexistent AccountID", "10", "", http.StatusBadRequest, 0},
+	}
+
+	for _, test := range tests {
+		currentTest := test
+		t.Run(currentTest.Name, func(t *testing.T) {
+			c := Controller{
+				lc:             logger.NewMockClient(),
+				ledgerFileName: LedgerFileName,
+			}
+			accountLedgers := getDefaultAccountLedgers()
+			accountLedgers.Data[0].Disputes = []Dispute{
+				{DisputeID: 1, TransactionID: 1579215712984890248, Statement: "Not mine", Status: DisputeStatusRejected},
+				{DisputeID: 2, TransactionID: 1579215712984890248, Statement: "Really not mine", Status: DisputeStatusOpen},
+			}
+			data, err := json.Marshal(accountLedgers)
+			require.NoError(t, err)
+			require.NoError(t, os.WriteFile(c.ledgerFileName, data, 0644))
+			defer func() {
+				os.Remove(c.ledgerFileName)
+			}()
+
+			req := httptest.NewRequest("GET", "http://localhost:48093/ledger/"+currentTest.AccountID+"/disputes"+currentTest.Query, nil)
+			req = mux.SetURLVars(req, map[string]string{"accountid": currentTest.AccountID})
+			w := httptest.NewRecorder()
+			c.LedgerDisputesGet(w, req)
+			resp := w.Result()
+			defer resp.Body.Close()
+
+			require.Equal(t, currentTest.ExpectedStatusCode, resp.StatusCode, "invalid status code")
+			if resp.StatusCode != http.StatusOK {
+				return
+			}
+			var disputes []Dispute
+			require.NoError(t, json.NewDecoder(resp.Body).Decode(&disputes))
+			assert.Len(t, disputes, currentTest.ExpectedDisputes)
+		})
+	}
+}
@@ -5,14 +5,19 @@ package routes
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io/fs"
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/gorilla/mux"
 )
 
-// LedgerAccountGet will get the transaction ledger for a specific account
+// LedgerAccountGet will get the transaction ledger for a specific account.
+// Both the ledgers and the balances of the account are projected from the
+// journal
 func (c *Controller) LedgerAccountGet(writer http.ResponseWriter, req *http.Request) {
 	//Get all ledgers for all accounts
 	accountLedgers, err := c.GetAllLedgers()
@@ -39,6 +44,7 @@ func (c *Controller) LedgerAccountGet(writer http.ResponseWriter, req *http.Requ
 	if accountID >= 0 {
 		for _, account := range accountLedgers.Data {
 			if accountID == account.AccountID {
+				account.Balances = accountLedgers.charges(account.AccountID)
 				accountLedger, err := json.Marshal(account)
 				if err != nil {
 					errMsg := fmt.Sprintf("Failed to retrieve account ledger %v", err.Error())
@@ -60,7 +66,9 @@ func (c *Controller) LedgerAccountGet(writer http.ResponseWriter, req *http.Requ
 	}
 }
 
-// AllAccountsGet will get the entire ledger with transactions for all accounts
+// AllAccountsGet will get the entire ledger with transactions for all
+// accounts. As for LedgerAccountGet, the ledgers and balances are projected
+// from the journal
 func (c *Controller) AllAccountsGet(writer http.ResponseWriter, req *http.Request) {
 	// Get the list of accounts with all ledgers
 	accountLedgers, err := c.GetAllLedgers()
@@ -72,8 +80,12 @@ func (c *Controller) AllAccountsGet(writer http.ResponseWriter, req *http.Reques
 		return
 	}
 
-	// No logic needs to be done here, since we are just reading the file
-	// and writing it back out. Simply marshaling it will validate its structure
+	// the balances of every account are filled in from the journal, and the
+	// journal itself is cleared from the response
+	for accountIndex := range accountLedgers.Data {
+		accountLedgers.Data[accountIndex].Balances = accountLedgers.charges(accountLedgers.Data[accountIndex].AccountID)
+	}
+	accountLedgers.Journal = nil
 	accountLedgersJSON, err := json.Marshal(accountLedgers)
 	if err != nil {
 		errMsg := "Failed to unmarshal accountLedgers"
@@ -85,3 +97,514 @@ func (c *Controller) AllAccountsGet(writer http.ResponseWriter, req *http.Reques
 	c.lc.Info("GET ALL ledger accounts successfully")
 	writer.Write(accountLedgersJSON)
 }
+
+// statementQuery returns the format and currency query parameters of a
+// statement request. They default to JSON and the configured currency
+func (c *Controller) statementQuery(req *http.Request) (string, string, error) {
+	query := req.URL.Query()
+	format := query.Get("format")
+	if format == "" {
+		format = statementFormatJSON
+	}
+	if format != statementFormatJSON && format != statementFormatCSV {
+		return "", "", fmt.Errorf("format must be %s or %s: %s", statementFormatJSON, statementFormatCSV, format)
+	}
+	currency := query.Get("currency")
+	if currency == "" {
+		currency = c.currencyOrDefault()
+	}
+	if !validCurrency(currency) {
+		return "", "", fmt.Errorf("currency is not a supported ISO 4217 currency code: %s", currency)
+	}
+	return format, currency, nil
+}
+
+// periodQuery returns the period of the "from" and "to" query parameters,
+// which default to from and to
+func periodQuery(req *http.Request, from int64, to int64) (int64, int64, error) {
+	query := req.URL.Query()
+	for name, value := range map[string]*int64{"from": &from, "to": &to} {
+		if !query.Has(name) {
+			continue
+		}
+		var err error
+		if *value, err = parseStatementTime(query.Get(name)); err != nil {
+			return 0, 0, fmt.Errorf("Invalid %s query parameter %v", name, err.Error())
+		}
+	}
+	if from >= to {
+		return 0, 0, errors.New("The from query parameter must be before the to query parameter")
+	}
+	return from, to, nil
+}
+
+// writeStatement writes the statement in the format, as an attachment with
+// the file name when it is CSV
+func (c *Controller) writeStatement(writer http.ResponseWriter, statement Statement, format string, fileName string) {
+	var data []byte
+	var err error
+	if format == statementFormatCSV {
+		data, err = statementCSV(statement)
+	} else {
+		data, err = json.Marshal(statement)
+	}
+	if err != nil {
+		errMsg := fmt.Sprintf("Failed to process statement %v", err.Error())
+		c.lc.Error(errMsg)
+		writer.WriteHeader(http.StatusInternalServerError)
+		writer.Write([]byte(errMsg))
+		return
+	}
+
+	if format == statementFormatCSV {
+		writer.Header().Set("Content-Type", "text/csv")
+		writer.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", fileName+"."+statementFormatCSV))
+	} else {
+		writer.Header().Set("Content-Type", "application/json")
+	}
+	writer.Write(data)
+}
+
+// LedgerStatementGet will get the statement of a specific account between
+// the "from" and "to" query parameters, which default to the start of the
+// current month and now. The "format" query parameter is json or csv, and
+// the "currency" query parameter selects the transactions of one currency
+func (c *Controller) LedgerStatementGet(writer http.ResponseWriter, req *http.Request) {
+	vars := mux.Vars(req)
+	accountID, err := strconv.Atoi(vars["accountid"])
+	if err != nil {
+		errMsg := fmt.Sprintf("AccountID is invalid %v", err.Error())
+		c.lc.Error(errMsg)
+		writer.WriteHeader(http.StatusBadRequest)
+		writer.Write([]byte(errMsg))
+		return
+	}
+
+	format, currency, err := c.statementQuery(req)
+	if err != nil {
+		errMsg := fmt.Sprintf("Invalid statement query %v", err.Error())
+		c.lc.Error(errMsg)
+		writer.WriteHeader(http.StatusBadRequest)
+		writer.Write([]byte(errMsg))
+		return
+	}
+
+	now := time.Now().UnixNano()
+	from, to, err := periodQuery(req, monthStart(now).UnixNano(), now)
+	if err != nil {
+		errMsg := err.Error()
+		c.lc.Error(errMsg)
+		writer.WriteHeader(http.StatusBadRequest)
+		writer.Write([]byte(errMsg))
+		return
+	}
+
+	accountLedgers, err := c.GetAllLedgers()
+	if err != nil {
+		errMsg := fmt.Sprintf("Failed to retrieve all ledgers for accounts %v", err.Error())
+		c.lc.Error(errMsg)
+		writer.WriteHeader(http.StatusInternalServerError)
+		writer.Write([]byte(errMsg))
+		return
+	}
+
+	for _, account := range accountLedgers.Data {
+		if accountID == account.AccountID {
+			statement := buildStatement(account, currency, from, to, now)
+			fileName := fmt.Sprintf("statement-%d-%s-%s", accountID, time.Unix(0, from).UTC().Format(statementDateLayout), time.Unix(0, to).UTC().Format(statementDateLayout))
+			c.lc.Info("GET ledger account statement successfully")
+			c.writeStatement(writer, statement, format, fileName)
+			return
+		}
+	}
+	errMsg := fmt.Sprintf("AccountID %v not found in ledger", accountID)
+	c.lc.Error(errMsg)
+	writer.WriteHeader(http.StatusBadRequest)
+	writer.Write([]byte(errMsg))
+}
+
+// LedgerStatementsGet will get the list of stored monthly statements of a
+// specific account
+func (c *Controller) LedgerStatementsGet(writer http.ResponseWriter, req *http.Request) {
+	vars := mux.Vars(req)
+	accountID, err := strconv.Atoi(vars["accountid"])
+	if err != nil {
+		errMsg := fmt.Sprintf("AccountID is invalid %v", err.Error())
+		c.lc.Error(errMsg)
+		writer.WriteHeader(http.StatusBadRequest)
+		writer.Write([]byte(errMsg))
+		return
+	}
+
+	storedStatements, err := c.getStoredStatements(accountID)
+	if err != nil {
+		errMsg := fmt.Sprintf("Failed to retrieve statements %v", err.Error())
+		c.lc.Error(errMsg)
+		writer.WriteHeader(http.StatusInternalServerError)
+		writer.Write([]byte(errMsg))
+		return
+	}
+	storedStatementsJSON, err := json.Marshal(storedStatements)
+	if err != nil {
+		errMsg := fmt.Sprintf("Failed to process statements %v", err.Error())
+		c.lc.Error(errMsg)
+		writer.WriteHeader(http.StatusInternalServerError)
+		writer.Write([]byte(errMsg))
+		return
+	}
+	c.lc.Info("GET ledger account statements successfully")
+	writer.Write(storedStatementsJSON)
+}
+
+// LedgerStoredStatementGet will download the stored monthly statement of a
+// specific account for a period such as 2026-09. The "format" and "currency"
+// query parameters are the same as for LedgerStatementGet
+func (c *Controller) LedgerStoredStatementGet(writer http.ResponseWriter, req *http.Request) {
+	vars := mux.Vars(req)
+	accountID, err := strconv.Atoi(vars["accountid"])
+	if err != nil {
+		errMsg := fmt.Sprintf("AccountID is invalid %v", err.Error())
+		c.lc.Error(errMsg)
+		writer.WriteHeader(http.StatusBadRequest)
+		writer.Write([]byte(errMsg))
+		return
+	}
+
+	period := vars["period"]
+	if _, err := time.Parse(statementPeriodLayout, period); err != nil {
+		errMsg := fmt.Sprintf("Statement period %s is not a month such as 2026-09", period)
+		c.lc.Error(errMsg)
+		writer.WriteHeader(http.StatusBadRequest)
+		writer.Write([]byte(errMsg))
+		return
+	}
+
+	format, currency, err := c.statementQuery(req)
+	if err != nil {
+		errMsg := fmt.Sprintf("Invalid statement query %v", err.Error())
+		c.lc.Error(errMsg)
+		writer.WriteHeader(http.StatusBadRequest)
+		writer.Write([]byte(errMsg))
+		return
+	}
+
+	statement, err := c.readStoredStatement(accountID, period, currency)
+	if errors.Is(err, fs.ErrNotExist) {
+		errMsg := fmt.Sprintf("No %s statement of account %v for %s", currency, accountID, period)
+		c.lc.Error(errMsg)
+		writer.WriteHeader(http.StatusNotFound)
+		writer.Write([]byte(errMsg))
+		return
+	}
+	if err != nil {
+		errMsg := fmt.Sprintf("Failed to retrieve statement %v", err.Error())
+		c.lc.Error(errMsg)
+		writer.WriteHeader(http.StatusInternalServerError)
+		writer.Write([]byte(errMsg))
+		return
+	}
+
+	c.lc.Info("GET ledger account stored statement successfully")
+	c.writeStatement(writer, statement, format, fmt.Sprintf("statement-%d-%s-%s", accountID, period, currency))
+}
+
+// ReconciliationGet will compare the ledger accounts with the accounts of the
+// authentication service, and report the accounts that are missing from the
+// ledger, orphaned in the ledger or inactive
+func (c *Controller) ReconciliationGet(writer http.ResponseWriter, req *http.Request) {
+	if c.accountsEndpoint == "" {
+		errMsg := "AccountsEndpoint is not set in ApplicationSettings"
+		c.lc.Error(errMsg)
+		writer.WriteHeader(http.StatusServiceUnavailable)
+		writer.Write([]byte(errMsg))
+		return
+	}
+
+	authAccounts, err := c.getAuthAccounts()
+	if err != nil {
+		errMsg := fmt.Sprintf("Failed to retrieve accounts from the authentication service %v", err.Error())
+		c.lc.Error(errMsg)
+		writer.WriteHeader(http.StatusServiceUnavailable)
+		writer.Write([]byte(errMsg))
+		return
+	}
+
+	accountLedgers, err := c.GetAllLedgers()
+	if err != nil {
+		errMsg := fmt.Sprintf("Failed to retrieve all ledgers for accounts %v", err.Error())
+		c.lc.Error(errMsg)
+		writer.WriteHeader(http.StatusInternalServerError)
+		writer.Write([]byte(errMsg))
+		return
+	}
+
+	reconciliationJSON, err := json.Marshal(reconcileAccounts(accountLedgers, authAccounts))
+	if err != nil {
+		errMsg := fmt.Sprintf("Failed to process account reconciliation %v", err.Error())
+		c.lc.Error(errMsg)
+		writer.WriteHeader(http.StatusInternalServerError)
+		writer.Write([]byte(errMsg))
+		return
+	}
+	c.lc.Info("GET ledger account reconciliation successfully")
+	writer.Write(reconciliationJSON)
+}
+
+// SalesAnalyticsGet will get the sales report of every account between the
+// "from" and "to" query parameters, which default to the last seven days. The
+// "currency" query parameter selects the transactions of one currency, and
+// the "top" query parameter sets the number of top products
+func (c *Controller) SalesAnalyticsGet(writer http.ResponseWriter, req *http.Request) {
+	now := time.Now().UnixNano()
+	from, to, err := periodQuery(req, now-defaultAnalyticsPeriod.Nanoseconds(), now)
+	if err != nil {
+		errMsg := err.Error()
+		c.lc.Error(errMsg)
+		writer.WriteHeader(http.StatusBadRequest)
+		writer.Write([]byte(errMsg))
+		return
+	}
+
+	_, currency, err := c.statementQuery(req)
+	if err != nil {
+		errMsg := fmt.Sprintf("Invalid sales analytics query %v", err.Error())
+		c.lc.Error(errMsg)
+		writer.WriteHeader(http.StatusBadRequest)
+		writer.Write([]byte(errMsg))
+		return
+	}
+
+	top := defaultTopProducts
+	if value := req.URL.Query().Get("top"); value != "" {
+		top, err = strconv.Atoi(value)
+		if err != nil || top < 1 {
+			errMsg := fmt.Sprintf("Invalid top query parameter, it must be a positive number: %s", value)
+			c.lc.Error(errMsg)
+			writer.WriteHeader(http.StatusBadRequest)
+			writer.Write([]byte(errMsg))
+			return
+		}
+	}
+
+	accountLedgers, err := c.GetAllLedgers()
+	if err != nil {
+		errMsg := fmt.Sprintf("Failed to retrieve all ledgers for accounts %v", err.Error())
+		c.lc.Error(errMsg)
+		writer.WriteHeader(http.StatusInternalServerError)
+		writer.Write([]byte(errMsg))
+		return
+	}
+
+	reportJSON, err := json.Marshal(buildSalesReport(accountLedgers, currency, from, to, top, now))
+	if err != nil {
+		errMsg := fmt.Sprintf("Failed to process sales report %v", err.Error())
+		c.lc.Error(errMsg)
+		writer.WriteHeader(http.StatusInternalServerError)
+		writer.Write([]byte(errMsg))
+		return
+	}
+	c.lc.Info("GET sales analytics successfully")
+	writer.Header().Set("Content-Type", "application/json")
+	writer.Write(reportJSON)
+}
+
+// JournalGet will get the journal entries of every account between the "from"
+// and "to" query parameters, which default to every entry up to now. The
+// "accountid" query parameter selects the entries of one account
+func (c *Controller) JournalGet(writer http.ResponseWriter, req *http.Request) {
+	now := time.Now().UnixNano()
+	from, to, err := periodQuery(req, 0, now+1)
+	if err != nil {
+		errMsg := err.Error()
+		c.lc.Error(errMsg)
+		writer.WriteHeader(http.StatusBadRequest)
+		writer.Write([]byte(errMsg))
+		return
+	}
+
+	accountID := -1
+	if value := req.URL.Query().Get("accountid"); value != "" {
+		if accountID, err = strconv.Atoi(value); err != nil {
+			errMsg := fmt.Sprintf("AccountID is invalid %v", err.Error())
+			c.lc.Error(errMsg)
+			writer.WriteHeader(http.StatusBadRequest)
+			writer.Write([]byte(errMsg))
+			return
+		}
+	}
+
+	accountLedgers, err := c.GetAllLedgers()
+	if err != nil {
+		errMsg := fmt.Sprintf("Failed to retrieve all ledgers for accounts %v", err.Error())
+		c.lc.Error(errMsg)
+		writer.WriteHeader(http.StatusInternalServerError)
+		writer.Write([]byte(errMsg))
+		return
+	}
+
+	entries := []JournalEntry{}
+	for _, entry := range accountLedgers.Journal {
+		if entry.TimeStamp < from || entry.TimeStamp >= to || (accountID >= 0 && entry.AccountID != accountID) {
+			continue
+		}
+		entries = append(entries, entry)
+	}
+	entriesJSON, err := json.Marshal(entries)
+	if err != nil {
+		errMsg := fmt.Sprintf("Failed to process journal %v", err.Error())
+		c.lc.Error(errMsg)
+		writer.WriteHeader(http.StatusInternalServerError)
+		writer.Write([]byte(errMsg))
+		return
+	}
+	c.lc.Info("GET journal successfully")
+	writer.Write(entriesJSON)
+}
+
+// TrialBalanceGet will get the trial balance of the journal up to the "asOf"
+// query parameter, which defaults to now. The "currency" query parameter
+// selects the entries of one currency
+func (c *Controller) TrialBalanceGet(writer http.ResponseWriter, req *http.Request) {
+	asOf := time.Now().UnixNano()
+	var err error
+	if value := req.URL.Query().Get("asOf"); value != "" {
+		if asOf, err = parseStatementTime(value); err != nil {
+			errMsg := fmt.Sprintf("Invalid asOf query parameter %v", err.Error())
+			c.lc.Error(errMsg)
+			writer.WriteHeader(http.StatusBadRequest)
+			writer.Write([]byte(errMsg))
+			return
+		}
+	}
+
+	_, currency, err := c.statementQuery(req)
+	if err != nil {
+		errMsg := fmt.Sprintf("Invalid trial balance query %v", err.Error())
+		c.lc.Error(errMsg)
+		writer.WriteHeader(http.StatusBadRequest)
+		writer.Write([]byte(errMsg))
+		return
+	}
+
+	accountLedgers, err := c.GetAllLedgers()
+	if err != nil {
+		errMsg := fmt.Sprintf("Failed to retrieve all ledgers for accounts %v", err.Error())
+		c.lc.Error(errMsg)
+		writer.WriteHeader(http.StatusInternalServerError)
+		writer.Write([]byte(errMsg))
+		return
+	}
+
+	trialBalanceJSON, err := json.Marshal(trialBalance(accountLedgers.Journal, currency, asOf))
+	if err != nil {
+		errMsg := fmt.Sprintf("Failed to process trial balance %v", err.Error())
+		c.lc.Error(errMsg)
+		writer.WriteHeader(http.StatusInternalServerError)
+		writer.Write([]byte(errMsg))
+		return
+	}
+	c.lc.Info("GET trial balance successfully")
+	writer.Write(trialBalanceJSON)
+}
+
+// LedgerBalanceGet will get the charges, payments and receivable of a
+// specific account per currency, projected from the journal
+func (c *Controller) LedgerBalanceGet(writer http.ResponseWriter, req *http.Request) {
+	vars := mux.Vars(req)
+	accountID, err := strconv.Atoi(vars["accountid"])
+	if err != nil {
+		errMsg := fmt.Sprintf("AccountID is invalid %v", err.Error())
+		c.lc.Error(errMsg)
+		writer.WriteHeader(http.StatusBadRequest)
+		writer.Write([]byte(errMsg))
+		return
+	}
+
+	accountLedgers, err := c.GetAllLedgers()
+	if err != nil {
+		errMsg := fmt.Sprintf("Failed to retrieve all ledgers for accounts %v", err.Error())
+		c.lc.Error(errMsg)
+		writer.WriteHeader(http.StatusInternalServerError)
+		writer.Write([]byte(errMsg))
+		return
+	}
+	if !accountLedgers.hasAccount(accountID) {
+		errMsg := fmt.Sprintf("AccountID %v not found in ledger", accountID)
+		c.lc.Error(errMsg)
+		writer.WriteHeader(http.StatusBadRequest)
+		writer.Write([]byte(errMsg))
+		return
+	}
+
+	balancesJSON, err := json.Marshal(accountLedgers.accountBalances(accountID))
+	if err != nil {
+		errMsg := fmt.Sprintf("Failed to process account balance %v", err.Error())
+		c.lc.Error(errMsg)
+		writer.WriteHeader(http.StatusInternalServerError)
+		writer.Write([]byte(errMsg))
+		return
+	}
+	c.lc.Info("GET ledger account balance successfully")
+	writer.Write(balancesJSON)
+}
+
+// LedgerDisputesGet will get the disputes of a specific account, optionally
+// only those with the "status" query parameter
+func (c *Controller) LedgerDisputesGet(writer http.ResponseWriter, req *http.Request) {
+	vars := mux.Vars(req)
+	accountID, err := strconv.Atoi(vars["accountid"])
+	if err != nil {
+		errMsg := fmt.Sprintf("AccountID is invalid %v", err.Error())
+		c.lc.Error(errMsg)
+		writer.WriteHeader(http.StatusBadRequest)
+		writer.Write([]byte(errMsg))
+		return
+	}
+
+	status := req.URL.Query().Get("status")
+	switch status {
+	case "", DisputeStatusOpen, DisputeStatusUpheld, DisputeStatusRejected:
+	default:
+		errMsg := fmt.Sprintf("status %q is not a dispute status", status)
+		c.lc.Error(errMsg)
+		writer.WriteHeader(http.StatusBadRequest)
+		writer.Write([]byte(errMsg))
+		return
+	}
+
+	accountLedgers, err := c.GetAllLedgers()
+	if err != nil {
+		errMsg := fmt.Sprintf("Failed to retrieve all ledgers for accounts %v", err.Error())
+		c.lc.Error(errMsg)
+		writer.WriteHeader(http.StatusInternalServerError)
+		writer.Write([]byte(errMsg))
+		return
+	}
+
+	for _, account := range accountLedgers.Data {
+		if accountID != account.AccountID {
+			continue
+		}
+		disputes := []Dispute{}
+		for _, dispute := range account.Disputes {
+			if status == "" || dispute.Status == status {
+				disputes = append(disputes, dispute)
+			}
+		}
+		disputesJSON, err := json.Marshal(disputes)
+		if err != nil {
+			errMsg := fmt.Sprintf("Failed to process disputes %v", err.Error())
+			c.lc.Error(errMsg)
+			writer.WriteHeader(http.StatusInternalServerError)
+			writer.Write([]byte(errMsg))
+			return
+		}
+		c.lc.Info("GET ledger disputes successfully")
+		writer.Write(disputesJSON)
+		return
+	}
+	errMsg := fmt.Sprintf("AccountID %v not found in ledger", accountID)
+	c.lc.Error(errMsg)
+	writer.WriteHeader(http.StatusBadRequest)
+	writer.Write([]byte(errMsg))
+}
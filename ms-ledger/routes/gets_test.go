@@ -40,10 +40,10 @@ func TestAllAccountsGet(t *testing.T) {
 			mockAppService.On("AddRoute", mock.Anything, mock.Anything, mock.Anything, mock.Anything).
 				Return(nil)
 			c := Controller{
-				lc:                logger.NewMockClient(),
-				service:           mockAppService,
-				inventoryEndpoint: "test.com",
-				ledgerFileName:    LedgerFileName,
+				lc:              logger.NewMockClient(),
+				service:         mockAppService,
+				pricingEndpoint: "test.com",
+				ledgerFileName:  LedgerFileName,
 			}
 			err := c.DeleteAllLedgers()
 			require.NoError(t, err)
@@ -95,10 +95,10 @@ func TestLedgerAccountGet(t *testing.T) {
 			mockAppService.On("AddRoute", mock.Anything, mock.Anything, mock.Anything, mock.Anything).
 				Return(nil)
 			c := Controller{
-				lc:                logger.NewMockClient(),
-				service:           mockAppService,
-				inventoryEndpoint: "test.com",
-				ledgerFileName:    LedgerFileName,
+				lc:              logger.NewMockClient(),
+				service:         mockAppService,
+				pricingEndpoint: "test.com",
+				ledgerFileName:  LedgerFileName,
 			}
 			err := c.DeleteAllLedgers()
 			require.NoError(t, err)
@@ -123,6 +123,11 @@ func TestLedgerAccountGet(t *testing.T) {
 			defer resp.Body.Close()
 
 			assert.Equal(t, currentTest.ExpectedStatusCode, resp.StatusCode, "invalid status code")
+			if resp.StatusCode == http.StatusOK {
+				var account Account
+				require.NoError(t, json.NewDecoder(resp.Body).Decode(&account))
+				assert.Equal(t, map[string]int64{"USD": 199}, account.Balances)
+			}
 		})
 	}
 }
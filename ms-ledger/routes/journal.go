@@ -0,0 +1,322 @@
+// Copyright © 2026 Intel Corporation. All rights reserved.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package routes
+
+import (
+	"slices"
+	"sort"
+)
+
+// Journal accounts. The receivable of every customer account is posted to
+// JournalAccountReceivable, with the customer in the AccountID of the entry
+const (
+	JournalAccountReceivable   = "customerReceivable"
+	JournalAccountRevenue      = "revenue"
+	JournalAccountTaxPayable   = "taxPayable"
+	JournalAccountRefunds      = "refunds"
+	JournalAccountCashClearing = "cashClearing"
+)
+
+// Types of journal entries that are not transaction types
+const (
+	JournalEntrySale            = "sale"
+	JournalEntryPayment         = "payment"
+	JournalEntryPaymentReversal = "paymentReversal"
+	JournalEntryReprice         = "reprice"
+)
+
+// journalAccounts is the order of the journal accounts in entries and trial
+// balances
+var journalAccounts = []string{
+	JournalAccountReceivable,
+	JournalAccountCashClearing,
+	JournalAccountRevenue,
+	JournalAccountRefunds,
+	JournalAccountTaxPayable,
+}
+
+// journalLines returns the lines of signed amounts per journal account, where
+// a positive amount is a debit and a negative amount a credit. Accounts
+// without an amount are left out
+func journalLines(amounts map[string]int64) []JournalLine {
+	lines := []JournalLine{}
+	for _, account := range journalAccounts {
+		amount := amounts[account]
+		if amount > 0 {
+			lines = append(lines, JournalLine{Account: account, Debit: amount})
+		} else if amount < 0 {
+			lines = append(lines, JournalLine{Account: account, Credit: -amount})
+		}
+	}
+	return lines
+}
+
+// transactionAmounts returns the signed amounts a transaction posts: its
+// grand total is owed by the customer, its subtotal is earned and its tax is
+// owed to the tax authority. Refunds are kept apart from revenue, while a
+// void takes the revenue of the sale back
+func transactionAmounts(ledger Ledger) map[string]int64 {
+	revenueAccount := JournalAccountRevenue
+	if ledger.Type == TransactionTypeRefund {
+		revenueAccount = JournalAccountRefunds
+	}
+	return map[string]int64{
+		JournalAccountReceivable: ledger.GrandTotal,
+		revenueAccount:           -ledger.Subtotal,
+		JournalAccountTaxPayable: -ledger.TaxTotal,
+	}
+}
+
+// post appends an entry to the journal with the next entry ID
+func (accountLedgers *Accounts) post(entryType string, accountID int, ledger Ledger, timestamp int64, amounts map[string]int64) {
+	accountLedgers.Journal = append(accountLedgers.Journal, JournalEntry{
+		EntryID:       int64(len(accountLedgers.Journal) + 1),
+		Type:          entryType,
+		AccountID:     accountID,
+		TransactionID: ledger.TransactionID,
+		TimeStamp:     timestamp,
+		Currency:      ledger.Currency,
+		Lines:         journalLines(amounts),
+	})
+}
+
+// journalTransaction returns a copy of the transaction as its journal entry
+// records it. Whether it is paid is recorded by payment entries instead
+func journalTransaction(ledger Ledger) *Ledger {
+	ledger.IsPaid = false
+	ledger.PaidAt = 0
+	ledger.LineItems = slices.Clone(ledger.LineItems)
+	return &ledger
+}
+
+// postTransaction posts a sale, refund or void of the account to the journal
+// when it is made, together with the transaction itself
+func (accountLedgers *Accounts) postTransaction(accountID int, ledger Ledger) {
+	entryType := ledger.Type
+	if entryType == "" {
+		entryType = JournalEntrySale
+	}
+	accountLedgers.post(entryType, accountID, ledger, ledger.TxTimeStamp, transactionAmounts(ledger))
+	accountLedgers.Journal[len(accountLedgers.Journal)-1].Transaction = journalTransaction(ledger)
+}
+
+// postPayment posts the payment of a transaction of the account to the
+// journal, or its reversal when the transaction is marked as unpaid again
+func (accountLedgers *Accounts) postPayment(accountID int, ledger Ledger, isPaid bool, timestamp int64) {
+	entryType, amount := JournalEntryPayment, ledger.GrandTotal
+	if !isPaid {
+		entryType, amount = JournalEntryPaymentReversal, -ledger.GrandTotal
+	}
+	accountLedgers.post(entryType, accountID, ledger, timestamp, map[string]int64{
+		JournalAccountCashClearing: amount,
+		JournalAccountReceivable:   -amount,
+	})
+}
+
+// postReprice posts the difference between the amounts of a transaction of
+// the account before and after it was re-priced to the journal, together
+// with the re-priced transaction that replaces it. Amounts in different
+// currencies cannot be netted, so a transaction that was re-priced in another
+// currency is reversed in its old currency and posted again in the new one
+func (accountLedgers *Accounts) postReprice(accountID int, before Ledger, after Ledger, timestamp int64) {
+	amounts := transactionAmounts(after)
+	if before.Currency == after.Currency {
+		for account, amount := range transactionAmounts(before) {
+			amounts[account] -= amount
+		}
+	} else {
+		reversal := transactionAmounts(before)
+		for account, amount := range reversal {
+			reversal[account] = -amount
+		}
+		accountLedgers.post(JournalEntryReprice, accountID, before, timestamp, reversal)
+	}
+	accountLedgers.post(JournalEntryReprice, accountID, after, timestamp, amounts)
+	accountLedgers.Journal[len(accountLedgers.Journal)-1].Transaction = journalTransaction(after)
+}
+
+// buildJournal returns the journal of ledgers that were recorded before the
+// journal was kept: every transaction is posted when it was made and every
+// paid transaction when it was paid. Ledgers without transactions have no
+// journal
+func buildJournal(accountLedgers Accounts) []JournalEntry {
+	var journal Accounts
+	for _, account := range accountLedgers.Data {
+		for _, ledger := range account.Ledgers {
+			journal.postTransaction(account.AccountID, ledger)
+			if ledger.IsPaid {
+				journal.postPayment(account.AccountID, ledger, true, ledger.paidAt())
+			}
+		}
+	}
+
+	sort.SliceStable(journal.Journal, func(i, j int) bool {
+		return journal.Journal[i].TimeStamp < journal.Journal[j].TimeStamp
+	})
+	for i := range journal.Journal {
+		journal.Journal[i].EntryID = int64(i + 1)
+	}
+	return journal.Journal
+}
+
+// attachTransactions records the ledgers of a version 4 ledger JSON file,
+// which were stored beside its journal, in the entries of their transactions.
+// Only the ledgers as they were last stored are known, so a re-priced
+// transaction is recorded as re-priced by its sale as well
+func attachTransactions(accountLedgers *Accounts) {
+	ledgers := map[transactionKey]Ledger{}
+	for _, account := range accountLedgers.Data {
+		for _, ledger := range account.Ledgers {
+			ledgers[transactionKey{account.AccountID, ledger.TransactionID}] = ledger
+		}
+	}
+	for i := range accountLedgers.Journal {
+		entry := &accountLedgers.Journal[i]
+		if entry.Type == JournalEntryPayment || entry.Type == JournalEntryPaymentReversal {
+			continue
+		}
+		if ledger, ok := ledgers[transactionKey{entry.AccountID, entry.TransactionID}]; ok {
+			entry.Transaction = journalTransaction(ledger)
+		}
+	}
+}
+
+// transactionKey identifies a transaction of an account in the journal
+type transactionKey struct {
+	accountID     int
+	transactionID int64
+}
+
+// projectLedgers replaces the ledgers of every account with their projection
+// over the journal, which is the record of the transactions: a transaction is
+// added by the entry that posts it and replaced by a reprice entry, and is
+// paid or unpaid by the last of its payment entries. The ledgers are in the
+// order their transactions were posted
+func (accountLedgers *Accounts) projectLedgers() {
+	ledgers := map[int][]Ledger{}
+	positions := map[transactionKey]int{}
+	for _, entry := range accountLedgers.Journal {
+		key := transactionKey{entry.AccountID, entry.TransactionID}
+		position, posted := positions[key]
+		switch {
+		case entry.Type == JournalEntryPayment || entry.Type == JournalEntryPaymentReversal:
+			if !posted {
+				continue
+			}
+			ledger := &ledgers[entry.AccountID][position]
+			ledger.IsPaid = entry.Type == JournalEntryPayment
+			ledger.PaidAt = 0
+			if ledger.IsPaid {
+				ledger.PaidAt = entry.TimeStamp
+			}
+			if entry.TimeStamp > ledger.UpdatedAt {
+				ledger.UpdatedAt = entry.TimeStamp
+			}
+		case entry.Transaction == nil:
+			continue
+		case posted:
+			ledger := &ledgers[entry.AccountID][position]
+			isPaid, paidAt := ledger.IsPaid, ledger.PaidAt
+			*ledger = *journalTransaction(*entry.Transaction)
+			ledger.IsPaid, ledger.PaidAt = isPaid, paidAt
+		default:
+			positions[key] = len(ledgers[entry.AccountID])
+			ledgers[entry.AccountID] = append(ledgers[entry.AccountID], *journalTransaction(*entry.Transaction))
+		}
+	}
+
+	for i := range accountLedgers.Data {
+		projected, ok := ledgers[accountLedgers.Data[i].AccountID]
+		if !ok {
+			projected = []Ledger{}
+		}
+		accountLedgers.Data[i].Ledgers = projected
+	}
+}
+
+// receivable returns the signed amount the entry posts to the receivable
+func (entry *JournalEntry) receivable() int64 {
+	var amount int64
+	for _, line := range entry.Lines {
+		if line.Account == JournalAccountReceivable {
+			amount += line.Debit - line.Credit
+		}
+	}
+	return amount
+}
+
+// accountBalances returns the receivable of the account per currency,
+// projected from the journal, ordered by currency
+func (accountLedgers *Accounts) accountBalances(accountID int) []AccountBalance {
+	byCurrency := map[string]*AccountBalance{}
+	for _, entry := range accountLedgers.Journal {
+		if entry.AccountID != accountID {
+			continue
+		}
+		balance, ok := byCurrency[entry.Currency]
+		if !ok {
+			balance = &AccountBalance{AccountID: accountID, Currency: entry.Currency}
+			byCurrency[entry.Currency] = balance
+		}
+		if entry.Type == JournalEntryPayment || entry.Type == JournalEntryPaymentReversal {
+			balance.Payments -= entry.receivable()
+		} else {
+			balance.Charges += entry.receivable()
+		}
+		balance.Receivable += entry.receivable()
+	}
+
+	balances := []AccountBalance{}
+	for _, balance := range byCurrency {
+		balances = append(balances, *balance)
+	}
+	sort.Slice(balances, func(i, j int) bool {
+		return balances[i].Currency < balances[j].Currency
+	})
+	return balances
+}
+
+// charges returns the net amount of the transactions and adjustments of the
+// account per currency, projected from the journal
+func (accountLedgers *Accounts) charges(accountID int) map[string]int64 {
+	charges := map[string]int64{}
+	for _, balance := range accountLedgers.accountBalances(accountID) {
+		charges[balance.Currency] = balance.Charges
+	}
+	return charges
+}
+
+// trialBalance returns the total debits and credits of every journal account
+// in the currency of the entries up to and including asOf
+func trialBalance(journal []JournalEntry, currency string, asOf int64) TrialBalance {
+	totals := map[string]*TrialBalanceLine{}
+	for _, entry := range journal {
+		if entry.Currency != currency || entry.TimeStamp > asOf {
+			continue
+		}
+		for _, line := range entry.Lines {
+			total, ok := totals[line.Account]
+			if !ok {
+				total = &TrialBalanceLine{Account: line.Account}
+				totals[line.Account] = total
+			}
+			total.Debit += line.Debit
+			total.Credit += line.Credit
+		}
+	}
+
+	balance := TrialBalance{Currency: currency, AsOf: asOf, Accounts: []TrialBalanceLine{}}
+	for _, account := range journalAccounts {
+		total, ok := totals[account]
+		if !ok {
+			total = &TrialBalanceLine{Account: account}
+		}
+		total.Balance = total.Debit - total.Credit
+		balance.Accounts = append(balance.Accounts, *total)
+		balance.TotalDebit += total.Debit
+		balance.TotalCredit += total.Credit
+	}
+	balance.Balanced = balance.TotalDebit == balance.TotalCredit
+	return balance
+}
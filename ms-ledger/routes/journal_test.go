@@ -0,0 +1,292 @@
+// Copyright © 2026 Intel Corporation. All rights reserved.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package routes
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strconv"
+	"testing"
+
+	"github.com/edgexfoundry/go-mod-core-contracts/v3/clients/logger"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPostReprice(t *testing.T) {
+	before := Ledger{TransactionID: 1, Subtotal: 199, TaxTotal: 14, GrandTotal: 213, Currency: "USD"}
+	after := Ledger{TransactionID: 1, Subtotal: 159, TaxTotal: 12, GrandTotal: 171, Currency: "USD"}
+
+	var accountLedgers Accounts
+	accountLedgers.postTransaction(1, before)
+	accountLedgers.postReprice(1, before, after, 5)
+
+	require.Len(t, accountLedgers.Journal, 2)
+	assert.Equal(t, JournalEntry{
+		EntryID: 2, Type: JournalEntryReprice, AccountID: 1, TransactionID: 1, TimeStamp: 5, Currency: "USD",
+		Lines:       []JournalLine{{Account: JournalAccountReceivable, Credit: 42}, {Account: JournalAccountRevenue, Debit: 40}, {Account: JournalAccountTaxPayable, Debit: 2}},
+		Transaction: &after,
+	}, accountLedgers.Journal[1])
+	assert.Equal(t, []AccountBalance{{AccountID: 1, Currency: "USD", Charges: 171, Receivable: 171}}, accountLedgers.accountBalances(1))
+}
+
+// TestPostRepriceCurrencyChange tests that a transaction re-priced in another
+// currency is reversed in its old currency and posted in the new one
+func TestPostRepriceCurrencyChange(t *testing.T) {
+	before := Ledger{TransactionID: 1, Subtotal: 199, TaxTotal: 14, GrandTotal: 213, Currency: "USD"}
+	after := Ledger{TransactionID: 1, Subtotal: 189, TaxTotal: 36, GrandTotal: 225, Currency: "EUR"}
+
+	var accountLedgers Accounts
+	accountLedgers.postTransaction(1, before)
+	accountLedgers.postReprice(1, before, after, 5)
+
+	require.Len(t, accountLedgers.Journal, 3)
+	assert.Equal(t, JournalEntry{
+		EntryID: 2, Type: JournalEntryReprice, AccountID: 1, TransactionID: 1, TimeStamp: 5, Currency: "USD",
+		Lines: []JournalLine{{Account: JournalAccountReceivable, Credit: 213}, {Account: JournalAccountRevenue, Debit: 199}, {Account: JournalAccountTaxPayable, Debit: 14}},
+	}, accountLedgers.Journal[1])
+	assert.Equal(t, JournalEntry{
+		EntryID: 3, Type: JournalEntryReprice, AccountID: 1, TransactionID: 1, TimeStamp: 5, Currency: "EUR",
+		Lines:       []JournalLine{{Account: JournalAccountReceivable, Debit: 225}, {Account: JournalAccountRevenue, Credit: 189}, {Account: JournalAccountTaxPayable, Credit: 36}},
+		Transaction: &after,
+	}, accountLedgers.Journal[2])
+	assert.Equal(t, []AccountBalance{
+		{AccountID: 1, Currency: "EUR", Charges: 225, Receivable: 225},
+		{AccountID: 1, Currency: "USD", Charges: 0, Receivable: 0},
+	}, accountLedgers.accountBalances(1))
+	for _, currency := range []string{"EUR", "USD"} {
+		assert.True(t, trialBalance(accountLedgers.Journal, currency, 5).Balanced, currency)
+	}
+
+	accountLedgers.Data = []Account{{AccountID: 1}}
+	accountLedgers.projectLedgers()
+	assert.Equal(t, []Ledger{after}, accountLedgers.Data[0].Ledgers)
+}
+
+// TestProjectLedgers tests that the ledgers of the accounts are projected
+// from the journal, whatever ledgers the accounts had
+func TestProjectLedgers(t *testing.T) {
+	sale := Ledger{TransactionID: 1, TxTimeStamp: 1, Subtotal: 199, TaxTotal: 14, GrandTotal: 213, Currency: "USD", UpdatedAt: 1, PriceUnverified: true}
+	repriced := sale
+	repriced.Subtotal, repriced.TaxTotal, repriced.GrandTotal = 159, 12, 171
+	repriced.PriceUnverified, repriced.RepricedAt, repriced.UpdatedAt = false, 3, 3
+	refund := Ledger{TransactionID: 2, Type: TransactionTypeRefund, AdjustsTransactionID: 1, TxTimeStamp: 5, Subtotal: -159, TaxTotal: -12, GrandTotal: -171, Currency: "USD", UpdatedAt: 5}
+
+	accountLedgers := Accounts{Data: []Account{{AccountID: 1, Ledgers: []Ledger{{TransactionID: 9}}}, {AccountID: 2}}}
+	accountLedgers.postTransaction(1, sale)
+	accountLedgers.postPayment(1, sale, true, 2)
+	accountLedgers.postPayment(1, sale, false, 2)
+	accountLedgers.postReprice(1, sale, repriced, 3)
+	accountLedgers.postPayment(1, repriced, true, 4)
+	accountLedgers.postTransaction(1, refund)
+	accountLedgers.projectLedgers()
+
+	paid := repriced
+	paid.IsPaid, paid.PaidAt, paid.UpdatedAt = true, 4, 4
+	assert.Equal(t, []Ledger{paid, refund}, accountLedgers.Data[0].Ledgers)
+	assert.Equal(t, []Ledger{}, accountLedgers.Data[1].Ledgers)
+}
+
+// TestJournalPostings tests that a sale, its payment, the reversal of the
+// payment and a refund post balanced entries, and that the balances of the
+// accounts projected from the journal match their ledgers
+func TestJournalPostings(t *testing.T) {
+	pricingServer := newPricingTestServer(t)
+	defer pricingServer.Close()
+
+	c := Controller{
+		lc:              logger.NewMockClient(),
+		pricingEndpoint: pricingServer.URL,
+		ledgerFileName:  LedgerFileName,
+		taxJurisdiction: "US-CA",
+		taxRules:        []taxRule{{"US-CA", anyTaxCategory, 7.25}},
+	}
+	data, err := json.Marshal(getDefaultAccountLedgers())
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(c.ledgerFileName, data, 0644))
+	defer func() {
+		os.Remove(c.ledgerFileName)
+	}()
+
+	w := httptest.NewRecorder()
+	c.LedgerAddTransaction(w, httptest.NewRequest("POST", "http://localhost:48093/ledger", bytes.NewBuffer([]byte(`{"accountId":2,"deltaSKUs":[{"sku":"4900002470","delta":-1}]}`))))
+	require.Equal(t, http.StatusOK, w.Code)
+	var sale Ledger
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&sale))
+	require.Equal(t, int64(171), sale.GrandTotal)
+	tid := strconv.FormatInt(sale.TransactionID, 10)
+
+	for _, isPaid := range []string{"true", "false", "true", "true"} {
+		w = httptest.NewRecorder()
+		c.SetPaymentStatus(w, httptest.NewRequest("POST", "http://localhost:48093/ledgerPaymentUpdate", bytes.NewBuffer([]byte(`{"accountID":2,"transactionID":"`+tid+`","isPaid":`+isPaid+`}`))))
+		require.Equal(t, http.StatusOK, w.Code)
+	}
+
+	req := httptest.NewRequest("POST", "http://localhost:48093/ledger/2/"+tid+"/adjustment", bytes.NewBuffer([]byte(`{"type":"refund","reasonCode":"damagedProduct","operator":"jdoe"}`)))
+	req = mux.SetURLVars(req, map[string]string{"accountid": "2", "tid": tid})
+	w = httptest.NewRecorder()
+	c.LedgerAdjustmentPost(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	accountLedgers, err := c.GetAllLedgers()
+	require.NoError(t, err)
+	var types []string
+	for _, entry := range accountLedgers.Journal[2:] {
+		types = append(types, entry.Type)
+	}
+	assert.Equal(t, []string{JournalEntrySale, JournalEntryPayment, JournalEntryPaymentReversal, JournalEntryPayment, TransactionTypeRefund}, types, "paying a paid transaction again should not post")
+
+	assert.Equal(t, []AccountBalance{{AccountID: 2, Currency: "USD", Charges: 299, Payments: 171, Receivable: 128}}, accountLedgers.accountBalances(2))
+	for _, account := range accountLedgers.Data {
+		assert.Equal(t, netCharges(account), accountLedgers.charges(account.AccountID))
+	}
+
+	balance := trialBalance(accountLedgers.Journal, "USD", sale.TxTimeStamp*2)
+	assert.True(t, balance.Balanced)
+	assert.Equal(t, []TrialBalanceLine{
+		{Account: JournalAccountReceivable, Debit: 199 + 299 + 171 + 171, Credit: 171 + 171 + 171, Balance: 327},
+		{Account: JournalAccountCashClearing, Debit: 171 + 171, Credit: 171, Balance: 171},
+		{Account: JournalAccountRevenue, Credit: 199 + 299 + 159, Balance: -657},
+		{Account: JournalAccountRefunds, Debit: 159, Balance: 159},
+		{Account: JournalAccountTaxPayable, Debit: 12, Credit: 12},
+	}, balance.Accounts)
+}
+
+func TestTrialBalanceGet(t *testing.T) {
+	tests := []struct {
+		Name               string
+		Query              string
+		ExpectedStatusCode int
+		ExpectedDebit      int64
+	}{
+		{"Now", "", http.StatusOK, 199},
+		{"As of", "?asOf=2579215712984890363", http.StatusOK, 199 + 299},
+		{"Before first sale", "?asOf=1579215712984890362", http.StatusOK, 0},
+		{"Other currency", "?asOf=2579215712984890363&currency=EUR", http.StatusOK, 0},
+		{"Invalid asOf", "?asOf=tomorrow", http.StatusBadRequest, 0},
+		{"Invalid currency", "?currency=dollars", http.StatusBadRequest, 0},
+	}
+
+	for _, test := range tests {
+		currentTest := test
+		t.Run(currentTest.Name, func(t *testing.T) {
+			c := Controller{
+				lc:             logger.NewMockClient(),
+				ledgerFileName: LedgerFileName,
+			}
+			data, err := json.Marshal(getDefaultAccountLedgers())
+			require.NoError(t, err)
+			require.NoError(t, os.WriteFile(c.ledgerFileName, data, 0644))
+			defer func() {
+				os.Remove(c.ledgerFileName)
+			}()
+
+			w := httptest.NewRecorder()
+			c.TrialBalanceGet(w, httptest.NewRequest("GET", "http://localhost:48093/ledgerTrialBalance"+currentTest.Query, nil))
+			resp := w.Result()
+			defer resp.Body.Close()
+
+			require.Equal(t, currentTest.ExpectedStatusCode, resp.StatusCode, "invalid status code")
+			if resp.StatusCode != http.StatusOK {
+				return
+			}
+			var balance TrialBalance
+			require.NoError(t, json.NewDecoder(resp.Body).Decode(&balance))
+			assert.True(t, balance.Balanced)
+			assert.Equal(t, currentTest.ExpectedDebit, balance.TotalDebit)
+		})
+	}
+}
+
+func TestLedgerBalanceGet(t *testing.T) {
+	tests := []struct {
+		Name               string
+		AccountID          string
+		ExpectedStatusCode int
+		ExpectedBalances   []AccountBalance
+	}{
+		{"Valid AccountID", "1", http.StatusOK, []AccountBalance{{AccountID: 1, Currency: "USD", Charges: 199, Receivable: 199}}},
+		{"Invalid AccountID", "one", http.StatusBadRequest, nil},
+		{"Nonexistent AccountID", "10", http.StatusBadRequest, nil},
+	}
+
+	for _, test := range tests {
+		currentTest := test
+		t.Run(currentTest.Name, func(t *testing.T) {
+			c := Controller{
+				lc:             logger.NewMockClient(),
+				ledgerFileName: LedgerFileName,
+			}
+			data, err := json.Marshal(getDefaultAccountLedgers())
+			require.NoError(t, err)
+			require.NoError(t, os.WriteFile(c.ledgerFileName, data, 0644))
+			defer func() {
+				os.Remove(c.ledgerFileName)
+			}()
+
+			req := httptest.NewRequest("GET", "http://localhost:48093/ledger/"+currentTest.AccountID+"/balance", nil)
+			req = mux.SetURLVars(req, map[string]string{"accountid": currentTest.AccountID})
+			w := httptest.NewRecorder()
+			c.LedgerBalanceGet(w, req)
+			resp := w.Result()
+			defer resp.Body.Close()
+
+			require.Equal(t, currentTest.ExpectedStatusCode, resp.StatusCode, "invalid status code")
+			if resp.StatusCode != http.StatusOK {
+				return
+			}
+			var balances []AccountBalance
+			require.NoError(t, json.NewDecoder(resp.Body).Decode(&balances))
+			assert.Equal(t, currentTest.ExpectedBalances, balances)
+		})
+	}
+}
+
+func TestJournalGet(t *testing.T) {
+	tests := []struct {
+		Name               string
+		Query              string
+		ExpectedStatusCode int
+		ExpectedEntries    int
+	}{
+		{"All entries up to now", "", http.StatusOK, 1},
+		{"Period", "?from=1579215712984890363&to=2579215712984890364", http.StatusOK, 2},
+		{"Account", "?from=1579215712984890363&to=2579215712984890364&accountid=2", http.StatusOK, 1},
+		{"Invalid AccountID", "?accountid=two", http.StatusBadRequest, 0},
+		{"Invalid from", "?from=yesterday", http.StatusBadRequest, 0},
+	}
+
+	for _, test := range tests {
+		currentTest := test
+		t.Run(currentTest.Name, func(t *testing.T) {
+			c := Controller{
+				lc:             logger.NewMockClient(),
+				ledgerFileName: LedgerFileName,
+			}
+			data, err := json.Marshal(getDefaultAccountLedgers())
+			require.NoError(t, err)
+			require.NoError(t, os.WriteFile(c.ledgerFileName, data, 0644))
+			defer func() {
+				os.Remove(c.ledgerFileName)
+			}()
+
+			w := httptest.NewRecorder()
+			c.JournalGet(w, httptest.NewRequest("GET", "http://localhost:48093/ledgerJournal"+currentTest.Query, nil))
+			resp := w.Result()
+			defer resp.Body.Close()
+
+			require.Equal(t, currentTest.ExpectedStatusCode, resp.StatusCode, "invalid status code")
+			if resp.StatusCode != http.StatusOK {
+				return
+			}
+			var entries []JournalEntry
+			require.NoError(t, json.NewDecoder(resp.Body).Decode(&entries))
+			assert.Len(t, entries, currentTest.ExpectedEntries)
+		})
+	}
+}
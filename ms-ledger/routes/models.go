@@ -3,42 +3,300 @@
 
 package routes
 
+// Accounts is the schema of the ledger JSON file. SchemaVersion is the
+// version of its format, see ledgerSchemaVersion. Journal is the double-entry
+// journal that every transaction, payment and adjustment of Data posts to,
+// and the ledgers of Data are projected from it
 type Accounts struct {
-	Data []Account `json:"data"`
+	SchemaVersion int            `json:"schemaVersion"`
+	Data          []Account      `json:"data"`
+	Journal       []JournalEntry `json:"journal,omitempty"`
 }
 
+// Ledger is a single transaction of an account. Every amount is in minor
+// units of Currency, such as cents for USD. LineTotal and Subtotal are the
+// sum of the line totals before tax, and GrandTotal includes TaxTotal.
+// A sale has no Type. Refunds and voids are adjustments of the sale with
+// AdjustsTransactionID, and have negative amounts and item counts. PaidAt is
+// when the transaction was last marked as paid, and IdempotencyKey the key
+// the transaction was posted with, if any. A transaction that was priced at
+// the last known prices while the inventory service was unavailable is
+// PriceUnverified until it is re-priced at RepricedAt
 type Ledger struct {
-	TransactionID int64      `json:"transactionID,string"`
-	TxTimeStamp   int64      `json:"txTimeStamp,string"`
-	LineTotal     float64    `json:"lineTotal"`
-	CreatedAt     int64      `json:"createdAt,string"`
-	UpdatedAt     int64      `json:"updatedAt,string"`
-	IsPaid        bool       `json:"isPaid"`
-	LineItems     []LineItem `json:"lineItems"`
+	TransactionID        int64      `json:"transactionID,string"`
+	Type                 string     `json:"type,omitempty"`
+	AdjustsTransactionID int64      `json:"adjustsTransactionID,string,omitempty"`
+	ReasonCode           string     `json:"reasonCode,omitempty"`
+	Operator             string     `json:"operator,omitempty"`
+	Note                 string     `json:"note,omitempty"`
+	TxTimeStamp          int64      `json:"txTimeStamp,string"`
+	LineTotal            int64      `json:"lineTotal"`
+	Subtotal             int64      `json:"subtotal"`
+	TaxTotal             int64      `json:"taxTotal"`
+	GrandTotal           int64      `json:"grandTotal"`
+	Currency             string     `json:"currency"`
+	TaxJurisdiction      string     `json:"taxJurisdiction,omitempty"`
+	CreatedAt            int64      `json:"createdAt,string"`
+	UpdatedAt            int64      `json:"updatedAt,string"`
+	IsPaid               bool       `json:"isPaid"`
+	PaidAt               int64      `json:"paidAt,string,omitempty"`
+	IdempotencyKey       string     `json:"idempotencyKey,omitempty"`
+	PriceUnverified      bool       `json:"priceUnverified,omitempty"`
+	RepricedAt           int64      `json:"repricedAt,string,omitempty"`
+	LineItems            []LineItem `json:"lineItems"`
 }
 
+// LineItem is all units of a single SKU in a transaction. ItemPrice,
+// Discount, LineTotal and Tax are in minor units of Currency, and TaxRate is
+// the percentage of LineTotal charged as Tax
 type LineItem struct {
-	SKU         string  `json:"sku"`
-	ProductName string  `json:"productName"`
-	ItemPrice   float64 `json:"itemPrice"`
-	ItemCount   int     `json:"itemCount"`
+	SKU          string   `json:"sku"`
+	ProductName  string   `json:"productName"`
+	ItemPrice    int64    `json:"itemPrice"`
+	ItemCount    int      `json:"itemCount"`
+	Discount     int64    `json:"discount,omitempty"`
+	LineTotal    int64    `json:"lineTotal"`
+	Currency     string   `json:"currency"`
+	TaxCategory  string   `json:"taxCategory,omitempty"`
+	TaxRate      float64  `json:"taxRate"`
+	Tax          int64    `json:"tax"`
+	AppliedRules []string `json:"appliedRules,omitempty"`
 }
 
+// Account is the ledger of a single account. Ledgers are its transactions,
+// projected from the journal when the ledger JSON file is read, and are not
+// stored. Balances is the net amount of its transactions and adjustments per
+// currency, paid or not, which is only filled in when an account is returned
+// by the API. Disputes are the disputes of its transactions, open or resolved
 type Account struct {
-	AccountID int      `json:"accountID"`
-	Ledgers   []Ledger `json:"ledgers"`
+	AccountID int              `json:"accountID"`
+	Ledgers   []Ledger         `json:"ledgers"`
+	Balances  map[string]int64 `json:"balances,omitempty"`
+	Disputes  []Dispute        `json:"disputes,omitempty"`
 }
 
-type Product struct {
-	SKU                string  `json:"sku"`
-	ItemPrice          float64 `json:"itemPrice"`
-	ProductName        string  `json:"productName"`
-	UnitsOnHand        int     `json:"unitsOnHand"`
-	MaxRestockingLevel int     `json:"maxRestockingLevel"`
-	MinRestockingLevel int     `json:"minRestockingLevel"`
-	CreatedAt          int64   `json:"createdAt,string"`
-	UpdatedAt          int64   `json:"updatedAt,string"`
-	IsActive           bool    `json:"isActive"`
+// Dispute is a customer's claim that a transaction, or the units of a single
+// SKU of it when SKU is set, was charged in error. A dispute is open until a
+// reviewer resolves it as upheld or rejected. An upheld dispute is refunded
+// by the adjustment with RefundTransactionID
+type Dispute struct {
+	DisputeID           int64           `json:"disputeID,string"`
+	TransactionID       int64           `json:"transactionID,string"`
+	SKU                 string          `json:"sku,omitempty"`
+	ItemCount           int             `json:"itemCount,omitempty"`
+	Statement           string          `json:"statement"`
+	Evidence            DisputeEvidence `json:"evidence"`
+	Operator            string          `json:"operator,omitempty"`
+	Status              string          `json:"status"`
+	OpenedAt            int64           `json:"openedAt,string"`
+	Reviewer            string          `json:"reviewer,omitempty"`
+	ResolutionNote      string          `json:"resolutionNote,omitempty"`
+	ResolvedAt          int64           `json:"resolvedAt,string,omitempty"`
+	RefundTransactionID int64           `json:"refundTransactionID,string,omitempty"`
+}
+
+// DisputeEvidence references the computer vision evidence of the vending
+// session of a disputed transaction: the inference images taken before and
+// after the door was opened, and the entry of the inventory audit log. They
+// are opaque references for the reviewer that are stored as given: the
+// images are kept outside of this service, and the audit log entry may have
+// been archived by the inventory service by the time the sale is disputed,
+// so neither is looked up
+type DisputeEvidence struct {
+	BeforeImage  string `json:"beforeImage,omitempty"`
+	AfterImage   string `json:"afterImage,omitempty"`
+	AuditEntryID string `json:"auditEntryId,omitempty"`
+}
+
+// JournalEntry is a balanced posting of a transaction of AccountID to the
+// journal accounts: the debits of its lines equal their credits. Every amount
+// is in minor units of Currency. The entry that posts a transaction, and the
+// entry of every re-price, records the Transaction as it then was; payment
+// entries only refer to it
+type JournalEntry struct {
+	EntryID       int64         `json:"entryID,string"`
+	Type          string        `json:"type"`
+	AccountID     int           `json:"accountID"`
+	TransactionID int64         `json:"transactionID,string"`
+	TimeStamp     int64         `json:"timeStamp,string"`
+	Currency      string        `json:"currency"`
+	Lines         []JournalLine `json:"lines"`
+	Transaction   *Ledger       `json:"transaction,omitempty"`
+}
+
+// JournalLine is the debit or credit of a journal entry to a journal account
+type JournalLine struct {
+	Account string `json:"account"`
+	Debit   int64  `json:"debit"`
+	Credit  int64  `json:"credit"`
+}
+
+// TrialBalance is the total debits and credits of every journal account in
+// Currency up to AsOf. Balance is the debits minus the credits of an account,
+// and the journal is Balanced when the total debits equal the total credits
+type TrialBalance struct {
+	Currency    string             `json:"currency"`
+	AsOf        int64              `json:"asOf,string"`
+	Accounts    []TrialBalanceLine `json:"accounts"`
+	TotalDebit  int64              `json:"totalDebit"`
+	TotalCredit int64              `json:"totalCredit"`
+	Balanced    bool               `json:"balanced"`
+}
+
+// TrialBalanceLine is the total debits and credits of a journal account
+type TrialBalanceLine struct {
+	Account string `json:"account"`
+	Debit   int64  `json:"debit"`
+	Credit  int64  `json:"credit"`
+	Balance int64  `json:"balance"`
+}
+
+// AccountBalance is the receivable of an account in Currency, projected from
+// the journal. Charges is the net amount of its transactions and adjustments,
+// Payments the amount it paid, and Receivable the amount it still owes
+type AccountBalance struct {
+	AccountID  int    `json:"accountID"`
+	Currency   string `json:"currency"`
+	Charges    int64  `json:"charges"`
+	Payments   int64  `json:"payments"`
+	Receivable int64  `json:"receivable"`
+}
+
+// Statement is the activity of an account in a single currency between From
+// and To. OpeningBalance and ClosingBalance are the amounts owed by the
+// account at From and To: every entry's Amount is what it adds to the
+// balance, so payments are negative
+type Statement struct {
+	AccountID      int              `json:"accountID"`
+	Currency       string           `json:"currency"`
+	From           int64            `json:"from,string"`
+	To             int64            `json:"to,string"`
+	OpeningBalance int64            `json:"openingBalance"`
+	Transactions   []StatementEntry `json:"transactions"`
+	Payments       []StatementEntry `json:"payments"`
+	Adjustments    []StatementEntry `json:"adjustments"`
+	ClosingBalance int64            `json:"closingBalance"`
+	GeneratedAt    int64            `json:"generatedAt,string"`
+}
+
+// StatementEntry is a sale, payment, refund or void on a statement
+type StatementEntry struct {
+	TransactionID        int64  `json:"transactionID,string"`
+	Type                 string `json:"type"`
+	AdjustsTransactionID int64  `json:"adjustsTransactionID,string,omitempty"`
+	ReasonCode           string `json:"reasonCode,omitempty"`
+	TimeStamp            int64  `json:"timeStamp,string"`
+	Amount               int64  `json:"amount"`
+}
+
+// StoredStatement is a monthly statement that is stored for download
+type StoredStatement struct {
+	Period   string `json:"period"`
+	Currency string `json:"currency"`
+}
+
+// adjustmentRequest is the body of a refund or void of a transaction. A
+// refund without LineItems refunds every unit that was not refunded yet
+type adjustmentRequest struct {
+	Type       string               `json:"type"`
+	ReasonCode string               `json:"reasonCode"`
+	Operator   string               `json:"operator"`
+	Note       string               `json:"note,omitempty"`
+	LineItems  []adjustmentLineItem `json:"lineItems,omitempty"`
+}
+
+// adjustmentLineItem is the number of units of a SKU to refund
+type adjustmentLineItem struct {
+	SKU       string `json:"sku"`
+	ItemCount int    `json:"itemCount"`
+}
+
+// disputeRequest is the body of a new dispute. A dispute without SKU
+// disputes the whole transaction, and one without ItemCount every unit of
+// the SKU
+type disputeRequest struct {
+	SKU       string          `json:"sku,omitempty"`
+	ItemCount int             `json:"itemCount,omitempty"`
+	Statement string          `json:"statement"`
+	Evidence  DisputeEvidence `json:"evidence"`
+	Operator  string          `json:"operator,omitempty"`
+}
+
+// disputeResolution is the body of the resolution of a dispute by a reviewer
+type disputeResolution struct {
+	Status   string `json:"status"`
+	Reviewer string `json:"reviewer"`
+	Note     string `json:"note,omitempty"`
+}
+
+// authAccount is the status of an account in the authentication service
+type authAccount struct {
+	AccountID int  `json:"accountID"`
+	IsActive  bool `json:"isActive"`
+}
+
+// AccountReconciliation compares the ledger accounts with the accounts of the
+// authentication service. MissingAccounts are active accounts without a
+// ledger account, OrphanedAccounts are ledger accounts that the
+// authentication service does not know and InactiveAccounts are ledger
+// accounts of inactive accounts
+type AccountReconciliation struct {
+	MissingAccounts  []int `json:"missingAccounts"`
+	OrphanedAccounts []int `json:"orphanedAccounts"`
+	InactiveAccounts []int `json:"inactiveAccounts"`
+}
+
+// SalesReport is the sales of every account in Currency between From and To.
+// Revenue is in minor units of the currency, before tax and net of discounts
+// and adjustments. Transactions is the number of sales that were not voided,
+// and the basket averages are taken over them. TopProducts are the SKUs with
+// the highest revenue
+type SalesReport struct {
+	From               int64         `json:"from,string"`
+	To                 int64         `json:"to,string"`
+	Currency           string        `json:"currency"`
+	Revenue            int64         `json:"revenue"`
+	Units              int           `json:"units"`
+	Transactions       int           `json:"transactions"`
+	AverageBasketUnits float64       `json:"averageBasketUnits"`
+	AverageBasketValue int64         `json:"averageBasketValue"`
+	BySKU              []SalesBucket `json:"bySKU"`
+	ByHour             []SalesBucket `json:"byHour"`
+	ByDay              []SalesBucket `json:"byDay"`
+	ByAccount          []SalesBucket `json:"byAccount"`
+	TopProducts        []SalesBucket `json:"topProducts"`
+	GeneratedAt        int64         `json:"generatedAt,string"`
+}
+
+// SalesBucket is the revenue, units and number of sales of a SKU, an hour of
+// the day in UTC such as 09, a UTC day such as 2026-09-01, or an account
+type SalesBucket struct {
+	Key          string `json:"key"`
+	ProductName  string `json:"productName,omitempty"`
+	Revenue      int64  `json:"revenue"`
+	Units        int    `json:"units"`
+	Transactions int    `json:"transactions"`
+}
+
+// pricedBasket is the response of the inventory service's basket pricing
+// endpoint. Its amounts are in whole minor units of Currency, such as cents,
+// like the amounts of the ledgers
+type pricedBasket struct {
+	LineItems []pricedLineItem `json:"lineItems"`
+	Total     int64            `json:"total"`
+	Currency  string           `json:"currency"`
+	PricedAt  int64            `json:"pricedAt,string"`
+}
+
+type pricedLineItem struct {
+	SKU          string   `json:"sku"`
+	ProductName  string   `json:"productName"`
+	ItemPrice    int64    `json:"itemPrice"`
+	ItemCount    int      `json:"itemCount"`
+	Discount     int64    `json:"discount"`
+	LineTotal    int64    `json:"lineTotal"`
+	TaxCategory  string   `json:"taxCategory,omitempty"`
+	AppliedRules []string `json:"appliedRules,omitempty"`
 }
 
 type paymentInfo struct {
@@ -47,9 +305,12 @@ type paymentInfo struct {
 	IsPaid        bool  `json:"isPaid"`
 }
 
+// deltaLedger is the body of a new transaction. IdempotencyKey is used when
+// the request has no Idempotency-Key header
 type deltaLedger struct {
-	AccountID int        `json:"accountId"`
-	DeltaSKUs []deltaSKU `json:"deltaSKUs"`
+	AccountID      int        `json:"accountId"`
+	DeltaSKUs      []deltaSKU `json:"deltaSKUs"`
+	IdempotencyKey string     `json:"idempotencyKey,omitempty"`
 }
 
 type deltaSKU struct {
@@ -0,0 +1,180 @@
+// Copyright © 2026 Intel Corporation. All rights reserved.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package routes
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+
+	"golang.org/x/text/currency"
+)
+
+const (
+	// ledgerSchemaVersion is the version of the ledger JSON format. Version
+	// 1 ledger files, which have no schemaVersion, hold amounts as floating
+	// point numbers in major units. Version 2 holds them as integer minor
+	// units of the ledger's currency. Version 3 adds the tax of every line
+	// item and the subtotal, tax total and grand total of every ledger.
+	// Version 4 adds the double-entry journal. Version 5 records every
+	// transaction in its journal entries and no longer stores the ledgers
+	ledgerSchemaVersion = 5
+	// defaultCurrency is the ISO 4217 currency of amounts that do not have one
+	defaultCurrency = "USD"
+)
+
+// minorUnitDigits returns the number of decimal places of the minor unit of
+// an ISO 4217 currency in the CLDR currency data, such as 0 for JPY, and
+// false for a code that is not an upper case ISO 4217 currency
+func minorUnitDigits(code string) (int, bool) {
+	unit, err := currency.ParseISO(code)
+	if err != nil || unit.String() != code {
+		return 0, false
+	}
+	digits, _ := currency.Standard.Rounding(unit)
+	return digits, true
+}
+
+// validCurrency returns true if ledgers can be kept in the currency
+func validCurrency(code string) bool {
+	_, ok := minorUnitDigits(code)
+	return ok
+}
+
+// toMinorUnits converts an amount in major units of the currency, such as
+// dollars, into whole minor units, such as cents
+func toMinorUnits(amount float64, currency string) (int64, error) {
+	digits, ok := minorUnitDigits(currency)
+	if !ok {
+		return 0, fmt.Errorf("unsupported currency %q", currency)
+	}
+	return int64(math.Round(amount * math.Pow10(digits))), nil
+}
+
+// formatMinorUnits formats an amount in minor units of the currency as a
+// decimal number in major units, such as "-7.96" for -796 USD cents
+func formatMinorUnits(amount int64, currency string) string {
+	digits, _ := minorUnitDigits(currency)
+	sign := ""
+	if amount < 0 {
+		sign = "-"
+		amount = -amount
+	}
+	if digits == 0 {
+		return fmt.Sprintf("%s%d", sign, amount)
+	}
+	scale := int64(math.Pow10(digits))
+	return fmt.Sprintf("%s%d.%0*d", sign, amount/scale, digits, amount%scale)
+}
+
+// legacyAccounts is the version 1 ledger JSON format
+type legacyAccounts struct {
+	Data []struct {
+		AccountID int `json:"accountID"`
+		Ledgers   []struct {
+			TransactionID int64   `json:"transactionID,string"`
+			TxTimeStamp   int64   `json:"txTimeStamp,string"`
+			LineTotal     float64 `json:"lineTotal"`
+			CreatedAt     int64   `json:"createdAt,string"`
+			UpdatedAt     int64   `json:"updatedAt,string"`
+			IsPaid        bool    `json:"isPaid"`
+			LineItems     []struct {
+				SKU          string   `json:"sku"`
+				ProductName  string   `json:"productName"`
+				ItemPrice    float64  `json:"itemPrice"`
+				ItemCount    int      `json:"itemCount"`
+				Discount     float64  `json:"discount,omitempty"`
+				AppliedRules []string `json:"appliedRules,omitempty"`
+			} `json:"lineItems"`
+		} `json:"ledgers"`
+	} `json:"data"`
+}
+
+// migrateLedgers converts a ledger JSON file of an older schema version into
+// the current format. Version 1 amounts have no currency, so they are taken
+// to be in the given one. Ledgers from before version 3 were not taxed, and
+// the journal of ledgers from before version 4 is built from their ledgers.
+// The ledgers of version 4 are recorded in the entries of its journal
+func migrateLedgers(data []byte, schemaVersion int, currency string) (Accounts, error) {
+	var accountLedgers Accounts
+	if schemaVersion < 2 {
+		var err error
+		if accountLedgers, err = migrateLegacyLedgers(data, currency); err != nil {
+			return Accounts{}, err
+		}
+	} else if err := json.Unmarshal(data, &accountLedgers); err != nil {
+		return Accounts{}, fmt.Errorf("failed to unmarshal version %d ledger JSON file: %s", schemaVersion, err.Error())
+	}
+
+	if schemaVersion < 3 {
+		for accountIndex := range accountLedgers.Data {
+			for ledgerIndex := range accountLedgers.Data[accountIndex].Ledgers {
+				ledger := &accountLedgers.Data[accountIndex].Ledgers[ledgerIndex]
+				ledger.Subtotal = ledger.LineTotal
+				ledger.TaxTotal = 0
+				ledger.GrandTotal = ledger.LineTotal
+			}
+		}
+	}
+	if schemaVersion < 4 {
+		accountLedgers.Journal = buildJournal(accountLedgers)
+	} else {
+		attachTransactions(&accountLedgers)
+	}
+	accountLedgers.SchemaVersion = ledgerSchemaVersion
+	return accountLedgers, nil
+}
+
+// migrateLegacyLedgers converts a version 1 ledger JSON file, with amounts as
+// floating point numbers, into minor units of the currency
+func migrateLegacyLedgers(data []byte, currency string) (Accounts, error) {
+	var legacy legacyAccounts
+	if err := json.Unmarshal(data, &legacy); err != nil {
+		return Accounts{}, fmt.Errorf("failed to unmarshal version 1 ledger JSON file: %s", err.Error())
+	}
+
+	accountLedgers := Accounts{SchemaVersion: ledgerSchemaVersion, Data: []Account{}}
+	for _, legacyAccount := range legacy.Data {
+		account := Account{AccountID: legacyAccount.AccountID, Ledgers: []Ledger{}}
+		for _, legacyLedger := range legacyAccount.Ledgers {
+			lineTotal, err := toMinorUnits(legacyLedger.LineTotal, currency)
+			if err != nil {
+				return Accounts{}, err
+			}
+			ledger := Ledger{
+				TransactionID: legacyLedger.TransactionID,
+				TxTimeStamp:   legacyLedger.TxTimeStamp,
+				LineTotal:     lineTotal,
+				Currency:      currency,
+				CreatedAt:     legacyLedger.CreatedAt,
+				UpdatedAt:     legacyLedger.UpdatedAt,
+				IsPaid:        legacyLedger.IsPaid,
+				LineItems:     []LineItem{},
+			}
+			for _, legacyItem := range legacyLedger.LineItems {
+				itemPrice, err := toMinorUnits(legacyItem.ItemPrice, currency)
+				if err != nil {
+					return Accounts{}, err
+				}
+				discount, err := toMinorUnits(legacyItem.Discount, currency)
+				if err != nil {
+					return Accounts{}, err
+				}
+				ledger.LineItems = append(ledger.LineItems, LineItem{
+					SKU:          legacyItem.SKU,
+					ProductName:  legacyItem.ProductName,
+					ItemPrice:    itemPrice,
+					ItemCount:    legacyItem.ItemCount,
+					Discount:     discount,
+					LineTotal:    itemPrice*int64(legacyItem.ItemCount) - discount,
+					Currency:     currency,
+					AppliedRules: legacyItem.AppliedRules,
+				})
+			}
+			account.Ledgers = append(account.Ledgers, ledger)
+		}
+		accountLedgers.Data = append(accountLedgers.Data, account)
+	}
+	return accountLedgers, nil
+}
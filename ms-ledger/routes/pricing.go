@@ -0,0 +1,325 @@
+// Copyright © 2026 Intel Corporation. All rights reserved.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package routes
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/url"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// errNoKnownPrice is returned when a transaction cannot be priced in degraded
+// mode because a SKU was never priced before
+var errNoKnownPrice = errors.New("no known price")
+
+// cachedBasket is a priced basket that is reused for identical baskets until
+// it expires
+type cachedBasket struct {
+	basket  pricedBasket
+	expires int64
+}
+
+// knownPrice is the last price of a SKU, in minor units of its currency
+type knownPrice struct {
+	productName string
+	itemPrice   int64
+	currency    string
+	taxCategory string
+}
+
+// basketCacheKey returns the key of a basket in the price cache. Baskets with
+// the same deltas in any order that are priced in the same minute share a
+// key. The daily windows of pricing rules start and end on whole minutes, so
+// a basket is never reused once a rule may have started or ended
+func basketCacheKey(deltaSKUs []deltaSKU, pricedAt int64) string {
+	parts := make([]string, 0, len(deltaSKUs))
+	for _, delta := range deltaSKUs {
+		parts = append(parts, delta.SKU+":"+strconv.Itoa(delta.Delta))
+	}
+	sort.Strings(parts)
+	return strconv.FormatInt(pricedAt/time.Minute.Nanoseconds(), 10) + "@" + strings.Join(parts, ",")
+}
+
+// priceBasket returns the basket of the deltas priced at now, from the price
+// cache when an identical basket was priced in the same minute within the
+// PriceCacheTTL before now
+func (c *Controller) priceBasket(deltaSKUs []deltaSKU, now int64) (pricedBasket, error) {
+	key := basketCacheKey(deltaSKUs, now)
+	c.pricingMutex.Lock()
+	cached, ok := c.priceCache[key]
+	c.pricingMutex.Unlock()
+	if ok && now < cached.expires {
+		return cached.basket, nil
+	}
+
+	endpoint, err := c.pricedAtEndpoint(now)
+	if err != nil {
+		return pricedBasket{}, err
+	}
+	basket, err := c.getBasketPrice(endpoint, deltaSKUs)
+	if err != nil {
+		return pricedBasket{}, err
+	}
+	c.rememberPrices(basket)
+
+	if c.priceCacheTTL > 0 {
+		c.pricingMutex.Lock()
+		if c.priceCache == nil {
+			c.priceCache = map[string]cachedBasket{}
+		}
+		// drop expired baskets so the cache only holds recent baskets
+		for cachedKey, cached := range c.priceCache {
+			if now >= cached.expires {
+				delete(c.priceCache, cachedKey)
+			}
+		}
+		c.priceCache[key] = cachedBasket{basket: basket, expires: now + c.priceCacheTTL.Nanoseconds()}
+		c.pricingMutex.Unlock()
+	}
+	return basket, nil
+}
+
+// rememberPrices keeps the regular price of every SKU of the priced basket as
+// its last known price
+func (c *Controller) rememberPrices(basket pricedBasket) {
+	currency := basket.Currency
+	if currency == "" {
+		currency = c.currencyOrDefault()
+	}
+
+	c.pricingMutex.Lock()
+	defer c.pricingMutex.Unlock()
+	if c.knownPrices == nil {
+		c.knownPrices = map[string]knownPrice{}
+	}
+	for _, pricedItem := range basket.LineItems {
+		c.knownPrices[pricedItem.SKU] = knownPrice{
+			productName: pricedItem.ProductName,
+			itemPrice:   pricedItem.ItemPrice,
+			currency:    currency,
+			taxCategory: pricedItem.TaxCategory,
+		}
+	}
+}
+
+// LoadKnownPrices takes the price of every SKU in its newest verified sale of
+// the ledgers as its last known price, so that transactions can be priced
+// in degraded mode right after a restart. It is called once at startup,
+// after which rememberPrices keeps the prices up to date
+func (c *Controller) LoadKnownPrices() error {
+	c.ledgerMutex.Lock()
+	defer c.ledgerMutex.Unlock()
+
+	if _, err := os.Stat(c.ledgerFileName); errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	accountLedgers, err := c.GetAllLedgers()
+	if err != nil {
+		return err
+	}
+
+	prices := map[string]knownPrice{}
+	pricedAt := map[string]int64{}
+	for _, account := range accountLedgers.Data {
+		for _, ledger := range account.Ledgers {
+			if ledger.Type != "" || ledger.PriceUnverified {
+				continue
+			}
+			for _, lineItem := range ledger.LineItems {
+				if lineItem.ItemCount <= 0 {
+					continue
+				}
+				if newest, ok := pricedAt[lineItem.SKU]; ok && ledger.TxTimeStamp <= newest {
+					continue
+				}
+				prices[lineItem.SKU] = knownPrice{
+					productName: lineItem.ProductName,
+					itemPrice:   lineItem.ItemPrice,
+					currency:    lineItem.Currency,
+					taxCategory: lineItem.TaxCategory,
+				}
+				pricedAt[lineItem.SKU] = ledger.TxTimeStamp
+			}
+		}
+	}
+
+	c.pricingMutex.Lock()
+	defer c.pricingMutex.Unlock()
+	if c.knownPrices == nil {
+		c.knownPrices = map[string]knownPrice{}
+	}
+	for sku, price := range prices {
+		if _, ok := c.knownPrices[sku]; !ok {
+			c.knownPrices[sku] = price
+		}
+	}
+	c.lc.Infof("Loaded the last known prices of %d SKUs from the ledgers", len(prices))
+	return nil
+}
+
+// lastKnownPrice returns the last price of the SKU, which is the price it was
+// last priced at by this service or the price of its newest verified sale
+// when the service started
+func (c *Controller) lastKnownPrice(sku string) (knownPrice, bool) {
+	c.pricingMutex.Lock()
+	defer c.pricingMutex.Unlock()
+	price, ok := c.knownPrices[sku]
+	return price, ok
+}
+
+// degradedLineItems prices the deltas at their last known prices, without
+// pricing rules, and returns the line items with their currency and total.
+// It is used when the inventory service cannot be reached
+func (c *Controller) degradedLineItems(deltaSKUs []deltaSKU) ([]LineItem, string, int64, error) {
+	currency := ""
+	lineItems := []LineItem{}
+	lineIndexes := map[string]int{}
+	for _, delta := range deltaSKUs {
+		units := delta.Delta
+		if units < 0 {
+			units = -units
+		}
+		if i, ok := lineIndexes[delta.SKU]; ok {
+			lineItems[i].ItemCount += units
+			lineItems[i].LineTotal = lineItems[i].ItemPrice * int64(lineItems[i].ItemCount)
+			continue
+		}
+
+		price, ok := c.lastKnownPrice(delta.SKU)
+		if !ok {
+			return nil, "", 0, fmt.Errorf("%w: %s", errNoKnownPrice, delta.SKU)
+		}
+		if currency == "" {
+			currency = price.currency
+		} else if price.currency != currency {
+			return nil, "", 0, fmt.Errorf("%s was last priced in %s instead of %s", delta.SKU, price.currency, currency)
+		}
+		lineIndexes[delta.SKU] = len(lineItems)
+		lineItems = append(lineItems, LineItem{
+			SKU:         delta.SKU,
+			ProductName: price.productName,
+			ItemPrice:   price.itemPrice,
+			ItemCount:   units,
+			LineTotal:   price.itemPrice * int64(units),
+			Currency:    price.currency,
+			TaxCategory: price.taxCategory,
+		})
+	}
+	if currency == "" {
+		currency = c.currencyOrDefault()
+	}
+
+	var total int64
+	for _, lineItem := range lineItems {
+		total += lineItem.LineTotal
+	}
+	return lineItems, currency, total, nil
+}
+
+// pricedAtEndpoint returns the PricingEndpoint that prices a basket at the
+// time of a transaction
+func (c *Controller) pricedAtEndpoint(timestamp int64) (string, error) {
+	endpoint, err := url.Parse(c.pricingEndpoint)
+	if err != nil {
+		return "", fmt.Errorf("PricingEndpoint is not a valid URL: %s", err.Error())
+	}
+	query := endpoint.Query()
+	query.Set("pricedAt", strconv.FormatInt(timestamp, 10))
+	endpoint.RawQuery = query.Encode()
+	return endpoint.String(), nil
+}
+
+// RepriceUnverifiedTransactions prices every transaction that was recorded
+// at the last known prices again, with the pricing rules that were active
+// when it was made, and returns the number of transactions it re-priced.
+// Transactions that are paid or adjusted keep their amounts. It stops when
+// the inventory service still cannot be reached
+func (c *Controller) RepriceUnverifiedTransactions(now int64) (int, error) {
+	c.ledgerMutex.Lock()
+	defer c.ledgerMutex.Unlock()
+
+	accountLedgers, err := c.GetAllLedgers()
+	if err != nil {
+		return 0, err
+	}
+
+	adjusted := map[int64]bool{}
+	for _, account := range accountLedgers.Data {
+		for _, ledger := range account.Ledgers {
+			if ledger.AdjustsTransactionID != 0 {
+				adjusted[ledger.AdjustsTransactionID] = true
+			}
+		}
+	}
+
+	repriced := 0
+reprice:
+	for accountIndex := range accountLedgers.Data {
+		for ledgerIndex := range accountLedgers.Data[accountIndex].Ledgers {
+			ledger := &accountLedgers.Data[accountIndex].Ledgers[ledgerIndex]
+			if !ledger.PriceUnverified || ledger.IsPaid || adjusted[ledger.TransactionID] {
+				continue
+			}
+
+			deltaSKUs := []deltaSKU{}
+			for _, lineItem := range ledger.LineItems {
+				deltaSKUs = append(deltaSKUs, deltaSKU{SKU: lineItem.SKU, Delta: -lineItem.ItemCount})
+			}
+			endpoint, err := c.pricedAtEndpoint(ledger.TxTimeStamp)
+			if err != nil {
+				return repriced, err
+			}
+			basket, err := c.getBasketPrice(endpoint, deltaSKUs)
+			if errors.Is(err, errServiceUnavailable) {
+				break reprice
+			}
+			if err != nil {
+				c.lc.Warnf("Could not re-price transaction %d: %s", ledger.TransactionID, err.Error())
+				continue
+			}
+			lineItems, currency, lineTotal, err := c.ledgerLineItems(basket)
+			if err != nil {
+				c.lc.Warnf("Could not re-price transaction %d: %s", ledger.TransactionID, err.Error())
+				continue
+			}
+
+			before := *ledger
+			ledger.LineItems, ledger.Currency, ledger.LineTotal = lineItems, currency, lineTotal
+			c.applyTaxes(ledger)
+			ledger.PriceUnverified = false
+			ledger.RepricedAt = now
+			ledger.UpdatedAt = now
+			accountLedgers.postReprice(accountLedgers.Data[accountIndex].AccountID, before, *ledger, now)
+			repriced++
+		}
+	}
+
+	if repriced == 0 {
+		return 0, nil
+	}
+	if err := c.writeLedgers(accountLedgers, "re-pricing"); err != nil {
+		return 0, err
+	}
+	c.lc.Infof("Re-priced %d transactions with unverified prices", repriced)
+	return repriced, nil
+}
+
+// MonitorUnverifiedPrices re-prices the transactions with unverified prices
+// on every RepricingInterval until the service exits
+func (c *Controller) MonitorUnverifiedPrices() {
+	ticker := time.NewTicker(c.repricingInterval)
+	defer ticker.Stop()
+	for {
+		<-ticker.C
+		if _, err := c.RepriceUnverifiedTransactions(time.Now().UnixNano()); err != nil {
+			c.lc.Errorf("Failed to re-price transactions: %s", err.Error())
+		}
+	}
+}
@@ -0,0 +1,264 @@
+// Copyright © 2026 Intel Corporation. All rights reserved.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package routes
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/edgexfoundry/go-mod-core-contracts/v3/clients/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newUnavailablePricingTestServer prices baskets like newPricingTestServer
+// while available is true, and responds with 503 Service Unavailable
+// otherwise. It counts the requests and keeps the last pricedAt query
+func newUnavailablePricingTestServer(t *testing.T, available *atomic.Bool, requests *atomic.Int32, pricedAt *atomic.Value) *httptest.Server {
+	pricingServer := newPricingTestServer(t)
+	t.Cleanup(pricingServer.Close)
+
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		pricedAt.Store(r.URL.Query().Get("pricedAt"))
+		if !available.Load() {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		pricingServer.Config.Handler.ServeHTTP(w, r)
+	}))
+}
+
+func TestBasketCacheKey(t *testing.T) {
+	pricedAt := time.Date(2026, time.March, 3, 16, 30, 0, 0, time.UTC).UnixNano()
+	assert.Equal(t,
+		basketCacheKey([]deltaSKU{{SKU: "1", Delta: -1}, {SKU: "2", Delta: -2}}, pricedAt),
+		basketCacheKey([]deltaSKU{{SKU: "2", Delta: -2}, {SKU: "1", Delta: -1}}, pricedAt+59*time.Second.Nanoseconds()))
+	assert.NotEqual(t,
+		basketCacheKey([]deltaSKU{{SKU: "1", Delta: -1}}, pricedAt),
+		basketCacheKey([]deltaSKU{{SKU: "1", Delta: -2}}, pricedAt))
+	assert.NotEqual(t,
+		basketCacheKey([]deltaSKU{{SKU: "1", Delta: -1}}, pricedAt),
+		basketCacheKey([]deltaSKU{{SKU: "1", Delta: -1}}, pricedAt+time.Minute.Nanoseconds()),
+		"baskets priced in different minutes should not share a key")
+}
+
+func TestPriceBasket(t *testing.T) {
+	var available atomic.Bool
+	var requests atomic.Int32
+	var pricedAt atomic.Value
+	available.Store(true)
+	pricingServer := newUnavailablePricingTestServer(t, &available, &requests, &pricedAt)
+	defer pricingServer.Close()
+
+	c := Controller{
+		lc:              logger.NewMockClient(),
+		pricingEndpoint: pricingServer.URL,
+		priceCacheTTL:   30 * time.Second,
+	}
+	basket := []deltaSKU{{SKU: "4900002470", Delta: -1}}
+	now := time.Date(2026, time.March, 3, 16, 30, 0, 0, time.UTC).UnixNano()
+
+	priced, err := c.priceBasket(basket, now)
+	require.NoError(t, err)
+	assert.Equal(t, int64(159), priced.Total)
+	assert.Equal(t, strconv.FormatInt(now, 10), pricedAt.Load(), "basket should be priced at the time of the transaction")
+	_, err = c.priceBasket(basket, now+time.Second.Nanoseconds())
+	require.NoError(t, err)
+	assert.Equal(t, int32(1), requests.Load(), "identical basket should be priced from the cache")
+
+	_, err = c.priceBasket(basket, now+time.Minute.Nanoseconds())
+	require.NoError(t, err)
+	assert.Equal(t, int32(2), requests.Load(), "basket of the next minute should be priced again")
+
+	_, err = c.priceBasket(basket, now+time.Minute.Nanoseconds()+31*time.Second.Nanoseconds())
+	require.NoError(t, err)
+	assert.Equal(t, int32(3), requests.Load(), "expired basket should be priced again")
+
+	price, ok := c.lastKnownPrice("4900002470")
+	require.True(t, ok)
+	assert.Equal(t, int64(199), price.itemPrice)
+	assert.Equal(t, "USD", price.currency)
+
+	// without a TTL every basket is priced
+	c.priceCacheTTL = 0
+	c.priceCache = nil
+	_, err = c.priceBasket(basket, now)
+	require.NoError(t, err)
+	_, err = c.priceBasket(basket, now)
+	require.NoError(t, err)
+	assert.Equal(t, int32(5), requests.Load())
+
+	available.Store(false)
+	_, err = c.priceBasket(basket, now)
+	require.ErrorIs(t, err, errServiceUnavailable)
+}
+
+// TestLoadKnownPrices tests that the last known price of a SKU is the price
+// of its newest verified sale, and that prices of this service win
+func TestLoadKnownPrices(t *testing.T) {
+	c := Controller{
+		lc:             logger.NewMockClient(),
+		ledgerFileName: LedgerFileName,
+		knownPrices:    map[string]knownPrice{"2200050408": {itemPrice: 249, currency: "USD"}},
+	}
+	accountLedgers := getDefaultAccountLedgers()
+	newer := accountLedgers.Data[0].Ledgers[0]
+	newer.TransactionID++
+	newer.TxTimeStamp++
+	newer.LineItems = []LineItem{{SKU: "1200050408", ProductName: "Mountain Dew - 16.9 oz", ItemPrice: 179, ItemCount: 1, LineTotal: 179, Currency: "USD"}}
+	unverified := newer
+	unverified.TransactionID++
+	unverified.TxTimeStamp++
+	unverified.PriceUnverified = true
+	unverified.LineItems = []LineItem{{SKU: "1200050408", ItemPrice: 99, ItemCount: 1, LineTotal: 99, Currency: "USD"}}
+	accountLedgers.Data[0].Ledgers = append(accountLedgers.Data[0].Ledgers, unverified, newer)
+	accountLedgers.Journal = buildJournal(accountLedgers)
+	data, err := json.Marshal(accountLedgers)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(c.ledgerFileName, data, 0644))
+	defer func() {
+		os.Remove(c.ledgerFileName)
+	}()
+
+	require.NoError(t, c.LoadKnownPrices())
+
+	price, ok := c.lastKnownPrice("1200050408")
+	require.True(t, ok)
+	assert.Equal(t, int64(179), price.itemPrice, "the newest verified sale should set the price")
+	price, ok = c.lastKnownPrice("2200050408")
+	require.True(t, ok)
+	assert.Equal(t, int64(249), price.itemPrice, "a price of this service should not be replaced")
+	_, ok = c.lastKnownPrice("4900002470")
+	assert.False(t, ok)
+
+	missing := Controller{lc: logger.NewMockClient(), ledgerFileName: "missing-" + LedgerFileName}
+	require.NoError(t, missing.LoadKnownPrices())
+}
+
+func TestLedgerAddTransactionDegradedPricing(t *testing.T) {
+	var available atomic.Bool
+	var requests atomic.Int32
+	var pricedAt atomic.Value
+	pricingServer := newUnavailablePricingTestServer(t, &available, &requests, &pricedAt)
+	defer pricingServer.Close()
+
+	tests := []struct {
+		Name               string
+		DegradedPricing    bool
+		UpdateLedger       string
+		ExpectedStatusCode int
+		ExpectedGrandTotal int64
+	}{
+		{"Last known price", true, `{"accountId":2,"deltaSKUs":[{"sku":"1200050408","delta":-1},{"sku":"1200050408","delta":-1}]}`, http.StatusOK, 398},
+		{"Never priced SKU", true, `{"accountId":2,"deltaSKUs":[{"sku":"4900002470","delta":-1}]}`, http.StatusServiceUnavailable, 0},
+		{"Degraded pricing disabled", false, `{"accountId":2,"deltaSKUs":[{"sku":"1200050408","delta":-1}]}`, http.StatusServiceUnavailable, 0},
+	}
+
+	for _, test := range tests {
+		currentTest := test
+		t.Run(currentTest.Name, func(t *testing.T) {
+			c := Controller{
+				lc:              logger.NewMockClient(),
+				pricingEndpoint: pricingServer.URL,
+				ledgerFileName:  LedgerFileName,
+				degradedPricing: currentTest.DegradedPricing,
+			}
+			data, err := json.Marshal(getDefaultAccountLedgers())
+			require.NoError(t, err)
+			require.NoError(t, os.WriteFile(c.ledgerFileName, data, 0644))
+			defer func() {
+				os.Remove(c.ledgerFileName)
+			}()
+			require.NoError(t, c.LoadKnownPrices())
+
+			req := httptest.NewRequest("POST", "http://localhost:48093/ledger", bytes.NewBuffer([]byte(currentTest.UpdateLedger)))
+			w := httptest.NewRecorder()
+			c.LedgerAddTransaction(w, req)
+			resp := w.Result()
+			defer resp.Body.Close()
+
+			require.Equal(t, currentTest.ExpectedStatusCode, resp.StatusCode, "invalid status code")
+			if currentTest.ExpectedStatusCode != http.StatusOK {
+				return
+			}
+			var ledger Ledger
+			require.NoError(t, json.NewDecoder(resp.Body).Decode(&ledger))
+			assert.True(t, ledger.PriceUnverified)
+			assert.Equal(t, currentTest.ExpectedGrandTotal, ledger.GrandTotal)
+			require.Len(t, ledger.LineItems, 1)
+			assert.Equal(t, 2, ledger.LineItems[0].ItemCount)
+		})
+	}
+}
+
+func TestRepriceUnverifiedTransactions(t *testing.T) {
+	var available atomic.Bool
+	var requests atomic.Int32
+	var pricedAt atomic.Value
+	pricingServer := newUnavailablePricingTestServer(t, &available, &requests, &pricedAt)
+	defer pricingServer.Close()
+
+	c := Controller{
+		lc:              logger.NewMockClient(),
+		pricingEndpoint: pricingServer.URL,
+		ledgerFileName:  LedgerFileName,
+	}
+	accountLedgers := getDefaultAccountLedgers()
+	unverified := Ledger{
+		TransactionID:   2579215712984891000,
+		TxTimeStamp:     2579215712984891000,
+		LineTotal:       199,
+		Subtotal:        199,
+		GrandTotal:      199,
+		Currency:        "USD",
+		PriceUnverified: true,
+		LineItems: []LineItem{{
+			SKU:         "4900002470",
+			ProductName: "Sprite (Lemon-Lime) - 16.9 oz",
+			ItemPrice:   199,
+			ItemCount:   1,
+			LineTotal:   199,
+			Currency:    "USD",
+		}},
+	}
+	paid := unverified
+	paid.TransactionID++
+	paid.IsPaid = true
+	paid.PaidAt = paid.TxTimeStamp
+	accountLedgers.Data[1].Ledgers = append(accountLedgers.Data[1].Ledgers, unverified, paid)
+	accountLedgers.Journal = buildJournal(accountLedgers)
+	data, err := json.Marshal(accountLedgers)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(c.ledgerFileName, data, 0644))
+	defer func() {
+		os.Remove(c.ledgerFileName)
+	}()
+
+	repriced, err := c.RepriceUnverifiedTransactions(3000000000000000000)
+	require.NoError(t, err)
+	assert.Equal(t, 0, repriced, "nothing should be re-priced while the PricingEndpoint is unavailable")
+
+	available.Store(true)
+	repriced, err = c.RepriceUnverifiedTransactions(3000000000000000000)
+	require.NoError(t, err)
+	assert.Equal(t, 1, repriced, "only the unpaid transaction should be re-priced")
+	assert.Equal(t, "2579215712984891000", pricedAt.Load())
+
+	accountLedgers, err = c.GetAllLedgers()
+	require.NoError(t, err)
+	ledger := accountLedgers.Data[1].Ledgers[1]
+	assert.False(t, ledger.PriceUnverified)
+	assert.Equal(t, int64(3000000000000000000), ledger.RepricedAt)
+	assert.Equal(t, int64(159), ledger.GrandTotal)
+	assert.Equal(t, []string{"happy-hour"}, ledger.LineItems[0].AppliedRules)
+	assert.True(t, accountLedgers.Data[1].Ledgers[2].PriceUnverified)
+}
@@ -5,13 +5,14 @@ package routes
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
-	"math"
 	"net/http"
-	"os"
 	"strconv"
 	"time"
+
+	"github.com/gorilla/mux"
 )
 
 // SetPaymentStatus sets the `isPaid` field for a transaction to true/false
@@ -37,6 +38,9 @@ func (c *Controller) SetPaymentStatus(writer http.ResponseWriter, req *http.Requ
 		return
 	}
 
+	c.ledgerMutex.Lock()
+	defer c.ledgerMutex.Unlock()
+
 	//Get all ledgers for all accounts
 	accountLedgers, err := c.GetAllLedgers()
 	if err != nil {
@@ -47,25 +51,20 @@ func (c *Controller) SetPaymentStatus(writer http.ResponseWriter, req *http.Requ
 		return
 	}
 
-	for accountIndex, account := range accountLedgers.Data {
+	for _, account := range accountLedgers.Data {
 		if paymentStatus.AccountID == account.AccountID {
-			for transactionIndex, transaction := range account.Ledgers {
+			for _, transaction := range account.Ledgers {
 				if paymentStatus.TransactionID == transaction.TransactionID {
-					accountLedgers.Data[accountIndex].Ledgers[transactionIndex].IsPaid = paymentStatus.IsPaid
-
-					data, err := json.Marshal(accountLedgers)
-					if err != nil {
-						errMsg := fmt.Sprintf("failed to marshal ledger JSON file for set: " + err.Error())
-						c.lc.Errorf("%s: %s", errMsg, err.Error())
-						writer.WriteHeader(http.StatusInternalServerError)
-						writer.Write([]byte(errMsg))
-						return
+					// the payment entry marks the transaction as paid or
+					// unpaid in the ledgers projected from the journal
+					if transaction.IsPaid != paymentStatus.IsPaid {
+						accountLedgers.postPayment(account.AccountID, transaction, paymentStatus.IsPaid, time.Now().UnixNano())
 					}
-					if err = os.WriteFile(c.ledgerFileName, data, 0644); err != nil {
-						errMsg := fmt.Sprintf("failed to write ledger JSON file for set: " + err.Error())
-						c.lc.Errorf("%s: %s", errMsg, err.Error())
+
+					if err := c.writeLedgers(accountLedgers, "set"); err != nil {
+						c.lc.Error(err.Error())
 						writer.WriteHeader(http.StatusInternalServerError)
-						writer.Write([]byte(errMsg))
+						writer.Write([]byte(err.Error()))
 						return
 					}
 
@@ -114,8 +113,26 @@ func (c *Controller) LedgerAddTransaction(writer http.ResponseWriter, req *http.
 		return
 	}
 
-	//Get all ledgers for all accounts
+	// the Idempotency-Key header takes precedence over the request field
+	idempotencyKey := req.Header.Get(idempotencyKeyHeader)
+	if idempotencyKey == "" {
+		idempotencyKey = updateLedger.IdempotencyKey
+	}
+	if len(idempotencyKey) > maxIdempotencyKeyLength {
+		errMsg := fmt.Sprintf("Idempotency key is longer than %d characters", maxIdempotencyKeyLength)
+		c.lc.Error(errMsg)
+		writer.WriteHeader(http.StatusBadRequest)
+		writer.Write([]byte(errMsg))
+		return
+	}
+
+	// the ledgers are not locked while other services are called, so they
+	// are read first to replay the transaction or to find out whether the
+	// account is new to the ledger
+	now := time.Now().UnixNano()
+	c.ledgerMutex.Lock()
 	accountLedgers, err := c.GetAllLedgers()
+	c.ledgerMutex.Unlock()
 	if err != nil {
 		errMsg := fmt.Sprintf("Failed to retrieve all ledgers for accounts %v", err.Error())
 		c.lc.Error(errMsg)
@@ -123,43 +140,106 @@ func (c *Controller) LedgerAddTransaction(writer http.ResponseWriter, req *http.
 		writer.Write([]byte(errMsg))
 		return
 	}
+	if c.replayTransaction(writer, accountLedgers, updateLedger.AccountID, idempotencyKey, now) {
+		return
+	}
 
-	ledgerChanged := false
-	var newLedger Ledger
+	// an account that is new to the ledger is provisioned when it is active
+	// in the authentication service
+	provision := c.accountsEndpoint != "" && !accountLedgers.hasAccount(updateLedger.AccountID)
+	if provision {
+		if err := c.verifyAccount(updateLedger.AccountID); err != nil {
+			errMsg := fmt.Sprintf("Could not provision account %d: %v", updateLedger.AccountID, err.Error())
+			c.lc.Error(errMsg)
+			if errors.Is(err, errAccountNotFound) || errors.Is(err, errAccountInactive) {
+				writer.WriteHeader(http.StatusBadRequest)
+			} else {
+				writer.WriteHeader(http.StatusServiceUnavailable)
+			}
+			writer.Write([]byte(errMsg))
+			return
+		}
+	}
+
+	newLedger := Ledger{
+		TxTimeStamp:    now,
+		LineTotal:      0,
+		Currency:       c.currencyOrDefault(),
+		CreatedAt:      now,
+		UpdatedAt:      now,
+		IsPaid:         false,
+		IdempotencyKey: idempotencyKey,
+		LineItems:      []LineItem{},
+	}
+
+	// price the whole basket at once so that discounts and bundles that span
+	// several SKUs are applied
+	basket, err := c.priceBasket(updateLedger.DeltaSKUs, now)
+	if errors.Is(err, errServiceUnavailable) && c.degradedPricing {
+		// record the transaction at the last known prices, to be re-priced
+		// once the inventory service is reachable again
+		c.lc.Warnf("Recording transaction at the last known prices: %s", err.Error())
+		newLedger.LineItems, newLedger.Currency, newLedger.LineTotal, err = c.degradedLineItems(updateLedger.DeltaSKUs)
+		if err != nil {
+			errMsg := fmt.Sprintf("Could not price the products of the transaction while the PricingEndpoint is unavailable: %v", err.Error())
+			c.lc.Error(errMsg)
+			writer.WriteHeader(http.StatusServiceUnavailable)
+			writer.Write([]byte(errMsg))
+			return
+		}
+		newLedger.PriceUnverified = true
+	} else if errors.Is(err, errServiceUnavailable) {
+		errMsg := fmt.Sprintf("Could not price the products of the transaction: %v", err.Error())
+		c.lc.Error(errMsg)
+		writer.WriteHeader(http.StatusServiceUnavailable)
+		writer.Write([]byte(errMsg))
+		return
+	} else if err != nil {
+		errMsg := fmt.Sprintf("Could not price the products of the transaction: %v", err.Error())
+		c.lc.Error(errMsg)
+		writer.WriteHeader(http.StatusBadRequest)
+		writer.Write([]byte(errMsg))
+		return
+	} else {
+		newLedger.LineItems, newLedger.Currency, newLedger.LineTotal, err = c.ledgerLineItems(basket)
+	}
+	if err != nil {
+		errMsg := fmt.Sprintf("Could not price the products of the transaction: %v", err.Error())
+		c.lc.Error(errMsg)
+		writer.WriteHeader(http.StatusBadRequest)
+		writer.Write([]byte(errMsg))
+		return
+	}
+	c.applyTaxes(&newLedger)
+
+	c.ledgerMutex.Lock()
+	defer c.ledgerMutex.Unlock()
 
+	// the ledgers are read again, as another request may have recorded the
+	// transaction or provisioned the account in the meantime
+	accountLedgers, err = c.GetAllLedgers()
+	if err != nil {
+		errMsg := fmt.Sprintf("Failed to retrieve all ledgers for accounts %v", err.Error())
+		c.lc.Error(errMsg)
+		writer.WriteHeader(http.StatusInternalServerError)
+		writer.Write([]byte(errMsg))
+		return
+	}
+	if c.replayTransaction(writer, accountLedgers, updateLedger.AccountID, idempotencyKey, now) {
+		return
+	}
+	if provision && !accountLedgers.hasAccount(updateLedger.AccountID) {
+		c.provisionAccount(&accountLedgers, updateLedger.AccountID)
+	}
+
+	ledgerChanged := false
 	for accountIndex, account := range accountLedgers.Data {
 		if updateLedger.AccountID == account.AccountID {
-			newLedger = Ledger{
-				TransactionID: time.Now().UnixNano(),
-				TxTimeStamp:   time.Now().UnixNano(),
-				LineTotal:     0,
-				CreatedAt:     time.Now().UnixNano(),
-				UpdatedAt:     time.Now().UnixNano(),
-				IsPaid:        false,
-				LineItems:     []LineItem{},
-			}
-
-			for _, deltaSKU := range updateLedger.DeltaSKUs {
-				itemInfo, err := c.getInventoryItemInfo(c.inventoryEndpoint, deltaSKU.SKU)
-				if err != nil {
-					errMsg := fmt.Sprintf("Could not find product Info for %v errir: %v", deltaSKU.SKU, err.Error())
-					c.lc.Error(errMsg)
-					writer.WriteHeader(http.StatusBadRequest)
-					writer.Write([]byte(errMsg))
-					return
-				}
-				newLineItem := LineItem{
-					SKU:         deltaSKU.SKU,
-					ProductName: itemInfo.ProductName,
-					ItemPrice:   itemInfo.ItemPrice,
-					ItemCount:   int(math.Abs(float64(deltaSKU.Delta))),
-				}
-				newLedger.LineItems = append(newLedger.LineItems, newLineItem)
-				newLedger.LineTotal = newLedger.LineTotal + (newLineItem.ItemPrice * float64(newLineItem.ItemCount))
-			}
+			newLedger.TransactionID = c.nextTransactionID(accountLedgers, now)
 
 			// Add new Ledger to array of Ledgers for that account
 			accountLedgers.Data[accountIndex].Ledgers = append(accountLedgers.Data[accountIndex].Ledgers, newLedger)
+			accountLedgers.postTransaction(account.AccountID, newLedger)
 			ledgerChanged = true
 		}
 	}
@@ -171,19 +251,10 @@ func (c *Controller) LedgerAddTransaction(writer http.ResponseWriter, req *http.
 		return
 	}
 
-	data, err := json.Marshal(accountLedgers)
-	if err != nil {
-		errMsg := fmt.Sprintf("failed to marshal ledger JSON file for update: " + err.Error())
-		c.lc.Errorf("%s: %s", errMsg, err.Error())
+	if err := c.writeLedgers(accountLedgers, "update"); err != nil {
+		c.lc.Error(err.Error())
 		writer.WriteHeader(http.StatusInternalServerError)
-		writer.Write([]byte(errMsg))
-		return
-	}
-	if err = os.WriteFile(c.ledgerFileName, data, 0644); err != nil {
-		errMsg := fmt.Sprintf("failed to write ledger JSON file for update: " + err.Error())
-		c.lc.Errorf("%s: %s", errMsg, err.Error())
-		writer.WriteHeader(http.StatusInternalServerError)
-		writer.Write([]byte(errMsg))
+		writer.Write([]byte(err.Error()))
 		return
 	}
 
@@ -199,13 +270,421 @@ func (c *Controller) LedgerAddTransaction(writer http.ResponseWriter, req *http.
 	}
 }
 
-// getInventoryItemInfo is a helper function that will take the inference data (SKU)
-// and return product details for a transaction to be recorded in the ledger
-func (c *Controller) getInventoryItemInfo(inventoryEndpoint string, SKU string) (Product, error) {
+// replayTransaction writes the transaction that was recorded with the
+// idempotency key for the account, if any. It returns true if the request
+// was answered, either by the replay or by an error
+func (c *Controller) replayTransaction(writer http.ResponseWriter, accountLedgers Accounts, accountID int, idempotencyKey string, now int64) bool {
+	if idempotencyKey == "" {
+		return false
+	}
+	original, found, err := c.findIdempotentTransaction(accountLedgers, accountID, idempotencyKey, now)
+	if err != nil {
+		errMsg := fmt.Sprintf("Could not replay the transaction: %v", err.Error())
+		c.lc.Error(errMsg)
+		writer.WriteHeader(http.StatusUnprocessableEntity)
+		writer.Write([]byte(errMsg))
+		return true
+	}
+	if !found {
+		return false
+	}
+
+	originalJSON, err := json.Marshal(original)
+	if err != nil {
+		errMsg := fmt.Sprintf("Failed to marshal the replayed transaction: %v", err.Error())
+		c.lc.Error(errMsg)
+		writer.WriteHeader(http.StatusInternalServerError)
+		writer.Write([]byte(errMsg))
+		return true
+	}
+	c.lc.Infof("Replayed transaction %d for idempotency key %s", original.TransactionID, idempotencyKey)
+	writer.Header().Set(idempotentReplayHeader, "true")
+	writer.Write(originalJSON)
+	return true
+}
+
+// LedgerAdjustmentPost records a refund or void of a transaction as a new
+// adjustment transaction that is linked to it
+func (c *Controller) LedgerAdjustmentPost(writer http.ResponseWriter, req *http.Request) {
+	// Read request body
+	body := make([]byte, req.ContentLength)
+	_, err := io.ReadFull(req.Body, body)
+	if err != nil {
+		errMsg := "Failed to parse request body"
+		c.lc.Errorf("%s: %s", errMsg, err.Error())
+		writer.WriteHeader(http.StatusBadRequest)
+		writer.Write([]byte(errMsg))
+		return
+	}
+
+	var request adjustmentRequest
+	if err := json.Unmarshal(body, &request); err != nil {
+		errMsg := "Failed to unmarshal body"
+		c.lc.Errorf("%s: %s", errMsg, err.Error())
+		writer.WriteHeader(http.StatusBadRequest)
+		writer.Write([]byte(errMsg))
+		return
+	}
+
+	vars := mux.Vars(req)
+	c.recordAdjustment(writer, vars["accountid"], vars["tid"], request)
+}
+
+// recordAdjustment adds the adjustment of a transaction of an account to its
+// ledger and writes it to the response
+func (c *Controller) recordAdjustment(writer http.ResponseWriter, accountIDstr string, tidstr string, request adjustmentRequest) {
+	tid, err := strconv.ParseInt(tidstr, 10, 64)
+	if err != nil {
+		errMsg := "transactionID contains bad data"
+		c.lc.Errorf("%s: %s", errMsg, err.Error())
+		writer.WriteHeader(http.StatusBadRequest)
+		writer.Write([]byte(errMsg))
+		return
+	}
+	accountID, err := strconv.Atoi(accountIDstr)
+	if err != nil {
+		errMsg := "accountID contains bad data"
+		c.lc.Errorf("%s: %s", errMsg, err.Error())
+		writer.WriteHeader(http.StatusBadRequest)
+		writer.Write([]byte(errMsg))
+		return
+	}
+
+	c.ledgerMutex.Lock()
+	defer c.ledgerMutex.Unlock()
 
-	resp, err := c.sendCommand("GET", inventoryEndpoint+"/"+SKU, []byte(""))
+	//Get all ledgers for all accounts
+	accountLedgers, err := c.GetAllLedgers()
+	if err != nil {
+		errMsg := fmt.Sprintf("Failed to retrieve all ledgers for accounts: %v", err.Error())
+		c.lc.Error(errMsg)
+		writer.WriteHeader(http.StatusInternalServerError)
+		writer.Write([]byte(errMsg))
+		return
+	}
+
+	for accountIndex, account := range accountLedgers.Data {
+		if accountID != account.AccountID {
+			continue
+		}
+		for _, transaction := range account.Ledgers {
+			if tid != transaction.TransactionID {
+				continue
+			}
+
+			now := time.Now().UnixNano()
+			adjustment, err := newAdjustment(account, transaction, request, c.nextTransactionID(accountLedgers, now), now)
+			if err != nil {
+				errMsg := fmt.Sprintf("Could not adjust transaction %v: %s", tidstr, err.Error())
+				c.lc.Error(errMsg)
+				if errors.Is(err, errAdjustmentConflict) {
+					writer.WriteHeader(http.StatusConflict)
+				} else {
+					writer.WriteHeader(http.StatusBadRequest)
+				}
+				writer.Write([]byte(errMsg))
+				return
+			}
+
+			accountLedgers.Data[accountIndex].Ledgers = append(accountLedgers.Data[accountIndex].Ledgers, adjustment)
+			accountLedgers.postTransaction(accountID, adjustment)
+			if err := c.writeLedgers(accountLedgers, "adjustment"); err != nil {
+				c.lc.Error(err.Error())
+				writer.WriteHeader(http.StatusInternalServerError)
+				writer.Write([]byte(err.Error()))
+				return
+			}
+
+			adjustmentJSON, err := json.Marshal(adjustment)
+			if err != nil {
+				c.lc.Warnf("Recorded adjustment successfully with error %s", err.Error())
+				writer.Write([]byte("Recorded adjustment successfully, but could not marshal to json"))
+				return
+			}
+			c.lc.Infof("Recorded %s of transaction %v for %s by %s", adjustment.Type, tidstr, adjustment.ReasonCode, adjustment.Operator)
+			writer.Write(adjustmentJSON)
+			return
+		}
+		errMsg := fmt.Sprintf("Could not find Transaction %v", tidstr)
+		c.lc.Error(errMsg)
+		writer.WriteHeader(http.StatusBadRequest)
+		writer.Write([]byte(errMsg))
+		return
+	}
+	errMsg := fmt.Sprintf("Could not find account %v", accountIDstr)
+	c.lc.Error(errMsg)
+	writer.WriteHeader(http.StatusBadRequest)
+	writer.Write([]byte(errMsg))
+}
+
+// LedgerDisputePost opens a dispute of a transaction, or of a SKU of it, with
+// the statement of the customer and references to the evidence of the
+// vending session
+func (c *Controller) LedgerDisputePost(writer http.ResponseWriter, req *http.Request) {
+	// Read request body
+	body := make([]byte, req.ContentLength)
+	_, err := io.ReadFull(req.Body, body)
+	if err != nil {
+		errMsg := "Failed to parse request body"
+		c.lc.Errorf("%s: %s", errMsg, err.Error())
+		writer.WriteHeader(http.StatusBadRequest)
+		writer.Write([]byte(errMsg))
+		return
+	}
+
+	var request disputeRequest
+	if err := json.Unmarshal(body, &request); err != nil {
+		errMsg := "Failed to unmarshal body"
+		c.lc.Errorf("%s: %s", errMsg, err.Error())
+		writer.WriteHeader(http.StatusBadRequest)
+		writer.Write([]byte(errMsg))
+		return
+	}
+
+	vars := mux.Vars(req)
+	tidstr := vars["tid"]
+	tid, err := strconv.ParseInt(tidstr, 10, 64)
+	if err != nil {
+		errMsg := "transactionID contains bad data"
+		c.lc.Errorf("%s: %s", errMsg, err.Error())
+		writer.WriteHeader(http.StatusBadRequest)
+		writer.Write([]byte(errMsg))
+		return
+	}
+	accountID, err := strconv.Atoi(vars["accountid"])
+	if err != nil {
+		errMsg := "accountID contains bad data"
+		c.lc.Errorf("%s: %s", errMsg, err.Error())
+		writer.WriteHeader(http.StatusBadRequest)
+		writer.Write([]byte(errMsg))
+		return
+	}
+
+	c.ledgerMutex.Lock()
+	defer c.ledgerMutex.Unlock()
+
+	//Get all ledgers for all accounts
+	accountLedgers, err := c.GetAllLedgers()
+	if err != nil {
+		errMsg := fmt.Sprintf("Failed to retrieve all ledgers for accounts: %v", err.Error())
+		c.lc.Error(errMsg)
+		writer.WriteHeader(http.StatusInternalServerError)
+		writer.Write([]byte(errMsg))
+		return
+	}
+
+	for accountIndex, account := range accountLedgers.Data {
+		if accountID != account.AccountID {
+			continue
+		}
+		for _, transaction := range account.Ledgers {
+			if tid != transaction.TransactionID {
+				continue
+			}
+
+			dispute, err := newDispute(account, transaction, request, nextDisputeID(accountLedgers), time.Now().UnixNano())
+			if err != nil {
+				errMsg := fmt.Sprintf("Could not dispute transaction %v: %s", tidstr, err.Error())
+				c.lc.Error(errMsg)
+				if errors.Is(err, errDisputeConflict) {
+					writer.WriteHeader(http.StatusConflict)
+				} else {
+					writer.WriteHeader(http.StatusBadRequest)
+				}
+				writer.Write([]byte(errMsg))
+				return
+			}
+
+			accountLedgers.Data[accountIndex].Disputes = append(accountLedgers.Data[accountIndex].Disputes, dispute)
+			if err := c.writeLedgers(accountLedgers, "dispute"); err != nil {
+				c.lc.Error(err.Error())
+				writer.WriteHeader(http.StatusInternalServerError)
+				writer.Write([]byte(err.Error()))
+				return
+			}
+
+			disputeJSON, err := json.Marshal(dispute)
+			if err != nil {
+				c.lc.Warnf("Opened dispute successfully with error %s", err.Error())
+				writer.Write([]byte("Opened dispute successfully, but could not marshal to json"))
+				return
+			}
+			c.lc.Infof("Opened dispute %d of transaction %v", dispute.DisputeID, tidstr)
+			writer.Write(disputeJSON)
+			return
+		}
+		errMsg := fmt.Sprintf("Could not find Transaction %v", tidstr)
+		c.lc.Error(errMsg)
+		writer.WriteHeader(http.StatusBadRequest)
+		writer.Write([]byte(errMsg))
+		return
+	}
+	errMsg := fmt.Sprintf("AccountID %v not found in ledger", accountID)
+	c.lc.Error(errMsg)
+	writer.WriteHeader(http.StatusBadRequest)
+	writer.Write([]byte(errMsg))
+}
+
+// DisputeResolutionPost resolves an open dispute of an account as upheld or
+// rejected. An upheld dispute is refunded right away
+func (c *Controller) DisputeResolutionPost(writer http.ResponseWriter, req *http.Request) {
+	// Read request body
+	body := make([]byte, req.ContentLength)
+	_, err := io.ReadFull(req.Body, body)
+	if err != nil {
+		errMsg := "Failed to parse request body"
+		c.lc.Errorf("%s: %s", errMsg, err.Error())
+		writer.WriteHeader(http.StatusBadRequest)
+		writer.Write([]byte(errMsg))
+		return
+	}
+
+	var resolution disputeResolution
+	if err := json.Unmarshal(body, &resolution); err != nil {
+		errMsg := "Failed to unmarshal body"
+		c.lc.Errorf("%s: %s", errMsg, err.Error())
+		writer.WriteHeader(http.StatusBadRequest)
+		writer.Write([]byte(errMsg))
+		return
+	}
+
+	vars := mux.Vars(req)
+	disputeIDstr := vars["disputeid"]
+	disputeID, err := strconv.ParseInt(disputeIDstr, 10, 64)
+	if err != nil {
+		errMsg := "disputeID contains bad data"
+		c.lc.Errorf("%s: %s", errMsg, err.Error())
+		writer.WriteHeader(http.StatusBadRequest)
+		writer.Write([]byte(errMsg))
+		return
+	}
+	accountID, err := strconv.Atoi(vars["accountid"])
+	if err != nil {
+		errMsg := "accountID contains bad data"
+		c.lc.Errorf("%s: %s", errMsg, err.Error())
+		writer.WriteHeader(http.StatusBadRequest)
+		writer.Write([]byte(errMsg))
+		return
+	}
+
+	c.ledgerMutex.Lock()
+	defer c.ledgerMutex.Unlock()
+
+	//Get all ledgers for all accounts
+	accountLedgers, err := c.GetAllLedgers()
+	if err != nil {
+		errMsg := fmt.Sprintf("Failed to retrieve all ledgers for accounts: %v", err.Error())
+		c.lc.Error(errMsg)
+		writer.WriteHeader(http.StatusInternalServerError)
+		writer.Write([]byte(errMsg))
+		return
+	}
+
+	for accountIndex, account := range accountLedgers.Data {
+		if accountID != account.AccountID {
+			continue
+		}
+		for disputeIndex, dispute := range account.Disputes {
+			if disputeID != dispute.DisputeID {
+				continue
+			}
+
+			now := time.Now().UnixNano()
+			resolved, refund, err := resolveDispute(account, dispute, resolution, c.nextTransactionID(accountLedgers, now), now)
+			if err != nil {
+				errMsg := fmt.Sprintf("Could not resolve dispute %v: %s", disputeIDstr, err.Error())
+				c.lc.Error(errMsg)
+				if errors.Is(err, errDisputeConflict) {
+					writer.WriteHeader(http.StatusConflict)
+				} else {
+					writer.WriteHeader(http.StatusBadRequest)
+				}
+				writer.Write([]byte(errMsg))
+				return
+			}
+
+			accountLedgers.Data[accountIndex].Disputes[disputeIndex] = resolved
+			if refund != nil {
+				accountLedgers.Data[accountIndex].Ledgers = append(accountLedgers.Data[accountIndex].Ledgers, *refund)
+				accountLedgers.postTransaction(accountID, *refund)
+			}
+			if err := c.writeLedgers(accountLedgers, "dispute resolution"); err != nil {
+				c.lc.Error(err.Error())
+				writer.WriteHeader(http.StatusInternalServerError)
+				writer.Write([]byte(err.Error()))
+				return
+			}
+
+			disputeJSON, err := json.Marshal(resolved)
+			if err != nil {
+				c.lc.Warnf("Resolved dispute successfully with error %s", err.Error())
+				writer.Write([]byte("Resolved dispute successfully, but could not marshal to json"))
+				return
+			}
+			c.lc.Infof("Resolved dispute %v as %s by %s", disputeIDstr, resolved.Status, resolved.Reviewer)
+			writer.Write(disputeJSON)
+			return
+		}
+		errMsg := fmt.Sprintf("Could not find dispute %v", disputeIDstr)
+		c.lc.Error(errMsg)
+		writer.WriteHeader(http.StatusBadRequest)
+		writer.Write([]byte(errMsg))
+		return
+	}
+	errMsg := fmt.Sprintf("AccountID %v not found in ledger", accountID)
+	c.lc.Error(errMsg)
+	writer.WriteHeader(http.StatusBadRequest)
+	writer.Write([]byte(errMsg))
+}
+
+// ledgerLineItems converts the line items of a priced basket, which are
+// already in minor units, into ledger line items, and returns them with
+// their currency and total. The total is the sum of the line totals
+func (c *Controller) ledgerLineItems(basket pricedBasket) ([]LineItem, string, int64, error) {
+	currency := basket.Currency
+	if currency == "" {
+		currency = c.currencyOrDefault()
+	}
+	if !validCurrency(currency) {
+		return nil, "", 0, fmt.Errorf("unsupported currency %q", currency)
+	}
+
+	lineItems := []LineItem{}
+	var total int64
+	for _, pricedItem := range basket.LineItems {
+		lineItems = append(lineItems, LineItem{
+			SKU:          pricedItem.SKU,
+			ProductName:  pricedItem.ProductName,
+			ItemPrice:    pricedItem.ItemPrice,
+			ItemCount:    pricedItem.ItemCount,
+			Discount:     pricedItem.Discount,
+			LineTotal:    pricedItem.LineTotal,
+			Currency:     currency,
+			TaxCategory:  pricedItem.TaxCategory,
+			AppliedRules: pricedItem.AppliedRules,
+		})
+		total += pricedItem.LineTotal
+	}
+	return lineItems, currency, total, nil
+}
+
+// getBasketPrice is a helper function that will take the inference data (SKU
+// deltas) and return the products and prices of the whole basket, with the
+// pricing rules of the inventory service applied, for a transaction to be
+// recorded in the ledger. An inventory service that cannot be reached
+// returns errServiceUnavailable
+func (c *Controller) getBasketPrice(pricingEndpoint string, deltaSKUs []deltaSKU) (pricedBasket, error) {
+	basketJSON, err := json.Marshal(deltaSKUs)
+	if err != nil {
+		return pricedBasket{}, fmt.Errorf("Could not serialize the basket: %s", err.Error())
+	}
+
+	resp, err := c.sendCommand("POST", pricingEndpoint, basketJSON)
+	if errors.Is(err, errServiceUnavailable) {
+		return pricedBasket{}, fmt.Errorf("Could not hit PricingEndpoint: %w", err)
+	}
 	if err != nil {
-		return Product{}, fmt.Errorf("Could not hit inventoryEndpoint, SKU may not exist")
+		return pricedBasket{}, fmt.Errorf("Could not hit PricingEndpoint, SKU may not exist")
 	}
 
 	defer resp.Body.Close()
@@ -213,15 +692,15 @@ func (c *Controller) getInventoryItemInfo(inventoryEndpoint string, SKU string)
 	// Read the HTTP Response Body
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return Product{}, fmt.Errorf("Could not read response body from InventoryEndpoint")
+		return pricedBasket{}, fmt.Errorf("Could not read response body from PricingEndpoint")
 	}
 
-	// Prepare to unmarshal the desired inventory item from the HTTP response's body (json)
-	var inventoryItem Product
-	err = json.Unmarshal(body, &inventoryItem)
+	// Prepare to unmarshal the priced basket from the HTTP response's body (json)
+	var basket pricedBasket
+	err = json.Unmarshal(body, &basket)
 	if err != nil {
-		return Product{}, fmt.Errorf("Received an invalid data structure from InventoryEndpoint")
+		return pricedBasket{}, fmt.Errorf("Received an invalid data structure from PricingEndpoint")
 	}
 
-	return inventoryItem, nil
+	return basket, nil
 }
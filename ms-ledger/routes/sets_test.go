@@ -10,54 +10,66 @@ import (
 	"net/http/httptest"
 	"os"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/mock"
 
 	"github.com/edgexfoundry/app-functions-sdk-go/v3/pkg/interfaces/mocks"
 	"github.com/edgexfoundry/go-mod-core-contracts/v3/clients/logger"
+	"github.com/gorilla/mux"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
 
-func getDefaultProduct() Product {
-	return Product{
-		CreatedAt:          1567787309,
-		IsActive:           true,
-		ItemPrice:          1.99,
-		MaxRestockingLevel: 24,
-		MinRestockingLevel: 0,
-		ProductName:        "Sprite (Lemon-Lime) - 16.9 oz",
-		SKU:                "4900002470",
-		UnitsOnHand:        0,
-		UpdatedAt:          1567787309,
+func getDefaultPricedLineItem() pricedLineItem {
+	return pricedLineItem{
+		SKU:          "4900002470",
+		ProductName:  "Sprite (Lemon-Lime) - 16.9 oz",
+		ItemPrice:    199,
+		ItemCount:    1,
+		Discount:     40,
+		LineTotal:    159,
+		AppliedRules: []string{"happy-hour"},
 	}
 }
 
-func newInventoryTestServer(t *testing.T) *httptest.Server {
+// newPricingTestServer prices baskets that only contain the default SKU, and
+// responds with 404 Not Found like the inventory service for any other SKU
+func newPricingTestServer(t *testing.T) *httptest.Server {
 
-	inventoryServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+	pricingServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 
 		// vars
-		defaultProduct := getDefaultProduct()
-		sku := r.RequestURI
-
-		if sku == "/"+defaultProduct.SKU {
-			w.WriteHeader(http.StatusOK)
-			jsonProduct, _ := json.Marshal(defaultProduct)
-			_, err := w.Write(jsonProduct)
-			if err != nil {
-				t.Fatal(err.Error())
-			}
-		} else {
-			w.WriteHeader(http.StatusNotFound)
-			_, err := w.Write([]byte("Could not find product for SKU"))
-			if err != nil {
-				t.Fatal(err.Error())
+		defaultLineItem := getDefaultPricedLineItem()
+		var basket []deltaSKU
+		if err := json.NewDecoder(r.Body).Decode(&basket); err != nil || r.Method != http.MethodPost {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		priced := pricedBasket{LineItems: []pricedLineItem{}, Currency: "USD"}
+		for _, item := range basket {
+			if item.SKU != defaultLineItem.SKU {
+				w.WriteHeader(http.StatusNotFound)
+				_, err := w.Write([]byte("Could not find product for SKU"))
+				if err != nil {
+					t.Fatal(err.Error())
+				}
+				return
 			}
+			priced.LineItems = append(priced.LineItems, defaultLineItem)
+			priced.Total += defaultLineItem.LineTotal
+		}
+
+		w.WriteHeader(http.StatusOK)
+		jsonBasket, _ := json.Marshal(priced)
+		_, err := w.Write(jsonBasket)
+		if err != nil {
+			t.Fatal(err.Error())
 		}
 	}))
 
-	return inventoryServer
+	return pricingServer
 }
 
 func TestLedgerAddTransaction(t *testing.T) {
@@ -65,7 +77,7 @@ func TestLedgerAddTransaction(t *testing.T) {
 	// Accounts slice
 	accountLedgers := getDefaultAccountLedgers()
 
-	inventoryServer := newInventoryTestServer(t)
+	pricingServer := newPricingTestServer(t)
 
 	tests := []struct {
 		Name               string
@@ -88,10 +100,10 @@ func TestLedgerAddTransaction(t *testing.T) {
 			mockAppService.On("AddRoute", mock.Anything, mock.Anything, mock.Anything, mock.Anything).
 				Return(nil)
 			c := Controller{
-				lc:                logger.NewMockClient(),
-				service:           mockAppService,
-				inventoryEndpoint: inventoryServer.URL,
-				ledgerFileName:    LedgerFileName,
+				lc:              logger.NewMockClient(),
+				service:         mockAppService,
+				pricingEndpoint: pricingServer.URL,
+				ledgerFileName:  LedgerFileName,
 			}
 			err := c.DeleteAllLedgers()
 			require.NoError(t, err)
@@ -117,30 +129,136 @@ func TestLedgerAddTransaction(t *testing.T) {
 			defer resp.Body.Close()
 
 			assert.Equal(t, currentTest.ExpectedStatusCode, resp.StatusCode, "invalid status code")
+			if resp.StatusCode == http.StatusOK {
+				var newLedger Ledger
+				require.NoError(t, json.NewDecoder(resp.Body).Decode(&newLedger))
+				require.Len(t, newLedger.LineItems, 1)
+				assert.Equal(t, int64(159), newLedger.LineTotal, "the ledger should be charged the discounted price")
+				assert.Equal(t, int64(0), newLedger.TaxTotal, "no tax rules are configured")
+				assert.Equal(t, int64(159), newLedger.GrandTotal)
+				assert.Equal(t, "USD", newLedger.Currency)
+				assert.Equal(t, int64(199), newLedger.LineItems[0].ItemPrice)
+				assert.Equal(t, int64(40), newLedger.LineItems[0].Discount)
+				assert.Equal(t, int64(159), newLedger.LineItems[0].LineTotal)
+				assert.Equal(t, "USD", newLedger.LineItems[0].Currency)
+				assert.Equal(t, []string{"happy-hour"}, newLedger.LineItems[0].AppliedRules)
+			}
 		})
 	}
 }
 
-func TestGetInventoryItemInfo(t *testing.T) {
+func TestLedgerAddTransactionIdempotency(t *testing.T) {
+	pricingServer := newPricingTestServer(t)
+	c := Controller{
+		lc:                   logger.NewMockClient(),
+		pricingEndpoint:      pricingServer.URL,
+		ledgerFileName:       LedgerFileName,
+		idempotencyRetention: time.Hour,
+	}
+	data, err := json.Marshal(getDefaultAccountLedgers())
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(c.ledgerFileName, data, 0644))
+	defer func() {
+		os.Remove(c.ledgerFileName)
+	}()
+
+	post := func(body string, idempotencyKey string) (*http.Response, Ledger) {
+		req := httptest.NewRequest("POST", "http://localhost:48093/ledger", bytes.NewBuffer([]byte(body)))
+		if idempotencyKey != "" {
+			req.Header.Set(idempotencyKeyHeader, idempotencyKey)
+		}
+		w := httptest.NewRecorder()
+		c.LedgerAddTransaction(w, req)
+		resp := w.Result()
+		defer resp.Body.Close()
+		var ledger Ledger
+		if resp.StatusCode == http.StatusOK {
+			require.NoError(t, json.NewDecoder(resp.Body).Decode(&ledger))
+		}
+		return resp, ledger
+	}
+	body := `{"accountId":2,"deltaSKUs":[{"sku":"4900002470","delta":-1}]}`
+
+	resp, original := post(body, "session-1")
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "session-1", original.IdempotencyKey)
+
+	resp, replayed := post(body, "session-1")
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "true", resp.Header.Get(idempotentReplayHeader))
+	assert.Equal(t, original, replayed, "a replay should return the original transaction")
+
+	resp, replayed = post(`{"accountId":2,"deltaSKUs":[{"sku":"4900002470","delta":-1}],"idempotencyKey":"session-1"}`, "")
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, original, replayed, "the key can be given in the request body")
+
+	resp, _ = post(`{"accountId":1,"deltaSKUs":[{"sku":"4900002470","delta":-1}]}`, "session-1")
+	assert.Equal(t, http.StatusUnprocessableEntity, resp.StatusCode, "a key cannot be reused for another account")
+
+	resp, other := post(body, "session-2")
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Greater(t, other.TransactionID, original.TransactionID)
+
+	accountsFromFile, err := c.GetAllLedgers()
+	require.NoError(t, err)
+	assert.Len(t, accountsFromFile.Data[1].Ledgers, 3, "replays should not add transactions")
+}
+
+// TestLedgerAddTransactionPricingUnlocked tests that the ledgers are not
+// locked while the basket of a transaction is priced
+func TestLedgerAddTransactionPricingUnlocked(t *testing.T) {
+	pricingServer := newPricingTestServer(t)
+	defer pricingServer.Close()
+
+	c := &Controller{
+		lc:             logger.NewMockClient(),
+		ledgerFileName: LedgerFileName,
+	}
+	lockCheckingServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		locked := !c.ledgerMutex.TryLock()
+		if !locked {
+			c.ledgerMutex.Unlock()
+		}
+		assert.False(t, locked, "the ledgers should not be locked while the basket is priced")
+		pricingServer.Config.Handler.ServeHTTP(w, r)
+	}))
+	defer lockCheckingServer.Close()
+	c.pricingEndpoint = lockCheckingServer.URL
+
+	data, err := json.Marshal(getDefaultAccountLedgers())
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(c.ledgerFileName, data, 0644))
+	defer func() {
+		os.Remove(c.ledgerFileName)
+	}()
+
+	w := httptest.NewRecorder()
+	c.LedgerAddTransaction(w, httptest.NewRequest("POST", "http://localhost:48093/ledger", bytes.NewBuffer([]byte(`{"accountId":2,"deltaSKUs":[{"sku":"4900002470","delta":-1}]}`))))
+	require.Equal(t, http.StatusOK, w.Code)
+
+	accountsFromFile, err := c.GetAllLedgers()
+	require.NoError(t, err)
+	assert.Len(t, accountsFromFile.Data[1].Ledgers, 2)
+}
+
+func TestGetBasketPrice(t *testing.T) {
 
 	// Default variables
-	defaultProduct := getDefaultProduct()
-	defaultSKU := "4900002470"
+	defaultLineItem := getDefaultPricedLineItem()
+	defaultBasket := []deltaSKU{{SKU: defaultLineItem.SKU, Delta: -1}}
 
-	inventoryServer := newInventoryTestServer(t)
+	pricingServer := newPricingTestServer(t)
 
 	tests := []struct {
-		Name              string
-		MissingAppSetting bool
-		InventoryEndpoint string
-		SKU               string
-		ProductMatch      bool
-		Error             bool
+		Name            string
+		PricingEndpoint string
+		Basket          []deltaSKU
+		Error           bool
 	}{
-		{"Valid SKU", false, inventoryServer.URL, defaultSKU, true, false},
-		{"Nonexistent SKU", false, inventoryServer.URL, "123", false, true},
-		{"Missing AppSetting", true, inventoryServer.URL, defaultSKU, false, true},
-		{"Invalid InventoryEndpoint", false, "badURL", defaultSKU, false, true},
+		{"Valid SKU", pricingServer.URL, defaultBasket, false},
+		{"Nonexistent SKU", pricingServer.URL, []deltaSKU{{SKU: "123", Delta: -1}}, true},
+		{"Missing AppSetting", "", defaultBasket, true},
+		{"Invalid PricingEndpoint", "badURL", defaultBasket, true},
 	}
 
 	for _, test := range tests {
@@ -150,28 +268,22 @@ func TestGetInventoryItemInfo(t *testing.T) {
 			mockAppService.On("AddRoute", mock.Anything, mock.Anything, mock.Anything, mock.Anything).
 				Return(nil)
 			c := Controller{
-				lc:                logger.NewMockClient(),
-				service:           mockAppService,
-				inventoryEndpoint: currentTest.InventoryEndpoint,
-				ledgerFileName:    LedgerFileName,
-			}
-			if currentTest.MissingAppSetting {
-				badInventoryEndpoint := ""
-				_, err := c.getInventoryItemInfo(badInventoryEndpoint, currentTest.SKU)
-				require.Error(t, err)
-				return
+				lc:              logger.NewMockClient(),
+				service:         mockAppService,
+				pricingEndpoint: currentTest.PricingEndpoint,
+				ledgerFileName:  LedgerFileName,
 			}
 
-			inventoryItem, err := c.getInventoryItemInfo(c.inventoryEndpoint, currentTest.SKU)
+			basket, err := c.getBasketPrice(c.pricingEndpoint, currentTest.Basket)
 			if currentTest.Error {
 				require.Error(t, err)
 				return
 			}
 			assert.NoError(t, err)
 
-			if currentTest.ProductMatch {
-				assert.Equal(t, defaultProduct, inventoryItem, "Products should match")
-			}
+			require.Len(t, basket.LineItems, 1)
+			assert.Equal(t, defaultLineItem, basket.LineItems[0], "Line items should match")
+			assert.Equal(t, defaultLineItem.LineTotal, basket.Total)
 		})
 	}
 }
@@ -200,10 +312,10 @@ func TestSetPaymentStatus(t *testing.T) {
 			mockAppService.On("AddRoute", mock.Anything, mock.Anything, mock.Anything, mock.Anything).
 				Return(nil)
 			c := Controller{
-				lc:                logger.NewMockClient(),
-				service:           mockAppService,
-				inventoryEndpoint: "test.com",
-				ledgerFileName:    LedgerFileName,
+				lc:              logger.NewMockClient(),
+				service:         mockAppService,
+				pricingEndpoint: "test.com",
+				ledgerFileName:  LedgerFileName,
 			}
 			err := c.DeleteAllLedgers()
 			require.NoError(t, err)
@@ -227,6 +339,114 @@ func TestSetPaymentStatus(t *testing.T) {
 			defer resp.Body.Close()
 
 			assert.Equal(t, currentTest.ExpectedStatusCode, resp.StatusCode, "invalid status code")
+			if resp.StatusCode == http.StatusOK {
+				accountsFromFile, err := c.GetAllLedgers()
+				require.NoError(t, err)
+				assert.True(t, accountsFromFile.Data[0].Ledgers[0].IsPaid)
+				assert.NotZero(t, accountsFromFile.Data[0].Ledgers[0].PaidAt, "the payment time should be recorded")
+			}
+		})
+	}
+}
+
+// TestLedgerLineItems tests that the line items of priced baskets keep their
+// minor units and currency
+func TestLedgerLineItems(t *testing.T) {
+	c := Controller{lc: logger.NewMockClient()}
+
+	basket := pricedBasket{
+		LineItems: []pricedLineItem{
+			{SKU: "4900002470", ItemPrice: 199, ItemCount: 2, LineTotal: 398},
+			{SKU: "1200050408", ItemPrice: 199, ItemCount: 1, Discount: 40, LineTotal: 159},
+			{SKU: "1200010735", ItemPrice: 10, ItemCount: 3, LineTotal: 30},
+		},
+		Total: 587,
+	}
+	lineItems, currency, total, err := c.ledgerLineItems(basket)
+	require.NoError(t, err)
+	assert.Equal(t, defaultCurrency, currency, "a basket without a currency is in the default currency")
+	assert.Equal(t, int64(587), total)
+	require.Len(t, lineItems, 3)
+	assert.Equal(t, int64(40), lineItems[1].Discount)
+	assert.Equal(t, int64(30), lineItems[2].LineTotal)
+
+	basket = pricedBasket{LineItems: []pricedLineItem{{SKU: "4900002470", ItemPrice: 150, ItemCount: 1, LineTotal: 150}}, Currency: "JPY"}
+	lineItems, currency, total, err = c.ledgerLineItems(basket)
+	require.NoError(t, err)
+	assert.Equal(t, "JPY", currency)
+	assert.Equal(t, int64(150), total)
+	assert.Equal(t, "JPY", lineItems[0].Currency)
+
+	basket.Currency = "XYZ"
+	_, _, _, err = c.ledgerLineItems(basket)
+	require.Error(t, err)
+}
+
+func TestLedgerAdjustmentPost(t *testing.T) {
+	accountLedgers := getDefaultAccountLedgers()
+	paidLedgers := getDefaultAccountLedgers()
+	paidLedgers.Data[0].Ledgers[0].IsPaid = true
+	paidLedgers.Data[0].Ledgers[0].PaidAt = paidLedgers.Data[0].Ledgers[0].UpdatedAt
+	paidLedgers.Journal = buildJournal(paidLedgers)
+	defaultTransactionID := "1579215712984890248"
+
+	tests := []struct {
+		Name               string
+		Ledgers            Accounts
+		AccountID          string
+		TransactionID      string
+		Body               string
+		ExpectedStatusCode int
+	}{
+		{"Refund", paidLedgers, "1", defaultTransactionID, `{"type":"refund","reasonCode":"damagedProduct","operator":"jdoe","note":"dented can"}`, http.StatusOK},
+		{"Refund of line item", accountLedgers, "1", defaultTransactionID, `{"type":"refund","reasonCode":"goodwill","operator":"jdoe","lineItems":[{"sku":"1200050408","itemCount":1}]}`, http.StatusOK},
+		{"Void", accountLedgers, "1", defaultTransactionID, `{"type":"void","reasonCode":"misdetection","operator":"jdoe"}`, http.StatusOK},
+		{"Void of paid transaction", paidLedgers, "1", defaultTransactionID, `{"type":"void","reasonCode":"misdetection","operator":"jdoe"}`, http.StatusConflict},
+		{"Unknown reason code", accountLedgers, "1", defaultTransactionID, `{"type":"refund","reasonCode":"typo","operator":"jdoe"}`, http.StatusBadRequest},
+		{"Invalid body", accountLedgers, "1", defaultTransactionID, `invalid`, http.StatusBadRequest},
+		{"Nonexistent TransactionID", accountLedgers, "1", "1579215712984890249", `{"type":"refund","reasonCode":"goodwill","operator":"jdoe"}`, http.StatusBadRequest},
+		{"Nonexistent AccountID", accountLedgers, "10", defaultTransactionID, `{"type":"refund","reasonCode":"goodwill","operator":"jdoe"}`, http.StatusBadRequest},
+	}
+
+	for _, test := range tests {
+		currentTest := test
+		t.Run(currentTest.Name, func(t *testing.T) {
+			c := Controller{
+				lc:              logger.NewMockClient(),
+				pricingEndpoint: "test.com",
+				ledgerFileName:  LedgerFileName,
+			}
+			data, err := json.Marshal(currentTest.Ledgers)
+			require.NoError(t, err)
+			require.NoError(t, os.WriteFile(c.ledgerFileName, data, 0644))
+			defer func() {
+				os.Remove(c.ledgerFileName)
+			}()
+
+			req := httptest.NewRequest("POST", "http://localhost:48093/ledger/"+currentTest.AccountID+"/"+currentTest.TransactionID+"/adjustment", bytes.NewBuffer([]byte(currentTest.Body)))
+			req = mux.SetURLVars(req, map[string]string{"accountid": currentTest.AccountID, "tid": currentTest.TransactionID})
+			w := httptest.NewRecorder()
+			c.LedgerAdjustmentPost(w, req)
+			resp := w.Result()
+			defer resp.Body.Close()
+
+			require.Equal(t, currentTest.ExpectedStatusCode, resp.StatusCode, "invalid status code")
+
+			accountsFromFile, err := c.GetAllLedgers()
+			require.NoError(t, err)
+			if resp.StatusCode != http.StatusOK {
+				assert.Equal(t, currentTest.Ledgers, accountsFromFile, "Ledgers should match")
+				return
+			}
+
+			var adjustment Ledger
+			require.NoError(t, json.NewDecoder(resp.Body).Decode(&adjustment))
+			assert.Equal(t, int64(1579215712984890248), adjustment.AdjustsTransactionID)
+			assert.Equal(t, int64(-199), adjustment.GrandTotal)
+			require.Len(t, accountsFromFile.Data[0].Ledgers, 2)
+			assert.Equal(t, currentTest.Ledgers.Data[0].Ledgers[0], accountsFromFile.Data[0].Ledgers[0], "the adjusted transaction should not change")
+			assert.Equal(t, adjustment, accountsFromFile.Data[0].Ledgers[1])
+			assert.Equal(t, map[string]int64{"USD": 0}, netCharges(accountsFromFile.Data[0]))
 		})
 	}
 }
@@ -0,0 +1,294 @@
+// Copyright © 2026 Intel Corporation. All rights reserved.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package routes
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Types of statement entries that are not adjustments
+const (
+	StatementEntrySale    = "sale"
+	StatementEntryPayment = "payment"
+)
+
+const (
+	statementFormatJSON   = "json"
+	statementFormatCSV    = "csv"
+	statementPeriodLayout = "2006-01"
+	statementDateLayout   = "2006-01-02"
+	statementFileSuffix   = ".json"
+)
+
+// paidAt returns when a transaction was paid. Transactions that were paid
+// before PaidAt was recorded fall back to their last update
+func (ledger *Ledger) paidAt() int64 {
+	if ledger.PaidAt != 0 {
+		return ledger.PaidAt
+	}
+	return ledger.UpdatedAt
+}
+
+// buildStatement returns the statement of the account's transactions in the
+// currency between from and to. The balance is the amount owed by the
+// account: sales and adjustments are added to it when they are made, and
+// their grand total is taken off it again when they are paid
+func buildStatement(account Account, currency string, from int64, to int64, now int64) Statement {
+	statement := Statement{
+		AccountID:    account.AccountID,
+		Currency:     currency,
+		From:         from,
+		To:           to,
+		Transactions: []StatementEntry{},
+		Payments:     []StatementEntry{},
+		Adjustments:  []StatementEntry{},
+		GeneratedAt:  now,
+	}
+
+	for _, ledger := range account.Ledgers {
+		if ledger.Currency != currency {
+			continue
+		}
+		if ledger.TxTimeStamp < from {
+			statement.OpeningBalance += ledger.GrandTotal
+		} else if ledger.TxTimeStamp < to {
+			entry := StatementEntry{
+				TransactionID:        ledger.TransactionID,
+				Type:                 ledger.Type,
+				AdjustsTransactionID: ledger.AdjustsTransactionID,
+				ReasonCode:           ledger.ReasonCode,
+				TimeStamp:            ledger.TxTimeStamp,
+				Amount:               ledger.GrandTotal,
+			}
+			if ledger.Type == "" {
+				entry.Type = StatementEntrySale
+				statement.Transactions = append(statement.Transactions, entry)
+			} else {
+				statement.Adjustments = append(statement.Adjustments, entry)
+			}
+		}
+
+		if !ledger.IsPaid {
+			continue
+		}
+		if paidAt := ledger.paidAt(); paidAt < from {
+			statement.OpeningBalance -= ledger.GrandTotal
+		} else if paidAt < to {
+			statement.Payments = append(statement.Payments, StatementEntry{
+				TransactionID: ledger.TransactionID,
+				Type:          StatementEntryPayment,
+				TimeStamp:     paidAt,
+				Amount:        -ledger.GrandTotal,
+			})
+		}
+	}
+
+	statement.ClosingBalance = statement.OpeningBalance
+	for _, entries := range [][]StatementEntry{statement.Transactions, statement.Payments, statement.Adjustments} {
+		sort.SliceStable(entries, func(i, j int) bool {
+			return entries[i].TimeStamp < entries[j].TimeStamp
+		})
+		for _, entry := range entries {
+			statement.ClosingBalance += entry.Amount
+		}
+	}
+	return statement
+}
+
+// entries returns every entry of the statement in the order they were made
+func (statement *Statement) entries() []StatementEntry {
+	entries := append([]StatementEntry{}, statement.Transactions...)
+	entries = append(entries, statement.Payments...)
+	entries = append(entries, statement.Adjustments...)
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].TimeStamp < entries[j].TimeStamp
+	})
+	return entries
+}
+
+// formatStatementTime formats a timestamp in nanoseconds as an RFC 3339 UTC
+// date
+func formatStatementTime(timestamp int64) string {
+	return time.Unix(0, timestamp).UTC().Format(time.RFC3339)
+}
+
+// statementCSV returns the statement as comma-separated values with the
+// opening balance as the first row, every entry in the order they were made
+// and the closing balance as the last row. Amounts are in major units of the
+// currency
+func statementCSV(statement Statement) ([]byte, error) {
+	var buffer bytes.Buffer
+	writer := csv.NewWriter(&buffer)
+	rows := [][]string{
+		{"date", "type", "transactionID", "adjustsTransactionID", "reasonCode", "amount", "currency"},
+		{formatStatementTime(statement.From), "openingBalance", "", "", "", formatMinorUnits(statement.OpeningBalance, statement.Currency), statement.Currency},
+	}
+	for _, entry := range statement.entries() {
+		adjustsTransactionID := ""
+		if entry.AdjustsTransactionID != 0 {
+			adjustsTransactionID = strconv.FormatInt(entry.AdjustsTransactionID, 10)
+		}
+		rows = append(rows, []string{
+			formatStatementTime(entry.TimeStamp),
+			entry.Type,
+			strconv.FormatInt(entry.TransactionID, 10),
+			adjustsTransactionID,
+			entry.ReasonCode,
+			formatMinorUnits(entry.Amount, statement.Currency),
+			statement.Currency,
+		})
+	}
+	rows = append(rows, []string{formatStatementTime(statement.To), "closingBalance", "", "", "", formatMinorUnits(statement.ClosingBalance, statement.Currency), statement.Currency})
+
+	if err := writer.WriteAll(rows); err != nil {
+		return nil, fmt.Errorf("failed to write statement CSV: %s", err.Error())
+	}
+	return buffer.Bytes(), nil
+}
+
+// parseStatementTime parses a statement query time, given either as
+// nanoseconds since the epoch like every other timestamp of this service, as
+// an RFC 3339 date or as a UTC day such as 2026-09-01
+func parseStatementTime(value string) (int64, error) {
+	if nanos, err := strconv.ParseInt(value, 10, 64); err == nil {
+		return nanos, nil
+	}
+	if parsed, err := time.Parse(time.RFC3339, value); err == nil {
+		return parsed.UnixNano(), nil
+	}
+	parsed, err := time.Parse(statementDateLayout, value)
+	if err != nil {
+		return 0, fmt.Errorf("%s is neither a timestamp in nanoseconds, an RFC 3339 date nor a day", value)
+	}
+	return parsed.UnixNano(), nil
+}
+
+// monthStart returns the start of the UTC month of a timestamp in nanoseconds
+func monthStart(timestamp int64) time.Time {
+	t := time.Unix(0, timestamp).UTC()
+	return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+}
+
+// statementFileName returns the file name of the stored statement of an
+// account for a month, such as 2026-09, in a currency
+func (c *Controller) statementFileName(accountID int, period string, currency string) string {
+	return filepath.Join(c.statementDir, strconv.Itoa(accountID), period+"-"+currency+statementFileSuffix)
+}
+
+// readStoredStatement returns a stored monthly statement
+func (c *Controller) readStoredStatement(accountID int, period string, currency string) (Statement, error) {
+	var statement Statement
+	data, err := os.ReadFile(c.statementFileName(accountID, period, currency))
+	if err != nil {
+		return statement, err
+	}
+	if err := json.Unmarshal(data, &statement); err != nil {
+		return statement, fmt.Errorf("failed to unmarshal statement of account %d for %s: %s", accountID, period, err.Error())
+	}
+	return statement, nil
+}
+
+// getStoredStatements returns the stored monthly statements of an account,
+// oldest first. An account without a statement directory has no statements
+func (c *Controller) getStoredStatements(accountID int) ([]StoredStatement, error) {
+	storedStatements := []StoredStatement{}
+	dirEntries, err := os.ReadDir(filepath.Join(c.statementDir, strconv.Itoa(accountID)))
+	if errors.Is(err, fs.ErrNotExist) {
+		return storedStatements, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read statement directory of account %d: %s", accountID, err.Error())
+	}
+
+	for _, dirEntry := range dirEntries {
+		name := strings.TrimSuffix(dirEntry.Name(), statementFileSuffix)
+		if dirEntry.IsDir() || name == dirEntry.Name() || len(name) <= len(statementPeriodLayout) {
+			continue
+		}
+		period, currency := name[:len(statementPeriodLayout)], name[len(statementPeriodLayout)+1:]
+		if _, err := time.Parse(statementPeriodLayout, period); err != nil || !validCurrency(currency) {
+			continue
+		}
+		storedStatements = append(storedStatements, StoredStatement{Period: period, Currency: currency})
+	}
+	sort.Slice(storedStatements, func(i, j int) bool {
+		if storedStatements[i].Period != storedStatements[j].Period {
+			return storedStatements[i].Period < storedStatements[j].Period
+		}
+		return storedStatements[i].Currency < storedStatements[j].Currency
+	})
+	return storedStatements, nil
+}
+
+// GenerateMonthlyStatements stores the statement of the month before now for
+// every account and every currency the account made transactions in, and
+// returns the number of statements it stored. Statements that are already
+// stored are kept, so it can run any number of times a month
+func (c *Controller) GenerateMonthlyStatements(now int64) (int, error) {
+	accountLedgers, err := c.GetAllLedgers()
+	if err != nil {
+		return 0, err
+	}
+
+	to := monthStart(now)
+	from := to.AddDate(0, -1, 0)
+	period := from.Format(statementPeriodLayout)
+	generated := 0
+	for _, account := range accountLedgers.Data {
+		currencies := map[string]bool{}
+		for _, ledger := range account.Ledgers {
+			if ledger.TxTimeStamp < to.UnixNano() {
+				currencies[ledger.Currency] = true
+			}
+		}
+
+		for currency := range currencies {
+			fileName := c.statementFileName(account.AccountID, period, currency)
+			if _, err := os.Stat(fileName); err == nil {
+				continue
+			}
+			statement := buildStatement(account, currency, from.UnixNano(), to.UnixNano(), now)
+			data, err := json.Marshal(statement)
+			if err != nil {
+				return generated, fmt.Errorf("failed to marshal statement of account %d for %s: %s", account.AccountID, period, err.Error())
+			}
+			if err := os.MkdirAll(filepath.Dir(fileName), 0755); err != nil {
+				return generated, fmt.Errorf("failed to create statement directory: %s", err.Error())
+			}
+			if err := os.WriteFile(fileName, data, 0644); err != nil {
+				return generated, fmt.Errorf("failed to write statement of account %d for %s: %s", account.AccountID, period, err.Error())
+			}
+			generated++
+		}
+	}
+
+	if generated > 0 {
+		c.lc.Infof("Generated %d monthly statements for %s", generated, period)
+	}
+	return generated, nil
+}
+
+// MonitorMonthlyStatements generates the statements of the previous month on
+// every StatementCheckInterval until the service exits
+func (c *Controller) MonitorMonthlyStatements() {
+	ticker := time.NewTicker(c.statementCheckInterval)
+	defer ticker.Stop()
+	for {
+		if _, err := c.GenerateMonthlyStatements(time.Now().UnixNano()); err != nil {
+			c.lc.Errorf("Failed to generate monthly statements: %s", err.Error())
+		}
+		<-ticker.C
+	}
+}
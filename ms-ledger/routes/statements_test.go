@@ -0,0 +1,239 @@
+// Copyright © 2026 Intel Corporation. All rights reserved.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package routes
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/edgexfoundry/go-mod-core-contracts/v3/clients/logger"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// statementDay returns the timestamp of noon on a UTC day in 2026
+func statementDay(month time.Month, day int) int64 {
+	return time.Date(2026, month, day, 12, 0, 0, 0, time.UTC).UnixNano()
+}
+
+// getStatementAccount returns an account with a sale in August that was paid
+// in September, a sale and its refund in September, a sale in another
+// currency and a sale in October
+func getStatementAccount() Account {
+	return Account{
+		AccountID: 1,
+		Ledgers: []Ledger{
+			{TransactionID: 1, TxTimeStamp: statementDay(time.August, 20), GrandTotal: 500, Currency: "USD", IsPaid: true, PaidAt: statementDay(time.September, 5)},
+			{TransactionID: 2, TxTimeStamp: statementDay(time.September, 10), GrandTotal: 300, Currency: "USD"},
+			{TransactionID: 3, Type: TransactionTypeRefund, AdjustsTransactionID: 2, ReasonCode: ReasonDamagedProduct, TxTimeStamp: statementDay(time.September, 12), GrandTotal: -100, Currency: "USD"},
+			{TransactionID: 4, TxTimeStamp: statementDay(time.September, 15), GrandTotal: 200, Currency: "EUR"},
+			{TransactionID: 5, TxTimeStamp: statementDay(time.October, 2), GrandTotal: 400, Currency: "USD"},
+		},
+	}
+}
+
+func TestBuildStatement(t *testing.T) {
+	from := time.Date(2026, time.September, 1, 0, 0, 0, 0, time.UTC).UnixNano()
+	to := time.Date(2026, time.October, 1, 0, 0, 0, 0, time.UTC).UnixNano()
+
+	statement := buildStatement(getStatementAccount(), "USD", from, to, to)
+
+	assert.Equal(t, int64(500), statement.OpeningBalance)
+	assert.Equal(t, []StatementEntry{{TransactionID: 2, Type: StatementEntrySale, TimeStamp: statementDay(time.September, 10), Amount: 300}}, statement.Transactions)
+	assert.Equal(t, []StatementEntry{{TransactionID: 1, Type: StatementEntryPayment, TimeStamp: statementDay(time.September, 5), Amount: -500}}, statement.Payments)
+	assert.Equal(t, []StatementEntry{{TransactionID: 3, Type: TransactionTypeRefund, AdjustsTransactionID: 2, ReasonCode: ReasonDamagedProduct, TimeStamp: statementDay(time.September, 12), Amount: -100}}, statement.Adjustments)
+	assert.Equal(t, int64(200), statement.ClosingBalance)
+
+	// the closing balance of a period is the opening balance of the next one
+	next := buildStatement(getStatementAccount(), "USD", to, statementDay(time.November, 1), to)
+	assert.Equal(t, statement.ClosingBalance, next.OpeningBalance)
+	assert.Equal(t, int64(600), next.ClosingBalance)
+
+	other := buildStatement(getStatementAccount(), "EUR", from, to, to)
+	assert.Equal(t, int64(0), other.OpeningBalance)
+	assert.Equal(t, int64(200), other.ClosingBalance)
+	assert.Empty(t, other.Payments)
+}
+
+func TestStatementCSV(t *testing.T) {
+	from := time.Date(2026, time.September, 1, 0, 0, 0, 0, time.UTC).UnixNano()
+	to := time.Date(2026, time.October, 1, 0, 0, 0, 0, time.UTC).UnixNano()
+
+	data, err := statementCSV(buildStatement(getStatementAccount(), "USD", from, to, to))
+	require.NoError(t, err)
+	assert.Equal(t, `date,type,transactionID,adjustsTransactionID,reasonCode,amount,currency
+2026-09-01T00:00:00Z,openingBalance,,,,5.00,USD
+2026-09-05T12:00:00Z,payment,1,,,-5.00,USD
+2026-09-10T12:00:00Z,sale,2,,,3.00,USD
+2026-09-12T12:00:00Z,refund,3,2,damagedProduct,-1.00,USD
+2026-10-01T00:00:00Z,closingBalance,,,,2.00,USD
+`, string(data))
+}
+
+func TestParseStatementTime(t *testing.T) {
+	expected := time.Date(2026, time.September, 1, 0, 0, 0, 0, time.UTC).UnixNano()
+	for _, value := range []string{"1788220800000000000", "2026-09-01T00:00:00Z", "2026-09-01"} {
+		parsed, err := parseStatementTime(value)
+		require.NoError(t, err, value)
+		assert.Equal(t, expected, parsed, value)
+	}
+	_, err := parseStatementTime("September")
+	require.Error(t, err)
+}
+
+func TestGenerateMonthlyStatements(t *testing.T) {
+	c := Controller{
+		lc:             logger.NewMockClient(),
+		ledgerFileName: LedgerFileName,
+		statementDir:   t.TempDir(),
+	}
+	data, err := json.Marshal(Accounts{SchemaVersion: ledgerSchemaVersion, Data: []Account{getStatementAccount(), {AccountID: 2, Ledgers: []Ledger{}}}})
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(c.ledgerFileName, data, 0644))
+	defer func() {
+		os.Remove(c.ledgerFileName)
+	}()
+
+	generated, err := c.GenerateMonthlyStatements(statementDay(time.October, 3))
+	require.NoError(t, err)
+	assert.Equal(t, 2, generated, "one statement per currency of account 1")
+
+	generated, err = c.GenerateMonthlyStatements(statementDay(time.October, 4))
+	require.NoError(t, err)
+	assert.Equal(t, 0, generated, "stored statements should be kept")
+
+	storedStatements, err := c.getStoredStatements(1)
+	require.NoError(t, err)
+	assert.Equal(t, []StoredStatement{{"2026-09", "EUR"}, {"2026-09", "USD"}}, storedStatements)
+
+	statement, err := c.readStoredStatement(1, "2026-09", "USD")
+	require.NoError(t, err)
+	assert.Equal(t, int64(500), statement.OpeningBalance)
+	assert.Equal(t, int64(200), statement.ClosingBalance)
+	assert.Equal(t, statementDay(time.October, 3), statement.GeneratedAt)
+
+	storedStatements, err = c.getStoredStatements(2)
+	require.NoError(t, err)
+	assert.Empty(t, storedStatements)
+}
+
+func TestLedgerStatementGet(t *testing.T) {
+	tests := []struct {
+		Name                string
+		AccountID           string
+		Query               string
+		ExpectedStatusCode  int
+		ExpectedContentType string
+	}{
+		{"Valid JSON", "1", "?from=2026-09-01&to=2026-10-01", http.StatusOK, "application/json"},
+		{"Valid CSV", "1", "?from=2026-09-01&to=2026-10-01&format=csv", http.StatusOK, "text/csv"},
+		{"Default period", "1", "", http.StatusOK, "application/json"},
+		{"Invalid format", "1", "?format=xml", http.StatusBadRequest, ""},
+		{"Invalid currency", "1", "?currency=dollars", http.StatusBadRequest, ""},
+		{"Invalid from", "1", "?from=September", http.StatusBadRequest, ""},
+		{"From after to", "1", "?from=2026-10-01&to=2026-09-01", http.StatusBadRequest, ""},
+		{"Invalid AccountID", "one", "", http.StatusBadRequest, ""},
+		{"Nonexistent AccountID", "10", "", http.StatusBadRequest, ""},
+	}
+
+	for _, test := range tests {
+		currentTest := test
+		t.Run(currentTest.Name, func(t *testing.T) {
+			c := Controller{
+				lc:             logger.NewMockClient(),
+				ledgerFileName: LedgerFileName,
+			}
+			data, err := json.Marshal(Accounts{SchemaVersion: ledgerSchemaVersion, Data: []Account{getStatementAccount()}})
+			require.NoError(t, err)
+			require.NoError(t, os.WriteFile(c.ledgerFileName, data, 0644))
+			defer func() {
+				os.Remove(c.ledgerFileName)
+			}()
+
+			req := httptest.NewRequest("GET", "http://localhost:48093/ledger/"+currentTest.AccountID+"/statement"+currentTest.Query, nil)
+			req = mux.SetURLVars(req, map[string]string{"accountid": currentTest.AccountID})
+			w := httptest.NewRecorder()
+			c.LedgerStatementGet(w, req)
+			resp := w.Result()
+			defer resp.Body.Close()
+
+			require.Equal(t, currentTest.ExpectedStatusCode, resp.StatusCode, "invalid status code")
+			if currentTest.ExpectedStatusCode != http.StatusOK {
+				return
+			}
+			assert.Equal(t, currentTest.ExpectedContentType, resp.Header.Get("Content-Type"))
+			if currentTest.ExpectedContentType == "text/csv" {
+				assert.Equal(t, `attachment; filename="statement-1-2026-09-01-2026-10-01.csv"`, resp.Header.Get("Content-Disposition"))
+				return
+			}
+			var statement Statement
+			require.NoError(t, json.NewDecoder(resp.Body).Decode(&statement))
+			assert.Equal(t, 1, statement.AccountID)
+			assert.Equal(t, defaultCurrency, statement.Currency)
+		})
+	}
+}
+
+func TestLedgerStoredStatementGet(t *testing.T) {
+	c := Controller{
+		lc:             logger.NewMockClient(),
+		ledgerFileName: LedgerFileName,
+		statementDir:   t.TempDir(),
+	}
+	data, err := json.Marshal(Accounts{SchemaVersion: ledgerSchemaVersion, Data: []Account{getStatementAccount()}})
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(c.ledgerFileName, data, 0644))
+	defer func() {
+		os.Remove(c.ledgerFileName)
+	}()
+	_, err = c.GenerateMonthlyStatements(statementDay(time.October, 3))
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(c.statementDir, "1", "2026-08-USD.json"), []byte("invalid json test"), 0644))
+
+	tests := []struct {
+		Name               string
+		Period             string
+		Query              string
+		ExpectedStatusCode int
+	}{
+		{"Valid JSON", "2026-09", "", http.StatusOK},
+		{"Valid CSV", "2026-09", "?format=csv&currency=EUR", http.StatusOK},
+		{"Invalid stored statement", "2026-08", "", http.StatusInternalServerError},
+		{"Missing statement", "2026-07", "", http.StatusNotFound},
+		{"Invalid period", "september", "", http.StatusBadRequest},
+		{"Invalid format", "2026-09", "?format=xml", http.StatusBadRequest},
+	}
+
+	for _, test := range tests {
+		currentTest := test
+		t.Run(currentTest.Name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "http://localhost:48093/ledger/1/statements/"+currentTest.Period+currentTest.Query, nil)
+			req = mux.SetURLVars(req, map[string]string{"accountid": "1", "period": currentTest.Period})
+			w := httptest.NewRecorder()
+			c.LedgerStoredStatementGet(w, req)
+			resp := w.Result()
+			defer resp.Body.Close()
+
+			assert.Equal(t, currentTest.ExpectedStatusCode, resp.StatusCode, "invalid status code")
+		})
+	}
+
+	req := httptest.NewRequest("GET", "http://localhost:48093/ledger/1/statements", nil)
+	req = mux.SetURLVars(req, map[string]string{"accountid": "1"})
+	w := httptest.NewRecorder()
+	c.LedgerStatementsGet(w, req)
+	resp := w.Result()
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	assert.JSONEq(t, `[{"period":"2026-08","currency":"USD"},{"period":"2026-09","currency":"EUR"},{"period":"2026-09","currency":"USD"}]`, string(body))
+}
@@ -0,0 +1,89 @@
+// Copyright © 2026 Intel Corporation. All rights reserved.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package routes
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// anyTaxCategory is the category of a tax rule that applies to every product
+// of its jurisdiction without a rule for its own category
+const anyTaxCategory = "*"
+
+// taxRule is the sales tax rate, in percent, of a product tax category in a
+// jurisdiction
+type taxRule struct {
+	jurisdiction string
+	category     string
+	rate         float64
+}
+
+// parseTaxRule parses a tax rule of the TaxRules setting, which has the form
+// <jurisdiction>/<category>=<rate in percent>, such as US-CA/*=7.25
+func parseTaxRule(value string) (taxRule, error) {
+	scope, rate, ok := strings.Cut(strings.TrimSpace(value), "=")
+	if !ok {
+		return taxRule{}, fmt.Errorf("tax rule %q is not of the form <jurisdiction>/<category>=<rate>", value)
+	}
+	jurisdiction, category, ok := strings.Cut(scope, "/")
+	if !ok || jurisdiction == "" || category == "" {
+		return taxRule{}, fmt.Errorf("tax rule %q is not of the form <jurisdiction>/<category>=<rate>", value)
+	}
+	percent, err := strconv.ParseFloat(rate, 64)
+	if err != nil || percent < 0 || percent > 100 {
+		return taxRule{}, fmt.Errorf("tax rule %q does not have a rate between 0 and 100 percent", value)
+	}
+	return taxRule{jurisdiction: jurisdiction, category: category, rate: percent}, nil
+}
+
+// taxRate returns the tax rate, in percent, of a product tax category in the
+// configured jurisdiction. A rule for the category itself wins over a rule
+// for any category, and products without a rule are not taxed
+func (c *Controller) taxRate(category string) float64 {
+	rate := 0.0
+	for _, rule := range c.taxRules {
+		if rule.jurisdiction != c.taxJurisdiction {
+			continue
+		}
+		if category != "" && rule.category == category {
+			return rule.rate
+		}
+		if rule.category == anyTaxCategory {
+			rate = rule.rate
+		}
+	}
+	return rate
+}
+
+// taxAmount returns the tax on an amount in minor units at a rate in
+// percent, rounded half away from zero to whole minor units. The rate is
+// applied in millionths so that the result is exact for rates with up to
+// four decimal places
+func taxAmount(amount int64, rate float64) int64 {
+	millionths := int64(math.Round(rate * 10000))
+	if amount < 0 {
+		return -taxAmount(-amount, rate)
+	}
+	return (amount*millionths + 500000) / 1000000
+}
+
+// applyTaxes sets the tax rate and amount of every line item of the ledger,
+// and its subtotal, tax total and grand total. Tax is rounded per line item
+func (c *Controller) applyTaxes(ledger *Ledger) {
+	ledger.TaxJurisdiction = c.taxJurisdiction
+	ledger.Subtotal = 0
+	ledger.TaxTotal = 0
+	for i := range ledger.LineItems {
+		lineItem := &ledger.LineItems[i]
+		lineItem.TaxRate = c.taxRate(lineItem.TaxCategory)
+		lineItem.Tax = taxAmount(lineItem.LineTotal, lineItem.TaxRate)
+		ledger.Subtotal += lineItem.LineTotal
+		ledger.TaxTotal += lineItem.Tax
+	}
+	ledger.LineTotal = ledger.Subtotal
+	ledger.GrandTotal = ledger.Subtotal + ledger.TaxTotal
+}
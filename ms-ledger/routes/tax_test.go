@@ -0,0 +1,97 @@
+// Copyright © 2026 Intel Corporation. All rights reserved.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package routes
+
+import (
+	"testing"
+
+	"github.com/edgexfoundry/go-mod-core-contracts/v3/clients/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseTaxRule(t *testing.T) {
+	tests := []struct {
+		Name        string
+		Value       string
+		Expected    taxRule
+		ExpectError bool
+	}{
+		{"any category", "US-CA/*=7.25", taxRule{"US-CA", "*", 7.25}, false},
+		{"category with spaces", " US-CA/food=0 ", taxRule{"US-CA", "food", 0}, false},
+		{"missing rate", "US-CA/*", taxRule{}, true},
+		{"missing category", "US-CA=7.25", taxRule{}, true},
+		{"empty jurisdiction", "/*=7.25", taxRule{}, true},
+		{"invalid rate", "US-CA/*=seven", taxRule{}, true},
+		{"negative rate", "US-CA/*=-1", taxRule{}, true},
+	}
+
+	for _, test := range tests {
+		currentTest := test
+		t.Run(currentTest.Name, func(t *testing.T) {
+			rule, err := parseTaxRule(currentTest.Value)
+			if currentTest.ExpectError {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, currentTest.Expected, rule)
+		})
+	}
+}
+
+func TestTaxAmount(t *testing.T) {
+	tests := []struct {
+		Amount   int64
+		Rate     float64
+		Expected int64
+	}{
+		{159, 7.25, 12},
+		{200, 7.25, 15},
+		{1000, 8.875, 89},
+		{398, 0, 0},
+		{-200, 7.25, -15},
+	}
+
+	for _, test := range tests {
+		assert.Equal(t, test.Expected, taxAmount(test.Amount, test.Rate), "tax of %d at %v%%", test.Amount, test.Rate)
+	}
+}
+
+func TestApplyTaxes(t *testing.T) {
+	c := Controller{
+		lc:              logger.NewMockClient(),
+		taxJurisdiction: "US-CA",
+		taxRules: []taxRule{
+			{"US-CA", anyTaxCategory, 7.25},
+			{"US-CA", "food", 0},
+			{"US-OR", anyTaxCategory, 0},
+		},
+	}
+
+	ledger := Ledger{
+		LineItems: []LineItem{
+			{SKU: "4900002470", LineTotal: 398, TaxCategory: "beverage"},
+			{SKU: "4900002525", LineTotal: 199, TaxCategory: "food"},
+			{SKU: "1200050408", LineTotal: 159},
+		},
+	}
+	c.applyTaxes(&ledger)
+
+	assert.Equal(t, "US-CA", ledger.TaxJurisdiction)
+	assert.Equal(t, 7.25, ledger.LineItems[0].TaxRate, "a category without its own rule gets the rule for any category")
+	assert.Equal(t, int64(29), ledger.LineItems[0].Tax)
+	assert.Equal(t, 0.0, ledger.LineItems[1].TaxRate)
+	assert.Equal(t, int64(0), ledger.LineItems[1].Tax)
+	assert.Equal(t, int64(12), ledger.LineItems[2].Tax)
+	assert.Equal(t, int64(756), ledger.Subtotal)
+	assert.Equal(t, int64(756), ledger.LineTotal)
+	assert.Equal(t, int64(41), ledger.TaxTotal)
+	assert.Equal(t, int64(797), ledger.GrandTotal)
+
+	c.taxJurisdiction = "US-WA"
+	c.applyTaxes(&ledger)
+	assert.Equal(t, int64(0), ledger.TaxTotal, "a jurisdiction without rules is not taxed")
+	assert.Equal(t, int64(756), ledger.GrandTotal)
+}
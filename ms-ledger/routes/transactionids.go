@@ -0,0 +1,73 @@
+// Copyright © 2026 Intel Corporation. All rights reserved.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package routes
+
+import (
+	"errors"
+	"fmt"
+)
+
+const (
+	// transactionNodeIDs is the number of ledger services that can create
+	// transactions without colliding. The node ID of a service is the last
+	// three digits of every transaction ID it creates
+	transactionNodeIDs = 1000
+	// idempotencyKeyHeader is the request header that makes posting a
+	// transaction idempotent
+	idempotencyKeyHeader = "Idempotency-Key"
+	// idempotentReplayHeader is set on the response to a replayed request
+	idempotentReplayHeader = "Idempotent-Replayed"
+	// maxIdempotencyKeyLength is the longest idempotency key accepted
+	maxIdempotencyKeyLength = 255
+)
+
+// errIdempotencyKeyReused is returned when an idempotency key is replayed
+// for another account than the one it was first used for
+var errIdempotencyKeyReused = errors.New("idempotency key was used for another account")
+
+// nextTransactionID returns a new transaction ID. Transaction IDs are the time
+// they were created in nanoseconds, with the last three digits replaced by
+// the transactionNodeID of this service. Every ID is greater than the IDs of
+// all transactions in the ledgers and every ID returned before, so they sort
+// in the order they were created even when the clock has not moved on. The
+// caller must hold ledgerMutex
+func (c *Controller) nextTransactionID(accountLedgers Accounts, now int64) int64 {
+	floor := c.lastTransactionID
+	for _, account := range accountLedgers.Data {
+		for _, ledger := range account.Ledgers {
+			if ledger.TransactionID > floor {
+				floor = ledger.TransactionID
+			}
+		}
+	}
+
+	transactionID := now - now%transactionNodeIDs + c.transactionNodeID
+	if transactionID <= floor {
+		transactionID = floor - floor%transactionNodeIDs + c.transactionNodeID
+		if transactionID <= floor {
+			transactionID += transactionNodeIDs
+		}
+	}
+	c.lastTransactionID = transactionID
+	return transactionID
+}
+
+// findIdempotentTransaction returns the transaction that was posted with the
+// idempotency key within the idempotency retention before now. A key that was
+// used for another account is an error
+func (c *Controller) findIdempotentTransaction(accountLedgers Accounts, accountID int, idempotencyKey string, now int64) (Ledger, bool, error) {
+	cutoff := now - c.idempotencyRetention.Nanoseconds()
+	for _, account := range accountLedgers.Data {
+		for _, ledger := range account.Ledgers {
+			if ledger.IdempotencyKey != idempotencyKey || ledger.CreatedAt < cutoff {
+				continue
+			}
+			if account.AccountID != accountID {
+				return Ledger{}, false, fmt.Errorf("%w: %s", errIdempotencyKeyReused, idempotencyKey)
+			}
+			return ledger, true, nil
+		}
+	}
+	return Ledger{}, false, nil
+}
@@ -0,0 +1,56 @@
+// Copyright © 2026 Intel Corporation. All rights reserved.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package routes
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNextTransactionID(t *testing.T) {
+	c := Controller{transactionNodeID: 42}
+	accountLedgers := getDefaultAccountLedgers()
+	now := int64(2579215712984990999)
+
+	first := c.nextTransactionID(accountLedgers, now)
+	assert.Equal(t, int64(2579215712984990042), first, "the last three digits are the node ID")
+
+	second := c.nextTransactionID(accountLedgers, now)
+	assert.Equal(t, int64(2579215712984991042), second, "IDs created at the same time should not collide")
+
+	// an ID is always greater than every transaction in the ledgers, even
+	// when the clock is behind them
+	behind := c.nextTransactionID(getDefaultAccountLedgers(), 1000)
+	assert.Greater(t, behind, second)
+	assert.Equal(t, int64(42), behind%transactionNodeIDs)
+
+	other := Controller{transactionNodeID: 7}
+	assert.NotEqual(t, first, other.nextTransactionID(accountLedgers, now), "services with other node IDs should not collide")
+}
+
+func TestFindIdempotentTransaction(t *testing.T) {
+	c := Controller{idempotencyRetention: time.Hour}
+	accountLedgers := getDefaultAccountLedgers()
+	accountLedgers.Data[0].Ledgers[0].IdempotencyKey = "session-1"
+	createdAt := accountLedgers.Data[0].Ledgers[0].CreatedAt
+
+	original, found, err := c.findIdempotentTransaction(accountLedgers, 1, "session-1", createdAt+time.Minute.Nanoseconds())
+	require.NoError(t, err)
+	require.True(t, found)
+	assert.Equal(t, accountLedgers.Data[0].Ledgers[0], original)
+
+	_, found, err = c.findIdempotentTransaction(accountLedgers, 1, "session-1", createdAt+2*time.Hour.Nanoseconds())
+	require.NoError(t, err)
+	assert.False(t, found, "keys older than the retention should be ignored")
+
+	_, found, err = c.findIdempotentTransaction(accountLedgers, 1, "session-2", createdAt)
+	require.NoError(t, err)
+	assert.False(t, found)
+
+	_, _, err = c.findIdempotentTransaction(accountLedgers, 2, "session-1", createdAt)
+	require.ErrorIs(t, err, errIdempotencyKeyReused)
+}